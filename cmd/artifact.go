@@ -16,12 +16,24 @@ package cmd
 
 import (
 	"context"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 
 	commonoptions "github.com/falcosecurity/falcoctl/pkg/options"
 )
 
+var (
+	// stateFile keeps track of the artifacts installed by falcoctl.
+	stateFile = filepath.Join(falcoctlPath, "state.yaml")
+	// metricsFile keeps the counters of the operations performed by falcoctl.
+	metricsFile = filepath.Join(falcoctlPath, "metrics.yaml")
+	// pinsFile locks the installed artifacts to their digests, see "artifact pin-all".
+	pinsFile = filepath.Join(falcoctlPath, "falcoctl-pins.yaml")
+	// annotationLocksFile records the artifact manifests whose annotations are locked, see "artifact lock-annotations".
+	annotationLocksFile = filepath.Join(falcoctlPath, "annotation-locks.yaml")
+)
+
 // NewArtifactCmd return the artifact command.
 func NewArtifactCmd(ctx context.Context, opt *commonoptions.CommonOptions) *cobra.Command {
 	cmd := &cobra.Command{
@@ -33,7 +45,57 @@ func NewArtifactCmd(ctx context.Context, opt *commonoptions.CommonOptions) *cobr
 
 	cmd.AddCommand(NewArtifactSearchCmd(ctx, opt))
 	cmd.AddCommand(NewArtifactInstallCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactInstallFromURLCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactInstallFromConfigCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactInstallHookCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactMissingDepsCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactImportFromDockerHubCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactForkCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactRenameCmd(ctx, opt))
 	cmd.AddCommand(NewArtifactInfoCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactCompareCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactExtractMetadataCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactFetchConfigCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactFetchReadmeCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactCoverageCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactOpenCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactCheckUpdatesCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactAutoUpdateCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactNeedsUpdateCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactLatestVersionCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactCrossPlatformCheckCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactCheckAPIVersionCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactListCompatibleCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactPromoteStableCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactTagFromSemverCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactSemverNextCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactSetAnnotationCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactLockAnnotationsCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactFetchAllVersionsCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactReferrersCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactInstalledVersionCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactPathCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactRepairCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactHashCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactSecurityScanCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactMetricsCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactHelmValuesCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactGithubActionCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactTektonPipelineCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactSbomCheckCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactComplianceReportCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactProvenanceVerifyCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactInTotoVerifyCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactAutoSignCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactSignAllTagsCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactBulkSignCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactVerifyAllTagsCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactPublishToIndexCmd(ctx, opt))
+	cmd.AddCommand(NewArtifactPinAllCmd(opt))
+	cmd.AddCommand(NewArtifactUnpinAllCmd(opt))
+	cmd.AddCommand(NewArtifactGarbageCollectStateCmd(opt))
+	cmd.AddCommand(NewArtifactShowStateCmd(opt))
+	cmd.AddCommand(NewArtifactDeprecationMigrateCmd(ctx, opt))
 
 	return cmd
 }
@@ -0,0 +1,193 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/signature"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+var longAutoSign = `Sign the installed artifacts lacking a valid signature
+
+For each installed artifact, the installed version is checked for a cosign signature valid for
+the public key matching --key, either in the "sha256-<digest>.sig" tag or among its referrers.
+The artifacts lacking one are signed and the signature is pushed to the "sha256-<digest>.sig" tag,
+next to the signatures already stored there. Artifacts installed from a URL are skipped.
+
+The key must be an unencrypted PEM private key (ECDSA, RSA or Ed25519): encrypted cosign keys,
+as generated by "cosign generate-key-pair", are not supported.
+
+Example - Show which installed artifacts would be signed:
+	falcoctl artifact auto-sign --key cosign.pem --dry-run
+
+Example - Sign the unsigned installed artifacts:
+	falcoctl artifact auto-sign --key cosign.pem
+`
+
+type artifactAutoSignOptions struct {
+	*options.CommonOptions
+	key    string
+	dryRun bool
+}
+
+func (o *artifactAutoSignOptions) validate() error {
+	if o.key == "" {
+		return fmt.Errorf("--key must be set")
+	}
+	return nil
+}
+
+// NewArtifactAutoSignCmd returns the artifact auto-sign command.
+func NewArtifactAutoSignCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactAutoSignOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "auto-sign --key keyfile [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Sign the installed artifacts lacking a valid signature",
+		Long:                  longAutoSign,
+		Args:                  cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactAutoSign(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.key, "key", "", "PEM private key used to sign the artifacts")
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", false, "only report the artifacts that would be signed")
+
+	return cmd
+}
+
+// RunArtifactAutoSign executes the business logic for the artifact auto-sign command.
+func (o *artifactAutoSignOptions) RunArtifactAutoSign(ctx context.Context, args []string) error {
+	signer, err := signature.LoadPrivateKey(o.key)
+	if err != nil {
+		return err
+	}
+
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return err
+	}
+
+	if len(installedState.Entries) == 0 {
+		o.Printer.Info.Println("No installed artifact to sign")
+		return nil
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	var signed, failed int
+	for i := range installedState.Entries {
+		entry := &installedState.Entries[i]
+
+		if entry.URL != "" {
+			o.Printer.Info.Printfln("Skipping %q: installed from %q", entry.Name, entry.URL)
+			continue
+		}
+
+		ok, err := o.autoSign(ctx, credentialStore, signer, entry)
+		if err != nil {
+			o.Printer.Warning.Printfln("cannot sign %q: %s", entry.Name, err.Error())
+			failed++
+			continue
+		}
+		if ok {
+			signed++
+		}
+	}
+
+	verb := "Signed"
+	if o.dryRun {
+		verb = "Would sign"
+	}
+	o.Printer.Success.Printfln("%s %d of %d installed artifacts", verb, signed, len(installedState.Entries))
+
+	if failed > 0 {
+		return fmt.Errorf("unable to sign %d installed artifacts", failed)
+	}
+
+	return nil
+}
+
+// autoSign signs the installed version of an artifact if it lacks a valid signature. It returns true if
+// the artifact has been signed, or would have been in dry-run mode.
+func (o *artifactAutoSignOptions) autoSign(ctx context.Context, credentialStore *authn.Store,
+	signer crypto.Signer, entry *state.Entry) (bool, error) {
+	parsedRef, err := registry.ParseReference(entry.Ref)
+	if err != nil {
+		return false, err
+	}
+	// Pin the installed version, the tag may have been moved since.
+	parsedRef.Reference = entry.Digest
+	ref := parsedRef.String()
+
+	reg, err := utils.GetRegistryFromRef(ref)
+	if err != nil {
+		return false, err
+	}
+
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return false, err
+	}
+	client := authn.NewClient(cred)
+
+	o.Printer.Verbosef("Checking signatures of %q", ref)
+	valid, err := signature.HasValidSignature(ctx, ref, client, entry.Digest, signer.Public())
+	switch {
+	case valid:
+		o.Printer.Info.Printfln("%q is already signed", entry.Name)
+		return false, nil
+	case errors.Is(err, oci.ErrIncompleteReferrers):
+		o.Printer.Warning.Printfln("the signatures of %q may be incomplete: %s", entry.Name, err.Error())
+	case err != nil:
+		return false, err
+	}
+
+	if o.dryRun {
+		o.Printer.Info.Printfln("%q would be signed", entry.Name)
+		return true, nil
+	}
+
+	o.Printer.Info.Printfln("Signing %q", entry.Name)
+	desc, err := signature.Attach(ctx, ref, client, entry.Digest, signer)
+	if err != nil {
+		return false, err
+	}
+	o.Printer.Success.Printfln("Signed %q, signature pushed to %s (%s)", entry.Name, signature.SignatureTag(entry.Digest), desc.Digest)
+
+	return true, nil
+}
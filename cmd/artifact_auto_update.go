@@ -0,0 +1,322 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+var longAutoUpdate = `Update the installed artifacts, meant to be run by cron or by a scheduled CI job
+
+Each installed artifact is updated within the constraint of its installed tag: an artifact installed
+with a semver version, e.g. 1.2.0, is updated to the highest release having the same major version,
+or the same minor version for 0.x releases, while an artifact installed with any other tag, e.g. latest,
+follows that tag. Artifacts pinned by "artifact pin-all", installed by digest or from a URL are not updated.
+
+A report of the run is appended as a JSON line to the file set by --log-file, and it is sent with a
+POST request to the URL set by --notify-webhook when at least one artifact has been updated.
+The command exits with code 0 even if no update is applied or some updates fail, failures are
+reported as warnings and in the report: use --strict to exit with a non-zero exit code on failures.
+
+Example - Update all the installed artifacts, logging the report:
+	falcoctl artifact auto-update --log-file /var/log/falcoctl-auto-update.log
+
+Example - Update the installed rulesfiles and notify a webhook:
+	falcoctl artifact auto-update --type rulesfile --notify-webhook https://hooks.example.com/falco
+`
+
+const (
+	autoUpdateUpdated  = "updated"
+	autoUpdateUpToDate = "up-to-date"
+	autoUpdatePinned   = "pinned"
+	autoUpdateSkipped  = "skipped"
+	autoUpdateFailed   = "failed"
+)
+
+// autoUpdateWebhookTimeout is the maximum time allowed to the webhook to answer.
+const autoUpdateWebhookTimeout = 30 * time.Second
+
+type autoUpdateResult struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	FromVersion string `json:"fromVersion,omitempty"`
+	ToVersion   string `json:"toVersion,omitempty"`
+	FromDigest  string `json:"fromDigest,omitempty"`
+	ToDigest    string `json:"toDigest,omitempty"`
+	Status      string `json:"status"`
+	Message     string `json:"message,omitempty"`
+}
+
+type autoUpdateReport struct {
+	Timestamp string             `json:"timestamp"`
+	Updated   int                `json:"updated"`
+	Failed    int                `json:"failed"`
+	Artifacts []autoUpdateResult `json:"artifacts"`
+}
+
+type artifactAutoUpdateOptions struct {
+	*options.CommonOptions
+	artifactType  oci.ArtifactType
+	logFile       string
+	notifyWebhook string
+}
+
+// NewArtifactAutoUpdateCmd returns the artifact auto-update command.
+func NewArtifactAutoUpdateCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactAutoUpdateOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "auto-update [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Update the installed artifacts, meant to be run by cron or by a scheduled CI job",
+		Long:                  longAutoUpdate,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactAutoUpdate(ctx, args))
+		},
+	}
+
+	cmd.Flags().Var(&o.artifactType, "type",
+		`update only artifacts of the given type. Allowed values: "rulesfile", "plugin"`)
+	cmd.Flags().StringVar(&o.logFile, "log-file", "", "file where to append the JSON report of the run")
+	cmd.Flags().StringVar(&o.notifyWebhook, "notify-webhook", "",
+		"URL where to POST the JSON report of the run when at least one artifact is updated")
+
+	return cmd
+}
+
+// RunArtifactAutoUpdate executes the business logic for the artifact auto-update command.
+func (o *artifactAutoUpdateOptions) RunArtifactAutoUpdate(ctx context.Context, args []string) error {
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	pins, err := state.LoadPins(pinsFile)
+	if err != nil {
+		return err
+	}
+
+	report := autoUpdateReport{Timestamp: time.Now().Format(timeFormat)}
+	for i := range installedState.Entries {
+		entry := &installedState.Entries[i]
+		if o.artifactType != "" && entry.Type != o.artifactType.String() {
+			continue
+		}
+
+		result := autoUpdateResult{
+			Name:        entry.Name,
+			Type:        entry.Type,
+			FromVersion: installedVersion(entry),
+			FromDigest:  entry.Digest,
+		}
+
+		switch _, pinned := pins.Get(entry.Name); {
+		case entry.URL != "":
+			result.Status = autoUpdateSkipped
+			result.FromVersion = ""
+			result.Message = fmt.Sprintf("installed from %q", entry.URL)
+		case pinned:
+			result.Status = autoUpdatePinned
+		default:
+			o.autoUpdate(ctx, credentialStore, entry, &result)
+		}
+
+		switch result.Status {
+		case autoUpdateUpdated:
+			report.Updated++
+			o.Printer.Success.Printfln("%q updated from %q to %q", entry.Name, result.FromVersion, result.ToVersion)
+		case autoUpdateFailed:
+			report.Failed++
+			o.Printer.Warning.Printfln("cannot update %q: %s", entry.Name, result.Message)
+		default:
+			o.Printer.Verbosef("%q %s", entry.Name, result.Status)
+		}
+
+		report.Artifacts = append(report.Artifacts, result)
+	}
+
+	o.Printer.Info.Printfln("%d artifacts checked, %d updated, %d failed", len(report.Artifacts), report.Updated, report.Failed)
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	if o.logFile != "" {
+		if err = appendLine(o.logFile, data); err != nil {
+			o.Printer.Warning.Printfln("cannot write the report to %q: %s", o.logFile, err.Error())
+		}
+	}
+
+	if o.notifyWebhook != "" && report.Updated > 0 {
+		if err = notifyWebhook(ctx, o.notifyWebhook, data); err != nil {
+			o.Printer.Warning.Printfln("cannot notify %q: %s", o.notifyWebhook, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// autoUpdate updates entry to the highest version allowed by its installed tag, filling result.
+func (o *artifactAutoUpdateOptions) autoUpdate(ctx context.Context, credentialStore *authn.Store, entry *state.Entry,
+	result *autoUpdateResult) {
+	fail := func(err error) {
+		result.Status = autoUpdateFailed
+		result.Message = err.Error()
+	}
+
+	parsedRef, err := registry.ParseReference(entry.Ref)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	if _, err = parsedRef.Digest(); err == nil {
+		result.Status = autoUpdateSkipped
+		result.Message = "installed by digest"
+		return
+	}
+
+	tag := result.FromVersion
+	parsedRef.Reference = ""
+	repo := parsedRef.String()
+
+	// Versions and digests are resolved in the registry the artifact is pulled from.
+	pullRepo, err := rewriteReference(o.Printer, repo)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	client, err := registryClient(ctx, credentialStore, pullRepo)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	// Artifacts installed with a semver version are only updated to compatible versions,
+	// while the other tags, e.g. "latest", are followed.
+	if _, err = semver.Parse(tag); err == nil {
+		versions, err := oci.Versions(ctx, pullRepo, client)
+		if err != nil {
+			fail(err)
+			return
+		}
+		if version, ok := oci.CompatibleVersion(tag, versions); ok {
+			tag = version
+		}
+	}
+
+	// Installed plugins always match the current OS and architecture.
+	desc, err := oci.ResolvePlatform(ctx, fmt.Sprintf("%s:%s", pullRepo, tag), client, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	if desc.Digest.String() == entry.Digest {
+		result.Status = autoUpdateUpToDate
+		return
+	}
+
+	// The state entry is named after the repository, unless it was installed through an index.
+	ref := fmt.Sprintf("%s:%s", repo, tag)
+	if utils.ArtifactName(ref) != entry.Name {
+		ref = fmt.Sprintf("%s:%s", entry.Name, tag)
+	}
+
+	install := artifactInstallOptions{
+		CommonOptions: o.CommonOptions,
+		rulesfilesDir: entry.Dir,
+		pluginsDir:    entry.Dir,
+	}
+	if err = install.RunArtifactInstall(ctx, []string{ref}); err != nil {
+		fail(err)
+		return
+	}
+
+	result.Status = autoUpdateUpdated
+	result.ToVersion = tag
+	result.ToDigest = desc.Digest.String()
+}
+
+// appendLine appends data, followed by a newline, to the file at path, creating it if needed.
+func appendLine(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Clean(path), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// notifyWebhook sends data to url with a POST request.
+func notifyWebhook(ctx context.Context, url string, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, autoUpdateWebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
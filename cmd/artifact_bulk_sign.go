@@ -0,0 +1,216 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/signature"
+)
+
+var longBulkSign = `Sign several artifacts at once
+
+Each reference is resolved to its digest and signed with a cosign signature pushed to the
+"sha256-<digest>.sig" tag, next to the signatures already stored there, unless a signature valid
+for --key is already present. Up to --concurrency artifacts are signed at the same time.
+
+A failure does not stop the command: the artifacts that could not be signed are reported together
+at the end, and the command exits with a non-zero exit code.
+
+The key must be a PEM private key (ECDSA, RSA or Ed25519). Keys encrypted in the legacy PEM format,
+e.g. with "openssl ec -aes256", are decrypted with the passphrase read from the environment variable
+set with --key-env. Encrypted cosign keys, as generated by "cosign generate-key-pair", are not supported.
+
+Example - Sign the plugins of a release:
+	falcoctl artifact bulk-sign --key cosign.pem \
+		ghcr.io/myorg/plugins/plugin/myplugin:0.2.0 ghcr.io/myorg/plugins/ruleset/myplugin:0.2.0
+
+Example - Sign with an encrypted key in CI:
+	KEY_PASSPHRASE=... falcoctl artifact bulk-sign --key cosign.pem --key-env KEY_PASSPHRASE --concurrency 8 \
+		ghcr.io/myorg/plugins/plugin/myplugin:0.2.0 ghcr.io/myorg/plugins/plugin/otherplugin:0.4.1
+`
+
+type artifactBulkSignOptions struct {
+	*options.CommonOptions
+	key         string
+	keyEnv      string
+	concurrency int
+}
+
+func (o *artifactBulkSignOptions) validate() error {
+	if o.key == "" {
+		return fmt.Errorf("--key must be set")
+	}
+	if o.concurrency < 1 {
+		return fmt.Errorf("--concurrency must be greater than 0, got %d", o.concurrency)
+	}
+	return nil
+}
+
+// NewArtifactBulkSignCmd returns the artifact bulk-sign command.
+func NewArtifactBulkSignCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactBulkSignOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "bulk-sign --key keyfile hostname/repo[:tag|@digest]... [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Sign several artifacts at once",
+		Long:                  longBulkSign,
+		Args:                  cobra.MinimumNArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactBulkSign(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.key, "key", "", "PEM private key used to sign the artifacts")
+	cmd.Flags().StringVar(&o.keyEnv, "key-env", "",
+		"environment variable holding the passphrase of the private key, if encrypted")
+	cmd.Flags().IntVar(&o.concurrency, "concurrency", 4, "maximum number of artifacts signed at the same time")
+
+	return cmd
+}
+
+// RunArtifactBulkSign executes the business logic for the artifact bulk-sign command.
+func (o *artifactBulkSignOptions) RunArtifactBulkSign(ctx context.Context, args []string) error {
+	var passphrase []byte
+	if o.keyEnv != "" {
+		value, ok := os.LookupEnv(o.keyEnv)
+		if !ok {
+			return fmt.Errorf("environment variable %q set with --key-env is not defined", o.keyEnv)
+		}
+		passphrase = []byte(value)
+	}
+
+	signer, err := signature.LoadPrivateKeyWithPassphrase(o.key, passphrase)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	// The references and their clients are prepared upfront, so that the credentials are read once.
+	refs := make([]string, len(args))
+	clients := make([]*auth.Client, len(args))
+	errs := make([]error, len(args))
+	for i, arg := range args {
+		if refs[i], errs[i] = normalizeReference(o.Printer, arg); errs[i] != nil {
+			continue
+		}
+		clients[i], errs[i] = registryClient(ctx, credentialStore, refs[i])
+	}
+
+	sem := make(chan struct{}, o.concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var signed, skipped int
+	for i := range refs {
+		if errs[i] != nil {
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			var ok bool
+			ok, errs[i] = o.signArtifact(ctx, refs[i], clients[i], signer)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errs[i] != nil:
+			case ok:
+				signed++
+			default:
+				skipped++
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var failures []string
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", args[i], err.Error()))
+		}
+	}
+
+	o.Printer.Success.Printfln("Signed %d artifact(s), %d already signed", signed, skipped)
+
+	if len(failures) > 0 {
+		o.Printer.Error.Printfln("Unable to sign %d artifact(s):\n%s", len(failures), strings.Join(failures, "\n"))
+		return fmt.Errorf("unable to sign %d of %d artifact(s)", len(failures), len(args))
+	}
+
+	return nil
+}
+
+// signArtifact signs the artifact pointed by ref if it lacks a valid signature.
+// It returns true if the artifact has been signed.
+func (o *artifactBulkSignOptions) signArtifact(ctx context.Context, ref string, client *auth.Client, signer crypto.Signer) (bool, error) {
+	desc, err := oci.Resolve(ctx, ref, client)
+	if err != nil {
+		return false, err
+	}
+	d := desc.Digest.String()
+
+	pinned, err := oci.PinReference(ref, d)
+	if err != nil {
+		return false, err
+	}
+
+	o.Printer.Verbosef("Checking signatures of %q", pinned)
+	valid, err := signature.HasValidSignature(ctx, pinned, client, d, signer.Public())
+	switch {
+	case valid:
+		o.Printer.Info.Printfln("Skipping %q: already signed", ref)
+		return false, nil
+	case errors.Is(err, oci.ErrIncompleteReferrers):
+		o.Printer.Warning.Printfln("the signatures of %q may be incomplete: %s", ref, err.Error())
+	case err != nil:
+		return false, err
+	}
+
+	o.Printer.Info.Printfln("Signing %q (%s)", ref, d)
+	sig, err := signature.Attach(ctx, pinned, client, d, signer)
+	if err != nil {
+		return false, err
+	}
+	o.Printer.Success.Printfln("Signed %q, signature pushed to %s (%s)", ref, signature.SignatureTag(d), sig.Digest)
+
+	return true, nil
+}
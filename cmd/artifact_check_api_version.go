@@ -0,0 +1,166 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/compatibility"
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+// compatibilityFile holds the compatibility matrix refreshed by "falcoctl index update".
+var compatibilityFile = filepath.Join(falcoctlPath, "compatibility.yaml")
+
+var longCheckAPIVersion = `Check whether a plugin is compatible with a Falco release
+
+The plugin API version required by the plugin is read from the "` + oci.PluginAPIVersionAnnotation + `"
+manifest annotation or, if missing, from the "` + oci.PluginAPIVersionRequirement + `" requirement stored in the config layer.
+It is then compared with the plugin API version provided by the Falco release, as reported by the
+compatibility matrix bundled with falcoctl and refreshed by "falcoctl index update".
+If --falco-version is not set, the version of the "falco" binary found in PATH is used.
+The command exits with code 1 if the plugin is not compatible.
+
+Example - Check the "cloudtrail" plugin against the installed Falco:
+	falcoctl artifact check-api-version cloudtrail
+
+Example - Check a plugin, given its reference, against Falco 0.34.1:
+	falcoctl artifact check-api-version ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.6.0 --falco-version 0.34.1
+`
+
+type artifactCheckAPIVersionOptions struct {
+	*options.CommonOptions
+	falcoVersion string
+}
+
+// NewArtifactCheckAPIVersionCmd returns the artifact check-api-version command.
+func NewArtifactCheckAPIVersionCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactCheckAPIVersionOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "check-api-version name|ref [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Check whether a plugin is compatible with a Falco release",
+		Long:                  longCheckAPIVersion,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactCheckAPIVersion(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.falcoVersion, "falco-version", "",
+		"Falco version to check against, detected from the falco binary in PATH if not set")
+
+	return cmd
+}
+
+// RunArtifactCheckAPIVersion executes the business logic for the artifact check-api-version command.
+func (o *artifactCheckAPIVersionOptions) RunArtifactCheckAPIVersion(ctx context.Context, args []string) error {
+	indexConfig, err := index.NewConfig(indexesFile)
+	if err != nil {
+		return err
+	}
+
+	mergedIndexes, err := utils.Indexes(indexConfig, falcoctlPath)
+	if err != nil {
+		return err
+	}
+
+	ref, err := utils.ParseReference(mergedIndexes, args[0])
+	if err != nil {
+		return err
+	}
+
+	reg, err := utils.GetRegistryFromRef(ref)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return err
+	}
+	client := authn.NewClient(cred)
+
+	manifest, err := oci.FetchManifest(ctx, ref, client, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+
+	required := manifest.Annotations[oci.PluginAPIVersionAnnotation]
+	if required == "" {
+		config, err := oci.FetchArtifactConfig(ctx, ref, client, manifest)
+		if err != nil {
+			return err
+		}
+		var ok bool
+		if required, ok = config.Requirement(oci.PluginAPIVersionRequirement); !ok {
+			return fmt.Errorf("no plugin API version found for %q, is it a plugin?", ref)
+		}
+	}
+
+	falcoVersion := o.falcoVersion
+	if falcoVersion == "" {
+		o.Printer.Verbosef("Detecting Falco version from the falco binary")
+		if falcoVersion, err = utils.FalcoVersion(ctx); err != nil {
+			return fmt.Errorf("unable to detect Falco version, set --falco-version: %w", err)
+		}
+	}
+
+	matrix, err := compatibility.Load(compatibilityFile)
+	if err != nil {
+		return err
+	}
+
+	provided, err := matrix.PluginAPIVersion(falcoVersion)
+	if err != nil {
+		return err
+	}
+
+	compatible, err := compatibility.Compatible(required, provided)
+	if err != nil {
+		return err
+	}
+
+	o.Printer.Info.Printfln("Required plugin API version: %s", required)
+	o.Printer.Info.Printfln("Falco version:               %s", falcoVersion)
+	o.Printer.Info.Printfln("Provided plugin API version: %s", provided)
+
+	if !compatible {
+		o.Printer.Error.Printfln("%q is not compatible with Falco %s", ref, falcoVersion)
+		return output.ErrSilentExit
+	}
+
+	o.Printer.Success.Printfln("%q is compatible with Falco %s", ref, falcoVersion)
+
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+var longCheckUpdates = `Check whether updates are available for the installed artifacts
+
+The digest of each installed artifact is compared against the one of the latest version
+available in the registry. No artifact is installed. The command exits with code 0 if all
+the artifacts are up to date, with code 1 if at least one update is available.
+The artifacts pinned by "artifact pin-all" are marked with ` + pinnedIcon + `.
+
+Example - Check updates for all the installed artifacts:
+	falcoctl artifact check-updates
+
+Example - Check updates only for the installed plugins:
+	falcoctl artifact check-updates --type plugin
+`
+
+type artifactCheckUpdatesOptions struct {
+	*options.CommonOptions
+	artifactType oci.ArtifactType
+}
+
+// NewArtifactCheckUpdatesCmd returns the artifact check-updates command.
+func NewArtifactCheckUpdatesCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactCheckUpdatesOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "check-updates [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Check whether updates are available for the installed artifacts",
+		Long:                  longCheckUpdates,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactCheckUpdates(ctx, args))
+		},
+	}
+
+	cmd.Flags().Var(&o.artifactType, "type",
+		`check only artifacts of the given type. Allowed values: "rulesfile", "plugin"`)
+
+	return cmd
+}
+
+// RunArtifactCheckUpdates executes the business logic for the artifact check-updates command.
+func (o *artifactCheckUpdatesOptions) RunArtifactCheckUpdates(ctx context.Context, args []string) error {
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	pins, err := state.LoadPins(pinsFile)
+	if err != nil {
+		return err
+	}
+
+	var data [][]string
+	var updates int
+	for i := range installedState.Entries {
+		entry := &installedState.Entries[i]
+		if o.artifactType != "" && entry.Type != o.artifactType.String() {
+			continue
+		}
+
+		installed, version, digest, err := latestVersion(ctx, o.Printer, credentialStore, entry)
+		if err != nil {
+			o.Printer.Warning.Printfln("cannot check updates for %q: %s", entry.Name, err.Error())
+			continue
+		}
+
+		update := "no"
+		if digest != entry.Digest {
+			update = "yes"
+			updates++
+		}
+
+		name := entry.Name
+		if _, pinned := pins.Get(entry.Name); pinned {
+			name = pinnedIcon + " " + name
+		}
+
+		data = append(data, []string{name, entry.Type, installed, version, update})
+	}
+
+	if len(data) == 0 {
+		o.Printer.Info.Println("No installed artifact to check")
+		return nil
+	}
+
+	if err = o.Printer.PrintTable(output.ArtifactUpdates, data); err != nil {
+		return err
+	}
+
+	if updates > 0 {
+		return output.ErrSilentExit
+	}
+
+	return nil
+}
+
+// latestVersion returns the installed version of an entry, the latest version available in the registry and its digest.
+// They are looked up in the registry the artifact is pulled from, once the configured rewrites are applied.
+func latestVersion(ctx context.Context, printer *output.Printer, credentialStore *authn.Store,
+	entry *state.Entry) (installed, version, digest string, err error) {
+	if entry.URL != "" {
+		return "", "", "", fmt.Errorf("installed from %q, only artifacts installed from a registry can be checked", entry.URL)
+	}
+
+	parsedRef, err := registry.ParseReference(entry.Ref)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	installed = installedVersion(entry)
+	parsedRef.Reference = ""
+	repo, err := rewriteReference(printer, parsedRef.String())
+	if err != nil {
+		return "", "", "", err
+	}
+
+	reg, err := utils.GetRegistryFromRef(repo)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	client := authn.NewClient(cred)
+
+	version, err = oci.LatestVersion(ctx, repo, client, false)
+	switch {
+	case errors.Is(err, oci.ErrNoVersionFound):
+		version = oci.DefaultTag
+	case err != nil:
+		return "", "", "", err
+	}
+
+	// Installed plugins always match the current OS and architecture.
+	desc, err := oci.ResolvePlatform(ctx, fmt.Sprintf("%s:%s", repo, version), client, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return installed, version, desc.Digest.String(), nil
+}
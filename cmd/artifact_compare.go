@@ -0,0 +1,276 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+var longCompare = `Compare two artifacts side by side
+
+The manifests and configs of both artifacts, for the platform where falcoctl is running or the one
+set with --platform, are fetched and the following fields are compared in a table: version, type,
+platforms, dependencies, license, description, number of annotations and total size of the layers.
+The fields that differ are highlighted. The version falls back to the tag of the reference if the
+artifact has no version annotation.
+
+Example - Compare two alternative plugins:
+	falcoctl artifact compare k8saudit k8saudit-eks
+
+Example - Compare two versions of an artifact as JSON:
+	falcoctl artifact compare ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.5.0 \
+		ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.6.0 --output json
+`
+
+type artifactCompareOptions struct {
+	*options.CommonOptions
+	platform string
+}
+
+// artifactSummary holds the fields of an artifact compared by the artifact compare command.
+type artifactSummary struct {
+	Ref          string   `json:"ref" yaml:"ref"`
+	Version      string   `json:"version" yaml:"version"`
+	Type         string   `json:"type" yaml:"type"`
+	Platforms    []string `json:"platforms" yaml:"platforms"`
+	Dependencies []string `json:"dependencies" yaml:"dependencies"`
+	License      string   `json:"license" yaml:"license"`
+	Description  string   `json:"description" yaml:"description"`
+	Annotations  int      `json:"annotations" yaml:"annotations"`
+	LayersSize   int64    `json:"layersSize" yaml:"layersSize"`
+}
+
+// artifactComparison is the structured output of the artifact compare command.
+type artifactComparison struct {
+	First       artifactSummary `json:"first" yaml:"first"`
+	Second      artifactSummary `json:"second" yaml:"second"`
+	Differences []string        `json:"differences" yaml:"differences"`
+}
+
+// NewArtifactCompareCmd returns the artifact compare command.
+func NewArtifactCompareCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactCompareOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "compare name|ref name|ref [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Compare two artifacts side by side",
+		Long:                  longCompare,
+		Args:                  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactCompare(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.platform, "platform", "",
+		"os and architecture of the compared artifacts in OS/ARCH format. Defaults to the platform where falcoctl is running")
+	o.CommonOptions.AddOutputFlags(cmd.Flags())
+
+	return cmd
+}
+
+// RunArtifactCompare executes the business logic for the artifact compare command.
+func (o *artifactCompareOptions) RunArtifactCompare(ctx context.Context, args []string) error {
+	goos, goarch := runtime.GOOS, runtime.GOARCH
+	if o.platform != "" {
+		var ok bool
+		if goos, goarch, ok = strings.Cut(o.platform, "/"); !ok || goos == "" || goarch == "" {
+			return fmt.Errorf("platform %q seems to be in the wrong format: needs to be in OS/ARCH", o.platform)
+		}
+	}
+
+	indexConfig, err := index.NewConfig(indexesFile)
+	if err != nil {
+		return err
+	}
+
+	mergedIndexes, err := utils.Indexes(indexConfig, falcoctlPath)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	summaries := make([]*artifactSummary, len(args))
+	for i, name := range args {
+		ref, err := utils.ParseReference(mergedIndexes, name)
+		if err != nil {
+			return err
+		}
+
+		if ref, err = rewriteReference(o.Printer, ref); err != nil {
+			return err
+		}
+
+		client, err := registryClient(ctx, credentialStore, ref)
+		if err != nil {
+			return err
+		}
+
+		if summaries[i], err = summarizeArtifact(ctx, ref, client, goos, goarch); err != nil {
+			return fmt.Errorf("unable to fetch %q: %w", name, err)
+		}
+	}
+
+	rows := compareSummaries(summaries[0], summaries[1])
+	comparison := artifactComparison{First: *summaries[0], Second: *summaries[1], Differences: []string{}}
+	for _, row := range rows {
+		if row.differs {
+			comparison.Differences = append(comparison.Differences, row.field)
+		}
+	}
+
+	if o.Output.IsStructured() {
+		return o.Printer.PrintData(o.Output, comparison)
+	}
+
+	data := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		if row.differs {
+			data = append(data, []string{output.Highlight(row.field), output.Highlight(row.first), output.Highlight(row.second)})
+		} else {
+			data = append(data, []string{row.field, row.first, row.second})
+		}
+	}
+
+	if err = o.Printer.PrintTable(output.ArtifactComparison, data); err != nil {
+		return err
+	}
+
+	if len(comparison.Differences) == 0 {
+		o.Printer.Info.Printfln("No difference found")
+	} else {
+		o.Printer.Info.Printfln("%d field(s) differ: %s", len(comparison.Differences), strings.Join(comparison.Differences, ", "))
+	}
+
+	return nil
+}
+
+// summarizeArtifact fetches the fields of the artifact pointed by ref compared by the artifact compare command.
+func summarizeArtifact(ctx context.Context, ref string, client *auth.Client, goos, goarch string) (*artifactSummary, error) {
+	desc, err := oci.Resolve(ctx, ref, client)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &artifactSummary{Ref: ref, Platforms: []string{}, Dependencies: []string{}}
+	if oci.IsIndex(desc.MediaType) {
+		platforms, err := oci.Platforms(ctx, ref, client)
+		if err != nil {
+			return nil, err
+		}
+		for p := range platforms {
+			summary.Platforms = append(summary.Platforms, strings.Replace(p, "-", "/", 1))
+		}
+		sort.Strings(summary.Platforms)
+	}
+
+	manifest, err := oci.FetchManifest(ctx, ref, client, goos, goarch)
+	if err != nil {
+		return nil, err
+	}
+
+	artifactType, err := oci.ArtifactTypeOf(manifest)
+	if err != nil {
+		return nil, err
+	}
+	summary.Type = artifactType.String()
+
+	config, err := oci.FetchArtifactConfig(ctx, ref, client, manifest)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range config.Dependencies {
+		deps := []string{d.Name + ":" + d.Version}
+		for _, alt := range d.Alternatives {
+			deps = append(deps, alt.Name+":"+alt.Version)
+		}
+		summary.Dependencies = append(summary.Dependencies, strings.Join(deps, "|"))
+	}
+
+	summary.Version = manifest.Annotations[v1.AnnotationVersion]
+	if summary.Version == "" {
+		if parsedRef, err := registry.ParseReference(ref); err == nil && parsedRef.Reference != "" {
+			if _, err = parsedRef.Digest(); err != nil {
+				summary.Version = parsedRef.Reference
+			}
+		}
+	}
+	summary.License = manifest.Annotations[v1.AnnotationLicenses]
+	summary.Description = manifest.Annotations[v1.AnnotationDescription]
+	summary.Annotations = len(manifest.Annotations)
+	for _, l := range manifest.Layers {
+		summary.LayersSize += l.Size
+	}
+
+	return summary, nil
+}
+
+// comparisonRow is a row of the table printed by the artifact compare command.
+type comparisonRow struct {
+	field, first, second string
+	differs              bool
+}
+
+// compareSummaries returns the rows comparing the fields of two artifacts.
+func compareSummaries(first, second *artifactSummary) []comparisonRow {
+	orNone := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+	row := func(field, first, second string) comparisonRow {
+		return comparisonRow{field: field, first: orNone(first), second: orNone(second), differs: first != second}
+	}
+
+	return []comparisonRow{
+		row("version", first.Version, second.Version),
+		row("type", first.Type, second.Type),
+		row("platforms", strings.Join(first.Platforms, ", "), strings.Join(second.Platforms, ", ")),
+		row("dependencies", strings.Join(first.Dependencies, ", "), strings.Join(second.Dependencies, ", ")),
+		row("license", first.License, second.License),
+		row("description", first.Description, second.Description),
+		row("annotations", strconv.Itoa(first.Annotations), strconv.Itoa(second.Annotations)),
+		{
+			field:   "layers size",
+			first:   output.FormatSize(first.LayersSize),
+			second:  output.FormatSize(second.LayersSize),
+			differs: first.LayersSize != second.LayersSize,
+		},
+	}
+}
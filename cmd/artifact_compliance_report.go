@@ -0,0 +1,354 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/compliance"
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/oci/registryapi"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+	"github.com/falcosecurity/falcoctl/pkg/provenance"
+	"github.com/falcosecurity/falcoctl/pkg/sbom"
+	"github.com/falcosecurity/falcoctl/pkg/signature"
+)
+
+var longArtifactComplianceReport = `Generate the compliance report of an artifact for a regulatory framework
+
+The security controls of the framework are mapped to the evidence available for the artifact:
+	signature            a cosign signature, verified with --key
+	sbom                 an SBOM, attached by "cosign attach sbom" or as a referrer
+	provenance           a SLSA provenance attestation of the artifact, verified with --key
+	vulnerability-scan   the results of the vulnerability scan, available from Harbor registries only
+
+Each control is "compliant" if all its evidence passed, "non-compliant" if any failed and
+"not-assessed" otherwise, e.g. when a signature is found but --key is not set. The evidence is
+collected for the digest the reference resolves to, which is recorded in the report.
+
+Supported frameworks: "pci-dss" (PCI DSS 4.0), "soc2" (SOC 2 Trust Services Criteria) and
+"nist-csf" (NIST Cybersecurity Framework 1.1). With --report-file, the report is also written
+in json format, to be included in audit packages.
+
+Example - Print the SOC 2 compliance of the falco rules:
+	falcoctl artifact compliance-report falco-rules:3 --framework soc2
+
+Example - Write the PCI DSS compliance report of a plugin, verifying its signature and provenance:
+	falcoctl artifact compliance-report ghcr.io/myorg/plugins/cloudtrail:0.6.0 --framework pci-dss \
+		--key cosign.pub --report-file cloudtrail-pci-dss.json
+`
+
+type artifactComplianceReportOptions struct {
+	*options.CommonOptions
+	framework  string
+	key        string
+	reportFile string
+}
+
+func (o *artifactComplianceReportOptions) validate() error {
+	if o.framework == "" {
+		return fmt.Errorf("--framework must be set")
+	}
+
+	_, err := compliance.ParseFramework(o.framework)
+	return err
+}
+
+// NewArtifactComplianceReportCmd returns the artifact compliance-report command.
+func NewArtifactComplianceReportCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactComplianceReportOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "compliance-report hostname/repo[:tag|@digest] --framework pci-dss|soc2|nist-csf [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Generate the compliance report of an artifact for a regulatory framework",
+		Long:                  longArtifactComplianceReport,
+		Args:                  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactComplianceReport(ctx, args))
+		},
+	}
+
+	o.CommonOptions.AddOutputFlags(cmd.Flags())
+	cmd.Flags().StringVar(&o.framework, "framework", "", `framework of the report. Allowed values: "pci-dss", "soc2", "nist-csf"`)
+	cmd.Flags().StringVar(&o.key, "key", "", "PEM encoded public key used to verify the signature and the provenance of the artifact")
+	cmd.Flags().StringVar(&o.reportFile, "report-file", "", "file where to write the report in json format")
+
+	return cmd
+}
+
+// RunArtifactComplianceReport executes the business logic for the artifact compliance-report command.
+func (o *artifactComplianceReportOptions) RunArtifactComplianceReport(ctx context.Context, args []string) error {
+	framework, err := compliance.ParseFramework(o.framework)
+	if err != nil {
+		return err
+	}
+
+	var publicKey crypto.PublicKey
+	if o.key != "" {
+		if publicKey, err = signature.LoadPublicKey(o.key); err != nil {
+			return err
+		}
+	}
+
+	indexConfig, err := index.NewConfig(indexesFile)
+	if err != nil {
+		return err
+	}
+
+	mergedIndexes, err := utils.Indexes(indexConfig, falcoctlPath)
+	if err != nil {
+		return err
+	}
+
+	ref, err := utils.ParseReference(mergedIndexes, args[0])
+	if err != nil {
+		return err
+	}
+
+	pullRef, err := rewriteReference(o.Printer, ref)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	client, err := registryClient(ctx, credentialStore, pullRef)
+	if err != nil {
+		return err
+	}
+
+	desc, err := oci.Resolve(ctx, pullRef, client)
+	if err != nil {
+		return err
+	}
+	d := desc.Digest.String()
+
+	// The evidence is collected for the resolved digest, so that it cannot change if the tag moves meanwhile.
+	pinned, err := oci.PinReference(pullRef, d)
+	if err != nil {
+		return err
+	}
+
+	evidence := []compliance.Evidence{
+		o.signatureEvidence(ctx, pinned, client, d, publicKey),
+		o.sbomEvidence(ctx, pinned, client),
+		o.provenanceEvidence(ctx, pinned, client, d, publicKey),
+		o.vulnerabilityScanEvidence(ctx, credentialStore, client, pinned, d),
+	}
+
+	report := compliance.NewReport(framework, ref, d, time.Now().UTC().Format(time.RFC3339), evidence)
+
+	if o.reportFile != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(o.reportFile, append(data, '\n'), 0o600); err != nil {
+			return fmt.Errorf("cannot write report file %q: %w", o.reportFile, err)
+		}
+		o.Printer.Info.Printfln("Report written to %q", o.reportFile)
+	}
+
+	if o.Output.IsStructured() {
+		return o.Printer.PrintData(o.Output, report)
+	}
+
+	data := make([][]string, 0, len(report.Controls))
+	for _, c := range report.Controls {
+		var evidence []string
+		for _, e := range c.Evidence {
+			evidence = append(evidence, fmt.Sprintf("%s: %s, %s", e.Kind, e.Status, e.Details))
+		}
+		data = append(data, []string{c.ID, c.Title, string(c.Status), strings.Join(evidence, "; ")})
+	}
+	if err = o.Printer.PrintTable(output.ComplianceControls, data); err != nil {
+		return err
+	}
+
+	switch report.Status {
+	case compliance.Compliant:
+		o.Printer.Success.Printfln("%q is compliant with the %s controls", ref, framework)
+	case compliance.NonCompliant:
+		o.Printer.Warning.Printfln("%q is not compliant with some %s controls", ref, framework)
+	default:
+		o.Printer.Warning.Printfln("Some %s controls could not be assessed for %q", framework, ref)
+	}
+
+	return nil
+}
+
+// signatureEvidence verifies the signature of the artifact with the public key. Without a key, the signature is
+// only looked for.
+func (o *artifactComplianceReportOptions) signatureEvidence(ctx context.Context, ref string, client *auth.Client, d string,
+	publicKey crypto.PublicKey) compliance.Evidence {
+	e := compliance.Evidence{Kind: compliance.EvidenceSignature}
+
+	if publicKey == nil {
+		layers, err := oci.AttachedLayers(ctx, ref, client, "sig", signature.SimpleSigningMediaType)
+		switch {
+		case len(layers) > 0:
+			e.Status, e.Details = compliance.EvidenceUnknown, fmt.Sprintf("%d signature(s) found, not verified since --key is not set", len(layers))
+		case err != nil:
+			e.Status, e.Details = compliance.EvidenceUnknown, err.Error()
+		default:
+			e.Status, e.Details = compliance.EvidenceFail, "no signature found"
+		}
+		return e
+	}
+
+	valid, err := signature.HasValidSignature(ctx, ref, client, d, publicKey)
+	switch {
+	case valid:
+		e.Status, e.Details = compliance.EvidencePass, "signature verified with "+o.key
+	case err != nil:
+		e.Status, e.Details = compliance.EvidenceUnknown, err.Error()
+	default:
+		e.Status, e.Details = compliance.EvidenceFail, "no signature valid for "+o.key
+	}
+
+	return e
+}
+
+// sbomEvidence looks for the SBOM of the artifact and checks that it can be parsed.
+func (o *artifactComplianceReportOptions) sbomEvidence(ctx context.Context, ref string, client *auth.Client) compliance.Evidence {
+	e := compliance.Evidence{Kind: compliance.EvidenceSBOM}
+
+	data, mediaType, err := sbom.Fetch(ctx, ref, client)
+	switch {
+	case errors.Is(err, sbom.ErrNotFound):
+		e.Status, e.Details = compliance.EvidenceFail, "no SBOM found"
+		return e
+	case err != nil:
+		e.Status, e.Details = compliance.EvidenceUnknown, err.Error()
+		return e
+	}
+
+	packages, err := sbom.Parse(data)
+	if err != nil {
+		e.Status, e.Details = compliance.EvidenceFail, fmt.Sprintf("%s SBOM found but invalid: %s", mediaType, err.Error())
+		return e
+	}
+
+	e.Status, e.Details = compliance.EvidencePass, fmt.Sprintf("%s SBOM listing %d package(s)", mediaType, len(packages))
+	return e
+}
+
+// provenanceEvidence looks for a SLSA provenance attestation of the artifact, verifying its signature with the
+// public key. Without a key, the attestations are only looked for.
+func (o *artifactComplianceReportOptions) provenanceEvidence(ctx context.Context, ref string, client *auth.Client, d string,
+	publicKey crypto.PublicKey) compliance.Evidence {
+	e := compliance.Evidence{Kind: compliance.EvidenceProvenance}
+
+	attestations, err := provenance.Fetch(ctx, ref, client)
+	switch {
+	case errors.Is(err, provenance.ErrNotFound):
+		e.Status, e.Details = compliance.EvidenceFail, "no SLSA provenance attestation found"
+		return e
+	case err != nil:
+		e.Status, e.Details = compliance.EvidenceUnknown, err.Error()
+		return e
+	}
+
+	var subject []provenance.Attestation
+	for i := range attestations {
+		if attestations[i].Statement.HasSubjectDigest(d) {
+			subject = append(subject, attestations[i])
+		}
+	}
+	if len(subject) == 0 {
+		e.Status, e.Details = compliance.EvidenceFail, fmt.Sprintf("no SLSA provenance attestation has %s as subject", d)
+		return e
+	}
+
+	if publicKey == nil {
+		e.Status, e.Details = compliance.EvidenceUnknown,
+			fmt.Sprintf("%d SLSA provenance attestation(s) found, not verified since --key is not set", len(subject))
+		return e
+	}
+
+	for i := range subject {
+		if subject[i].Envelope.Verify(publicKey) == nil {
+			e.Status, e.Details = compliance.EvidencePass, "SLSA provenance attestation verified with "+o.key
+			return e
+		}
+	}
+
+	e.Status, e.Details = compliance.EvidenceFail, "no SLSA provenance attestation valid for "+o.key
+	return e
+}
+
+// vulnerabilityScanEvidence checks the results of the vulnerability scan of the artifact, passing if no critical
+// or high vulnerability was found. Scan results are only available from Harbor registries.
+func (o *artifactComplianceReportOptions) vulnerabilityScanEvidence(ctx context.Context, credentialStore *authn.Store,
+	client *auth.Client, ref, d string) compliance.Evidence {
+	e := compliance.Evidence{Kind: compliance.EvidenceVulnerabilityScan}
+
+	parsedRef, err := registry.ParseReference(ref)
+	if err != nil {
+		e.Status, e.Details = compliance.EvidenceUnknown, err.Error()
+		return e
+	}
+
+	if oci.Flavor(ctx, client, parsedRef.Registry, false) != oci.FlavorHarbor {
+		e.Status, e.Details = compliance.EvidenceUnknown, "vulnerability scan results are only available from Harbor registries"
+		return e
+	}
+
+	cred, err := credentialStore.Credential(ctx, parsedRef.Registry)
+	if err != nil {
+		e.Status, e.Details = compliance.EvidenceUnknown, err.Error()
+		return e
+	}
+
+	summary, err := registryapi.NewClient("https://"+parsedRef.Registry, registryapi.Harbor, cred).ScanSummary(ctx, parsedRef.Repository, d)
+	switch {
+	case err != nil:
+		e.Status, e.Details = compliance.EvidenceUnknown, err.Error()
+	case summary.Status != registryapi.ScanStatusSuccess:
+		e.Status, e.Details = compliance.EvidenceUnknown, fmt.Sprintf("scan status %q", summary.Status)
+	case summary.Critical+summary.High > 0:
+		e.Status, e.Details = compliance.EvidenceFail,
+			fmt.Sprintf("%d critical and %d high vulnerabilities found", summary.Critical, summary.High)
+	default:
+		e.Status, e.Details = compliance.EvidencePass,
+			fmt.Sprintf("no critical or high vulnerability found, %d medium, %d low", summary.Medium, summary.Low)
+	}
+
+	return e
+}
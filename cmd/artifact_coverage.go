@@ -0,0 +1,182 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+var longCoverage = `Report the coverage of the standard OCI annotations across the artifacts of the indexes
+
+The manifest of the latest version of each artifact is fetched from its registry and the
+percentage of artifacts having each standard OCI annotation is reported. The artifacts
+missing any of the critical annotations (created, source, licenses) are listed by name.
+
+Example - Report the annotation coverage of all the configured indexes:
+	falcoctl artifact coverage
+
+Example - Report the annotation coverage of the index "falcosecurity":
+	falcoctl artifact coverage --index falcosecurity
+`
+
+// standardAnnotations are the annotations defined by the OCI image spec whose coverage is reported.
+var standardAnnotations = []string{
+	v1.AnnotationCreated,
+	v1.AnnotationAuthors,
+	v1.AnnotationURL,
+	v1.AnnotationDocumentation,
+	v1.AnnotationSource,
+	v1.AnnotationVersion,
+	v1.AnnotationRevision,
+	v1.AnnotationVendor,
+	v1.AnnotationLicenses,
+	v1.AnnotationTitle,
+	v1.AnnotationDescription,
+}
+
+// criticalAnnotations are the annotations needed by search and compliance tooling.
+var criticalAnnotations = []string{
+	v1.AnnotationCreated,
+	v1.AnnotationSource,
+	v1.AnnotationLicenses,
+}
+
+type artifactCoverageOptions struct {
+	*options.CommonOptions
+	index string
+}
+
+// NewArtifactCoverageCmd returns the artifact coverage command.
+func NewArtifactCoverageCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactCoverageOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "coverage [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Report the coverage of the standard OCI annotations across the artifacts of the indexes",
+		Long:                  longCoverage,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactCoverage(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.index, "index", "", "name of the index whose artifacts are scanned. Defaults to all the configured indexes")
+
+	return cmd
+}
+
+// RunArtifactCoverage executes the business logic for the artifact coverage command.
+func (o *artifactCoverageOptions) RunArtifactCoverage(ctx context.Context, args []string) error {
+	indexConfig, err := index.NewConfig(indexesFile)
+	if err != nil {
+		return err
+	}
+
+	if o.index != "" {
+		if _, err = indexConfig.Get(o.index); err != nil {
+			return err
+		}
+	}
+
+	mergedIndexes, err := utils.Indexes(indexConfig, falcoctlPath)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]int, len(standardAnnotations))
+	var scanned int
+	for _, entry := range mergedIndexes.Entries {
+		if o.index != "" && mergedIndexes.IndexByEntry(entry).Name != o.index {
+			continue
+		}
+
+		annotations, err := o.annotations(ctx, credentialStore, entry)
+		if err != nil {
+			o.Printer.Warning.Printfln("cannot fetch the manifest of %q, skipping: %s", entry.Name, err.Error())
+			continue
+		}
+		scanned++
+
+		for _, key := range standardAnnotations {
+			if annotations[key] != "" {
+				counts[key]++
+			}
+		}
+
+		var missing []string
+		for _, key := range criticalAnnotations {
+			if annotations[key] == "" {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) > 0 {
+			o.Printer.Warning.Printfln("%q misses critical annotations: %s", entry.Name, strings.Join(missing, ", "))
+		}
+	}
+
+	if scanned == 0 {
+		o.Printer.Info.Println("No artifact to scan")
+		return nil
+	}
+
+	data := make([][]string, 0, len(standardAnnotations))
+	for _, key := range standardAnnotations {
+		data = append(data, []string{key, fmt.Sprintf("%.1f%%", float64(counts[key])*100/float64(scanned))})
+	}
+
+	o.Printer.Info.Printfln("%d artifact(s) scanned", scanned)
+
+	return o.Printer.PrintTable(output.AnnotationCoverage, data)
+}
+
+// annotations returns the manifest annotations of the latest version of an index entry.
+func (o *artifactCoverageOptions) annotations(ctx context.Context, credentialStore *authn.Store, entry *index.Entry) (map[string]string, error) {
+	cred, err := credentialStore.Credential(ctx, entry.Registry)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := fmt.Sprintf("%s/%s:%s", entry.Registry, entry.Repository, oci.DefaultTag)
+	o.Printer.Verbosef("Fetching manifest of %q", ref)
+
+	// Plugins are scanned for the current OS and architecture.
+	manifest, err := oci.FetchManifest(ctx, ref, authn.NewClient(cred), runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest.Annotations, nil
+}
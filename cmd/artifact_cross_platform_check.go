@@ -0,0 +1,148 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+var longCrossPlatformCheck = `Detect platform regressions across the versions of an artifact
+
+The platforms of each version of the artifact, i.e. each tag which is a valid semver
+version, are compared with the ones of the previous version. A version missing any of the
+platforms of the previous version is flagged as a regression, and the command exits with
+code 1. Versions which are not multi-platform, e.g. rulesfiles, are skipped.
+
+Example - Check the platforms of all the versions of a plugin:
+	falcoctl artifact cross-platform-check ghcr.io/falcosecurity/plugins/plugin/cloudtrail
+`
+
+type artifactCrossPlatformCheckOptions struct {
+	*options.CommonOptions
+}
+
+// NewArtifactCrossPlatformCheckCmd returns the artifact cross-platform-check command.
+func NewArtifactCrossPlatformCheckCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactCrossPlatformCheckOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "cross-platform-check hostname/repo [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Detect platform regressions across the versions of an artifact",
+		Long:                  longCrossPlatformCheck,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactCrossPlatformCheck(ctx, args))
+		},
+	}
+
+	return cmd
+}
+
+// RunArtifactCrossPlatformCheck executes the business logic for the artifact cross-platform-check command.
+func (o *artifactCrossPlatformCheckOptions) RunArtifactCrossPlatformCheck(ctx context.Context, args []string) error {
+	repo := args[0]
+
+	reg, err := utils.GetRegistryFromRef(repo)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return err
+	}
+
+	client := authn.NewClient(cred)
+
+	versions, err := oci.Versions(ctx, repo, client)
+	if err != nil {
+		return err
+	}
+
+	if len(versions) == 0 {
+		return fmt.Errorf("%s: %w", repo, oci.ErrNoVersionFound)
+	}
+
+	var data [][]string
+	var previous map[string]struct{}
+	var regressions int
+	for _, version := range versions {
+		platforms, err := oci.Platforms(ctx, fmt.Sprintf("%s:%s", repo, version), client)
+		if err != nil {
+			o.Printer.Verbosef("Skipping version %q: %s", version, err.Error())
+			continue
+		}
+
+		var missing []string
+		for platform := range previous {
+			if _, ok := platforms[platform]; !ok {
+				missing = append(missing, platform)
+			}
+		}
+		sort.Strings(missing)
+
+		if len(missing) > 0 {
+			regressions++
+			o.Printer.Warning.Printfln("Version %q misses platforms of the previous version: %s", version, strings.Join(missing, ", "))
+		}
+
+		data = append(data, []string{version, strings.Join(sortedPlatforms(platforms), ", "), strings.Join(missing, ", ")})
+		previous = platforms
+	}
+
+	if len(data) == 0 {
+		o.Printer.Info.Printfln("No multi-platform version found for %q", repo)
+		return nil
+	}
+
+	if err = o.Printer.PrintTable(output.PlatformRegressions, data); err != nil {
+		return err
+	}
+
+	if regressions > 0 {
+		return output.ErrSilentExit
+	}
+
+	return nil
+}
+
+func sortedPlatforms(platforms map[string]struct{}) []string {
+	result := make([]string, 0, len(platforms))
+	for platform := range platforms {
+		result = append(result, platform)
+	}
+	sort.Strings(result)
+
+	return result
+}
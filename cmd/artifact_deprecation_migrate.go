@@ -0,0 +1,311 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/rules"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+var longDeprecationMigrate = `Migrate an installed artifact to its replacement, if it is deprecated
+
+The manifest of the installed artifact is checked for the deprecation annotations:
+	- "io.falcosecurity.deprecated" holds the deprecation message
+	- "io.falcosecurity.replaced-by" holds the replacement, either an index name or a reference
+
+If a replacement is set, it is looked up in the configured indexes and the migration is performed:
+	- the replacement is installed
+	- for rules files, the local overrides of the old rules files, e.g. "falco_rules.local.yaml",
+	  are merged into the overrides file of the new ones
+	- the files of the old artifact are removed, along with its entry in the state file
+
+The old overrides files are left untouched, to be removed once the migration has been checked.
+When running in a terminal the migration must be confirmed, otherwise --yes must be set.
+
+Example - Migrate the "k8saudit-rules" artifact to its replacement:
+	falcoctl artifact deprecation-migrate k8saudit-rules
+
+Example - Only show the migration steps:
+	falcoctl artifact deprecation-migrate k8saudit-rules --dry-run
+`
+
+type artifactDeprecationMigrateOptions struct {
+	*options.CommonOptions
+	rulesfilesDir string
+	pluginsDir    string
+	dryRun        bool
+	yes           bool
+}
+
+// NewArtifactDeprecationMigrateCmd returns the artifact deprecation-migrate command.
+func NewArtifactDeprecationMigrateCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactDeprecationMigrateOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "deprecation-migrate name [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Migrate an installed artifact to its replacement, if it is deprecated",
+		Long:                  longDeprecationMigrate,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactDeprecationMigrate(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.rulesfilesDir, "rulesfiles-dir", "", defaultRulesfilesDir,
+		"directory where to install rules. Defaults to /etc/falco")
+	cmd.Flags().StringVarP(&o.pluginsDir, "plugins-dir", "", defaultPluginsDir,
+		"directory where to install plugins. Defaults to /usr/share/falco/plugins")
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", false, "only show the migration steps, without performing them")
+	cmd.Flags().BoolVar(&o.yes, "yes", false, "perform the migration without asking for confirmation")
+
+	return cmd
+}
+
+// RunArtifactDeprecationMigrate executes the business logic for the artifact deprecation-migrate command.
+func (o *artifactDeprecationMigrateOptions) RunArtifactDeprecationMigrate(ctx context.Context, args []string) error {
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return err
+	}
+
+	entry, err := installedState.Get(utils.ArtifactName(args[0]))
+	if err != nil {
+		return err
+	}
+
+	message, replacement, err := o.deprecation(ctx, entry)
+	if err != nil {
+		return err
+	}
+
+	if message == "" && replacement == "" {
+		o.Printer.Success.Printfln("Artifact %q is not deprecated, nothing to migrate", entry.Name)
+		return nil
+	}
+
+	if message != "" {
+		o.Printer.Warning.Printfln("Artifact %q is deprecated: %s", entry.Name, message)
+	}
+	if replacement == "" {
+		return fmt.Errorf("artifact %q is deprecated without a replacement, it cannot be migrated", entry.Name)
+	}
+
+	indexConfig, err := index.NewConfig(indexesFile)
+	if err != nil {
+		return err
+	}
+
+	mergedIndexes, err := utils.Indexes(indexConfig, falcoctlPath)
+	if err != nil {
+		return err
+	}
+
+	replacementRef, err := utils.ParseReference(mergedIndexes, replacement)
+	if err != nil {
+		return fmt.Errorf("cannot find replacement %q of %q: %w", replacement, entry.Name, err)
+	}
+	replacementName := utils.ArtifactName(replacement)
+
+	steps := []string{fmt.Sprintf("install %q", replacementName)}
+	if entry.Type == string(oci.Rulesfile) {
+		steps = append(steps, fmt.Sprintf("merge the local overrides of the rules files of %q", entry.Name))
+	}
+	steps = append(steps, fmt.Sprintf("remove the %d file(s) of %q and its entry in the state file", len(entry.Files), entry.Name))
+
+	o.Printer.Info.Printfln("Migrating %q to %q (%s):", entry.Name, replacementName, replacementRef)
+	for i, step := range steps {
+		o.Printer.DefaultText.Printfln("  %d. %s", i+1, step)
+	}
+
+	if o.dryRun {
+		return nil
+	}
+
+	if err = o.confirm(); err != nil {
+		return err
+	}
+
+	// The install updates the state file, keep the old entry.
+	old := *entry
+
+	install := artifactInstallOptions{
+		CommonOptions: o.CommonOptions,
+		rulesfilesDir: o.rulesfilesDir,
+		pluginsDir:    o.pluginsDir,
+	}
+	if err = install.RunArtifactInstall(ctx, []string{replacement}); err != nil {
+		return fmt.Errorf("cannot install replacement %q: %w", replacement, err)
+	}
+
+	if installedState, err = state.New(stateFile); err != nil {
+		return err
+	}
+
+	replacementEntry, err := installedState.Get(replacementName)
+	if err != nil {
+		return err
+	}
+	installed := *replacementEntry
+
+	if old.Type == string(oci.Rulesfile) {
+		o.mergeOverrides(&old, &installed)
+	}
+
+	return o.uninstall(installedState, &old, &installed)
+}
+
+// deprecation returns the deprecation message and the replacement of an installed artifact, read from the
+// manifest it was installed from.
+func (o *artifactDeprecationMigrateOptions) deprecation(ctx context.Context, entry *state.Entry) (message, replacement string, err error) {
+	if entry.URL != "" {
+		return "", "", fmt.Errorf("%q was installed from %q, only artifacts installed from a registry can be migrated", entry.Name, entry.URL)
+	}
+
+	ref, err := oci.PinReference(entry.Ref, entry.Digest)
+	if err != nil {
+		return "", "", err
+	}
+
+	if ref, err = rewriteReference(o.Printer, ref); err != nil {
+		return "", "", err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return "", "", err
+	}
+
+	client, err := registryClient(ctx, credentialStore, ref)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Installed artifacts always match the current OS and architecture.
+	manifest, err := oci.FetchManifest(ctx, ref, client, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot fetch the manifest of %q: %w", entry.Name, err)
+	}
+
+	message, replacement, deprecated := oci.Deprecation(manifest)
+	if deprecated && message == "" {
+		message = "no deprecation message"
+	}
+
+	return message, replacement, nil
+}
+
+// confirm asks the user to confirm the migration, unless --yes is set.
+func (o *artifactDeprecationMigrateOptions) confirm() error {
+	if o.yes {
+		return nil
+	}
+
+	if !o.Printer.IsInteractive() {
+		return errors.New("not running in a terminal, use --yes to perform the migration")
+	}
+
+	answer, err := o.Printer.Select("Proceed with the migration?", []string{"yes", "no"}, "no")
+	if err != nil {
+		return err
+	}
+	if answer != "yes" {
+		return errors.New("migration aborted")
+	}
+
+	return nil
+}
+
+// mergeOverrides merges the local overrides of the rules files of entry into the overrides file of the first
+// rules file of replacement. Failures are only reported, the overrides can be merged by hand.
+func (o *artifactDeprecationMigrateOptions) mergeOverrides(entry, replacement *state.Entry) {
+	var target string
+	for _, file := range replacement.Files {
+		if ext := filepath.Ext(file.Path); ext == ".yaml" || ext == ".yml" {
+			target = rules.OverridesFile(file.Path)
+			break
+		}
+	}
+
+	for _, file := range entry.Files {
+		overrides := rules.OverridesFile(file.Path)
+		if _, err := os.Stat(overrides); err != nil {
+			continue
+		}
+
+		if target == "" {
+			o.Printer.Warning.Printfln("%q has no rules file, merge the overrides in %q by hand", replacement.Name, overrides)
+			continue
+		}
+
+		merged, err := rules.MergeOverrides(overrides, target)
+		if err != nil {
+			o.Printer.Warning.Printfln("cannot merge the overrides in %q into %q, merge them by hand: %s", overrides, target, err.Error())
+			continue
+		}
+
+		o.Printer.Info.Printfln("%d override(s) merged from %q into %q, remove %q once checked", merged, overrides, target, overrides)
+	}
+}
+
+// uninstall removes the files of entry not shared with replacement and its entry in the state file.
+func (o *artifactDeprecationMigrateOptions) uninstall(installedState *state.State, entry, replacement *state.Entry) error {
+	shared := make(map[string]bool, len(replacement.Files))
+	for _, file := range replacement.Files {
+		shared[file.Path] = true
+	}
+
+	for _, file := range entry.Files {
+		if shared[file.Path] {
+			o.Printer.Verbosef("%q is now part of %q, not removing it", file.Path, replacement.Name)
+			continue
+		}
+		if err := os.Remove(file.Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cannot remove %q: %w", file.Path, err)
+		}
+		o.Printer.Verbosef("Removed %q", file.Path)
+	}
+
+	// An artifact replaced by one with the same name has already been overwritten in the state file.
+	if entry.Name != replacement.Name {
+		if err := installedState.Remove(entry.Name); err != nil {
+			return err
+		}
+
+		if err := installedState.Write(stateFile); err != nil {
+			return fmt.Errorf("cannot update state file %q: %w", stateFile, err)
+		}
+	}
+
+	o.Printer.Success.Printfln("Artifact %q migrated to %q", entry.Name, replacement.Name)
+
+	return nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+const (
+	// metadataFormatEnv prints the metadata as KEY=value lines.
+	metadataFormatEnv = "env"
+	// metadataEnvPrefix is the default prefix of the variables printed in env format.
+	metadataEnvPrefix = "ARTIFACT"
+	// ociAnnotationPrefix is stripped from the annotation keys to get shorter variable names.
+	ociAnnotationPrefix = "org.opencontainers.image."
+)
+
+var longExtractMetadata = `Print all the metadata of an artifact in a format suitable for scripts
+
+The manifest of the artifact, for the platform where falcoctl is running, is fetched and all its
+annotations and descriptor fields are printed as a flat set of key-value pairs. Allowed formats:
+  - env: KEY=value lines, e.g. ARTIFACT_TITLE, ARTIFACT_VERSION, ARTIFACT_DIGEST, suitable for
+    being sourced by a shell. The "org.opencontainers.image." prefix is stripped from the annotations
+    and the prefix of the variables can be changed with --env-prefix. ARTIFACT_REF, ARTIFACT_DIGEST,
+    ARTIFACT_MEDIA_TYPE, ARTIFACT_SIZE, ARTIFACT_CONFIG_DIGEST, ARTIFACT_CONFIG_MEDIA_TYPE, ARTIFACT_LAYERS
+    and ARTIFACT_VERSION are always set, the latter to the tag of the reference if the artifact has no
+    version annotation. With --env-file the lines are appended to a file, such as $GITHUB_ENV, rather
+    than printed: values are not quoted and multi-line ones are written as KEY<<DELIMITER heredocs;
+  - json: a flat JSON object;
+  - yaml: a flat YAML map.
+
+Example - Export the metadata of the "cloudtrail" artifact to the current shell:
+	eval "$(falcoctl artifact extract-metadata cloudtrail --format env)"
+
+Example - Export the metadata of the "cloudtrail" artifact to the next steps of a GitHub Actions job:
+	falcoctl artifact extract-metadata cloudtrail --env-file "$GITHUB_ENV" --env-prefix FALCOCTL
+
+Example - Print the metadata of an artifact given its reference as JSON:
+	falcoctl artifact extract-metadata ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.6.0 --format json
+`
+
+type artifactExtractMetadataOptions struct {
+	*options.CommonOptions
+	format    string
+	envFile   string
+	envPrefix string
+}
+
+func (o *artifactExtractMetadataOptions) validate() error {
+	if o.envFile != "" && o.format != metadataFormatEnv {
+		return fmt.Errorf("--env-file requires --format %s", metadataFormatEnv)
+	}
+
+	switch o.format {
+	case metadataFormatEnv, string(output.JSON), string(output.YAML):
+		return nil
+	default:
+		return fmt.Errorf("format %q not supported, allowed values: %q, %q, %q",
+			o.format, metadataFormatEnv, output.JSON, output.YAML)
+	}
+}
+
+// NewArtifactExtractMetadataCmd returns the artifact extract-metadata command.
+func NewArtifactExtractMetadataCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactExtractMetadataOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "extract-metadata name|ref [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Print all the metadata of an artifact in a format suitable for scripts",
+		Long:                  longExtractMetadata,
+		Args:                  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			// Keep stdout clean, it only holds the metadata.
+			o.Printer.RedirectLogs(os.Stderr)
+			o.Printer.CheckErr(o.RunArtifactExtractMetadata(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.format, "format", metadataFormatEnv, `output format. Allowed values: "env", "json", "yaml"`)
+	cmd.Flags().StringVar(&o.envFile, "env-file", "", "file where to append the metadata in env format, rather than printing it")
+	cmd.Flags().StringVar(&o.envPrefix, "env-prefix", metadataEnvPrefix, "prefix of the variable names in env format")
+
+	return cmd
+}
+
+// RunArtifactExtractMetadata executes the business logic for the artifact extract-metadata command.
+func (o *artifactExtractMetadataOptions) RunArtifactExtractMetadata(ctx context.Context, args []string) error {
+	indexConfig, err := index.NewConfig(indexesFile)
+	if err != nil {
+		return err
+	}
+
+	mergedIndexes, err := utils.Indexes(indexConfig, falcoctlPath)
+	if err != nil {
+		return err
+	}
+
+	ref, err := utils.ParseReference(mergedIndexes, args[0])
+	if err != nil {
+		return err
+	}
+
+	reg, err := utils.GetRegistryFromRef(ref)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return err
+	}
+	client := authn.NewClient(cred)
+
+	desc, err := oci.ResolvePlatform(ctx, ref, client, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := oci.FetchManifest(ctx, ref, client, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+
+	layerDigests := make([]string, 0, len(manifest.Layers))
+	for _, l := range manifest.Layers {
+		layerDigests = append(layerDigests, l.Digest.String())
+	}
+
+	// The descriptor fields are set last, so that they win over annotations with the same key.
+	metadata := make(map[string]string, len(manifest.Annotations)+7)
+	for k, v := range manifest.Annotations {
+		metadata[k] = v
+	}
+	metadata["ref"] = ref
+	metadata["digest"] = desc.Digest.String()
+	metadata["mediaType"] = desc.MediaType
+	metadata["size"] = strconv.FormatInt(desc.Size, 10)
+	metadata["config.digest"] = manifest.Config.Digest.String()
+	metadata["config.mediaType"] = manifest.Config.MediaType
+	metadata["layers"] = strings.Join(layerDigests, ",")
+
+	// Scripts can always rely on the version, falling back to the tag of the reference.
+	if _, ok := metadata[v1.AnnotationVersion]; !ok {
+		if parsedRef, err := registry.ParseReference(ref); err == nil && parsedRef.Reference != "" {
+			if _, err = parsedRef.Digest(); err != nil {
+				metadata[v1.AnnotationVersion] = parsedRef.Reference
+			}
+		}
+	}
+
+	if o.format != metadataFormatEnv {
+		return o.Printer.PrintData(output.Format(o.format), metadata)
+	}
+
+	vars := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		vars[output.EnvName(o.envPrefix, strings.TrimPrefix(k, ociAnnotationPrefix))] = v
+	}
+
+	if o.envFile == "" {
+		o.Printer.PrintEnv(vars)
+		return nil
+	}
+
+	if err = appendLine(o.envFile, []byte(strings.Join(output.EnvFileLines(vars), "\n"))); err != nil {
+		return fmt.Errorf("unable to write %q: %w", o.envFile, err)
+	}
+	o.Printer.Success.Printfln("%d variables written to %q", len(vars), o.envFile)
+
+	return nil
+}
@@ -0,0 +1,185 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	ocipuller "github.com/falcosecurity/falcoctl/pkg/oci/puller"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+var longFetchAllVersions = `Download every tagged version of an artifact
+
+The main layer of each tag of the repository is pulled and written as <tag>.tar.gz in the
+output directory. Plugins are pulled for the platform where falcoctl is running, unless a
+different one is set with --platform. With --skip-existing, tags for which a file already
+exists in the output directory are skipped, making the command resumable after an
+interruption. The command exits with a non-zero exit code if any tag cannot be downloaded.
+
+Example - Download all the versions of a plugin in the "versions" directory:
+	falcoctl artifact fetch-all-versions ghcr.io/falcosecurity/plugins/plugin/cloudtrail --output-dir ./versions
+
+Example - Resume the download of all the versions of a plugin for linux/arm64:
+	falcoctl artifact fetch-all-versions ghcr.io/falcosecurity/plugins/plugin/cloudtrail \
+		--output-dir ./versions --platform linux/arm64 --skip-existing
+`
+
+type artifactFetchAllVersionsOptions struct {
+	*options.CommonOptions
+	outputDir    string
+	platform     string
+	skipExisting bool
+}
+
+// NewArtifactFetchAllVersionsCmd returns the artifact fetch-all-versions command.
+func NewArtifactFetchAllVersionsCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactFetchAllVersionsOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "fetch-all-versions hostname/repo [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Download every tagged version of an artifact",
+		Long:                  longFetchAllVersions,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactFetchAllVersions(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.outputDir, "output-dir", ".", "directory where to write the downloaded versions")
+	cmd.Flags().StringVar(&o.platform, "platform", "",
+		"os and architecture of the plugins to be downloaded in OS/ARCH format. Defaults to the platform where falcoctl is running")
+	cmd.Flags().BoolVar(&o.skipExisting, "skip-existing", false, "skip the tags for which a file already exists in the output directory")
+
+	return cmd
+}
+
+// RunArtifactFetchAllVersions executes the business logic for the artifact fetch-all-versions command.
+func (o *artifactFetchAllVersionsOptions) RunArtifactFetchAllVersions(ctx context.Context, args []string) error {
+	goos, goarch := runtime.GOOS, runtime.GOARCH
+	if o.platform != "" {
+		var ok bool
+		if goos, goarch, ok = strings.Cut(o.platform, "/"); !ok || goos == "" || goarch == "" {
+			return fmt.Errorf("platform %q seems to be in the wrong format: needs to be in OS/ARCH", o.platform)
+		}
+	}
+
+	repo, err := rewriteReference(o.Printer, args[0])
+	if err != nil {
+		return err
+	}
+
+	reg, err := utils.GetRegistryFromRef(repo)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return err
+	}
+
+	client := authn.NewClient(cred)
+
+	tags, err := oci.ListTags(ctx, repo, client)
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		o.Printer.Info.Printfln("No tag found for %q", args[0])
+		return nil
+	}
+
+	if err = os.MkdirAll(o.outputDir, 0o750); err != nil {
+		return fmt.Errorf("cannot create output directory %q: %w", o.outputDir, err)
+	}
+
+	puller := ocipuller.NewPuller(client, newPullProgressTracker(o.Printer))
+
+	var fetched, skipped, failed int
+	for _, tag := range tags {
+		dst := filepath.Join(o.outputDir, tag+".tar.gz")
+
+		if o.skipExisting {
+			if _, err = os.Stat(dst); err == nil {
+				o.Printer.Verbosef("Skipping tag %q, %q already exists", tag, dst)
+				skipped++
+				continue
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+
+		if err = o.fetchVersion(ctx, puller, fmt.Sprintf("%s:%s", repo, tag), dst, goos, goarch); err != nil {
+			o.Printer.Error.Printfln("cannot fetch tag %q: %s", tag, err.Error())
+			failed++
+			continue
+		}
+		fetched++
+	}
+
+	o.Printer.Info.Printfln("%d version(s) downloaded, %d skipped, %d failed", fetched, skipped, failed)
+
+	if failed > 0 {
+		return output.ErrSilentExit
+	}
+
+	return nil
+}
+
+// fetchVersion pulls the main layer of ref and writes it to dst. The layer is pulled in a temporary
+// directory next to dst, so that dst is only created once the layer is complete.
+func (o *artifactFetchAllVersionsOptions) fetchVersion(ctx context.Context, puller *ocipuller.Puller, ref, dst, goos, goarch string) error {
+	tmpDir, err := os.MkdirTemp(o.outputDir, ".falcoctl-")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	result, err := puller.Pull(ctx, ref, tmpDir, goos, goarch)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Rename(filepath.Join(tmpDir, result.Filename), dst); err != nil {
+		return err
+	}
+
+	o.Printer.Info.Printfln("Downloaded %q %q to %q", result.Type, ref, dst)
+	recordTransferredFiles(o.Printer, dst)
+
+	return nil
+}
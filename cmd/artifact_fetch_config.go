@@ -0,0 +1,158 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+var longFetchConfig = `Download and display the config blob of an artifact
+
+The manifest of the artifact, for the platform where falcoctl is running unless a different one
+is set with --platform, is fetched and its config blob is downloaded. It holds the Falco specific
+metadata of the artifact, e.g. its dependencies and requirements, without the need of installing it.
+The config is written to the file set with --output, or printed to stdout. JSON configs are indented,
+unless --raw is set, in which case the blob is written exactly as stored in the registry.
+
+Example - Print the config of the "cloudtrail" artifact:
+	falcoctl artifact fetch-config cloudtrail
+
+Example - Save the config of an artifact, given its reference, as stored in the registry:
+	falcoctl artifact fetch-config ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.6.0 --output config.json --raw
+`
+
+type artifactFetchConfigOptions struct {
+	*options.CommonOptions
+	output   string
+	raw      bool
+	platform string
+}
+
+// NewArtifactFetchConfigCmd returns the artifact fetch-config command.
+func NewArtifactFetchConfigCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactFetchConfigOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "fetch-config name|ref [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Download and display the config blob of an artifact",
+		Long:                  longFetchConfig,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if o.output == "" {
+				// Keep stdout clean, it only holds the config.
+				o.Printer.RedirectLogs(os.Stderr)
+			}
+			o.Printer.CheckErr(o.RunArtifactFetchConfig(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.output, "output", "", "file where the config is written. Defaults to stdout")
+	cmd.Flags().BoolVar(&o.raw, "raw", false, "write the config exactly as stored in the registry, without indenting it")
+	cmd.Flags().StringVar(&o.platform, "platform", "",
+		"os and architecture of the artifact in OS/ARCH format. Defaults to the platform where falcoctl is running")
+
+	return cmd
+}
+
+// RunArtifactFetchConfig executes the business logic for the artifact fetch-config command.
+func (o *artifactFetchConfigOptions) RunArtifactFetchConfig(ctx context.Context, args []string) error {
+	goos, goarch := runtime.GOOS, runtime.GOARCH
+	if o.platform != "" {
+		var ok bool
+		if goos, goarch, ok = strings.Cut(o.platform, "/"); !ok || goos == "" || goarch == "" {
+			return fmt.Errorf("platform %q seems to be in the wrong format: needs to be in OS/ARCH", o.platform)
+		}
+	}
+
+	indexConfig, err := index.NewConfig(indexesFile)
+	if err != nil {
+		return err
+	}
+
+	mergedIndexes, err := utils.Indexes(indexConfig, falcoctlPath)
+	if err != nil {
+		return err
+	}
+
+	ref, err := utils.ParseReference(mergedIndexes, args[0])
+	if err != nil {
+		return err
+	}
+
+	if ref, err = rewriteReference(o.Printer, ref); err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	client, err := registryClient(ctx, credentialStore, ref)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := oci.FetchManifest(ctx, ref, client, goos, goarch)
+	if err != nil {
+		return err
+	}
+
+	o.Printer.Verbosef("Fetching config %s of media type %q", manifest.Config.Digest, manifest.Config.MediaType)
+	config, err := oci.FetchConfig(ctx, ref, client, manifest)
+	if err != nil {
+		return err
+	}
+
+	if !o.raw {
+		var indented bytes.Buffer
+		if err = json.Indent(&indented, config, "", "  "); err != nil {
+			o.Printer.Verbosef("Config is not in JSON format, writing it as is: %s", err.Error())
+		} else {
+			indented.WriteByte('\n')
+			config = indented.Bytes()
+		}
+	}
+
+	if o.output == "" {
+		o.Printer.DefaultText.Print(string(config))
+		return nil
+	}
+
+	if err = os.WriteFile(filepath.Clean(o.output), config, 0o600); err != nil {
+		return fmt.Errorf("unable to write config to %q: %w", o.output, err)
+	}
+	o.Printer.Success.Printfln("Config of %q written to %q", ref, o.output)
+
+	return nil
+}
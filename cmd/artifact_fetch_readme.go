@@ -0,0 +1,221 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+const (
+	// readmeFormatRendered renders the README for the terminal.
+	readmeFormatRendered = "rendered"
+	// readmeFormatRaw prints the README as is.
+	readmeFormatRaw = "raw"
+	// defaultPager is used by --pager when $PAGER is not set.
+	defaultPager = "less -R"
+)
+
+var longFetchReadme = `Retrieve and display the documentation of an artifact
+
+The README of the artifact is read from the first layer, of the manifest for the platform where
+falcoctl is running, having the "text/markdown" media type. If there is none, the documentation is
+downloaded from the URL in the "org.opencontainers.image.documentation" annotation, as long as
+it is not an HTML page: use "falcoctl artifact open" to browse it.
+
+The README is rendered for the terminal, or printed as is with --format raw. With --pager it is
+piped through the pager set by $PAGER, defaulting to "less -R". With --output the Markdown is
+written to a file instead.
+
+Example - Display the README of the "cloudtrail" artifact:
+	falcoctl artifact fetch-readme cloudtrail --pager
+
+Example - Save the README of an artifact given its reference:
+	falcoctl artifact fetch-readme ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.6.0 --output README.md
+`
+
+type artifactFetchReadmeOptions struct {
+	*options.CommonOptions
+	output string
+	format string
+	pager  bool
+}
+
+func (o *artifactFetchReadmeOptions) validate() error {
+	switch o.format {
+	case readmeFormatRendered, readmeFormatRaw:
+		return nil
+	default:
+		return fmt.Errorf("format %q not supported, allowed values: %q, %q", o.format, readmeFormatRendered, readmeFormatRaw)
+	}
+}
+
+// NewArtifactFetchReadmeCmd returns the artifact fetch-readme command.
+func NewArtifactFetchReadmeCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactFetchReadmeOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "fetch-readme name|ref [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Retrieve and display the documentation of an artifact",
+		Long:                  longFetchReadme,
+		Args:                  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if o.output == "" {
+				// Keep stdout clean, it only holds the README.
+				o.Printer.RedirectLogs(os.Stderr)
+			}
+			o.Printer.CheckErr(o.RunArtifactFetchReadme(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.output, "output", "", "file where the Markdown of the README is written. Defaults to stdout")
+	cmd.Flags().StringVar(&o.format, "format", readmeFormatRendered,
+		`format of the README printed to stdout. Allowed values: "rendered", "raw"`)
+	cmd.Flags().BoolVar(&o.pager, "pager", false, `pipe the README through $PAGER, defaulting to "`+defaultPager+`"`)
+
+	return cmd
+}
+
+// RunArtifactFetchReadme executes the business logic for the artifact fetch-readme command.
+func (o *artifactFetchReadmeOptions) RunArtifactFetchReadme(ctx context.Context, args []string) error {
+	indexConfig, err := index.NewConfig(indexesFile)
+	if err != nil {
+		return err
+	}
+
+	mergedIndexes, err := utils.Indexes(indexConfig, falcoctlPath)
+	if err != nil {
+		return err
+	}
+
+	ref, err := utils.ParseReference(mergedIndexes, args[0])
+	if err != nil {
+		return err
+	}
+
+	if ref, err = rewriteReference(o.Printer, ref); err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	client, err := registryClient(ctx, credentialStore, ref)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := oci.FetchManifest(ctx, ref, client, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+
+	readme, err := oci.FetchReadme(ctx, ref, client, manifest)
+	if errors.Is(err, oci.ErrNoReadme) {
+		url := manifest.Annotations[v1.AnnotationDocumentation]
+		if url == "" {
+			return fmt.Errorf("%q has neither a README layer nor a %q annotation", ref, v1.AnnotationDocumentation)
+		}
+		o.Printer.Verbosef("No README layer found, fetching the documentation from %q", url)
+		readme, err = fetchDocumentation(ctx, url)
+	}
+	if err != nil {
+		return err
+	}
+
+	if o.output != "" {
+		if err = os.WriteFile(filepath.Clean(o.output), readme, 0o600); err != nil {
+			return fmt.Errorf("unable to write README to %q: %w", o.output, err)
+		}
+		o.Printer.Success.Printfln("README of %q written to %q", ref, o.output)
+		return nil
+	}
+
+	text := string(readme)
+	if o.format == readmeFormatRendered {
+		text = output.RenderMarkdown(text)
+	}
+
+	if !o.pager {
+		o.Printer.DefaultText.Print(text)
+		return nil
+	}
+
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = defaultPager
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", pager) //nolint:gosec // the pager is set by the user
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("pager %q failed: %w", pager, err)
+	}
+
+	return nil
+}
+
+// fetchDocumentation downloads the Markdown documentation at url, rejecting HTML pages.
+func fetchDocumentation(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot download %q: unexpected status %q", url, resp.Status)
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil && mediaType == "text/html" {
+		return nil, fmt.Errorf("documentation at %q is an HTML page, use \"falcoctl artifact open\" to browse it", url)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, oci.DefaultMaxMetadataSize))
+}
@@ -0,0 +1,194 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+var longFork = `Create a locally maintained copy of an artifact
+
+The source artifact, with all its platforms, is copied to the destination reference as done by
+"registry copy", and the annotations of its manifests and indexes are updated to reflect the fork:
+  - "org.opencontainers.image.title" is set to --name;
+  - "org.opencontainers.image.authors" is set to --maintainer, in "Name <email>" format;
+  - "org.opencontainers.image.description" is set to --description, defaulting to the original
+    description prefixed by the source reference;
+  - "org.opencontainers.image.source" is set to --source, defaulting to the destination repository;
+  - "org.falcosecurity.artifact.forked-from" is set to the source reference, pinned by digest.
+Layers and configs are copied unchanged. Since a forked artifact records the one it was forked from,
+forks of forks form an auditable provenance chain.
+
+Example - Fork the "k8saudit-rules" rulesfile to customize it:
+	falcoctl artifact fork k8saudit-rules ghcr.io/myorg/rules/k8saudit-rules:0.6.0 \
+		--name "My k8saudit rules" --maintainer "Jane Doe <jane@myorg.com>"
+`
+
+type artifactForkOptions struct {
+	*options.CommonOptions
+	name        string
+	maintainer  string
+	description string
+	source      string
+}
+
+func (o *artifactForkOptions) validate() error {
+	if o.name == "" {
+		return fmt.Errorf("--name must be set")
+	}
+	if o.maintainer == "" {
+		return fmt.Errorf("--maintainer must be set")
+	}
+	if _, err := mail.ParseAddress(o.maintainer); err != nil {
+		return fmt.Errorf("--maintainer %q not in \"Name <email>\" format: %w", o.maintainer, err)
+	}
+	return nil
+}
+
+// NewArtifactForkCmd returns the artifact fork command.
+func NewArtifactForkCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactForkOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "fork name|ref hostname/repo:tag --name name --maintainer \"Name <email>\" [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Create a locally maintained copy of an artifact",
+		Long:                  longFork,
+		Args:                  cobra.ExactArgs(2),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactFork(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.name, "name", "", "display name of the fork, set as its title")
+	cmd.Flags().StringVar(&o.maintainer, "maintainer", "", "maintainer of the fork, in \"Name <email>\" format")
+	cmd.Flags().StringVar(&o.description, "description", "",
+		"description of the fork. Defaults to the original description prefixed by the source reference")
+	cmd.Flags().StringVar(&o.source, "source", "", "URL of the source code of the fork. Defaults to the destination repository")
+
+	return cmd
+}
+
+// RunArtifactFork executes the business logic for the artifact fork command.
+func (o *artifactForkOptions) RunArtifactFork(ctx context.Context, args []string) error {
+	indexConfig, err := index.NewConfig(indexesFile)
+	if err != nil {
+		return err
+	}
+
+	mergedIndexes, err := utils.Indexes(indexConfig, falcoctlPath)
+	if err != nil {
+		return err
+	}
+
+	src, err := utils.ParseReference(mergedIndexes, args[0])
+	if err != nil {
+		return err
+	}
+
+	if src, err = rewriteReference(o.Printer, src); err != nil {
+		return err
+	}
+
+	dst, err := normalizeReference(o.Printer, args[1])
+	if err != nil {
+		return err
+	}
+
+	parsedDst, err := registry.ParseReference(dst)
+	if err != nil {
+		return err
+	}
+	if parsedDst.Reference == "" {
+		return fmt.Errorf("a destination reference with a tag is required, got %q", args[1])
+	}
+	if _, err = parsedDst.Digest(); err == nil {
+		return fmt.Errorf("a destination reference with a tag is required, got %q", args[1])
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	srcClient, err := registryClient(ctx, credentialStore, src)
+	if err != nil {
+		return err
+	}
+
+	dstClient, err := registryClient(ctx, credentialStore, dst)
+	if err != nil {
+		return err
+	}
+
+	srcDesc, srcAnnotations, err := oci.FetchAnnotations(ctx, src, srcClient)
+	if err != nil {
+		return err
+	}
+
+	pinned, err := oci.PinReference(src, srcDesc.Digest.String())
+	if err != nil {
+		return err
+	}
+
+	description := o.description
+	if description == "" {
+		description = fmt.Sprintf("Fork of %s", pinned)
+		if original := srcAnnotations[v1.AnnotationDescription]; original != "" {
+			description = fmt.Sprintf("%s: %s", description, original)
+		}
+	}
+
+	source := o.source
+	if source == "" {
+		parsedDst.Reference = ""
+		source = parsedDst.String()
+	}
+
+	annotations := map[string]string{
+		v1.AnnotationTitle:       o.name,
+		v1.AnnotationAuthors:     o.maintainer,
+		v1.AnnotationDescription: description,
+		v1.AnnotationSource:      source,
+		oci.ForkedFromAnnotation: pinned,
+	}
+
+	o.Printer.Info.Printfln("Forking %q to %q", pinned, dst)
+	_, dstDesc, err := oci.Fork(ctx, src, srcClient, dst, dstClient, annotations)
+	if err != nil {
+		return err
+	}
+
+	o.Printer.Success.Printfln("Artifact forked to %q, digest: %s", dst, dstDesc.Digest)
+
+	return nil
+}
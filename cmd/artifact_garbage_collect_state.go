@@ -0,0 +1,122 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+var longGarbageCollectState = `Remove the orphaned entries from the state file
+
+The files of each installed artifact are checked against the state file. The entries none of whose
+files is on disk and accessible, e.g. because they were deleted by hand, are removed, as well as the
+duplicate entries for the same artifact, keeping the most recently updated one. Files whose digest
+differs from the recorded one are only reported: use "artifact repair" to download them again.
+
+Example - List the orphaned entries without modifying the state file:
+	falcoctl artifact garbage-collect-state --dry-run
+
+Example - Remove the orphaned entries:
+	falcoctl artifact garbage-collect-state
+`
+
+type artifactGarbageCollectStateOptions struct {
+	*options.CommonOptions
+	dryRun bool
+}
+
+// NewArtifactGarbageCollectStateCmd returns the artifact garbage-collect-state command.
+func NewArtifactGarbageCollectStateCmd(opt *options.CommonOptions) *cobra.Command {
+	o := artifactGarbageCollectStateOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "garbage-collect-state [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Remove the orphaned entries from the state file",
+		Long:                  longGarbageCollectState,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactGarbageCollectState())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", false, "only list the orphaned entries, without modifying the state file")
+
+	return cmd
+}
+
+// RunArtifactGarbageCollectState executes the business logic for the artifact garbage-collect-state command.
+func (o *artifactGarbageCollectStateOptions) RunArtifactGarbageCollectState() error {
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return err
+	}
+
+	action := "Removed"
+	if o.dryRun {
+		action = "Would remove"
+	}
+
+	removed := 0
+	for _, entry := range installedState.RemoveDuplicates() {
+		o.Printer.Info.Printfln("%s duplicate entry %q updated on %s", action, entry.Name, entry.UpdatedTimestamp)
+		removed++
+	}
+
+	var entries []state.Entry
+	for i := range installedState.Entries {
+		entry := &installedState.Entries[i]
+
+		missing, modified := entry.CheckFiles()
+		if len(missing) == len(entry.Files) {
+			o.Printer.Info.Printfln("%s orphaned entry %q, none of its %d file(s) is on disk", action, entry.Name, len(entry.Files))
+			removed++
+			continue
+		}
+
+		for _, path := range missing {
+			o.Printer.Verbosef("%s: %q missing, run \"falcoctl artifact repair\" to prune it", entry.Name, path)
+		}
+		for _, path := range modified {
+			o.Printer.Warning.Printfln("%s: %q modified, run \"falcoctl artifact repair\" to restore it", entry.Name, path)
+		}
+		entries = append(entries, *entry)
+	}
+
+	if removed == 0 {
+		o.Printer.Success.Println("No orphaned entry found")
+		return nil
+	}
+
+	if o.dryRun {
+		o.Printer.Success.Printfln("%d entry(ies) would be removed from %q", removed, stateFile)
+		return nil
+	}
+
+	installedState.Entries = entries
+	if err = installedState.Write(stateFile); err != nil {
+		return fmt.Errorf("cannot update state file %q: %w", stateFile, err)
+	}
+
+	o.Printer.Success.Printfln("%d entry(ies) removed from %q", removed, stateFile)
+	return nil
+}
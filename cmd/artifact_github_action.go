@@ -0,0 +1,224 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const (
+	defaultGithubActionName = "falco-artifact-push"
+	githubActionFile        = "action.yml"
+)
+
+var longGithubAction = `Generate a reusable GitHub Actions composite action to build and push Falco artifacts
+
+The generated action builds the artifact using a Makefile target, authenticates to the
+registry, pushes the artifact using falcoctl, signs it with cosign and attaches an SBOM to it.
+All the parameters are exposed as action inputs.
+
+Example - Generate the action in ".github/actions/falco-artifact-push":
+	falcoctl artifact github-action
+
+Example - Generate the action named "push" in ".github/actions/push":
+	falcoctl artifact github-action --name push --output .github/actions/push
+
+Example - Use the generated action in a workflow:
+	- uses: ./.github/actions/falco-artifact-push
+	  with:
+	    artifact-ref: ghcr.io/myorg/myplugin:0.1.0
+	    artifact-type: plugin
+	    artifact-path: myplugin.tar.gz
+	    platform: linux/amd64
+	    registry-password: ${{ secrets.GITHUB_TOKEN }}
+	    cosign-private-key: ${{ secrets.COSIGN_PRIVATE_KEY }}
+	    cosign-password: ${{ secrets.COSIGN_PASSWORD }}
+`
+
+// githubActionTemplate uses "[[" and "]]" as delimiters to not clash with the GitHub expressions syntax.
+var githubActionTemplate = template.Must(template.New(githubActionFile).Delims("[[", "]]").Parse(`name: [[ .Name ]]
+description: Build, push, sign and attach an SBOM to a Falco artifact using falcoctl
+
+inputs:
+  registry:
+    description: Registry where to push the artifact
+    required: false
+    default: ghcr.io
+  registry-username:
+    description: Username used to authenticate to the registry
+    required: false
+    default: ${{ github.actor }}
+  registry-password:
+    description: Password or token used to authenticate to the registry
+    required: true
+  artifact-ref:
+    description: Reference of the artifact to be pushed, e.g. ghcr.io/myorg/myplugin:0.1.0
+    required: true
+  artifact-type:
+    description: Type of the artifact, either "plugin" or "rulesfile"
+    required: false
+    default: plugin
+  artifact-path:
+    description: Path of the artifact tarball produced by the build
+    required: true
+  platform:
+    description: Platform of the artifact in OS/ARCH format (only for plugins)
+    required: false
+    default: linux/amd64
+  make-target:
+    description: Makefile target used to build the artifact
+    required: false
+    default: [[ .MakeTarget ]]
+  falcoctl-version:
+    description: Version of falcoctl to be used
+    required: false
+    default: [[ .FalcoctlVersion ]]
+  cosign-private-key:
+    description: Cosign private key used to sign the artifact. Signing is skipped if empty
+    required: false
+    default: ""
+  cosign-password:
+    description: Password of the cosign private key
+    required: false
+    default: ""
+  sbom:
+    description: Generate and attach an SBOM to the artifact
+    required: false
+    default: "true"
+
+runs:
+  using: composite
+  steps:
+    - name: Build artifact
+      shell: bash
+      run: make ${{ inputs.make-target }}
+
+    - name: Install falcoctl
+      shell: bash
+      run: |
+        version="${{ inputs.falcoctl-version }}"
+        url="https://github.com/falcosecurity/falcoctl/releases/download/v${version}"
+        curl --fail -LS "${url}/falcoctl_${version}_linux_amd64.tar.gz" | tar -xz falcoctl
+        sudo install -o root -g root -m 0755 falcoctl /usr/local/bin/falcoctl
+
+    - name: Authenticate to the registry
+      shell: bash
+      env:
+        REGISTRY_PASSWORD: ${{ inputs.registry-password }}
+      run: echo "${REGISTRY_PASSWORD}" | docker login "${{ inputs.registry }}" --username "${{ inputs.registry-username }}" --password-stdin
+
+    - name: Push artifact
+      shell: bash
+      run: |
+        if [ "${{ inputs.artifact-type }}" = "plugin" ]; then
+          falcoctl registry push --type plugin "${{ inputs.artifact-ref }}" "${{ inputs.artifact-path }}" --platform "${{ inputs.platform }}"
+        else
+          falcoctl registry push --type "${{ inputs.artifact-type }}" "${{ inputs.artifact-ref }}" "${{ inputs.artifact-path }}"
+        fi
+
+    - name: Install cosign
+      if: ${{ inputs.cosign-private-key != '' || inputs.sbom == 'true' }}
+      uses: sigstore/cosign-installer@v2
+
+    - name: Sign artifact
+      if: ${{ inputs.cosign-private-key != '' }}
+      shell: bash
+      env:
+        COSIGN_PRIVATE_KEY: ${{ inputs.cosign-private-key }}
+        COSIGN_PASSWORD: ${{ inputs.cosign-password }}
+      run: cosign sign --key env://COSIGN_PRIVATE_KEY "${{ inputs.artifact-ref }}"
+
+    - name: Generate SBOM
+      if: ${{ inputs.sbom == 'true' }}
+      uses: anchore/sbom-action@v0
+      with:
+        path: ${{ inputs.artifact-path }}
+        format: spdx-json
+        output-file: sbom.spdx.json
+        upload-artifact: false
+
+    - name: Attach SBOM
+      if: ${{ inputs.sbom == 'true' }}
+      shell: bash
+      run: cosign attach sbom --sbom sbom.spdx.json "${{ inputs.artifact-ref }}"
+`))
+
+type artifactGithubActionOptions struct {
+	*options.CommonOptions
+	Name            string
+	MakeTarget      string
+	FalcoctlVersion string
+	output          string
+}
+
+// NewArtifactGithubActionCmd returns the artifact github-action command.
+func NewArtifactGithubActionCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactGithubActionOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "github-action [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Generate a GitHub Actions composite action to push Falco artifacts",
+		Long:                  longGithubAction,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactGithubAction(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Name, "name", defaultGithubActionName, "name of the generated action")
+	cmd.Flags().StringVar(&o.output, "output", "",
+		`directory where to write the action. Defaults to ".github/actions/<name>"`)
+	cmd.Flags().StringVar(&o.MakeTarget, "make-target", "build", "default Makefile target used to build the artifact")
+	cmd.Flags().StringVar(&o.FalcoctlVersion, "falcoctl-version", "0.2.0", "default falcoctl version used by the action")
+
+	return cmd
+}
+
+// RunArtifactGithubAction executes the business logic for the artifact github-action command.
+func (o *artifactGithubActionOptions) RunArtifactGithubAction(ctx context.Context, args []string) error {
+	if o.output == "" {
+		o.output = filepath.Join(".github", "actions", o.Name)
+	}
+
+	if err := os.MkdirAll(o.output, 0o750); err != nil {
+		return fmt.Errorf("cannot create directory %q: %w", o.output, err)
+	}
+
+	path := filepath.Join(o.output, githubActionFile)
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err = githubActionTemplate.Execute(f, o); err != nil {
+		return fmt.Errorf("cannot generate %q: %w", path, err)
+	}
+
+	o.Printer.Success.Printfln("GitHub action %q written to %q", o.Name, path)
+
+	return nil
+}
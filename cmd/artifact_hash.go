@@ -0,0 +1,127 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+var longHash = `Print the digest of the manifest of an artifact
+
+The reference is resolved without downloading the artifact content, making the digest
+suitable to be used as cache key. With --layer, the digest of the Nth layer of the manifest
+is printed instead. For multi-platform artifacts, the manifest of the platform where
+falcoctl is running is used.
+
+Example - Print the digest of the manifest of "cloudtrail:0.6.0":
+	falcoctl artifact hash cloudtrail:0.6.0
+
+Example - Print the digest of the first layer of an artifact given its reference:
+	falcoctl artifact hash ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.6.0 --layer 0
+`
+
+type artifactHashOptions struct {
+	*options.CommonOptions
+	layer int
+}
+
+// NewArtifactHashCmd returns the artifact hash command.
+func NewArtifactHashCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactHashOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "hash name|ref [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Print the digest of the manifest of an artifact",
+		Long:                  longHash,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactHash(ctx, args))
+		},
+	}
+
+	cmd.Flags().IntVar(&o.layer, "layer", -1, "print the digest of the Nth layer, starting from 0, instead of the manifest one")
+
+	return cmd
+}
+
+// RunArtifactHash executes the business logic for the artifact hash command.
+func (o *artifactHashOptions) RunArtifactHash(ctx context.Context, args []string) error {
+	indexConfig, err := index.NewConfig(indexesFile)
+	if err != nil {
+		return err
+	}
+
+	mergedIndexes, err := utils.Indexes(indexConfig, falcoctlPath)
+	if err != nil {
+		return err
+	}
+
+	ref, err := utils.ParseReference(mergedIndexes, args[0])
+	if err != nil {
+		return err
+	}
+
+	reg, err := utils.GetRegistryFromRef(ref)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return err
+	}
+
+	client := authn.NewClient(cred)
+
+	if o.layer < 0 {
+		desc, err := oci.Resolve(ctx, ref, client)
+		if err != nil {
+			return err
+		}
+		o.Printer.DefaultText.Println(desc.Digest.String())
+		return nil
+	}
+
+	manifest, err := oci.FetchManifest(ctx, ref, client, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+
+	if o.layer >= len(manifest.Layers) {
+		return fmt.Errorf("layer %d not found: %q has %d layers", o.layer, ref, len(manifest.Layers))
+	}
+
+	o.Printer.DefaultText.Println(manifest.Layers[o.layer].Digest.String())
+
+	return nil
+}
@@ -0,0 +1,189 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+const (
+	defaultHelmPluginsDir    = "/plugins"
+	defaultHelmRulesfilesDir = "/rulesfiles"
+)
+
+var longHelmValues = `Generate the falcoctl section of the values.yaml file of the Falco Helm chart
+
+The generated values enable the falcoctl artifact install feature of the chart and
+configure it to install the same artifacts installed locally by falcoctl, as recorded
+in the state file. Artifacts installed from a URL are skipped, since the chart installs
+artifacts from registries only. Alternatively, the references can be read from a spec file:
+
+	refs:
+	  - ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.6.0
+	  - ghcr.io/falcosecurity/plugins/ruleset/cloudtrail:0.6.0
+
+Example - Print the values for the artifacts installed locally:
+	falcoctl artifact helm-values
+
+Example - Write the values generated from a spec file to "falcoctl-values.yaml":
+	falcoctl artifact helm-values --spec artifacts.yaml --output-file falcoctl-values.yaml
+
+Example - Merge the generated values in a Helm deployment:
+	helm install falco falcosecurity/falco -f values.yaml -f falcoctl-values.yaml
+`
+
+type artifactHelmValuesOptions struct {
+	*options.CommonOptions
+	specFile      string
+	outputFile    string
+	rulesfilesDir string
+	pluginsDir    string
+}
+
+// helmValuesSpec is the spec file accepted by the helm-values command.
+type helmValuesSpec struct {
+	Refs []string `yaml:"refs"`
+}
+
+// helmValues mirrors the falcoctl section of the values.yaml file of the Falco Helm chart.
+type helmValues struct {
+	Falcoctl struct {
+		Artifact struct {
+			Install struct {
+				Enabled bool `yaml:"enabled"`
+			} `yaml:"install"`
+		} `yaml:"artifact"`
+		Config struct {
+			Artifact struct {
+				AllowedTypes []string `yaml:"allowedTypes"`
+				Install      struct {
+					Refs          []string `yaml:"refs"`
+					RulesfilesDir string   `yaml:"rulesfilesDir"`
+					PluginsDir    string   `yaml:"pluginsDir"`
+				} `yaml:"install"`
+			} `yaml:"artifact"`
+		} `yaml:"config"`
+	} `yaml:"falcoctl"`
+}
+
+// NewArtifactHelmValuesCmd returns the artifact helm-values command.
+func NewArtifactHelmValuesCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactHelmValuesOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "helm-values [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Generate the falcoctl values for the Falco Helm chart",
+		Long:                  longHelmValues,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactHelmValues(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.specFile, "spec", "",
+		"spec file containing the references to be installed. Defaults to the artifacts recorded in the state file")
+	cmd.Flags().StringVar(&o.outputFile, "output-file", "",
+		"file where to write the generated values. Defaults to stdout")
+	cmd.Flags().StringVar(&o.rulesfilesDir, "rulesfiles-dir", defaultHelmRulesfilesDir,
+		"directory where the chart installs rules. Defaults to /rulesfiles")
+	cmd.Flags().StringVar(&o.pluginsDir, "plugins-dir", defaultHelmPluginsDir,
+		"directory where the chart installs plugins. Defaults to /plugins")
+
+	return cmd
+}
+
+// RunArtifactHelmValues executes the business logic for the artifact helm-values command.
+func (o *artifactHelmValuesOptions) RunArtifactHelmValues(ctx context.Context, args []string) error {
+	refs, err := o.refs()
+	if err != nil {
+		return err
+	}
+
+	if len(refs) == 0 {
+		o.Printer.Warning.Println("No artifact to install found, the generated values will not install any artifact")
+	}
+
+	var values helmValues
+	values.Falcoctl.Artifact.Install.Enabled = true
+	values.Falcoctl.Config.Artifact.AllowedTypes = []string{"plugin", "rulesfile"}
+	values.Falcoctl.Config.Artifact.Install.Refs = refs
+	values.Falcoctl.Config.Artifact.Install.RulesfilesDir = o.rulesfilesDir
+	values.Falcoctl.Config.Artifact.Install.PluginsDir = o.pluginsDir
+
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("cannot marshal helm values: %w", err)
+	}
+
+	if o.outputFile == "" {
+		o.Printer.DefaultText.Print(string(data))
+		return nil
+	}
+
+	if err = os.WriteFile(filepath.Clean(o.outputFile), data, 0o600); err != nil {
+		return fmt.Errorf("cannot write helm values to %q: %w", o.outputFile, err)
+	}
+
+	o.Printer.Success.Printfln("Helm values written to %q", o.outputFile)
+
+	return nil
+}
+
+// refs returns the references to be installed by the chart, reading them from
+// the spec file if given, from the state file otherwise.
+func (o *artifactHelmValuesOptions) refs() ([]string, error) {
+	if o.specFile != "" {
+		data, err := os.ReadFile(filepath.Clean(o.specFile))
+		if err != nil {
+			return nil, err
+		}
+
+		var spec helmValuesSpec
+		if err = yaml.Unmarshal(data, &spec); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal spec file %q: %w", o.specFile, err)
+		}
+
+		return spec.Refs, nil
+	}
+
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]string, 0, len(installedState.Entries))
+	for i := range installedState.Entries {
+		entry := &installedState.Entries[i]
+		if entry.URL != "" || entry.Ref == "" {
+			o.Printer.Warning.Printfln("Skipping %q, installed from URL %q", entry.Name, entry.URL)
+			continue
+		}
+		refs = append(refs, entry.Ref)
+	}
+
+	return refs, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+func TestHelmValuesFromState(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldStateFile := stateFile
+	stateFile = filepath.Join(tmpDir, "state.yaml")
+	t.Cleanup(func() { stateFile = oldStateFile })
+
+	s := &state.State{Entries: []state.Entry{
+		{Name: "cloudtrail", Type: "plugin", Ref: "ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.6.0"},
+		{Name: "custom", Type: "rulesfile", URL: "https://example.com/custom_rules.yaml"},
+		{Name: "k8saudit-rules", Type: "rulesfile", Ref: "ghcr.io/falcosecurity/plugins/ruleset/k8saudit:0.5.0"},
+	}}
+	if err := s.Write(stateFile); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	outputFile := filepath.Join(tmpDir, "values.yaml")
+	o := &artifactHelmValuesOptions{
+		CommonOptions: &options.CommonOptions{Printer: output.NewPrinter("", false, &bytes.Buffer{})},
+		outputFile:    outputFile,
+		rulesfilesDir: defaultHelmRulesfilesDir,
+		pluginsDir:    defaultHelmPluginsDir,
+	}
+	if err := o.RunArtifactHelmValues(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Clean(outputFile))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var values helmValues
+	if err = yaml.Unmarshal(data, &values); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.6.0",
+		"ghcr.io/falcosecurity/plugins/ruleset/k8saudit:0.5.0",
+	}
+	if refs := values.Falcoctl.Config.Artifact.Install.Refs; !reflect.DeepEqual(refs, expected) {
+		t.Errorf("expected refs %v, got %v", expected, refs)
+	}
+	if !values.Falcoctl.Artifact.Install.Enabled {
+		t.Errorf("expected the artifact install to be enabled")
+	}
+}
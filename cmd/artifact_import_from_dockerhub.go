@@ -0,0 +1,219 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	ocipusher "github.com/falcosecurity/falcoctl/pkg/oci/pusher"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+var longImportFromDockerHub = `Import a plugin or rulesfile published as a container image into a Falco OCI artifact
+
+The container image is pulled from Docker Hub, or from any other registry if its reference
+contains a registry host, for the given platform. The files matching --file are extracted
+from its layers, by default "*.so" for plugins and "*.yaml", "*.yml" for rulesfiles, and
+packed in the main layer of a Falco artifact with the correct media types, which is then
+pushed to the reference given with --output.
+
+Labels of the image are mapped to the equivalent OCI annotations of the artifact manifest,
+e.g. "org.label-schema.vcs-url" becomes "org.opencontainers.image.source".
+
+Example - Import an old plugin image into ghcr.io:
+	falcoctl artifact import-from-dockerhub falcosecurity/myplugin:0.1.0 --type plugin \
+		--output ghcr.io/myorg/plugins/plugin/myplugin:0.1.0
+
+Example - Import the linux/arm64 build of a plugin image:
+	falcoctl artifact import-from-dockerhub falcosecurity/myplugin:0.1.0 --type plugin --platform linux/arm64 \
+		--output ghcr.io/myorg/plugins/plugin/myplugin:0.1.0
+
+Example - Import a rulesfile image, selecting the rules file to import:
+	falcoctl artifact import-from-dockerhub falcosecurity/myrules:0.1.0 --type rulesfile \
+		--file myrules.yaml --output ghcr.io/myorg/rules/myrules:0.1.0
+`
+
+type artifactImportFromDockerHubOptions struct {
+	*options.CommonOptions
+	artifactType oci.ArtifactType
+	output       string
+	platform     string
+	files        []string
+}
+
+// NewArtifactImportFromDockerHubCmd returns the artifact import-from-dockerhub command.
+func NewArtifactImportFromDockerHubCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactImportFromDockerHubOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "import-from-dockerhub dockerhub-ref --type plugin|rulesfile --output hostname/repo[:tag] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Import a plugin or rulesfile published as a container image into a Falco OCI artifact",
+		Long:                  longImportFromDockerHub,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactImportFromDockerHub(ctx, args))
+		},
+	}
+
+	cmd.Flags().Var(&o.artifactType, "type", `type of the artifact. Allowed values: "rulesfile", "plugin"`)
+	cmd.Flags().StringVar(&o.output, "output", "", "reference of the Falco artifact to be pushed")
+	cmd.Flags().StringVar(&o.platform, "platform", "",
+		"os and architecture of the image to be imported in OS/ARCH format. Defaults to the platform where falcoctl is running")
+	cmd.Flags().StringSliceVar(&o.files, "file", nil,
+		`comma separated list of glob patterns matching the base name of the files to be imported. Defaults to "*.so" for plugins, `+
+			`"*.yaml,*.yml" for rulesfiles`)
+
+	return cmd
+}
+
+// RunArtifactImportFromDockerHub executes the business logic for the artifact import-from-dockerhub command.
+func (o *artifactImportFromDockerHubOptions) RunArtifactImportFromDockerHub(ctx context.Context, args []string) error {
+	switch {
+	case o.artifactType == "":
+		return fmt.Errorf("--type must be set")
+	case o.output == "":
+		return fmt.Errorf("--output must be set")
+	}
+
+	goos, goarch := runtime.GOOS, runtime.GOARCH
+	if o.platform != "" {
+		var ok bool
+		if goos, goarch, ok = strings.Cut(o.platform, "/"); !ok || goos == "" || goarch == "" {
+			return fmt.Errorf("platform %q seems to be in the wrong format: needs to be in OS/ARCH", o.platform)
+		}
+	}
+
+	patterns := o.files
+	if len(patterns) == 0 {
+		switch o.artifactType {
+		case oci.Plugin:
+			patterns = []string{"*.so"}
+		case oci.Rulesfile:
+			patterns = []string{"*.yaml", "*.yml"}
+		}
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+		}
+	}
+
+	src, err := normalizeReference(o.Printer, args[0])
+	if err != nil {
+		return err
+	}
+	if src, err = rewriteReference(o.Printer, src); err != nil {
+		return err
+	}
+
+	dst, err := normalizeReference(o.Printer, o.output)
+	if err != nil {
+		return err
+	}
+
+	if _, err = registry.ParseReference(dst); err != nil {
+		return fmt.Errorf("invalid output reference %q: %w", o.output, err)
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	srcClient, err := registryClient(ctx, credentialStore, src)
+	if err != nil {
+		return err
+	}
+
+	o.Printer.Info.Printfln("Fetching image %q for platform %s/%s", src, goos, goarch)
+
+	manifest, err := oci.FetchManifest(ctx, src, srcClient, goos, goarch)
+	if err != nil {
+		return err
+	}
+
+	image, err := oci.FetchImageConfig(ctx, src, srcClient, manifest)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "falcoctl")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// The directory is packed in the main layer, which is named after it.
+	layerDir := filepath.Join(tmpDir, utils.ArtifactName(dst))
+	if err = os.Mkdir(layerDir, 0o750); err != nil {
+		return err
+	}
+
+	files, err := oci.ExtractImageFiles(ctx, src, srcClient, manifest, patterns, layerDir)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no file matching %q found in image %q", strings.Join(patterns, ","), src)
+	}
+	for _, f := range files {
+		o.Printer.Verbosef("Importing %q", filepath.Base(f))
+	}
+
+	annotations := oci.AnnotationsFromLabels(image.Config.Labels)
+	for key, value := range annotations {
+		o.Printer.Verbosef("Label mapped to annotation %q: %q", key, value)
+	}
+
+	dstClient, err := registryClient(ctx, credentialStore, dst)
+	if err != nil {
+		return err
+	}
+
+	opts := ocipusher.Options{ocipusher.WithAnnotations(annotations)}
+	switch o.artifactType {
+	case oci.Plugin:
+		opts = append(opts, ocipusher.WithFilepathsAndPlatforms([]string{layerDir}, []string{goos + "/" + goarch}))
+	case oci.Rulesfile:
+		opts = append(opts, ocipusher.WithFilepaths([]string{layerDir}))
+	}
+
+	pusher := ocipusher.NewPusher(dstClient, false, newPushProgressTracker(o.Printer))
+	res, err := pusher.Push(ctx, o.artifactType, dst, opts...)
+	if err != nil {
+		return err
+	}
+
+	o.Printer.Success.Printfln("Image %q imported as %q %q. Digest: %q", args[0], o.artifactType, dst, res.Digest)
+
+	recordTransferredFiles(o.Printer, files...)
+
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/intoto"
+	"github.com/falcosecurity/falcoctl/pkg/metrics"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+var longInTotoVerify = `Verify the in-toto link metadata attached to an artifact against a supply chain layout
+
+The link metadata are retrieved from the referrers of the artifact, as layers with media type
+"application/vnd.in-toto.link+json" or "application/vnd.in-toto+json". The layout must be signed by
+all the keys in --layout-keys and must not be expired, and the ID of each key must match the key. For each
+step of the layout, the links must be signed by at least "threshold" distinct functionaries among the keys
+of the step, must record the same materials and products, and these must satisfy the artifact rules of the
+step (expected materials and products). A command differing from the expected one is reported as a warning.
+
+Layouts with inspections are rejected, since verifying them would require running their commands.
+
+The command exits with code 1 if any step fails.
+
+Example - Verify the supply chain of a plugin:
+	falcoctl artifact in-toto-verify ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.3.0 \
+		--layout root.layout --layout-keys layout-keys.json
+`
+
+type artifactInTotoVerifyOptions struct {
+	*options.CommonOptions
+	layout     string
+	layoutKeys string
+}
+
+func (o *artifactInTotoVerifyOptions) validate() error {
+	if o.layout == "" {
+		return fmt.Errorf("--layout must be set")
+	}
+	if o.layoutKeys == "" {
+		return fmt.Errorf("--layout-keys must be set")
+	}
+	return nil
+}
+
+// NewArtifactInTotoVerifyCmd returns the artifact in-toto-verify command.
+func NewArtifactInTotoVerifyCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactInTotoVerifyOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "in-toto-verify hostname/repo[:tag|@digest] --layout layout.json --layout-keys keys.json [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Verify the in-toto link metadata attached to an artifact against a supply chain layout",
+		Long:                  longInTotoVerify,
+		Args:                  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactInTotoVerify(ctx, args))
+		},
+	}
+
+	o.CommonOptions.AddOutputFlags(cmd.Flags())
+	cmd.Flags().StringVar(&o.layout, "layout", "", "signed in-toto layout file")
+	cmd.Flags().StringVar(&o.layoutKeys, "layout-keys", "",
+		"json file with the public keys of the layout owners, as a map of key IDs to keys in the in-toto format")
+
+	return cmd
+}
+
+// RunArtifactInTotoVerify executes the business logic for the artifact in-toto-verify command.
+func (o *artifactInTotoVerifyOptions) RunArtifactInTotoVerify(ctx context.Context, args []string) error {
+	ref := args[0]
+
+	layout, err := intoto.LoadMetablock(o.layout)
+	if err != nil {
+		return fmt.Errorf("unable to load layout: %w", err)
+	}
+
+	layoutKeys, err := intoto.LoadKeys(o.layoutKeys)
+	if err != nil {
+		return err
+	}
+
+	reg, err := utils.GetRegistryFromRef(ref)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return err
+	}
+
+	links, err := intoto.FetchLinks(ctx, ref, authn.NewClient(cred))
+	if err != nil && !errors.Is(err, oci.ErrIncompleteReferrers) {
+		return err
+	}
+	if err != nil {
+		o.Printer.Warning.Printfln("the list of link metadata may be incomplete: %s", err.Error())
+	}
+	o.Printer.Verbosef("Found %d link metadata for %q", len(links), ref)
+
+	results, err := intoto.Verify(layout, layoutKeys, links, time.Now())
+	if err != nil {
+		return fmt.Errorf("unable to verify layout %q: %w", o.layout, err)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if !r.Passed {
+			failed++
+		}
+	}
+
+	if o.Output.IsStructured() {
+		if err = o.Printer.PrintData(o.Output, results); err != nil {
+			return err
+		}
+	} else {
+		data := make([][]string, 0, len(results))
+		for _, r := range results {
+			result := "passed"
+			if !r.Passed {
+				result = "failed"
+			}
+			data = append(data, []string{r.Name, result, strconv.Itoa(r.Links), r.Error})
+		}
+		if err = o.Printer.PrintTable(output.InTotoSteps, data); err != nil {
+			return err
+		}
+		for _, r := range results {
+			for _, w := range r.Warnings {
+				o.Printer.Warning.Printfln("step %q: %s", r.Name, w)
+			}
+		}
+	}
+
+	recordMetrics(o.Printer, func(m *metrics.Metrics) {
+		m.RecordVerification(failed == 0)
+	})
+
+	if failed > 0 {
+		o.Printer.Error.Printfln("Supply chain of %q not verified: %d of %d steps failed", ref, failed, len(results))
+		return output.ErrSilentExit
+	}
+
+	o.Printer.Success.Printfln("Supply chain of %q verified", ref)
+	return nil
+}
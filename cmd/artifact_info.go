@@ -34,6 +34,12 @@ type artifactInfoOptions struct {
 	*options.CommonOptions
 }
 
+// artifactInfo is the structured output of the artifact info command.
+type artifactInfo struct {
+	Ref  string   `json:"ref" yaml:"ref"`
+	Tags []string `json:"tags" yaml:"tags"`
+}
+
 // NewArtifactInfoCmd returns the artifact info command.
 func NewArtifactInfoCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
 	o := artifactInfoOptions{
@@ -51,6 +57,8 @@ func NewArtifactInfoCmd(ctx context.Context, opt *options.CommonOptions) *cobra.
 		},
 	}
 
+	o.CommonOptions.AddOutputFlags(cmd.Flags())
+
 	return cmd
 }
 
@@ -70,7 +78,7 @@ func (o *artifactInfoOptions) RunArtifactInfo(ctx context.Context, args []string
 		return err
 	}
 
-	var data [][]string
+	var infos []artifactInfo
 	for _, name := range args {
 		var ref string
 		parsedRef, err := registry.ParseReference(name)
@@ -104,8 +112,16 @@ func (o *artifactInfoOptions) RunArtifactInfo(ctx context.Context, args []string
 			continue
 		}
 
-		joinedTags := strings.Join(tags, " ")
-		data = append(data, []string{ref, joinedTags})
+		infos = append(infos, artifactInfo{Ref: ref, Tags: tags})
+	}
+
+	if o.Output.IsStructured() {
+		return o.Printer.PrintData(o.Output, infos)
+	}
+
+	data := make([][]string, 0, len(infos))
+	for _, info := range infos {
+		data = append(data, []string{info.Ref, strings.Join(info.Tags, " ")})
 	}
 
 	if err = o.Printer.PrintTable(output.ArtifactInfo, data); err != nil {
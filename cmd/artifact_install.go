@@ -20,15 +20,19 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
 	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/metrics"
 	"github.com/falcosecurity/falcoctl/pkg/oci"
 	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
 	ocipuller "github.com/falcosecurity/falcoctl/pkg/oci/puller"
 	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/state"
 )
 
 const (
@@ -90,6 +94,14 @@ func (o *artifactInstallOptions) RunArtifactInstall(ctx context.Context, args []
 		return err
 	}
 
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return err
+	}
+
+	// Report the missing dependencies before the first download.
+	o.checkDependencies(ctx, mergedIndexes, args)
+
 	// Create temp dir where to put pulled artifacts
 	tmpDir, err := os.MkdirTemp("", "falcoctl")
 	if err != nil {
@@ -106,7 +118,13 @@ func (o *artifactInstallOptions) RunArtifactInstall(ctx context.Context, args []
 
 		o.Printer.Info.Printfln("Preparing to pull %q", ref)
 
-		reg, err := utils.GetRegistryFromRef(ref)
+		// The installed artifact is tracked using the canonical reference, the rewritten one is only pulled.
+		pullRef, err := rewriteReference(o.Printer, ref)
+		if err != nil {
+			return err
+		}
+
+		reg, err := utils.GetRegistryFromRef(pullRef)
 		if err != nil {
 			return err
 		}
@@ -117,17 +135,30 @@ func (o *artifactInstallOptions) RunArtifactInstall(ctx context.Context, args []
 		}
 
 		// Install will always install artifact for the current OS and architecture
-		result, err := puller.Pull(ctx, ref, tmpDir, runtime.GOOS, runtime.GOARCH)
+		result, err := puller.Pull(ctx, pullRef, tmpDir, runtime.GOOS, runtime.GOARCH)
 		if err != nil {
 			return err
 		}
 
+		if err = checkPinned(utils.ArtifactName(name), result.Digest); err != nil {
+			return err
+		}
+
 		var destDir string
 		switch result.Type {
 		case oci.Plugin:
 			destDir = o.pluginsDir
 		case oci.Rulesfile:
 			destDir = o.rulesfilesDir
+		case oci.Collection:
+			return fmt.Errorf("%q is a collection and cannot be installed, use \"registry pull\" to pull its members", name)
+		}
+
+		_, err = installedState.Get(utils.ArtifactName(name))
+		installed := err == nil
+		hookEntry := &state.Entry{Name: utils.ArtifactName(name), Type: string(result.Type), Dir: destDir}
+		if err = runHooks(ctx, o.Printer, installedState, state.PreInstall, hookEntry); err != nil {
+			return err
 		}
 
 		sp, _ := o.Printer.Spinner.Start(fmt.Sprintf("Extracting and installing %q %q", result.Type, result.Filename))
@@ -138,8 +169,13 @@ func (o *artifactInstallOptions) RunArtifactInstall(ctx context.Context, args []
 			return err
 		}
 
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+
 		// Extract artifact and move it to its destination directory
-		err = utils.ExtractTarGz(f, destDir)
+		files, err := utils.ExtractTarGz(f, destDir)
 		if err != nil {
 			return fmt.Errorf("cannot extract %q to %q: %w", result.Filename, destDir, err)
 		}
@@ -149,7 +185,98 @@ func (o *artifactInstallOptions) RunArtifactInstall(ctx context.Context, args []
 			return err
 		}
 
+		// Keep track of the installed artifact in the state file.
+		if err = recordInstall(installedState, name, ref, destDir, result, files); err != nil {
+			return err
+		}
+
+		recordMetrics(o.Printer, func(m *metrics.Metrics) {
+			m.AddBytesTransferred(info.Size())
+			if installed {
+				m.RecordUpdate(time.Now())
+			}
+		})
+
 		sp.Success(fmt.Sprintf("Artifact successfully installed in %q", destDir))
+
+		postEvent := state.PostInstall
+		if installed {
+			postEvent = state.PostUpdate
+		}
+		if err = runHooks(ctx, o.Printer, installedState, postEvent, hookEntry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkDependencies warns about the dependencies of the artifacts to be installed that cannot be resolved.
+// Artifacts whose config cannot be fetched are skipped: the error is reported when installing them.
+func (o *artifactInstallOptions) checkDependencies(ctx context.Context, mergedIndexes *index.MergedIndexes, args []string) {
+	resolver := newDependencyResolver(o.Printer, o.credentialStore, mergedIndexes)
+
+	var missing int
+	for _, name := range args {
+		ref, err := utils.ParseReference(mergedIndexes, name)
+		if err != nil {
+			continue
+		}
+
+		pullRef, err := rewriteReference(o.Printer, ref)
+		if err != nil {
+			continue
+		}
+
+		config, err := fetchArtifactConfig(ctx, o.credentialStore, pullRef, runtime.GOOS, runtime.GOARCH)
+		if err != nil {
+			o.Printer.Verbosef("Unable to check the dependencies of %q: %s", name, err.Error())
+			continue
+		}
+
+		for _, m := range missingDependencies(ctx, resolver, config) {
+			missing++
+			msg := fmt.Sprintf("Dependency %q of %q cannot be resolved: %s", m.Dependency, name, m.Reason)
+			if len(m.Suggestions) > 0 {
+				msg += fmt.Sprintf(". Did you mean %s?", strings.Join(m.Suggestions, ", "))
+			}
+			o.Printer.Warning.Println(msg)
+		}
+	}
+
+	if missing > 0 {
+		o.Printer.Warning.Printfln("%d dependency(ies) of the artifacts to be installed cannot be resolved, "+
+			"the installed artifacts may not work until they are installed", missing)
+	}
+}
+
+// recordInstall adds the installed artifact to the state and writes it to disk.
+func recordInstall(installedState *state.State, name, ref, destDir string, result *oci.RegistryResult, files []string) error {
+	entry := &state.Entry{
+		Name:             utils.ArtifactName(name),
+		Type:             string(result.Type),
+		Ref:              ref,
+		Digest:           result.Digest,
+		Dir:              destDir,
+		UpdatedTimestamp: time.Now().Format(timeFormat),
+	}
+
+	if _, err := installedState.Get(entry.Name); err != nil {
+		entry.InstalledTimestamp = entry.UpdatedTimestamp
+	}
+
+	for _, path := range files {
+		file, err := state.NewFile(path)
+		if err != nil {
+			return err
+		}
+		entry.Files = append(entry.Files, *file)
+	}
+
+	installedState.Upsert(entry)
+
+	if err := installedState.Write(stateFile); err != nil {
+		return fmt.Errorf("cannot update state file %q: %w", stateFile, err)
 	}
 
 	return nil
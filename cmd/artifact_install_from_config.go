@@ -0,0 +1,126 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/falcoconfig"
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const defaultFalcoConfig = "/etc/falco/falco.yaml"
+
+var longArtifactInstallFromConfig = `Install the artifacts referenced by a Falco configuration file
+
+The plugins loaded by the configuration, i.e. the ones listed in "load_plugins" or all the ones in
+"plugins" if it is not set, and the rules files listed in "rules_file" are looked up in the
+configured indexes and installed as done by "artifact install".
+
+Plugins are matched by name, falling back to the name of their library, e.g. "libk8saudit.so"
+matches "k8saudit". Rules files are matched by their file name, e.g. "k8s_audit_rules.yaml" matches
+"k8saudit-rules". Names are compared ignoring case and characters other than letters and digits.
+References matching no index entry, e.g. local rules files, or several of them are skipped with a
+warning. Rules directories are not resolved.
+
+Example - Install the artifacts referenced by the default Falco configuration:
+	falcoctl artifact install-from-config
+
+Example - Install the artifacts referenced by a custom Falco configuration in custom directories:
+	falcoctl artifact install-from-config --falco-config ./falco.yaml --rulesfiles-dir ./rules --plugins-dir ./plugins
+`
+
+type artifactInstallFromConfigOptions struct {
+	artifactInstallOptions
+	falcoConfig string
+}
+
+// NewArtifactInstallFromConfigCmd returns the artifact install-from-config command.
+func NewArtifactInstallFromConfigCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactInstallFromConfigOptions{
+		artifactInstallOptions: artifactInstallOptions{CommonOptions: opt},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "install-from-config [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Install the artifacts referenced by a Falco configuration file",
+		Long:                  longArtifactInstallFromConfig,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactInstallFromConfig(ctx))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.falcoConfig, "falco-config", defaultFalcoConfig, "path of the Falco configuration file")
+	cmd.Flags().StringVarP(&o.rulesfilesDir, "rulesfiles-dir", "", defaultRulesfilesDir,
+		"directory where to install rules. Defaults to /etc/falco")
+	cmd.Flags().StringVarP(&o.pluginsDir, "plugins-dir", "", defaultPluginsDir,
+		"directory where to install plugins. Defaults to /usr/share/falco/plugins")
+
+	return cmd
+}
+
+// RunArtifactInstallFromConfig executes the business logic for the artifact install-from-config command.
+func (o *artifactInstallFromConfigOptions) RunArtifactInstallFromConfig(ctx context.Context) error {
+	cfg, err := falcoconfig.Load(o.falcoConfig)
+	if err != nil {
+		return err
+	}
+
+	indexConfig, err := index.NewConfig(indexesFile)
+	if err != nil {
+		return err
+	}
+
+	mergedIndexes, err := utils.Indexes(indexConfig, falcoctlPath)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, res := range cfg.Resolve(mergedIndexes.Entries) {
+		switch {
+		case len(res.Candidates) > 0:
+			o.Printer.Warning.Printfln("%s %q is ambiguous, it matches %s: skipping it, install the right one with \"artifact install\"",
+				res.Type, res.Reference, strings.Join(res.Candidates, ", "))
+		case res.Name == "":
+			o.Printer.Warning.Printfln("%s %q not found in the configured indexes, skipping it", res.Type, res.Reference)
+		default:
+			if res.ByFilename {
+				o.Printer.Info.Printfln("%s %q matched to %q by file name", res.Type, res.Reference, res.Name)
+			} else {
+				o.Printer.Verbosef("%s %q found in the configured indexes", res.Type, res.Reference)
+			}
+			if !contains(names, res.Name) {
+				names = append(names, res.Name)
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		o.Printer.Info.Printfln("No artifact referenced by %q found in the configured indexes", o.falcoConfig)
+		return nil
+	}
+
+	o.Printer.Info.Printfln("Installing %s", strings.Join(names, ", "))
+
+	return o.RunArtifactInstall(ctx, names)
+}
@@ -0,0 +1,219 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/metrics"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+var longInstallFromURL = `Install an artifact downloaded from a plain HTTP(S) URL
+
+The file is downloaded, its checksum is verified and then it is installed as any other artifact:
+archives (*.tar.gz, *.tgz) are extracted in the install directory, any other file is copied as is.
+The URL and the checksum are recorded in the state file, so that the installation can be repaired
+with the "artifact repair" command.
+
+The name of the installed artifact is derived from the name of the downloaded file, unless
+it is given with --name.
+
+Example - Install a plugin published as a GitHub release asset:
+	falcoctl artifact install-from-url --type plugin \
+		--url https://github.com/example/plugin/releases/download/v0.1.0/myplugin-0.1.0-x86_64.tar.gz \
+		--checksum sha256:<hash> \
+		--name myplugin
+`
+
+type artifactInstallFromURLOptions struct {
+	*options.CommonOptions
+	artifactType  oci.ArtifactType
+	url           string
+	checksum      string
+	name          string
+	rulesfilesDir string
+	pluginsDir    string
+}
+
+// NewArtifactInstallFromURLCmd returns the artifact install-from-url command.
+func NewArtifactInstallFromURLCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactInstallFromURLOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "install-from-url --type plugin|rulesfile --url url --checksum sha256:<hash> [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Install an artifact downloaded from a plain HTTP(S) URL",
+		Long:                  longInstallFromURL,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactInstallFromURL(ctx, args))
+		},
+	}
+
+	cmd.Flags().Var(&o.artifactType, "type", `type of the artifact. Allowed values: "rulesfile", "plugin"`)
+	cmd.Flags().StringVar(&o.url, "url", "", "URL of the file to be downloaded")
+	cmd.Flags().StringVar(&o.checksum, "checksum", "", `checksum of the downloaded file, in the "sha256:<hash>" format`)
+	cmd.Flags().StringVar(&o.name, "name", "", "name of the installed artifact. Defaults to the name of the downloaded file")
+	cmd.Flags().StringVarP(&o.rulesfilesDir, "rulesfiles-dir", "", defaultRulesfilesDir,
+		"directory where to install rules. Defaults to /etc/falco")
+	cmd.Flags().StringVarP(&o.pluginsDir, "plugins-dir", "", defaultPluginsDir,
+		"directory where to install plugins. Defaults to /usr/share/falco/plugins")
+
+	return cmd
+}
+
+// RunArtifactInstallFromURL executes the business logic for the artifact install-from-url command.
+func (o *artifactInstallFromURLOptions) RunArtifactInstallFromURL(ctx context.Context, args []string) error {
+	switch {
+	case o.artifactType == "":
+		return fmt.Errorf("--type must be set")
+	case o.url == "":
+		return fmt.Errorf("--url must be set")
+	case o.checksum == "":
+		return fmt.Errorf("--checksum must be set")
+	}
+
+	filename, err := downloadFilename(o.url)
+	if err != nil {
+		return err
+	}
+
+	name := o.name
+	if name == "" {
+		name = strings.TrimSuffix(strings.TrimSuffix(filename, ".tgz"), ".tar.gz")
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+
+	var destDir string
+	switch o.artifactType {
+	case oci.Plugin:
+		destDir = o.pluginsDir
+	case oci.Rulesfile:
+		destDir = o.rulesfilesDir
+	}
+
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return err
+	}
+
+	if err = checkPinned(name, o.checksum); err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "falcoctl")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	o.Printer.Info.Printfln("Downloading %q", o.url)
+
+	downloaded := filepath.Join(tmpDir, filename)
+	size, err := utils.Download(ctx, o.url, o.checksum, downloaded)
+	if err != nil {
+		return err
+	}
+
+	_, notInstalled := installedState.Get(name)
+	hookEntry := &state.Entry{Name: name, Type: o.artifactType.String(), Dir: destDir}
+	if err = runHooks(ctx, o.Printer, installedState, state.PreInstall, hookEntry); err != nil {
+		return err
+	}
+
+	sp, _ := o.Printer.Spinner.Start(fmt.Sprintf("Installing %q %q", o.artifactType, filename))
+
+	files, err := utils.InstallFile(downloaded, destDir)
+	if err != nil {
+		return fmt.Errorf("cannot install %q to %q: %w", filename, destDir, err)
+	}
+
+	entry := &state.Entry{
+		Name:             name,
+		Type:             o.artifactType.String(),
+		URL:              o.url,
+		Checksum:         o.checksum,
+		Digest:           o.checksum,
+		Dir:              destDir,
+		UpdatedTimestamp: time.Now().Format(timeFormat),
+	}
+	if notInstalled != nil {
+		entry.InstalledTimestamp = entry.UpdatedTimestamp
+	}
+
+	for _, p := range files {
+		file, err := state.NewFile(p)
+		if err != nil {
+			return err
+		}
+		entry.Files = append(entry.Files, *file)
+	}
+
+	installedState.Upsert(entry)
+
+	if err = installedState.Write(stateFile); err != nil {
+		return fmt.Errorf("cannot update state file %q: %w", stateFile, err)
+	}
+
+	recordMetrics(o.Printer, func(m *metrics.Metrics) {
+		m.AddBytesTransferred(size)
+		if notInstalled == nil {
+			m.RecordUpdate(time.Now())
+		}
+	})
+
+	sp.Success(fmt.Sprintf("Artifact %q successfully installed in %q", name, destDir))
+
+	postEvent := state.PostInstall
+	if notInstalled == nil {
+		postEvent = state.PostUpdate
+	}
+
+	return runHooks(ctx, o.Printer, installedState, postEvent, hookEntry)
+}
+
+// downloadFilename returns the name of the file pointed by rawURL.
+func downloadFilename(rawURL string) (string, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return "", fmt.Errorf("invalid URL %q: only http and https are supported", rawURL)
+	}
+
+	filename := path.Base(parsedURL.Path)
+	if filename == "/" || filename == "." {
+		return "", fmt.Errorf("invalid URL %q: cannot derive the name of the downloaded file", rawURL)
+	}
+
+	return filename, nil
+}
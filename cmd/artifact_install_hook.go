@@ -0,0 +1,152 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+var longInstallHook = `Register a script to be executed around the installation of an artifact
+
+Hooks are recorded in the state file and executed by "artifact install" and
+"artifact install-from-url" at the following points:
+	- pre-install: before the artifact is installed or updated. If the script exits with a
+	  non-zero code the installation is aborted
+	- post-install: after the artifact is installed for the first time
+	- post-update: after an already installed artifact is installed again
+
+Failures of post-install and post-update hooks are reported as warnings, the installed files
+are kept. Scripts receive the FALCOCTL_ARTIFACT_NAME, FALCOCTL_ARTIFACT_TYPE,
+FALCOCTL_ARTIFACT_DIR and FALCOCTL_HOOK_EVENT environment variables.
+
+Example - Stop Falco before updating the "cloudtrail" plugin and restart it afterwards:
+	falcoctl artifact install-hook cloudtrail --event pre-install --script /opt/hooks/stop-falco.sh
+	falcoctl artifact install-hook cloudtrail --event post-update --script /opt/hooks/start-falco.sh
+`
+
+type artifactInstallHookOptions struct {
+	*options.CommonOptions
+	event  state.HookEvent
+	script string
+}
+
+// NewArtifactInstallHookCmd returns the artifact install-hook command.
+func NewArtifactInstallHookCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactInstallHookOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "install-hook name --event pre-install|post-install|post-update --script path [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Register a script to be executed around the installation of an artifact",
+		Long:                  longInstallHook,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactInstallHook(ctx, args))
+		},
+	}
+
+	cmd.Flags().Var(&o.event, "event",
+		`lifecycle point where the script is executed. Allowed values: "pre-install", "post-install", "post-update"`)
+	cmd.Flags().StringVar(&o.script, "script", "", "path of the script to be executed")
+
+	return cmd
+}
+
+// RunArtifactInstallHook executes the business logic for the artifact install-hook command.
+func (o *artifactInstallHookOptions) RunArtifactInstallHook(_ context.Context, args []string) error {
+	switch {
+	case o.event == "":
+		return fmt.Errorf("--event must be set")
+	case o.script == "":
+		return fmt.Errorf("--script must be set")
+	}
+
+	script, err := filepath.Abs(o.script)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(script)
+	if err != nil {
+		return fmt.Errorf("cannot use script %q: %w", o.script, err)
+	}
+	if info.Mode().Perm()&0o111 == 0 {
+		return fmt.Errorf("cannot use script %q: not executable", o.script)
+	}
+
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return err
+	}
+
+	hook := &state.Hook{
+		Name:   utils.ArtifactName(args[0]),
+		Event:  o.event,
+		Script: script,
+	}
+
+	if !installedState.AddHook(hook) {
+		o.Printer.Info.Printfln("Hook %q already registered for %q on %s", script, hook.Name, hook.Event)
+		return nil
+	}
+
+	if err = installedState.Write(stateFile); err != nil {
+		return fmt.Errorf("cannot update state file %q: %w", stateFile, err)
+	}
+
+	o.Printer.Success.Printfln("Hook %q registered for %q on %s", script, hook.Name, hook.Event)
+
+	return nil
+}
+
+// runHooks executes the hooks registered for an artifact and an event. The first failing pre-install
+// hook aborts the execution and its error is returned, failures of the other hooks are reported as warnings.
+func runHooks(ctx context.Context, printer *output.Printer, installedState *state.State, event state.HookEvent, entry *state.Entry) error {
+	for _, hook := range installedState.HooksFor(entry.Name, event) {
+		printer.Info.Printfln("Running %s hook %q for %q", event, hook.Script, entry.Name)
+
+		cmd := exec.CommandContext(ctx, hook.Script) //nolint:gosec // hooks are registered by the user
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Env = append(os.Environ(),
+			"FALCOCTL_ARTIFACT_NAME="+entry.Name,
+			"FALCOCTL_ARTIFACT_TYPE="+entry.Type,
+			"FALCOCTL_ARTIFACT_DIR="+entry.Dir,
+			"FALCOCTL_HOOK_EVENT="+string(event),
+		)
+
+		if err := cmd.Run(); err != nil {
+			if event == state.PreInstall {
+				return fmt.Errorf("%s hook %q for %q failed, aborting: %w", event, hook.Script, entry.Name, err)
+			}
+			printer.Warning.Printfln("%s hook %q for %q failed: %s", event, hook.Script, entry.Name, err.Error())
+		}
+	}
+
+	return nil
+}
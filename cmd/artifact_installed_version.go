@@ -0,0 +1,95 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+var longInstalledVersion = `Print the installed version of an artifact
+
+Only the bare version string is printed to stdout, making the command suitable for scripts.
+If the artifact is not installed nothing is printed and the command exits with code 1.
+
+Example - Print the installed version of the "cloudtrail" artifact:
+	falcoctl artifact installed-version cloudtrail
+
+Example - Check whether the installed version of "cloudtrail" is the latest one:
+	[ "$(falcoctl artifact installed-version cloudtrail)" = "$(falcoctl artifact latest-version cloudtrail)" ]
+`
+
+type artifactInstalledVersionOptions struct {
+	*options.CommonOptions
+}
+
+// NewArtifactInstalledVersionCmd returns the artifact installed-version command.
+func NewArtifactInstalledVersionCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactInstalledVersionOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "installed-version name [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Print the installed version of an artifact",
+		Long:                  longInstalledVersion,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Keep stdout clean, it only holds the version string.
+			o.Printer.RedirectLogs(os.Stderr)
+			o.Printer.CheckErr(o.RunArtifactInstalledVersion(ctx, args))
+		},
+	}
+
+	return cmd
+}
+
+// RunArtifactInstalledVersion executes the business logic for the artifact installed-version command.
+func (o *artifactInstalledVersionOptions) RunArtifactInstalledVersion(ctx context.Context, args []string) error {
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return err
+	}
+
+	entry, err := installedState.Get(utils.ArtifactName(args[0]))
+	if err != nil {
+		o.Printer.Verbosef("%s", err.Error())
+		return output.ErrSilentExit
+	}
+
+	o.Printer.DefaultText.Print(installedVersion(entry))
+
+	return nil
+}
+
+// installedVersion returns the tag, or the digest if no tag was used, of an installed artifact.
+func installedVersion(entry *state.Entry) string {
+	parsedRef, err := registry.ParseReference(entry.Ref)
+	if err != nil || parsedRef.Reference == "" {
+		return oci.DefaultTag
+	}
+
+	return parsedRef.Reference
+}
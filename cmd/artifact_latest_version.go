@@ -0,0 +1,117 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+var longLatestVersion = `Print the latest available version of an artifact
+
+The artifact can be specified either by its name in the configured indexes or by its reference.
+Only the bare version string is printed to stdout, making the command suitable for scripts.
+Pre-releases are ignored unless --pre-release is set. The command exits with code 1 if no
+version of the artifact is found.
+
+Example - Print the latest version of the "cloudtrail" artifact:
+	falcoctl artifact latest-version cloudtrail
+
+Example - Print the latest version, including pre-releases, of an artifact given its reference:
+	falcoctl artifact latest-version ghcr.io/falcosecurity/plugins/plugin/cloudtrail --pre-release
+`
+
+type artifactLatestVersionOptions struct {
+	*options.CommonOptions
+	preRelease bool
+}
+
+// NewArtifactLatestVersionCmd returns the artifact latest-version command.
+func NewArtifactLatestVersionCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactLatestVersionOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "latest-version name|ref [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Print the latest available version of an artifact",
+		Long:                  longLatestVersion,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Keep stdout clean, it only holds the version string.
+			o.Printer.RedirectLogs(os.Stderr)
+			o.Printer.CheckErr(o.RunArtifactLatestVersion(ctx, args))
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.preRelease, "pre-release", false, "consider pre-release versions")
+
+	return cmd
+}
+
+// RunArtifactLatestVersion executes the business logic for the artifact latest-version command.
+func (o *artifactLatestVersionOptions) RunArtifactLatestVersion(ctx context.Context, args []string) error {
+	indexConfig, err := index.NewConfig(indexesFile)
+	if err != nil {
+		return err
+	}
+
+	mergedIndexes, err := utils.Indexes(indexConfig, falcoctlPath)
+	if err != nil {
+		return err
+	}
+
+	repo, err := utils.RepositoryFromName(mergedIndexes, args[0])
+	if err != nil {
+		return err
+	}
+
+	if repo, err = rewriteReference(o.Printer, repo); err != nil {
+		return err
+	}
+
+	reg, err := utils.GetRegistryFromRef(repo)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return err
+	}
+
+	version, err := oci.LatestVersion(ctx, repo, authn.NewClient(cred), o.preRelease)
+	if err != nil {
+		return err
+	}
+
+	o.Printer.DefaultText.Print(version)
+
+	return nil
+}
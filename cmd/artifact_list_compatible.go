@@ -0,0 +1,174 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/compatibility"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+// Compatibility statuses of the installed artifacts.
+const (
+	compatibilityCompatible   = "compatible"
+	compatibilityIncompatible = "incompatible"
+	compatibilityUnknown      = "unknown"
+)
+
+var longListCompatible = `List the installed artifacts compatible with a Falco version
+
+The "` + oci.RequiresFalcoVersionAnnotation + `" annotation of each installed artifact is
+checked against the given Falco version. It holds either a minimum version, e.g. "0.35.0", or a range,
+e.g. ">=0.35.0 <0.37.0". Artifacts lacking the annotation are reported with "unknown" compatibility.
+If --falco-version is not set, the version of the "falco" binary found in PATH is used.
+The command exits with code 1 if at least one artifact is incompatible.
+
+Example - Check the installed artifacts before upgrading to Falco 0.36.0:
+	falcoctl artifact list-compatible --falco-version 0.36.0
+`
+
+type artifactListCompatibleOptions struct {
+	*options.CommonOptions
+	falcoVersion string
+}
+
+// NewArtifactListCompatibleCmd returns the artifact list-compatible command.
+func NewArtifactListCompatibleCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactListCompatibleOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "list-compatible [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "List the installed artifacts compatible with a Falco version",
+		Long:                  longListCompatible,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactListCompatible(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.falcoVersion, "falco-version", "",
+		"Falco version to check against, detected from the falco binary in PATH if not set")
+
+	return cmd
+}
+
+// RunArtifactListCompatible executes the business logic for the artifact list-compatible command.
+func (o *artifactListCompatibleOptions) RunArtifactListCompatible(ctx context.Context, args []string) error {
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return err
+	}
+
+	if len(installedState.Entries) == 0 {
+		o.Printer.Info.Println("No installed artifact to check")
+		return nil
+	}
+
+	falcoVersion := o.falcoVersion
+	if falcoVersion == "" {
+		o.Printer.Verbosef("Detecting Falco version from the falco binary")
+		if falcoVersion, err = utils.FalcoVersion(ctx); err != nil {
+			return fmt.Errorf("unable to detect Falco version, set --falco-version: %w", err)
+		}
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	var data [][]string
+	var incompatible int
+	for i := range installedState.Entries {
+		entry := &installedState.Entries[i]
+
+		status := compatibilityUnknown
+		requirement, err := requiredFalcoVersion(ctx, credentialStore, entry)
+		switch {
+		case err != nil:
+			o.Printer.Warning.Printfln("cannot check compatibility of %q: %s", entry.Name, err.Error())
+		case requirement != "":
+			compatible, err := compatibility.SatisfiesFalcoVersion(requirement, falcoVersion)
+			if err != nil {
+				o.Printer.Warning.Printfln("cannot check compatibility of %q: %s", entry.Name, err.Error())
+				break
+			}
+			status = compatibilityCompatible
+			if !compatible {
+				status = compatibilityIncompatible
+				incompatible++
+			}
+		}
+
+		data = append(data, []string{entry.Name, entry.Type, installedVersion(entry), requirement, status})
+	}
+
+	if err = o.Printer.PrintTable(output.ArtifactCompatibility, data); err != nil {
+		return err
+	}
+
+	if incompatible > 0 {
+		return output.ErrSilentExit
+	}
+
+	return nil
+}
+
+// requiredFalcoVersion returns the Falco version requirement of the installed version of an artifact,
+// empty if the artifact does not declare it.
+func requiredFalcoVersion(ctx context.Context, credentialStore *authn.Store, entry *state.Entry) (string, error) {
+	if entry.URL != "" {
+		return "", fmt.Errorf("installed from %q, only artifacts installed from a registry can be checked", entry.URL)
+	}
+
+	parsedRef, err := registry.ParseReference(entry.Ref)
+	if err != nil {
+		return "", err
+	}
+	// Pin the installed version, the tag may have been moved since.
+	parsedRef.Reference = entry.Digest
+	ref := parsedRef.String()
+
+	reg, err := utils.GetRegistryFromRef(ref)
+	if err != nil {
+		return "", err
+	}
+
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return "", err
+	}
+
+	manifest, err := oci.FetchManifest(ctx, ref, authn.NewClient(cred), runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", err
+	}
+
+	return manifest.Annotations[oci.RequiresFalcoVersionAnnotation], nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+var longLockAnnotations = `Lock the annotations of an already pushed artifact
+
+The "org.falcosecurity.artifact.annotations-locked" annotation is set to "true" on the manifest of
+the artifact, or its index for multi-platform plugins, as done by "artifact set-annotation", and the
+resulting digest is recorded in the local registry of the locked artifacts. Afterwards,
+"artifact set-annotation" refuses to modify the annotations of the locked manifest, unless
+--force-unlock is passed with its digest as a confirmation.
+
+Example - Lock the annotations of an artifact after its security verification:
+	falcoctl artifact lock-annotations ghcr.io/myorg/rules/myrules:1.0.0
+`
+
+type artifactLockAnnotationsOptions struct {
+	*options.CommonOptions
+}
+
+// NewArtifactLockAnnotationsCmd returns the artifact lock-annotations command.
+func NewArtifactLockAnnotationsCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactLockAnnotationsOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "lock-annotations hostname/repo[:tag|@digest] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Lock the annotations of an already pushed artifact",
+		Long:                  longLockAnnotations,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactLockAnnotations(ctx, args))
+		},
+	}
+
+	return cmd
+}
+
+// RunArtifactLockAnnotations executes the business logic for the artifact lock-annotations command.
+func (o *artifactLockAnnotationsOptions) RunArtifactLockAnnotations(ctx context.Context, args []string) error {
+	ref, err := normalizeReference(o.Printer, args[0])
+	if err != nil {
+		return err
+	}
+
+	locks, err := state.LoadAnnotationLocks(annotationLocksFile)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	client, err := registryClient(ctx, credentialStore, ref)
+	if err != nil {
+		return err
+	}
+
+	oldDigest, newDigest, err := oci.UpdateAnnotation(ctx, ref, client, oci.AnnotationsLockedAnnotation, "true")
+	if err != nil {
+		return err
+	}
+
+	locks.Add(ref, newDigest, time.Now().Format(timeFormat))
+	if err = locks.Write(annotationLocksFile); err != nil {
+		return fmt.Errorf("cannot write annotation locks file %q: %w", annotationLocksFile, err)
+	}
+
+	o.Printer.DefaultText.Printfln("old digest: %s\nnew digest: %s", oldDigest, newDigest)
+	if oldDigest == newDigest {
+		o.Printer.Info.Printfln("Annotations of %q already locked", ref)
+	} else {
+		o.Printer.Success.Printfln("Annotations of %q locked, the artifact has a new digest", ref)
+	}
+
+	return nil
+}
+
+// checkAnnotationsLock returns true if the annotations of the manifest described by desc are locked, either by the
+// annotation set by "artifact lock-annotations" or by the local registry of the locked artifacts. An error is returned
+// if they are locked and forceUnlock does not hold the digest of the manifest.
+func checkAnnotationsLock(locks *state.AnnotationLocks, desc *v1.Descriptor, annotations map[string]string,
+	forceUnlock string) (bool, error) {
+	d := desc.Digest.String()
+	if _, ok := locks.Get(d); !ok && annotations[oci.AnnotationsLockedAnnotation] != "true" {
+		return false, nil
+	}
+
+	switch forceUnlock {
+	case "":
+		return true, fmt.Errorf("the annotations of %s are locked, use --force-unlock %s to modify them anyway", d, d)
+	case d:
+		return true, nil
+	default:
+		return true, fmt.Errorf("the annotations of %s are locked, and --force-unlock holds a different digest %s", d, forceUnlock)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/metrics"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+const (
+	metricsFormatPrometheus = "prometheus"
+	metricsFormatJSON       = "json"
+)
+
+var longMetrics = `Export usage and health metrics of the artifacts managed by falcoctl
+
+The following metrics are exported: number of installed artifacts, number of updates in the
+last 30 days, number of passed and failed verifications and number of bytes transferred from
+and to remote registries. Counters are accumulated in a local metrics file.
+
+Example - Print the metrics in the Prometheus text format:
+	falcoctl artifact metrics
+
+Example - Print the metrics in JSON format:
+	falcoctl artifact metrics --format json
+
+Example - Clear the accumulated counters:
+	falcoctl artifact metrics --reset
+`
+
+type artifactMetricsOptions struct {
+	*options.CommonOptions
+	format string
+	reset  bool
+}
+
+// NewArtifactMetricsCmd returns the artifact metrics command.
+func NewArtifactMetricsCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactMetricsOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "metrics [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Export usage and health metrics of the artifacts managed by falcoctl",
+		Long:                  longMetrics,
+		Args:                  cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactMetrics(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.format, "format", metricsFormatPrometheus, `output format. Allowed values: "prometheus", "json"`)
+	cmd.Flags().BoolVar(&o.reset, "reset", false, "clear the accumulated counters")
+
+	return cmd
+}
+
+func (o *artifactMetricsOptions) validate() error {
+	switch o.format {
+	case metricsFormatPrometheus, metricsFormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q: must be one of %q, %q", o.format, metricsFormatPrometheus, metricsFormatJSON)
+	}
+}
+
+// RunArtifactMetrics executes the business logic for the artifact metrics command.
+func (o *artifactMetricsOptions) RunArtifactMetrics(ctx context.Context, args []string) error {
+	if o.reset {
+		if err := os.Remove(metricsFile); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cannot reset metrics: %w", err)
+		}
+		o.Printer.Success.Println("Metrics successfully reset")
+		return nil
+	}
+
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return err
+	}
+
+	m, err := metrics.New(metricsFile)
+	if err != nil {
+		return err
+	}
+
+	snapshot := m.Snapshot(len(installedState.Entries), time.Now())
+
+	var buf bytes.Buffer
+	switch o.format {
+	case metricsFormatJSON:
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	default:
+		if err = snapshot.WritePrometheus(&buf); err != nil {
+			return err
+		}
+	}
+
+	o.Printer.DefaultText.Println(strings.TrimRight(buf.String(), "\n"))
+
+	return nil
+}
+
+// recordMetrics updates the metrics file. Failures are not fatal for the operation being measured.
+func recordMetrics(printer *output.Printer, fn func(m *metrics.Metrics)) {
+	if err := metrics.Update(metricsFile, fn); err != nil {
+		printer.Verbosef("Unable to update metrics file %q: %s", metricsFile, err.Error())
+	}
+}
+
+// recordTransferredFiles adds the size of the given files to the bytes transferred.
+func recordTransferredFiles(printer *output.Printer, paths ...string) {
+	var size int64
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			size += info.Size()
+		}
+	}
+
+	recordMetrics(printer, func(m *metrics.Metrics) {
+		m.AddBytesTransferred(size)
+	})
+}
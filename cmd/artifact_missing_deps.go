@@ -0,0 +1,212 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+const (
+	// similarNameMinScore is the minimum similarity of the names of the artifacts suggested for a missing dependency.
+	similarNameMinScore = 0.6
+	// maxSuggestions is the maximum number of artifacts suggested for a missing dependency.
+	maxSuggestions = 3
+)
+
+var longMissingDeps = `Report the dependencies of an artifact that cannot be resolved
+
+The dependencies stored in the config of the artifact, for the platform where falcoctl is running or
+the one set with --platform, are resolved against the artifacts of the configured indexes or, for
+dependencies naming a complete reference, e.g. "ghcr.io/myorg/plugins/myplugin", against its registry.
+A dependency is missing if neither the artifact it names nor any of its alternatives is available in
+a version compatible with the required one, as done by "registry resolve-deps".
+
+For each missing dependency the reason is reported along with the artifacts of the configured indexes
+having a similar name, e.g. to spot a typo. The command exits with a non-zero exit code if any
+dependency is missing. The same check runs before the first download of "artifact install".
+
+Example - Report the missing dependencies of "k8saudit-rules":
+	falcoctl artifact missing-deps k8saudit-rules:0.5.0
+
+Example - Report the missing dependencies of an artifact for platform "linux/arm64" in JSON format:
+	falcoctl artifact missing-deps localhost:5000/myrulesfile:0.1.0 --platform linux/arm64 --output json
+`
+
+type artifactMissingDepsOptions struct {
+	*options.CommonOptions
+	platform string
+}
+
+// missingDependency is a dependency that cannot be resolved, as reported by the artifact missing-deps command.
+type missingDependency struct {
+	Dependency  string   `json:"dependency" yaml:"dependency"`
+	Reason      string   `json:"reason" yaml:"reason"`
+	Suggestions []string `json:"suggestions,omitempty" yaml:"suggestions,omitempty"`
+}
+
+// NewArtifactMissingDepsCmd returns the artifact missing-deps command.
+func NewArtifactMissingDepsCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactMissingDepsOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "missing-deps name|ref [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Report the dependencies of an artifact that cannot be resolved",
+		Long:                  longMissingDeps,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactMissingDeps(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.platform, "platform", "",
+		"os and architecture of the artifact in OS/ARCH format. Defaults to the platform where falcoctl is running")
+	o.CommonOptions.AddOutputFlags(cmd.Flags())
+
+	return cmd
+}
+
+// RunArtifactMissingDeps executes the business logic for the artifact missing-deps command.
+func (o *artifactMissingDepsOptions) RunArtifactMissingDeps(ctx context.Context, args []string) error {
+	goos, goarch := runtime.GOOS, runtime.GOARCH
+	if o.platform != "" {
+		platform, err := oci.ParsePlatform(o.platform)
+		if err != nil {
+			return err
+		}
+		goos, goarch = platform.OS, platform.Architecture
+	}
+
+	indexConfig, err := index.NewConfig(indexesFile)
+	if err != nil {
+		return err
+	}
+
+	mergedIndexes, err := utils.Indexes(indexConfig, falcoctlPath)
+	if err != nil {
+		return err
+	}
+
+	ref, err := utils.ParseReference(mergedIndexes, args[0])
+	if err != nil {
+		return err
+	}
+
+	if ref, err = rewriteReference(o.Printer, ref); err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	config, err := fetchArtifactConfig(ctx, credentialStore, ref, goos, goarch)
+	if err != nil {
+		return err
+	}
+
+	resolver := newDependencyResolver(o.Printer, credentialStore, mergedIndexes)
+	missing := missingDependencies(ctx, resolver, config)
+
+	if o.Output.IsStructured() {
+		if missing == nil {
+			missing = []missingDependency{}
+		}
+		if err = o.Printer.PrintData(o.Output, missing); err != nil {
+			return err
+		}
+	} else if len(missing) == 0 {
+		o.Printer.Success.Printfln("All the %d dependency(ies) of %q can be resolved", len(config.Dependencies), ref)
+	} else {
+		data := make([][]string, 0, len(missing))
+		for _, m := range missing {
+			data = append(data, []string{m.Dependency, m.Reason, strings.Join(m.Suggestions, ", ")})
+		}
+		if err = o.Printer.PrintTable(output.MissingDependencies, data); err != nil {
+			return err
+		}
+	}
+
+	if len(missing) > 0 {
+		o.Printer.Error.Printfln("%d dependency(ies) of %q cannot be resolved", len(missing), ref)
+		return output.ErrSilentExit
+	}
+
+	return nil
+}
+
+// fetchArtifactConfig returns the config of the artifact pointed by ref for the given platform.
+func fetchArtifactConfig(ctx context.Context, credentialStore *authn.Store, ref, goos, goarch string) (*oci.ArtifactConfig, error) {
+	client, err := registryClient(ctx, credentialStore, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := oci.FetchManifest(ctx, ref, client, goos, goarch)
+	if err != nil {
+		return nil, err
+	}
+
+	return oci.FetchArtifactConfig(ctx, ref, client, manifest)
+}
+
+// missingDependencies returns the dependencies in config satisfied neither by the artifact they name nor by
+// their alternatives, along with the artifacts of the configured indexes with a similar name.
+func missingDependencies(ctx context.Context, resolver *dependencyResolver, config *oci.ArtifactConfig) []missingDependency {
+	var missing []missingDependency
+	for i := range config.Dependencies {
+		resolution := resolver.resolveDependency(ctx, &config.Dependencies[i])
+		if resolution.Status != dependencyUnsatisfiable {
+			continue
+		}
+
+		var reasons, suggestions []string
+		for _, c := range dependencyCandidates(&config.Dependencies[i]) {
+			if len(resolver.versions[c.name]) > 0 {
+				reasons = append(reasons, fmt.Sprintf("no version of %q compatible with %s", c.name, c.version))
+				continue
+			}
+			reasons = append(reasons, fmt.Sprintf("%q not found", c.name))
+			for _, name := range resolver.mergedIndexes.SimilarNames(c.name, similarNameMinScore, maxSuggestions) {
+				if !contains(suggestions, name) {
+					suggestions = append(suggestions, name)
+				}
+			}
+		}
+
+		missing = append(missing, missingDependency{
+			Dependency:  resolution.Dependency,
+			Reason:      strings.Join(reasons, ", "),
+			Suggestions: suggestions,
+		})
+	}
+
+	return missing
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+var longNeedsUpdate = `Check whether an update is available for an installed artifact
+
+Nothing is printed on stdout: the command exits with code 0 if an update is available,
+with code 1 if the installed version is the latest one or the check fails.
+
+Example - Update the "cloudtrail" artifact only if needed:
+	if falcoctl artifact needs-update cloudtrail; then falcoctl artifact install cloudtrail; fi
+`
+
+type artifactNeedsUpdateOptions struct {
+	*options.CommonOptions
+}
+
+// NewArtifactNeedsUpdateCmd returns the artifact needs-update command.
+func NewArtifactNeedsUpdateCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactNeedsUpdateOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "needs-update name [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Check whether an update is available for an installed artifact",
+		Long:                  longNeedsUpdate,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Keep stdout clean, the result is the exit code.
+			o.Printer.RedirectLogs(os.Stderr)
+			o.Printer.CheckErr(o.RunArtifactNeedsUpdate(ctx, args))
+		},
+	}
+
+	return cmd
+}
+
+// RunArtifactNeedsUpdate executes the business logic for the artifact needs-update command.
+func (o *artifactNeedsUpdateOptions) RunArtifactNeedsUpdate(ctx context.Context, args []string) error {
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return err
+	}
+
+	entry, err := installedState.Get(utils.ArtifactName(args[0]))
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	installed, version, digest, err := latestVersion(ctx, o.Printer, credentialStore, entry)
+	if err != nil {
+		return err
+	}
+
+	if digest == entry.Digest {
+		o.Printer.Verbosef("Installed version %q of %q is the latest one", installed, entry.Name)
+		return output.ErrSilentExit
+	}
+
+	o.Printer.Verbosef("Update available for %q: %q -> %q", entry.Name, installed, version)
+
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/browser"
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+var longOpen = `Open the documentation of an artifact in the default browser
+
+The URL is read from the "org.opencontainers.image.url" annotation of the manifest of the
+artifact, falling back to the "org.opencontainers.image.documentation" one. With --source,
+the repository URL in the "org.opencontainers.image.source" annotation is opened instead.
+
+Example - Open the documentation of "cloudtrail":
+	falcoctl artifact open cloudtrail
+
+Example - Open the source repository of an artifact given its reference:
+	falcoctl artifact open ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.6.0 --source
+`
+
+type artifactOpenOptions struct {
+	*options.CommonOptions
+	source bool
+}
+
+// NewArtifactOpenCmd returns the artifact open command.
+func NewArtifactOpenCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactOpenOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "open name|ref [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Open the documentation of an artifact in the default browser",
+		Long:                  longOpen,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactOpen(ctx, args))
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.source, "source", false, "open the source repository of the artifact instead of its documentation")
+
+	return cmd
+}
+
+// RunArtifactOpen executes the business logic for the artifact open command.
+func (o *artifactOpenOptions) RunArtifactOpen(ctx context.Context, args []string) error {
+	indexConfig, err := index.NewConfig(indexesFile)
+	if err != nil {
+		return err
+	}
+
+	mergedIndexes, err := utils.Indexes(indexConfig, falcoctlPath)
+	if err != nil {
+		return err
+	}
+
+	ref, err := utils.ParseReference(mergedIndexes, args[0])
+	if err != nil {
+		return err
+	}
+
+	reg, err := utils.GetRegistryFromRef(ref)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := oci.FetchManifest(ctx, ref, authn.NewClient(cred), runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{v1.AnnotationURL, v1.AnnotationDocumentation}
+	if o.source {
+		keys = []string{v1.AnnotationSource}
+	}
+
+	var url string
+	for _, key := range keys {
+		if url = manifest.Annotations[key]; url != "" {
+			break
+		}
+	}
+
+	if url == "" {
+		return fmt.Errorf("no URL annotation found for %q, run \"falcoctl artifact info %s\" to inspect the artifact", ref, args[0])
+	}
+
+	o.Printer.Info.Printfln("Opening %q", url)
+
+	return browser.OpenURL(url)
+}
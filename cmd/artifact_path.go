@@ -0,0 +1,82 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+var longPath = `Print the absolute paths of the files installed by an artifact
+
+Each path is printed on a separate line. If the artifact is not installed nothing is
+printed and the command exits with code 1.
+
+Example - Print the path of the files installed by the "cloudtrail" artifact:
+	falcoctl artifact path cloudtrail
+`
+
+type artifactPathOptions struct {
+	*options.CommonOptions
+}
+
+// NewArtifactPathCmd returns the artifact path command.
+func NewArtifactPathCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactPathOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "path name [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Print the absolute paths of the files installed by an artifact",
+		Long:                  longPath,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Keep stdout clean, it only holds the paths.
+			o.Printer.RedirectLogs(os.Stderr)
+			o.Printer.CheckErr(o.RunArtifactPath(ctx, args))
+		},
+	}
+
+	return cmd
+}
+
+// RunArtifactPath executes the business logic for the artifact path command.
+func (o *artifactPathOptions) RunArtifactPath(ctx context.Context, args []string) error {
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return err
+	}
+
+	entry, err := installedState.Get(utils.ArtifactName(args[0]))
+	if err != nil {
+		o.Printer.Verbosef("%s", err.Error())
+		return output.ErrSilentExit
+	}
+
+	for _, f := range entry.Files {
+		o.Printer.DefaultText.Println(f.Path)
+	}
+
+	return nil
+}
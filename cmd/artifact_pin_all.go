@@ -0,0 +1,148 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+// pinnedIcon marks the pinned artifacts in the commands listing the installed artifacts.
+const pinnedIcon = "🔒"
+
+var longPinAll = `Lock all the installed artifacts to their current digests
+
+The digest of each installed artifact is recorded in the "falcoctl-pins.yaml" lock file, next to
+the state file. Until "artifact unpin-all" is called, "artifact install" and "artifact install-from-url"
+refuse to install a pinned artifact with a different digest, e.g. during a production change freeze.
+Running the command again replaces the pins with the current state.
+
+Example - Freeze the installed artifacts:
+	falcoctl artifact pin-all
+`
+
+var longUnpinAll = `Remove the pins recorded by "artifact pin-all"
+
+Example - Allow the installed artifacts to be updated again:
+	falcoctl artifact unpin-all
+`
+
+type artifactPinAllOptions struct {
+	*options.CommonOptions
+}
+
+// NewArtifactPinAllCmd returns the artifact pin-all command.
+func NewArtifactPinAllCmd(opt *options.CommonOptions) *cobra.Command {
+	o := artifactPinAllOptions{
+		CommonOptions: opt,
+	}
+
+	return &cobra.Command{
+		Use:                   "pin-all [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Lock all the installed artifacts to their current digests",
+		Long:                  longPinAll,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactPinAll())
+		},
+	}
+}
+
+// NewArtifactUnpinAllCmd returns the artifact unpin-all command.
+func NewArtifactUnpinAllCmd(opt *options.CommonOptions) *cobra.Command {
+	o := artifactPinAllOptions{
+		CommonOptions: opt,
+	}
+
+	return &cobra.Command{
+		Use:                   "unpin-all [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 `Remove the pins recorded by "artifact pin-all"`,
+		Long:                  longUnpinAll,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactUnpinAll())
+		},
+	}
+}
+
+// RunArtifactPinAll executes the business logic for the artifact pin-all command.
+func (o *artifactPinAllOptions) RunArtifactPinAll() error {
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return err
+	}
+
+	if len(installedState.Entries) == 0 {
+		o.Printer.Info.Println("No installed artifact to pin")
+		return nil
+	}
+
+	existing, err := state.LoadPins(pinsFile)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		o.Printer.Warning.Printfln("Replacing the pins recorded on %s", existing.PinnedTimestamp)
+	}
+
+	pins := state.NewPins(installedState, time.Now().Format(timeFormat))
+	for _, pin := range pins.Artifacts {
+		o.Printer.Info.Printfln("%s Pinning %q to %s", pinnedIcon, pin.Name, pin.Digest)
+	}
+
+	if err = pins.Write(pinsFile); err != nil {
+		return fmt.Errorf("cannot write pins file %q: %w", pinsFile, err)
+	}
+
+	o.Printer.Success.Printfln("%d installed artifacts pinned in %q", len(pins.Artifacts), pinsFile)
+	return nil
+}
+
+// RunArtifactUnpinAll executes the business logic for the artifact unpin-all command.
+func (o *artifactPinAllOptions) RunArtifactUnpinAll() error {
+	err := os.Remove(pinsFile)
+	if os.IsNotExist(err) {
+		o.Printer.Info.Println("No pinned artifact")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot remove pins file %q: %w", pinsFile, err)
+	}
+
+	o.Printer.Success.Println("All the installed artifacts unpinned")
+	return nil
+}
+
+// checkPinned returns an error if the artifact is pinned to a digest other than the given one.
+func checkPinned(name, digest string) error {
+	pins, err := state.LoadPins(pinsFile)
+	if err != nil {
+		return err
+	}
+
+	if err = pins.Check(name, digest); err != nil {
+		return fmt.Errorf(`%w: run "falcoctl artifact unpin-all" to allow updates`, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+const defaultStableTag = "stable"
+
+var longPromoteStable = `Point a floating tag to the latest stable version of an artifact
+
+The highest tag which is a valid semver version, excluding pre-releases, is tagged as
+"stable", or as the tag given with --tag-name. The old and the new digest of the floating
+tag are printed, so that the change can be audited.
+
+Example - Tag the latest stable version of a plugin as "stable":
+	falcoctl artifact promote-stable ghcr.io/falcosecurity/plugins/plugin/cloudtrail
+
+Example - Tag the latest stable version of a plugin as "latest":
+	falcoctl artifact promote-stable ghcr.io/falcosecurity/plugins/plugin/cloudtrail --tag-name latest
+`
+
+type artifactPromoteStableOptions struct {
+	*options.CommonOptions
+	tagName string
+}
+
+// NewArtifactPromoteStableCmd returns the artifact promote-stable command.
+func NewArtifactPromoteStableCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactPromoteStableOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "promote-stable hostname/repo [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Point a floating tag to the latest stable version of an artifact",
+		Long:                  longPromoteStable,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactPromoteStable(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.tagName, "tag-name", defaultStableTag, "name of the floating tag")
+
+	return cmd
+}
+
+// RunArtifactPromoteStable executes the business logic for the artifact promote-stable command.
+func (o *artifactPromoteStableOptions) RunArtifactPromoteStable(ctx context.Context, args []string) error {
+	repo := args[0]
+
+	reg, err := utils.GetRegistryFromRef(repo)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return err
+	}
+
+	client := authn.NewClient(cred)
+
+	version, err := oci.LatestVersion(ctx, repo, client, false)
+	if err != nil {
+		return err
+	}
+	o.Printer.Verbosef("Latest stable version of %q is %q", repo, version)
+
+	oldDigest, newDigest, err := oci.MoveTag(ctx, repo, version, o.tagName, client)
+	if err != nil {
+		return err
+	}
+
+	if oldDigest == "" {
+		oldDigest = "none"
+	}
+
+	o.Printer.DefaultText.Printfln("tag: %s\nversion: %s\nold digest: %s\nnew digest: %s", o.tagName, version, oldDigest, newDigest)
+
+	if oldDigest == newDigest {
+		o.Printer.Info.Printfln("Tag %q already points to version %q", o.tagName, version)
+		return nil
+	}
+
+	o.Printer.Success.Printfln("Tag %q promoted to version %q", o.tagName, version)
+
+	return nil
+}
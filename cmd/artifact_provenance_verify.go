@@ -0,0 +1,197 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/metrics"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+	"github.com/falcosecurity/falcoctl/pkg/provenance"
+	"github.com/falcosecurity/falcoctl/pkg/signature"
+)
+
+var longProvenanceVerify = `Verify the SLSA provenance attestation of an artifact
+
+The attestation is looked for in the tag used by "cosign attest" and among the referrers of the artifact.
+The following checks are performed and reported individually:
+	signature    the DSSE envelope of the attestation is signed by the key passed with --key
+	builder      the builder ID of the provenance matches --builder
+	source       the source repository of the provenance matches --source
+	digest       one of the subjects of the provenance has the digest of the artifact
+	invocation   the build invocation parameters are present
+
+Both the v0.2 and the v1 SLSA provenance formats are supported. Only key-based signatures are verified:
+keyless signatures, relying on Fulcio certificates and the Rekor transparency log, are not supported.
+If several provenance attestations are attached, the one passing most checks is reported.
+The command exits with code 1 if any check fails.
+
+Example - Verify the provenance of a plugin built by the SLSA GitHub generator:
+	falcoctl artifact provenance-verify ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.3.0 \
+		--key cosign.pub \
+		--builder https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml@refs/tags/v1.9.0 \
+		--source https://github.com/falcosecurity/plugins
+`
+
+type artifactProvenanceVerifyOptions struct {
+	*options.CommonOptions
+	builder string
+	source  string
+	key     string
+}
+
+func (o *artifactProvenanceVerifyOptions) validate() error {
+	if o.builder == "" {
+		return fmt.Errorf("--builder must be set")
+	}
+	if o.source == "" {
+		return fmt.Errorf("--source must be set")
+	}
+	if o.key == "" {
+		return fmt.Errorf("--key must be set")
+	}
+	return nil
+}
+
+// NewArtifactProvenanceVerifyCmd returns the artifact provenance-verify command.
+func NewArtifactProvenanceVerifyCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactProvenanceVerifyOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "provenance-verify hostname/repo[:tag|@digest] --key cosign.pub --builder uri --source repo-url [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Verify the SLSA provenance attestation of an artifact",
+		Long:                  longProvenanceVerify,
+		Args:                  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactProvenanceVerify(ctx, args))
+		},
+	}
+
+	o.CommonOptions.AddOutputFlags(cmd.Flags())
+	cmd.Flags().StringVar(&o.builder, "builder", "", "expected builder ID of the provenance")
+	cmd.Flags().StringVar(&o.source, "source", "", "expected URL of the source repository of the provenance")
+	cmd.Flags().StringVar(&o.key, "key", "", "PEM encoded public key used to verify the signature of the attestation")
+
+	return cmd
+}
+
+// RunArtifactProvenanceVerify executes the business logic for the artifact provenance-verify command.
+func (o *artifactProvenanceVerifyOptions) RunArtifactProvenanceVerify(ctx context.Context, args []string) error {
+	ref := args[0]
+
+	publicKey, err := signature.LoadPublicKey(o.key)
+	if err != nil {
+		return err
+	}
+
+	reg, err := utils.GetRegistryFromRef(ref)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return err
+	}
+
+	client := authn.NewClient(cred)
+
+	desc, err := oci.Resolve(ctx, ref, client)
+	if err != nil {
+		return err
+	}
+
+	attestations, err := provenance.Fetch(ctx, ref, client)
+	if err != nil {
+		return err
+	}
+	o.Printer.Verbosef("Found %d SLSA provenance attestations for %q", len(attestations), ref)
+
+	exp := &provenance.Expectations{
+		BuilderID: o.builder,
+		SourceURI: o.source,
+		Digest:    desc.Digest.String(),
+	}
+
+	var checks []provenance.Check
+	bestPassed := -1
+	for i := range attestations {
+		signed := provenance.Check{Name: provenance.CheckSignature, Passed: true, Details: "signed by " + o.key}
+		if err = attestations[i].Envelope.Verify(publicKey); err != nil {
+			signed.Passed = false
+			signed.Details = err.Error()
+		}
+		current := append([]provenance.Check{signed}, attestations[i].Statement.Verify(exp)...)
+
+		passed := 0
+		for _, c := range current {
+			if c.Passed {
+				passed++
+			}
+		}
+		if passed > bestPassed {
+			checks, bestPassed = current, passed
+		}
+	}
+
+	if o.Output.IsStructured() {
+		if err = o.Printer.PrintData(o.Output, checks); err != nil {
+			return err
+		}
+	} else {
+		data := make([][]string, 0, len(checks))
+		for _, c := range checks {
+			result := "passed"
+			if !c.Passed {
+				result = "failed"
+			}
+			data = append(data, []string{c.Name, result, c.Details})
+		}
+		if err = o.Printer.PrintTable(output.ProvenanceChecks, data); err != nil {
+			return err
+		}
+	}
+
+	verified := bestPassed == len(checks)
+	recordMetrics(o.Printer, func(m *metrics.Metrics) {
+		m.RecordVerification(verified)
+	})
+
+	if !verified {
+		o.Printer.Error.Printfln("Provenance of %q not verified: %d of %d checks failed", ref, len(checks)-bestPassed, len(checks))
+		return output.ErrSilentExit
+	}
+
+	o.Printer.Success.Printfln("Provenance of %q verified", ref)
+	return nil
+}
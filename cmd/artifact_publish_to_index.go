@@ -0,0 +1,217 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+// indexTokenEnv is the environment variable holding the index API token when --token is not set.
+const indexTokenEnv = "FALCOCTL_INDEX_TOKEN"
+
+var longPublishToIndex = `Submit an artifact to an index for review
+
+The manifest of the artifact, for the platform where falcoctl is running, is fetched and its
+annotations are used to build the index entry:
+  - name: the last component of the repository;
+  - description: "org.opencontainers.image.description";
+  - home: "org.opencontainers.image.url", or "org.opencontainers.image.documentation";
+  - license: "org.opencontainers.image.licenses";
+  - maintainers: "org.opencontainers.image.authors", as a comma separated list of "Name <email>";
+  - sources: "org.opencontainers.image.source";
+  - keywords: "` + index.KeywordsAnnotation + `", as a comma separated list.
+
+The entry is sent to the index API, which returns a submission ID. The review status of the
+submission can be checked with "falcoctl artifact publish-to-index status <id>".
+The API token is read from --token or from the ` + indexTokenEnv + ` environment variable.
+
+Example - Print the index entry of a plugin without submitting it:
+	falcoctl artifact publish-to-index ghcr.io/myorg/plugins/myplugin:0.1.0 --index-api https://index.example.com/api --dry-run
+
+Example - Submit a plugin to an index:
+	falcoctl artifact publish-to-index ghcr.io/myorg/plugins/myplugin:0.1.0 --index-api https://index.example.com/api --token $TOKEN
+`
+
+var longPublishToIndexStatus = `Check the review status of an artifact submitted to an index
+
+The status is one of "pending", "approved" or "rejected", reported along with the message of the
+reviewers, if any.
+
+Example - Check the status of a submission:
+	falcoctl artifact publish-to-index status 42 --index-api https://index.example.com/api --token $TOKEN
+`
+
+type artifactPublishToIndexOptions struct {
+	*options.CommonOptions
+	indexAPI string
+	token    string
+	dryRun   bool
+}
+
+func (o *artifactPublishToIndexOptions) validate() error {
+	if o.indexAPI == "" && !o.dryRun {
+		return fmt.Errorf("--index-api must be set")
+	}
+	if o.token == "" {
+		o.token = os.Getenv(indexTokenEnv)
+	}
+	return nil
+}
+
+// NewArtifactPublishToIndexCmd returns the artifact publish-to-index command.
+func NewArtifactPublishToIndexCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactPublishToIndexOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "publish-to-index hostname/repo[:tag|@digest] --index-api url [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Submit an artifact to an index for review",
+		Long:                  longPublishToIndex,
+		Args:                  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactPublishToIndex(ctx, args))
+		},
+	}
+
+	o.CommonOptions.AddOutputFlags(cmd.PersistentFlags())
+	cmd.PersistentFlags().StringVar(&o.indexAPI, "index-api", "", "URL of the index API")
+	cmd.PersistentFlags().StringVar(&o.token, "token", "",
+		"token used to authenticate to the index API, read from "+indexTokenEnv+" if not set")
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", false, "only print the index entry, without submitting it")
+
+	cmd.AddCommand(newArtifactPublishToIndexStatusCmd(ctx, &o))
+
+	return cmd
+}
+
+func newArtifactPublishToIndexStatusCmd(ctx context.Context, o *artifactPublishToIndexOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:                   "status id --index-api url [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Check the review status of an artifact submitted to an index",
+		Long:                  longPublishToIndexStatus,
+		Args:                  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactPublishToIndexStatus(ctx, args))
+		},
+	}
+}
+
+// RunArtifactPublishToIndex executes the business logic for the artifact publish-to-index command.
+func (o *artifactPublishToIndexOptions) RunArtifactPublishToIndex(ctx context.Context, args []string) error {
+	ref := args[0]
+
+	parsedRef, err := registry.ParseReference(ref)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	client, err := registryClient(ctx, credentialStore, ref)
+	if err != nil {
+		return err
+	}
+
+	o.Printer.Verbosef("Fetching manifest of %q", ref)
+	manifest, err := oci.FetchManifest(ctx, ref, client, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+
+	artifactType, err := oci.ArtifactTypeOf(manifest)
+	if err != nil {
+		return err
+	}
+
+	entry := index.NewEntry(parsedRef.Registry, parsedRef.Repository, artifactType.String(), manifest.Annotations)
+	if entry.Description == "" || entry.License == "" || len(entry.Sources) == 0 {
+		o.Printer.Warning.Printfln("the entry of %q lacks some of description, license and sources, "+
+			"reviewers may ask to add the missing annotations", entry.Name)
+	}
+
+	if o.dryRun {
+		// The entry is printed as an index file, ready to be added to the index.
+		return o.Printer.PrintData(output.YAML, []*index.Entry{entry})
+	}
+
+	submission, err := index.NewSubmissionClient(o.indexAPI, o.token).Submit(ctx, entry)
+	if err != nil {
+		return fmt.Errorf("unable to submit %q to %s: %w", entry.Name, o.indexAPI, err)
+	}
+
+	if o.Output.IsStructured() {
+		return o.Printer.PrintData(o.Output, submission)
+	}
+
+	o.Printer.Success.Printfln("%q submitted for review, submission ID: %s", entry.Name, submission.ID)
+	o.Printer.Info.Printfln("Check the review status with: falcoctl artifact publish-to-index status %s --index-api %s",
+		submission.ID, o.indexAPI)
+
+	return nil
+}
+
+// RunArtifactPublishToIndexStatus executes the business logic for the artifact publish-to-index status command.
+func (o *artifactPublishToIndexOptions) RunArtifactPublishToIndexStatus(ctx context.Context, args []string) error {
+	submission, err := index.NewSubmissionClient(o.indexAPI, o.token).Status(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("unable to retrieve submission %q from %s: %w", args[0], o.indexAPI, err)
+	}
+
+	if o.Output.IsStructured() {
+		return o.Printer.PrintData(o.Output, submission)
+	}
+
+	printer := o.Printer.Info
+	switch submission.Status {
+	case index.SubmissionApproved:
+		printer = o.Printer.Success
+	case index.SubmissionRejected:
+		printer = o.Printer.Error
+	}
+	printer.Printfln("Submission %s: %s", submission.ID, submission.Status)
+
+	if submission.Message != "" {
+		o.Printer.DefaultText.Println(submission.Message)
+	}
+	if submission.URL != "" {
+		o.Printer.DefaultText.Println(submission.URL)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+var longReferrers = `List the artifacts referring to an artifact, such as signatures and SBOMs
+
+Referrers are retrieved both from the Referrers API, following all the returned pages,
+and from the fallback tag used by registries not supporting it. The results are merged
+and de-duplicated by digest. If one of the sources cannot be read, the referrers found
+in the other ones are listed anyway and the command exits with a non-zero exit code.
+
+Example - List the referrers of a plugin:
+	falcoctl artifact referrers ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.3.0
+
+Example - Print only the digests of the referrers of a plugin:
+	falcoctl artifact referrers ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.3.0 --output 'go-template={{range .}}{{.Digest}}{{"\n"}}{{end}}'
+`
+
+type artifactReferrersOptions struct {
+	*options.CommonOptions
+}
+
+// NewArtifactReferrersCmd returns the artifact referrers command.
+func NewArtifactReferrersCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactReferrersOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "referrers hostname/repo[:tag|@digest] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "List the artifacts referring to an artifact, such as signatures and SBOMs",
+		Long:                  longReferrers,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactReferrers(ctx, args))
+		},
+	}
+
+	o.CommonOptions.AddOutputFlags(cmd.Flags())
+
+	return cmd
+}
+
+// RunArtifactReferrers executes the business logic for the artifact referrers command.
+func (o *artifactReferrersOptions) RunArtifactReferrers(ctx context.Context, args []string) error {
+	ref := args[0]
+
+	reg, err := utils.GetRegistryFromRef(ref)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return err
+	}
+
+	client := authn.NewClient(cred)
+
+	desc, err := oci.Resolve(ctx, ref, client)
+	if err != nil {
+		return err
+	}
+
+	referrers, err := oci.Referrers(ctx, client, ref, desc.Digest, false)
+	if err != nil && !errors.Is(err, oci.ErrIncompleteReferrers) {
+		return err
+	}
+	incomplete := err != nil
+	if incomplete {
+		o.Printer.Warning.Printfln("the list of referrers may be incomplete: %s", err.Error())
+	}
+
+	switch {
+	case o.Output.IsStructured():
+		if err = o.Printer.PrintData(o.Output, referrers); err != nil {
+			return err
+		}
+	case len(referrers) == 0:
+		o.Printer.Info.Printfln("No referrer found for %q", ref)
+	default:
+		var data [][]string
+		for i := range referrers {
+			data = append(data, []string{referrers[i].Digest.String(), referrers[i].MediaType, strconv.FormatInt(referrers[i].Size, 10)})
+		}
+
+		if err = o.Printer.PrintTable(output.ArtifactReferrers, data); err != nil {
+			return err
+		}
+	}
+
+	if incomplete {
+		return output.ErrSilentExit
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+var longArtifactRename = `Move an artifact to another repository path
+
+All the tags of the old repository are copied to the new one, keeping their names and digests.
+The references are repositories, without tag or digest. The installed artifacts pointing to the
+old repository are updated in the state file to point to the new one, so that they keep being
+updated.
+
+With --delete-source, the manifests of the old repository are deleted once all the tags have been
+copied. The registry must support deletion, and the nodes they refer to are left to its garbage
+collection. Nothing is deleted if any tag could not be copied.
+
+Renaming across registries requires --allow-cross-registry, to prevent copying artifacts to
+another tenant by mistake.
+
+Example - Move a plugin to another path of the same registry:
+	falcoctl artifact rename ghcr.io/myorg/plugins/cloudtrail ghcr.io/myorg/falco/plugins/cloudtrail
+
+Example - Move a rulesfile and delete the old repository:
+	falcoctl artifact rename ghcr.io/myorg/rules ghcr.io/myorg/falco/rules --delete-source
+
+Example - Move a plugin to another registry:
+	falcoctl artifact rename ghcr.io/myorg/plugins/cloudtrail registry.corp/falco/cloudtrail --allow-cross-registry
+`
+
+type artifactRenameOptions struct {
+	*options.CommonOptions
+	deleteSource       bool
+	allowCrossRegistry bool
+}
+
+// NewArtifactRenameCmd returns the artifact rename command.
+func NewArtifactRenameCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactRenameOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "rename old-hostname/repo new-hostname/repo [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Move an artifact to another repository path",
+		Long:                  longArtifactRename,
+		Args:                  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactRename(ctx, args))
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.deleteSource, "delete-source", false, "delete the manifests of the old repository once all the tags are copied")
+	cmd.Flags().BoolVar(&o.allowCrossRegistry, "allow-cross-registry", false, "allow the new repository to be on another registry")
+
+	return cmd
+}
+
+// RunArtifactRename executes the business logic for the artifact rename command.
+func (o *artifactRenameOptions) RunArtifactRename(ctx context.Context, args []string) error {
+	src, err := normalizeReference(o.Printer, args[0])
+	if err != nil {
+		return err
+	}
+
+	dst, err := normalizeReference(o.Printer, args[1])
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range []string{src, dst} {
+		parsedRef, err := registry.ParseReference(ref)
+		if err != nil {
+			return err
+		}
+		if parsedRef.Reference != "" {
+			return fmt.Errorf("rename requires repository references without tag or digest, got %q", ref)
+		}
+	}
+
+	if src == dst {
+		return fmt.Errorf("old and new repositories are the same: %q", src)
+	}
+
+	srcRegistry, err := utils.GetRegistryFromRef(src)
+	if err != nil {
+		return err
+	}
+	dstRegistry, err := utils.GetRegistryFromRef(dst)
+	if err != nil {
+		return err
+	}
+	if srcRegistry != dstRegistry && !o.allowCrossRegistry {
+		return fmt.Errorf("%q and %q are on different registries, use --allow-cross-registry to rename across registries", src, dst)
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	srcClient, err := registryClient(ctx, credentialStore, src)
+	if err != nil {
+		return err
+	}
+
+	dstClient, err := registryClient(ctx, credentialStore, dst)
+	if err != nil {
+		return err
+	}
+
+	o.Printer.Info.Printfln("Preparing to rename %q to %q", src, dst)
+
+	var failed int
+	results, err := oci.CopyAllTags(ctx, src, srcClient, dst, dstClient, nil, func(result oci.TagCopy) {
+		if result.Err != nil {
+			failed++
+			o.Printer.Warning.Printfln("cannot copy tag %q: %s", result.Tag, result.Err.Error())
+			return
+		}
+		o.Printer.Info.Printfln("Tag %q copied, digest: %s", result.Tag, result.Destination.Digest)
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		return fmt.Errorf("no tag found in %q", src)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("unable to copy %d of %d tags to %q, the old repository is left unchanged", failed, len(results), dst)
+	}
+
+	if err := o.renameInstalled(src, dst); err != nil {
+		return err
+	}
+
+	if o.deleteSource {
+		descs := make([]v1.Descriptor, 0, len(results))
+		for _, result := range results {
+			descs = append(descs, *result.Source)
+		}
+		if err := oci.DeleteManifests(ctx, src, srcClient, descs); err != nil {
+			return fmt.Errorf("all the tags were copied to %q, but the old repository cannot be deleted: %w", dst, err)
+		}
+		o.Printer.Info.Printfln("Deleted the %d tags of %q", len(results), src)
+	}
+
+	o.Printer.Success.Printfln("Artifact renamed from %q to %q, %d tags copied", src, dst, len(results))
+
+	return nil
+}
+
+// renameInstalled points the installed artifacts of the src repository to the dst one.
+func (o *artifactRenameOptions) renameInstalled(src, dst string) error {
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return err
+	}
+
+	renamed := installedState.RenameRepository(src, dst)
+	if len(renamed) == 0 {
+		return nil
+	}
+
+	if err := installedState.Write(stateFile); err != nil {
+		return fmt.Errorf("cannot update state file %q: %w", stateFile, err)
+	}
+
+	for _, name := range renamed {
+		o.Printer.Info.Printfln("Installed artifact %q now points to %q", name, dst)
+	}
+
+	return nil
+}
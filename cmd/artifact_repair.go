@@ -0,0 +1,295 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+var longRepair = `Repair the installation of the installed artifacts
+
+The files of the installed artifacts are checked against the state file and the following
+problems are fixed:
+	- missing install directories are created
+	- files whose digest does not match the state file are downloaded again
+	- file permissions are restored to the ones recorded in the state file
+	- files no longer on disk are removed from the state file
+
+Running the command more than once produces the same final state.
+
+Example - Repair all the installed artifacts:
+	falcoctl artifact repair
+
+Example - Repair only the installed artifact "cloudtrail":
+	falcoctl artifact repair cloudtrail
+`
+
+type artifactRepairOptions struct {
+	*options.CommonOptions
+}
+
+// NewArtifactRepairCmd returns the artifact repair command.
+func NewArtifactRepairCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactRepairOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "repair [name1 [name2 ...]] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Repair the installation of the installed artifacts",
+		Long:                  longRepair,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactRepair(ctx, args))
+		},
+	}
+
+	return cmd
+}
+
+// RunArtifactRepair executes the business logic for the artifact repair command.
+func (o *artifactRepairOptions) RunArtifactRepair(ctx context.Context, args []string) error {
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return err
+	}
+
+	var entries []*state.Entry
+	if len(args) == 0 {
+		for i := range installedState.Entries {
+			entries = append(entries, &installedState.Entries[i])
+		}
+	}
+	for _, name := range args {
+		entry, err := installedState.Get(utils.ArtifactName(name))
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	}
+
+	var repaired, pruned int
+	for _, entry := range entries {
+		entryRepaired, entryPruned, err := o.repairEntry(ctx, entry)
+		repaired += entryRepaired
+		pruned += entryPruned
+		if err != nil {
+			o.Printer.Error.Printfln("cannot repair %q: %s", entry.Name, err.Error())
+		}
+	}
+
+	if pruned > 0 {
+		if err = installedState.Write(stateFile); err != nil {
+			return fmt.Errorf("cannot update state file %q: %w", stateFile, err)
+		}
+	}
+
+	if repaired+pruned == 0 {
+		o.Printer.Success.Println("No problem found, nothing to repair")
+		return nil
+	}
+
+	o.Printer.Success.Printfln("%d problem(s) repaired", repaired+pruned)
+
+	return nil
+}
+
+// repairEntry repairs the installation of a single artifact. It returns the number of repaired files
+// and directories and the number of files pruned from the entry.
+func (o *artifactRepairOptions) repairEntry(ctx context.Context, entry *state.Entry) (repaired, pruned int, err error) {
+	if _, err = os.Stat(entry.Dir); errors.Is(err, os.ErrNotExist) {
+		if err = os.MkdirAll(entry.Dir, 0o750); err != nil {
+			return 0, 0, fmt.Errorf("cannot create directory %q: %w", entry.Dir, err)
+		}
+		o.Printer.Info.Printfln("%s: created missing directory %q", entry.Name, entry.Dir)
+		repaired++
+	} else if err != nil {
+		return 0, 0, err
+	}
+
+	var files []state.File
+	var corrupted []*state.File
+	for i := range entry.Files {
+		file := &entry.Files[i]
+
+		current, err := state.NewFile(file.Path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			o.Printer.Info.Printfln("%s: removed %q from the state file, no longer on disk", entry.Name, file.Path)
+			pruned++
+			continue
+		case err != nil:
+			return repaired, pruned, err
+		case current.Digest != file.Digest:
+			corrupted = append(corrupted, file)
+		case current.Mode != file.Mode:
+			if err = os.Chmod(file.Path, file.Mode); err != nil {
+				return repaired, pruned, fmt.Errorf("cannot change permissions of %q: %w", file.Path, err)
+			}
+			o.Printer.Info.Printfln("%s: restored permissions %s of %q", entry.Name, file.Mode, file.Path)
+			repaired++
+		}
+		files = append(files, *file)
+	}
+
+	if len(corrupted) > 0 {
+		if err = o.downloadFiles(ctx, entry, corrupted); err != nil {
+			return repaired, pruned, err
+		}
+		repaired += len(corrupted)
+	}
+
+	if pruned > 0 {
+		entry.Files = files
+	}
+
+	return repaired, pruned, nil
+}
+
+// downloadFiles downloads again the installed version of an artifact, from its registry or from the URL it was
+// installed from, and replaces the given files with the downloaded ones.
+func (o *artifactRepairOptions) downloadFiles(ctx context.Context, entry *state.Entry, files []*state.File) error {
+	tmpDir, err := os.MkdirTemp("", "falcoctl")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	extractDir := filepath.Join(tmpDir, "extracted")
+	if err = os.Mkdir(extractDir, 0o750); err != nil {
+		return err
+	}
+
+	var source string
+	if entry.URL != "" {
+		source = entry.URL
+		err = downloadURL(ctx, entry, tmpDir, extractDir)
+	} else {
+		source, err = o.pullInstalled(ctx, entry, tmpDir, extractDir)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		src := filepath.Join(extractDir, filepath.Base(file.Path))
+
+		pulled, err := state.NewFile(src)
+		if err != nil {
+			return fmt.Errorf("cannot find %q in %q: %w", filepath.Base(file.Path), source, err)
+		}
+		if pulled.Digest != file.Digest {
+			return fmt.Errorf("digest of %q in %q does not match the state file", filepath.Base(file.Path), source)
+		}
+
+		data, err := os.ReadFile(filepath.Clean(src))
+		if err != nil {
+			return err
+		}
+
+		if err = os.WriteFile(file.Path, data, file.Mode); err != nil {
+			return fmt.Errorf("cannot write %q: %w", file.Path, err)
+		}
+		// WriteFile does not change the permissions of existing files.
+		if err = os.Chmod(file.Path, file.Mode); err != nil {
+			return fmt.Errorf("cannot change permissions of %q: %w", file.Path, err)
+		}
+
+		o.Printer.Info.Printfln("%s: downloaded again %q, digest did not match the state file", entry.Name, file.Path)
+	}
+
+	return nil
+}
+
+// pullInstalled pulls the installed version of an artifact and extracts it to extractDir.
+// It returns the pulled reference.
+func (o *artifactRepairOptions) pullInstalled(ctx context.Context, entry *state.Entry, tmpDir, extractDir string) (string, error) {
+	// Pin the reference to the installed digest, to get exactly the installed content.
+	parsedRef, err := registry.ParseReference(entry.Ref)
+	if err != nil {
+		return "", err
+	}
+	parsedRef.Reference = entry.Digest
+	ref, err := rewriteReference(o.Printer, parsedRef.String())
+	if err != nil {
+		return "", err
+	}
+
+	if parsedRef, err = registry.ParseReference(ref); err != nil {
+		return "", err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return "", err
+	}
+
+	installOptions := artifactInstallOptions{
+		CommonOptions:   o.CommonOptions,
+		credentialStore: credentialStore,
+	}
+
+	puller, err := installOptions.getPuller(ctx, parsedRef.Registry)
+	if err != nil {
+		return "", err
+	}
+
+	// Installed plugins always match the current OS and architecture.
+	result, err := puller.Pull(ctx, ref, tmpDir, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(filepath.Join(tmpDir, result.Filename))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err = utils.ExtractTarGz(f, extractDir); err != nil {
+		return "", fmt.Errorf("cannot extract %q: %w", result.Filename, err)
+	}
+
+	return ref, nil
+}
+
+// downloadURL downloads an artifact installed from a URL, verifying its checksum, and installs it to extractDir.
+func downloadURL(ctx context.Context, entry *state.Entry, tmpDir, extractDir string) error {
+	filename, err := downloadFilename(entry.URL)
+	if err != nil {
+		return err
+	}
+
+	downloaded := filepath.Join(tmpDir, filename)
+	if _, err = utils.Download(ctx, entry.URL, entry.Checksum, downloaded); err != nil {
+		return err
+	}
+
+	_, err = utils.InstallFile(downloaded, extractDir)
+	return err
+}
@@ -0,0 +1,214 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+var rulesContent = []byte("- rule: test\n")
+
+// setupRepair installs a rules file from a test server and records it in a temporary state file.
+// It returns the options of the repair command, the installed entry and the output of the printer.
+func setupRepair(t *testing.T) (*artifactRepairOptions, *state.Entry, *bytes.Buffer) {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(rulesContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	tmpDir := t.TempDir()
+	oldStateFile := stateFile
+	stateFile = filepath.Join(tmpDir, "state.yaml")
+	t.Cleanup(func() { stateFile = oldStateFile })
+
+	installDir := filepath.Join(tmpDir, "rules")
+	if err := os.Mkdir(installDir, 0o750); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	path := filepath.Join(installDir, "rules.yaml")
+	if err := os.WriteFile(path, rulesContent, 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	file, err := state.NewFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entry := state.Entry{
+		Name:     "rules",
+		Type:     "rulesfile",
+		URL:      srv.URL + "/rules.yaml",
+		Checksum: digest.FromBytes(rulesContent).String(),
+		Digest:   digest.FromBytes(rulesContent).String(),
+		Dir:      installDir,
+		Files:    []state.File{*file},
+	}
+	s := &state.State{Entries: []state.Entry{entry}}
+	if err = s.Write(stateFile); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := &bytes.Buffer{}
+	o := &artifactRepairOptions{
+		CommonOptions: &options.CommonOptions{Printer: output.NewPrinter("", false, out)},
+	}
+
+	return o, &entry, out
+}
+
+func readState(t *testing.T) *state.State {
+	t.Helper()
+
+	s, err := state.New(stateFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return s
+}
+
+func TestRepairNothingToRepair(t *testing.T) {
+	o, entry, out := setupRepair(t)
+
+	if err := o.RunArtifactRepair(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "No problem found") {
+		t.Errorf("expected no problem to be found, got output %q", out.String())
+	}
+	if s := readState(t); len(s.Entries) != 1 || len(s.Entries[0].Files) != len(entry.Files) {
+		t.Errorf("expected the state file to be unchanged, got %+v", s.Entries)
+	}
+}
+
+func TestRepairMissingDirectory(t *testing.T) {
+	o, entry, out := setupRepair(t)
+
+	if err := os.RemoveAll(entry.Dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := o.RunArtifactRepair(context.Background(), []string{"rules"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info, err := os.Stat(entry.Dir); err != nil || !info.IsDir() {
+		t.Errorf("expected directory %q to be created, got error %v", entry.Dir, err)
+	}
+	// The files were removed together with the directory.
+	if s := readState(t); len(s.Entries) != 1 || len(s.Entries[0].Files) != 0 {
+		t.Errorf("expected the missing files to be removed from the state file, got %+v", s.Entries)
+	}
+	if !strings.Contains(out.String(), "2 problem(s) repaired") {
+		t.Errorf("expected 2 problems to be repaired, got output %q", out.String())
+	}
+}
+
+func TestRepairOrphanedFile(t *testing.T) {
+	o, entry, out := setupRepair(t)
+
+	if err := os.Remove(entry.Files[0].Path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := o.RunArtifactRepair(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s := readState(t); len(s.Entries) != 1 || len(s.Entries[0].Files) != 0 {
+		t.Errorf("expected the missing file to be removed from the state file, got %+v", s.Entries)
+	}
+	if !strings.Contains(out.String(), "no longer on disk") {
+		t.Errorf("expected the missing file to be reported, got output %q", out.String())
+	}
+
+	// Running the command again finds nothing to repair.
+	out.Reset()
+	if err := o.RunArtifactRepair(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "No problem found") {
+		t.Errorf("expected no problem to be found, got output %q", out.String())
+	}
+}
+
+func TestRepairDigestMismatch(t *testing.T) {
+	o, entry, out := setupRepair(t)
+
+	path := entry.Files[0].Path
+	if err := os.WriteFile(path, []byte("tampered"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := o.RunArtifactRepair(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(data, rulesContent) {
+		t.Errorf("expected %q to be downloaded again, got content %q", path, data)
+	}
+	if !strings.Contains(out.String(), "1 problem(s) repaired") {
+		t.Errorf("expected 1 problem to be repaired, got output %q", out.String())
+	}
+	if s := readState(t); len(s.Entries) != 1 || len(s.Entries[0].Files) != 1 {
+		t.Errorf("expected the state file to be unchanged, got %+v", s.Entries)
+	}
+}
+
+func TestRepairPermissions(t *testing.T) {
+	o, entry, out := setupRepair(t)
+
+	path := entry.Files[0].Path
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := o.RunArtifactRepair(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Mode() != entry.Files[0].Mode {
+		t.Errorf("expected permissions %s of %q to be restored, got %s", entry.Files[0].Mode, path, info.Mode())
+	}
+	if !strings.Contains(out.String(), "restored permissions") {
+		t.Errorf("expected the permissions to be reported, got output %q", out.String())
+	}
+}
+
+func TestRepairUnknownArtifact(t *testing.T) {
+	o, _, _ := setupRepair(t)
+
+	if err := o.RunArtifactRepair(context.Background(), []string{"unknown"}); err == nil {
+		t.Errorf("expected an error repairing an artifact that is not installed")
+	}
+}
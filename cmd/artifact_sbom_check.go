@@ -0,0 +1,161 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+	"github.com/falcosecurity/falcoctl/pkg/sbom"
+)
+
+var longSbomCheck = `Verify that all the packages listed in the SBOM of an artifact are in an allowlist
+
+The SBOM is looked for in the tag used by "cosign attach sbom" and among the referrers of the artifact.
+SPDX, CycloneDX and Syft SBOMs in json format are supported.
+
+The allowlist is a json file containing either an array of entries or an object with the entries
+under the "packages" key. Each entry is matched against the name of a package, its name@version
+and its package URL. Entries containing "*" or "?" are patterns, where "*" matches any sequence
+of characters and "?" any single character; the other ones must match exactly.
+
+The command exits with code 1 if the SBOM lists packages not in the allowlist.
+
+Example - Check the SBOM of a plugin:
+	falcoctl artifact sbom-check ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.3.0 --known-packages allowlist.json
+
+Example - An allowlist approving an exact version of a package and all the golang.org/x packages:
+	{"packages": ["github.com/spf13/cobra@v1.5.0", "golang.org/x/*"]}
+`
+
+type artifactSbomCheckOptions struct {
+	*options.CommonOptions
+	knownPackages string
+}
+
+// artifactSbomCheck is the structured output of the artifact sbom-check command.
+type artifactSbomCheck struct {
+	Ref      string         `json:"ref" yaml:"ref"`
+	Packages int            `json:"packages" yaml:"packages"`
+	Unknown  []sbom.Package `json:"unknown" yaml:"unknown"`
+}
+
+func (o *artifactSbomCheckOptions) validate() error {
+	if o.knownPackages == "" {
+		return fmt.Errorf("--known-packages must be set")
+	}
+	return nil
+}
+
+// NewArtifactSbomCheckCmd returns the artifact sbom-check command.
+func NewArtifactSbomCheckCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactSbomCheckOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "sbom-check hostname/repo[:tag|@digest] --known-packages allowlist.json [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Verify that all the packages listed in the SBOM of an artifact are in an allowlist",
+		Long:                  longSbomCheck,
+		Args:                  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactSbomCheck(ctx, args))
+		},
+	}
+
+	o.CommonOptions.AddOutputFlags(cmd.Flags())
+	cmd.Flags().StringVar(&o.knownPackages, "known-packages", "", "json file with the allowlist of the approved packages")
+
+	return cmd
+}
+
+// RunArtifactSbomCheck executes the business logic for the artifact sbom-check command.
+func (o *artifactSbomCheckOptions) RunArtifactSbomCheck(ctx context.Context, args []string) error {
+	ref := args[0]
+
+	allowlist, err := sbom.LoadAllowlist(o.knownPackages)
+	if err != nil {
+		return err
+	}
+
+	reg, err := utils.GetRegistryFromRef(ref)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return err
+	}
+
+	data, mediaType, err := sbom.Fetch(ctx, ref, authn.NewClient(cred))
+	if err != nil {
+		return err
+	}
+	o.Printer.Verbosef("Found SBOM of type %q", mediaType)
+
+	packages, err := sbom.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	result := artifactSbomCheck{
+		Ref:      ref,
+		Packages: len(packages),
+		Unknown:  allowlist.Unknown(packages),
+	}
+	if result.Unknown == nil {
+		result.Unknown = []sbom.Package{}
+	}
+
+	if o.Output.IsStructured() {
+		if err = o.Printer.PrintData(o.Output, result); err != nil {
+			return err
+		}
+	} else if len(result.Unknown) == 0 {
+		o.Printer.Success.Printfln("All the %d packages listed in the SBOM of %q are known", result.Packages, ref)
+	} else {
+		tableData := make([][]string, 0, len(result.Unknown))
+		for _, p := range result.Unknown {
+			tableData = append(tableData, []string{p.Name, p.Version, p.PURL})
+		}
+		if err = o.Printer.PrintTable(output.UnknownPackages, tableData); err != nil {
+			return err
+		}
+		o.Printer.Error.Printfln("%d of the %d packages listed in the SBOM of %q are not in the allowlist",
+			len(result.Unknown), result.Packages, ref)
+	}
+
+	if len(result.Unknown) > 0 {
+		return output.ErrSilentExit
+	}
+
+	return nil
+}
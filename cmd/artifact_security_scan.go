@@ -0,0 +1,111 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+	"github.com/falcosecurity/falcoctl/pkg/rules"
+)
+
+var longSecurityScan = `Analyze a Falco rules file for quality issues
+
+The following findings are reported, each one with a severity level:
+	error      rules without output fields
+	warning    rules whose condition has no process filter and may match too broadly
+	info       rules without exceptions for common administrative tools
+
+Macros defined in the rules file are expanded before analyzing the conditions. The command
+exits with code 1 if at least one finding of severity "error" is reported.
+
+Example - Analyze the rules file "falco_rules.yaml":
+	falcoctl artifact security-scan falco_rules.yaml
+
+Example - Analyze the rules file "falco_rules.yaml" ignoring the rule "Terminal shell in container":
+	falcoctl artifact security-scan falco_rules.yaml --ignore-rule "Terminal shell in container"
+`
+
+type artifactSecurityScanOptions struct {
+	*options.CommonOptions
+	ignoreRules []string
+}
+
+// NewArtifactSecurityScanCmd returns the artifact security-scan command.
+func NewArtifactSecurityScanCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactSecurityScanOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "security-scan rulesfile [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Analyze a Falco rules file for quality issues",
+		Long:                  longSecurityScan,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactSecurityScan(ctx, args))
+		},
+	}
+
+	o.CommonOptions.AddOutputFlags(cmd.Flags())
+	cmd.Flags().StringArrayVar(&o.ignoreRules, "ignore-rule", nil,
+		"suppress the findings for the given rule. Can be specified multiple times")
+
+	return cmd
+}
+
+// RunArtifactSecurityScan executes the business logic for the artifact security-scan command.
+func (o *artifactSecurityScanOptions) RunArtifactSecurityScan(ctx context.Context, args []string) error {
+	data, err := os.ReadFile(filepath.Clean(args[0]))
+	if err != nil {
+		return err
+	}
+
+	items, err := rules.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	findings := rules.Scan(items, o.ignoreRules)
+
+	switch {
+	case o.Output.IsStructured():
+		if err = o.Printer.PrintData(o.Output, findings); err != nil {
+			return err
+		}
+	case len(findings) == 0:
+		o.Printer.Success.Printfln("No findings for %q", args[0])
+	default:
+		var table [][]string
+		for _, f := range findings {
+			table = append(table, []string{f.Rule, string(f.Severity), f.Message})
+		}
+		if err = o.Printer.PrintTable(output.RulesFindings, table); err != nil {
+			return err
+		}
+	}
+
+	if rules.HasErrors(findings) {
+		return output.ErrSilentExit
+	}
+
+	return nil
+}
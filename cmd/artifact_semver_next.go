@@ -0,0 +1,163 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/conventional"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+var longSemverNext = `Compute the next version of an artifact from the conventional commits since the last release
+
+The commits of the git repository in the current directory made since the git tag set with --since-tag
+are analyzed following the conventional commits specification: a breaking change, i.e. "feat!:" or
+a "BREAKING CHANGE:" footer, bumps the major version, a "feat:" commit the minor one and a "fix:"
+commit the patch one. The version of the tag, with its "v" prefix stripped, is bumped accordingly
+and the next version is printed. If no commit requires a release, the current version is printed.
+
+With --push-tag, the artifact at the given reference, "latest" if it has neither a tag nor a digest,
+is tagged with the next version in the registry, unless a tag with that name already exists.
+
+Example - Print the next version of a plugin:
+	falcoctl artifact semver-next ghcr.io/falcosecurity/plugins/plugin/cloudtrail --since-tag v0.6.0
+
+Example - Tag the artifact pushed as "latest" with the next version:
+	falcoctl artifact semver-next ghcr.io/falcosecurity/plugins/plugin/cloudtrail --since-tag v0.6.0 --push-tag
+`
+
+type artifactSemverNextOptions struct {
+	*options.CommonOptions
+	sinceTag string
+	pushTag  bool
+}
+
+func (o *artifactSemverNextOptions) validate() error {
+	if o.sinceTag == "" {
+		return fmt.Errorf("--since-tag must be set")
+	}
+
+	return nil
+}
+
+// NewArtifactSemverNextCmd returns the artifact semver-next command.
+func NewArtifactSemverNextCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactSemverNextOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "semver-next hostname/repo[:tag|@digest] --since-tag tag [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Compute the next version of an artifact from the conventional commits since the last release",
+		Long:                  longSemverNext,
+		Args:                  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactSemverNext(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.sinceTag, "since-tag", "", "git tag of the last release, e.g. \"v0.6.0\"")
+	cmd.Flags().BoolVar(&o.pushTag, "push-tag", false, "tag the artifact with the next version in the registry")
+
+	return cmd
+}
+
+// RunArtifactSemverNext executes the business logic for the artifact semver-next command.
+func (o *artifactSemverNextOptions) RunArtifactSemverNext(ctx context.Context, args []string) error {
+	parsedRef, err := registry.ParseReference(args[0])
+	if err != nil {
+		return err
+	}
+
+	messages, err := utils.GitCommitMessages(ctx, o.sinceTag)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range messages {
+		o.Printer.Verbosef("%s: %s", conventional.Classify(m), firstLine(m))
+	}
+
+	bump := conventional.Analyze(messages)
+	next, err := conventional.Next(o.sinceTag, bump)
+	if err != nil {
+		return err
+	}
+
+	o.Printer.DefaultText.Println(next)
+
+	if bump == conventional.None {
+		o.Printer.Info.Printfln("No commit since %q requires a release", o.sinceTag)
+		return nil
+	}
+	o.Printer.Info.Printfln("%d commit(s) since %q, %s bump", len(messages), o.sinceTag, bump)
+
+	if !o.pushTag {
+		return nil
+	}
+
+	if parsedRef.Reference == "" {
+		parsedRef.Reference = oci.DefaultTag
+	}
+	ref := parsedRef.String()
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	client, err := registryClient(ctx, credentialStore, ref)
+	if err != nil {
+		return err
+	}
+
+	parsedRef.Reference = ""
+	tags, err := oci.ListTags(ctx, parsedRef.String(), client)
+	if err != nil {
+		return err
+	}
+	for _, t := range tags {
+		if t == next {
+			return fmt.Errorf("tag %q already exists in %q", next, parsedRef.String())
+		}
+	}
+
+	digest, err := oci.ApplyTags(ctx, ref, []string{next}, client)
+	if err != nil {
+		return err
+	}
+	o.Printer.Success.Printfln("Artifact %s tagged as %q", digest, next)
+
+	return nil
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
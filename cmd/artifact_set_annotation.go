@@ -0,0 +1,165 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+var longSetAnnotation = `Add or update an annotation of an already pushed artifact
+
+The manifest of the artifact, or its index for multi-platform plugins, is fetched, the annotation
+is set and the manifest is pushed again, without uploading its content again. Since the digest
+depends on the manifest, the updated artifact has a new digest, which is printed so that pinned
+references can be updated. If the reference holds a tag, the tag is moved to the updated artifact,
+while other tags, as well as signatures and attestations, keep pointing to the old one.
+
+Fields derived from the content, e.g. "digest" or "size", cannot be set. Annotations with the
+"io.falcosecurity.artifact." prefix are reserved to falcoctl, use --force-annotations to set them anyway.
+
+The annotations of artifacts locked with "artifact lock-annotations" cannot be modified, unless
+--force-unlock is passed with the digest of the locked manifest as a confirmation. The updated manifest
+stays locked, unless the "org.falcosecurity.artifact.annotations-locked" annotation is set to "false".
+
+Example - Add a security advisory to an artifact:
+	falcoctl artifact set-annotation ghcr.io/myorg/rules/myrules:1.0.0 --key io.falcosecurity.advisory --value CVE-2023-0001
+
+Example - Deprecate an artifact in favor of a new one:
+	falcoctl artifact set-annotation ghcr.io/myorg/rules/myrules:1.0.0 \
+		--key io.falcosecurity.deprecated --value "Use myrules-v2 instead"
+`
+
+type artifactSetAnnotationOptions struct {
+	*options.CommonOptions
+	key              string
+	value            string
+	forceAnnotations bool
+	forceUnlock      string
+}
+
+func (o *artifactSetAnnotationOptions) validate() error {
+	if o.key == "" {
+		return fmt.Errorf("--key must be set")
+	}
+
+	if strings.HasPrefix(o.key, oci.ReservedAnnotationPrefix) && !o.forceAnnotations {
+		return fmt.Errorf("annotation %q is reserved to falcoctl, use --force-annotations to set it anyway", o.key)
+	}
+
+	return nil
+}
+
+// NewArtifactSetAnnotationCmd returns the artifact set-annotation command.
+func NewArtifactSetAnnotationCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactSetAnnotationOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "set-annotation hostname/repo[:tag|@digest] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Add or update an annotation of an already pushed artifact",
+		Long:                  longSetAnnotation,
+		Args:                  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactSetAnnotation(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.key, "key", "", "key of the annotation")
+	cmd.Flags().StringVar(&o.value, "value", "", "value of the annotation")
+	cmd.Flags().BoolVar(&o.forceAnnotations, "force-annotations", false,
+		fmt.Sprintf("allow setting annotations with the %q prefix, reserved to falcoctl", oci.ReservedAnnotationPrefix))
+	cmd.Flags().StringVar(&o.forceUnlock, "force-unlock", "",
+		"modify the annotations of a locked artifact anyway. The digest of its manifest must be passed as a confirmation")
+
+	return cmd
+}
+
+// RunArtifactSetAnnotation executes the business logic for the artifact set-annotation command.
+func (o *artifactSetAnnotationOptions) RunArtifactSetAnnotation(ctx context.Context, args []string) error {
+	ref, err := normalizeReference(o.Printer, args[0])
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	client, err := registryClient(ctx, credentialStore, ref)
+	if err != nil {
+		return err
+	}
+
+	locks, err := state.LoadAnnotationLocks(annotationLocksFile)
+	if err != nil {
+		return err
+	}
+
+	desc, annotations, err := oci.FetchAnnotations(ctx, ref, client)
+	if err != nil {
+		return err
+	}
+
+	locked, err := checkAnnotationsLock(locks, desc, annotations, o.forceUnlock)
+	if err != nil {
+		return err
+	}
+	if locked {
+		o.Printer.Warning.Printfln("Modifying the locked annotations of %q (%s)", ref, desc.Digest)
+	}
+
+	oldDigest, newDigest, err := oci.UpdateAnnotation(ctx, ref, client, o.key, o.value)
+	if err != nil {
+		return err
+	}
+
+	if lock, ok := locks.Get(oldDigest); ok && oldDigest != newDigest {
+		// The lock follows the updated manifest, unless it has been explicitly unlocked.
+		timestamp := lock.LockedTimestamp
+		locks.Remove(oldDigest)
+		if o.key != oci.AnnotationsLockedAnnotation || o.value == "true" {
+			locks.Add(ref, newDigest, timestamp)
+		}
+		if err = locks.Write(annotationLocksFile); err != nil {
+			return fmt.Errorf("cannot write annotation locks file %q: %w", annotationLocksFile, err)
+		}
+	}
+
+	o.Printer.DefaultText.Printfln("annotation: %s\nold digest: %s\nnew digest: %s", o.key, oldDigest, newDigest)
+
+	if oldDigest == newDigest {
+		o.Printer.Info.Printfln("Annotation %q of %q is already set to %q", o.key, ref, o.value)
+		return nil
+	}
+
+	o.Printer.Success.Printfln("Annotation %q of %q set, the artifact has a new digest", o.key, ref)
+
+	return nil
+}
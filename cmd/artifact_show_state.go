@@ -0,0 +1,92 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+	"github.com/falcosecurity/falcoctl/pkg/state"
+)
+
+var longShowState = `Print the content of the state file as JSON
+
+The state file keeps track of the artifacts installed by falcoctl, their files and digests. Printing
+it helps debugging installation issues without looking for the file on disk.
+
+Example - Print the whole state file:
+	falcoctl artifact show-state
+
+Example - Print only the entry of an artifact:
+	falcoctl artifact show-state --filter cloudtrail-rules
+
+Example - Print the path of the state file:
+	falcoctl artifact show-state --path-only
+`
+
+type artifactShowStateOptions struct {
+	*options.CommonOptions
+	pathOnly bool
+	filter   string
+}
+
+// NewArtifactShowStateCmd returns the artifact show-state command.
+func NewArtifactShowStateCmd(opt *options.CommonOptions) *cobra.Command {
+	o := artifactShowStateOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "show-state [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Print the content of the state file as JSON",
+		Long:                  longShowState,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactShowState())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.pathOnly, "path-only", false, "only print the path of the state file")
+	cmd.Flags().StringVar(&o.filter, "filter", "", "only print the entry of the artifact with the given name")
+	cmd.MarkFlagsMutuallyExclusive("path-only", "filter")
+
+	return cmd
+}
+
+// RunArtifactShowState executes the business logic for the artifact show-state command.
+func (o *artifactShowStateOptions) RunArtifactShowState() error {
+	if o.pathOnly {
+		o.Printer.DefaultText.Println(stateFile)
+		return nil
+	}
+
+	installedState, err := state.New(stateFile)
+	if err != nil {
+		return err
+	}
+
+	if o.filter == "" {
+		return o.Printer.PrintData(output.JSON, installedState)
+	}
+
+	entry, err := installedState.Get(o.filter)
+	if err != nil {
+		return err
+	}
+
+	return o.Printer.PrintData(output.JSON, entry)
+}
@@ -0,0 +1,205 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/signature"
+)
+
+var longSignAllTags = `Sign every tagged version of an artifact lacking a valid signature
+
+Each tag of the repository is resolved to its digest, and the digests lacking a cosign signature
+valid for the public key matching --key, either in the "sha256-<digest>.sig" tag or among their
+referrers, are signed. The signature is pushed to the "sha256-<digest>.sig" tag, next to the
+signatures already stored there. Tags pointing to the same digest are signed once, while the tags
+of the manifests attached by cosign, e.g. "sha256-<digest>.sig", are skipped.
+
+A failure does not stop the command: the tags that could not be signed are reported at the end.
+
+The key must be an unencrypted PEM private key (ECDSA, RSA or Ed25519): encrypted cosign keys,
+as generated by "cosign generate-key-pair", are not supported.
+
+Example - Show which tags of "myplugin" would be signed:
+	falcoctl artifact sign-all-tags localhost:5000/myplugin --key cosign.pem --dry-run
+
+Example - Sign all the unsigned tags of "myplugin":
+	falcoctl artifact sign-all-tags localhost:5000/myplugin --key cosign.pem
+`
+
+type artifactSignAllTagsOptions struct {
+	*options.CommonOptions
+	key    string
+	dryRun bool
+}
+
+func (o *artifactSignAllTagsOptions) validate() error {
+	if o.key == "" {
+		return fmt.Errorf("--key must be set")
+	}
+	return nil
+}
+
+// NewArtifactSignAllTagsCmd returns the artifact sign-all-tags command.
+func NewArtifactSignAllTagsCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactSignAllTagsOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "sign-all-tags hostname/repo --key keyfile [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Sign every tagged version of an artifact lacking a valid signature",
+		Long:                  longSignAllTags,
+		Args:                  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactSignAllTags(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.key, "key", "", "PEM private key used to sign the artifacts")
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", false, "only report the tags that would be signed")
+
+	return cmd
+}
+
+// RunArtifactSignAllTags executes the business logic for the artifact sign-all-tags command.
+func (o *artifactSignAllTagsOptions) RunArtifactSignAllTags(ctx context.Context, args []string) error {
+	signer, err := signature.LoadPrivateKey(o.key)
+	if err != nil {
+		return err
+	}
+
+	ref, err := normalizeReference(o.Printer, args[0])
+	if err != nil {
+		return err
+	}
+
+	parsedRef, err := registry.ParseReference(ref)
+	if err != nil {
+		return err
+	}
+	if parsedRef.Reference != "" {
+		return fmt.Errorf("a repository reference without tag or digest is required, got %q", args[0])
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	client, err := registryClient(ctx, credentialStore, ref)
+	if err != nil {
+		return err
+	}
+
+	tags, err := oci.ListTags(ctx, ref, client)
+	if err != nil {
+		return fmt.Errorf("unable to list the tags of %q: %w", ref, err)
+	}
+
+	// The digests already processed, with the tags pointing to them.
+	processed := make(map[string]string)
+	var signed, skipped int
+	var failures []string
+	for _, tag := range tags {
+		if oci.IsAttachedTag(tag) {
+			o.Printer.Verbosef("Skipping %q: attached by cosign", tag)
+			continue
+		}
+
+		desc, err := oci.Resolve(ctx, fmt.Sprintf("%s:%s", ref, tag), client)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", tag, err.Error()))
+			continue
+		}
+
+		d := desc.Digest.String()
+		if other, ok := processed[d]; ok {
+			o.Printer.Info.Printfln("Skipping %q: same digest as %q", tag, other)
+			continue
+		}
+		processed[d] = tag
+
+		ok, err := o.signTag(ctx, fmt.Sprintf("%s@%s", ref, d), client, signer, tag, d)
+		switch {
+		case err != nil:
+			failures = append(failures, fmt.Sprintf("%s: %s", tag, err.Error()))
+		case ok:
+			signed++
+		default:
+			skipped++
+		}
+	}
+
+	verb := "Signed"
+	if o.dryRun {
+		verb = "Would sign"
+	}
+	o.Printer.Success.Printfln("%s %d digest(s), %d already signed", verb, signed, skipped)
+
+	if len(failures) > 0 {
+		o.Printer.Error.Printfln("Unable to sign %d tag(s):\n%s", len(failures), strings.Join(failures, "\n"))
+		return fmt.Errorf("unable to sign %d tag(s) of %q", len(failures), ref)
+	}
+
+	return nil
+}
+
+// signTag signs the artifact pointed by ref, pinned to the digest d of tag, if it lacks a valid signature.
+// It returns true if the artifact has been signed, or would have been in dry-run mode.
+func (o *artifactSignAllTagsOptions) signTag(ctx context.Context, ref string, client *auth.Client,
+	signer crypto.Signer, tag, d string) (bool, error) {
+	o.Printer.Verbosef("Checking signatures of %q", ref)
+	valid, err := signature.HasValidSignature(ctx, ref, client, d, signer.Public())
+	switch {
+	case valid:
+		o.Printer.Info.Printfln("Skipping %q: already signed", tag)
+		return false, nil
+	case errors.Is(err, oci.ErrIncompleteReferrers):
+		o.Printer.Warning.Printfln("the signatures of %q may be incomplete: %s", tag, err.Error())
+	case err != nil:
+		return false, err
+	}
+
+	if o.dryRun {
+		o.Printer.Info.Printfln("%q would be signed", tag)
+		return true, nil
+	}
+
+	o.Printer.Info.Printfln("Signing %q (%s)", tag, d)
+	desc, err := signature.Attach(ctx, ref, client, d, signer)
+	if err != nil {
+		return false, err
+	}
+	o.Printer.Success.Printfln("Signed %q, signature pushed to %s (%s)", tag, signature.SignatureTag(d), desc.Digest)
+
+	return true, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+var longTagFromSemver = `Tag an artifact with a semver version and all the floating tags decomposed from it
+
+The artifact at the given reference, or tagged with the version if the reference has neither a tag
+nor a digest, is resolved once and tagged with the version, e.g. "1.2.3", and with the floating
+"MAJOR.MINOR" and "MAJOR" tags, e.g. "1.2" and "1", plus "latest" with --include-latest. All the
+tags point to the same digest. The floating tags of the lines already having a higher stable version
+are not moved backwards, and pre-releases are only tagged with their own version.
+
+Example - Tag the artifact pushed as "1.2.3" as "1.2" and "1" too:
+	falcoctl artifact tag-from-semver ghcr.io/falcosecurity/plugins/plugin/cloudtrail 1.2.3
+
+Example - Print the tags which would be applied to a digest, including "latest":
+	falcoctl artifact tag-from-semver ghcr.io/falcosecurity/plugins/plugin/cloudtrail@sha256:... 1.2.3 --include-latest --dry-run
+`
+
+type artifactTagFromSemverOptions struct {
+	*options.CommonOptions
+	includeLatest bool
+	dryRun        bool
+}
+
+// NewArtifactTagFromSemverCmd returns the artifact tag-from-semver command.
+func NewArtifactTagFromSemverCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactTagFromSemverOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "tag-from-semver hostname/repo[:tag|@digest] version [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Tag an artifact with a semver version and all the floating tags decomposed from it",
+		Long:                  longTagFromSemver,
+		Args:                  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactTagFromSemver(ctx, args))
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.includeLatest, "include-latest", false, `tag the artifact as "latest" too, unless a higher version exists`)
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", false, "print the tags which would be applied without applying them")
+
+	return cmd
+}
+
+// RunArtifactTagFromSemver executes the business logic for the artifact tag-from-semver command.
+func (o *artifactTagFromSemverOptions) RunArtifactTagFromSemver(ctx context.Context, args []string) error {
+	ref, version := args[0], args[1]
+
+	parsedRef, err := registry.ParseReference(ref)
+	if err != nil {
+		return err
+	}
+	if parsedRef.Reference == "" {
+		parsedRef.Reference = version
+	}
+	ref = parsedRef.String()
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	client, err := registryClient(ctx, credentialStore, ref)
+	if err != nil {
+		return err
+	}
+
+	parsedRef.Reference = ""
+	versions, err := oci.Versions(ctx, parsedRef.String(), client)
+	if err != nil {
+		return err
+	}
+
+	tags, err := oci.SemverTags(version, versions, o.includeLatest)
+	if err != nil {
+		return err
+	}
+
+	if o.dryRun {
+		desc, err := oci.Resolve(ctx, ref, client)
+		if err != nil {
+			return err
+		}
+		o.Printer.DefaultText.Printfln("digest: %s\ntags: %s", desc.Digest, strings.Join(tags, ", "))
+		o.Printer.Info.Printfln("Dry run, no tag applied")
+		return nil
+	}
+
+	digest, err := oci.ApplyTags(ctx, ref, tags, client)
+	if err != nil {
+		return err
+	}
+
+	o.Printer.DefaultText.Printfln("digest: %s\ntags: %s", digest, strings.Join(tags, ", "))
+	o.Printer.Success.Printfln("%d tag(s) applied to %q", len(tags), ref)
+
+	return nil
+}
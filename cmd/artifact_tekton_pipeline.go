@@ -0,0 +1,273 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+var longTektonPipeline = `Generate Tekton Pipelines manifests to build and push Falco artifacts
+
+The following manifests are generated in the output directory:
+	task-build.yaml     a Task building the artifact using a Makefile target
+	task-push.yaml      a Task pushing the artifact using the falcoctl container image
+	pipeline.yaml       a Pipeline combining the build and push tasks
+	pipelinerun.yaml    a PipelineRun template binding the source and registry credentials workspaces
+
+Registry credentials are read from a Secret of type "kubernetes.io/dockerconfigjson".
+
+Example - Generate the manifests in the "tekton" directory and apply them:
+	falcoctl artifact tekton-pipeline --output tekton/
+	kubectl apply -f tekton/
+
+Example - Generate the manifests using a custom prefix for the resources names:
+	falcoctl artifact tekton-pipeline --name myplugin --output tekton/
+`
+
+// tektonManifests maps each generated file to its template.
+var tektonManifests = map[string]*template.Template{
+	"task-build.yaml": template.Must(template.New("task-build.yaml").Parse(`apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: {{ .Name }}-build
+spec:
+  description: Build a Falco artifact using a Makefile target
+  params:
+    - name: make-target
+      type: string
+      default: {{ .MakeTarget }}
+    - name: builder-image
+      type: string
+      default: {{ .BuilderImage }}
+  workspaces:
+    - name: source
+      description: Source code of the artifact
+  steps:
+    - name: build
+      image: $(params.builder-image)
+      workingDir: $(workspaces.source.path)
+      command: ["make"]
+      args: ["$(params.make-target)"]
+`)),
+	"task-push.yaml": template.Must(template.New("task-push.yaml").Parse(`apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: {{ .Name }}-push
+spec:
+  description: Push a Falco artifact to an OCI registry using falcoctl
+  params:
+    - name: artifact-ref
+      type: string
+      description: Reference of the artifact to be pushed, e.g. ghcr.io/myorg/myplugin:0.1.0
+    - name: artifact-type
+      type: string
+      description: Type of the artifact, either "plugin" or "rulesfile"
+      default: plugin
+    - name: artifact-path
+      type: string
+      description: Path of the artifact tarball, relative to the source workspace
+    - name: platform
+      type: string
+      description: Platform of the artifact in OS/ARCH format (only for plugins)
+      default: linux/amd64
+    - name: falcoctl-image
+      type: string
+      default: {{ .Image }}
+  workspaces:
+    - name: source
+      description: Workspace containing the artifact tarball
+    - name: dockerconfig
+      description: Workspace containing the registry credentials in a "config.json" file
+  steps:
+    - name: push
+      image: $(params.falcoctl-image)
+      workingDir: $(workspaces.source.path)
+      env:
+        - name: DOCKER_CONFIG
+          value: $(workspaces.dockerconfig.path)
+      command: ["falcoctl"]
+      args:
+        - registry
+        - push
+        - --type
+        - $(params.artifact-type)
+        - --platform
+        - $(params.platform)
+        - $(params.artifact-ref)
+        - $(params.artifact-path)
+`)),
+	"pipeline.yaml": template.Must(template.New("pipeline.yaml").Parse(`apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: {{ .Name }}
+spec:
+  description: Build and push a Falco artifact
+  params:
+    - name: artifact-ref
+      type: string
+    - name: artifact-type
+      type: string
+      default: plugin
+    - name: artifact-path
+      type: string
+    - name: platform
+      type: string
+      default: linux/amd64
+    - name: make-target
+      type: string
+      default: {{ .MakeTarget }}
+  workspaces:
+    - name: source
+    - name: dockerconfig
+  tasks:
+    - name: build
+      taskRef:
+        name: {{ .Name }}-build
+      params:
+        - name: make-target
+          value: $(params.make-target)
+      workspaces:
+        - name: source
+          workspace: source
+    - name: push
+      runAfter: ["build"]
+      taskRef:
+        name: {{ .Name }}-push
+      params:
+        - name: artifact-ref
+          value: $(params.artifact-ref)
+        - name: artifact-type
+          value: $(params.artifact-type)
+        - name: artifact-path
+          value: $(params.artifact-path)
+        - name: platform
+          value: $(params.platform)
+      workspaces:
+        - name: source
+          workspace: source
+        - name: dockerconfig
+          workspace: dockerconfig
+`)),
+	"pipelinerun.yaml": template.Must(template.New("pipelinerun.yaml").Parse(`apiVersion: tekton.dev/v1beta1
+kind: PipelineRun
+metadata:
+  name: {{ .Name }}-run
+spec:
+  pipelineRef:
+    name: {{ .Name }}
+  params:
+    - name: artifact-ref
+      value: ghcr.io/myorg/myplugin:0.1.0
+    - name: artifact-type
+      value: plugin
+    - name: artifact-path
+      value: myplugin.tar.gz
+    - name: platform
+      value: linux/amd64
+  workspaces:
+    - name: source
+      volumeClaimTemplate:
+        spec:
+          accessModes: ["ReadWriteOnce"]
+          resources:
+            requests:
+              storage: 1Gi
+    - name: dockerconfig
+      secret:
+        secretName: {{ .Secret }}
+        items:
+          - key: .dockerconfigjson
+            path: config.json
+`)),
+}
+
+type artifactTektonPipelineOptions struct {
+	*options.CommonOptions
+	Name         string
+	Image        string
+	BuilderImage string
+	MakeTarget   string
+	Secret       string
+	output       string
+}
+
+// NewArtifactTektonPipelineCmd returns the artifact tekton-pipeline command.
+func NewArtifactTektonPipelineCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactTektonPipelineOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "tekton-pipeline [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Generate Tekton Pipelines manifests to push Falco artifacts",
+		Long:                  longTektonPipeline,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunArtifactTektonPipeline(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.output, "output", "tekton", "directory where to write the manifests")
+	cmd.Flags().StringVar(&o.Name, "name", "falcoctl-artifact", "prefix of the names of the generated resources")
+	cmd.Flags().StringVar(&o.Image, "image", "docker.io/falcosecurity/falcoctl:latest", "falcoctl container image used to push artifacts")
+	cmd.Flags().StringVar(&o.BuilderImage, "builder-image", "docker.io/library/golang:1.19", "container image used to build artifacts")
+	cmd.Flags().StringVar(&o.MakeTarget, "make-target", "build", "default Makefile target used to build the artifact")
+	cmd.Flags().StringVar(&o.Secret, "secret", "registry-credentials",
+		`name of the Secret of type "kubernetes.io/dockerconfigjson" holding the registry credentials`)
+
+	return cmd
+}
+
+// RunArtifactTektonPipeline executes the business logic for the artifact tekton-pipeline command.
+func (o *artifactTektonPipelineOptions) RunArtifactTektonPipeline(ctx context.Context, args []string) error {
+	if err := os.MkdirAll(o.output, 0o750); err != nil {
+		return fmt.Errorf("cannot create directory %q: %w", o.output, err)
+	}
+
+	for name, tmpl := range tektonManifests {
+		if err := o.writeManifest(filepath.Join(o.output, name), tmpl); err != nil {
+			return err
+		}
+	}
+
+	o.Printer.Success.Printfln("Tekton manifests written to %q, apply them using \"kubectl apply -f %s\"", o.output, o.output)
+
+	return nil
+}
+
+func (o *artifactTektonPipelineOptions) writeManifest(path string, tmpl *template.Template) error {
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err = tmpl.Execute(f, o); err != nil {
+		return fmt.Errorf("cannot generate %q: %w", path, err)
+	}
+
+	o.Printer.Verbosef("Manifest %q written", path)
+
+	return nil
+}
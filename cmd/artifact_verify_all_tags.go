@@ -0,0 +1,187 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+	"github.com/falcosecurity/falcoctl/pkg/signature"
+)
+
+var longVerifyAllTags = `Verify the signatures of every tagged version of an artifact
+
+Each tag of the repository is resolved to its digest, which is checked for a cosign signature
+valid for the public key passed with --key, either in the "sha256-<digest>.sig" tag or among its
+referrers. The tags of the manifests attached by cosign, e.g. "sha256-<digest>.sig", are skipped.
+
+A table reports, for each tag, whether it is signed. The command exits with code 1 if any tag
+lacks a valid signature. With "--format csv" the report is printed as CSV on stdout, while the
+logs are printed on stderr.
+
+Example - Audit the signatures of all the tags of "myplugin":
+	falcoctl artifact verify-all-tags localhost:5000/myplugin --key cosign.pub
+
+Example - Export the signature coverage of "myplugin" to a CSV file:
+	falcoctl artifact verify-all-tags localhost:5000/myplugin --key cosign.pub --format csv > coverage.csv
+`
+
+// Formats of the report of the artifact verify-all-tags command.
+const (
+	reportTable = "table"
+	reportCSV   = "csv"
+)
+
+type artifactVerifyAllTagsOptions struct {
+	*options.CommonOptions
+	key    string
+	format string
+}
+
+func (o *artifactVerifyAllTagsOptions) validate() error {
+	if o.key == "" {
+		return fmt.Errorf("--key must be set")
+	}
+	if o.format != reportTable && o.format != reportCSV {
+		return fmt.Errorf("--format %q not supported, allowed values: %q, %q", o.format, reportTable, reportCSV)
+	}
+	return nil
+}
+
+// NewArtifactVerifyAllTagsCmd returns the artifact verify-all-tags command.
+func NewArtifactVerifyAllTagsCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := artifactVerifyAllTagsOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "verify-all-tags hostname/repo --key cosign.pub [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Verify the signatures of every tagged version of an artifact",
+		Long:                  longVerifyAllTags,
+		Args:                  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if o.format == reportCSV {
+				// Keep stdout clean, it holds the report.
+				o.Printer.RedirectLogs(os.Stderr)
+			}
+			o.Printer.CheckErr(o.RunArtifactVerifyAllTags(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.key, "key", "", "PEM public key used to verify the signatures")
+	cmd.Flags().StringVar(&o.format, "format", reportTable, `format of the report. Allowed values: "table", "csv"`)
+
+	return cmd
+}
+
+// RunArtifactVerifyAllTags executes the business logic for the artifact verify-all-tags command.
+func (o *artifactVerifyAllTagsOptions) RunArtifactVerifyAllTags(ctx context.Context, args []string) error {
+	publicKey, err := signature.LoadPublicKey(o.key)
+	if err != nil {
+		return err
+	}
+
+	ref, err := normalizeReference(o.Printer, args[0])
+	if err != nil {
+		return err
+	}
+
+	parsedRef, err := registry.ParseReference(ref)
+	if err != nil {
+		return err
+	}
+	if parsedRef.Reference != "" {
+		return fmt.Errorf("a repository reference without tag or digest is required, got %q", args[0])
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	client, err := registryClient(ctx, credentialStore, ref)
+	if err != nil {
+		return err
+	}
+
+	tags, err := oci.ListTags(ctx, ref, client)
+	if err != nil {
+		return fmt.Errorf("unable to list the tags of %q: %w", ref, err)
+	}
+
+	var data [][]string
+	var unsigned int
+	for _, tag := range tags {
+		if oci.IsAttachedTag(tag) {
+			continue
+		}
+
+		o.Printer.Verbosef("Verifying the signatures of %q", tag)
+		var d, details string
+		var valid bool
+		desc, err := oci.Resolve(ctx, fmt.Sprintf("%s:%s", ref, tag), client)
+		if err == nil {
+			d = desc.Digest.String()
+			valid, err = signature.HasValidSignature(ctx, fmt.Sprintf("%s@%s", ref, d), client, d, publicKey)
+		}
+
+		switch {
+		case valid:
+			details = "valid signature"
+		case errors.Is(err, oci.ErrIncompleteReferrers):
+			details = "no valid signature found, the referrers may be incomplete"
+		case err != nil:
+			details = err.Error()
+		default:
+			details = "no valid signature"
+		}
+
+		if !valid {
+			unsigned++
+		}
+		data = append(data, []string{tag, d, strconv.FormatBool(valid), details})
+	}
+
+	if o.format == reportCSV {
+		err = o.Printer.PrintCSV(output.TagSignatures, data)
+	} else {
+		err = o.Printer.PrintTable(output.TagSignatures, data)
+	}
+	if err != nil {
+		return err
+	}
+
+	if unsigned > 0 {
+		return fmt.Errorf("%d of %d tag(s) of %q lack a valid signature", unsigned, len(data), ref)
+	}
+
+	o.Printer.Success.Printfln("All the %d tag(s) of %q have a valid signature", len(data), ref)
+
+	return nil
+}
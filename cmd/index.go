@@ -28,6 +28,7 @@ var (
 	configDir    = filepath.Join(homedir.Get(), ".config")
 	falcoctlPath = filepath.Join(configDir, "falcoctl")
 	indexesFile  = filepath.Join(falcoctlPath, "indexes.yaml")
+	configFile   = filepath.Join(falcoctlPath, "falcoctl.yaml")
 	timeFormat   = "2006-01-02 15:04:05"
 )
 
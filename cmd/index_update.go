@@ -16,19 +16,25 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
+	"os"
+	"path"
 	"path/filepath"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/falcosecurity/falcoctl/pkg/compatibility"
 	"github.com/falcosecurity/falcoctl/pkg/index"
 	"github.com/falcosecurity/falcoctl/pkg/options"
 )
 
 type indexUpdateOptions struct {
 	*options.CommonOptions
-	indexConfig *index.Config
+	indexConfig   *index.Config
+	compatibility bool
 }
 
 func (o *indexUpdateOptions) Validate(args []string) error {
@@ -68,12 +74,25 @@ func NewIndexUpdateCmd(ctx context.Context, opt *options.CommonOptions) *cobra.C
 		},
 	}
 
+	cmd.Flags().BoolVar(&o.compatibility, "compatibility", false,
+		"also refresh the compatibility matrix with the compatibility.yaml file published next to each index")
+
 	return cmd
 }
 
 func (o *indexUpdateOptions) RunIndexUpdate(ctx context.Context, args []string) error {
 	ts := time.Now().Format(timeFormat)
 
+	// The compatibility matrices published next to the indexes are merged in the local one.
+	var matrix *compatibility.Matrix
+	if o.compatibility {
+		var err error
+		if matrix, err = compatibility.Load(compatibilityFile); err != nil {
+			return err
+		}
+	}
+	matrixUpdated := false
+
 	for _, name := range args {
 		nameYaml := fmt.Sprintf("%s%s", name, ".yaml")
 		indexFile := filepath.Join(falcoctlPath, nameYaml)
@@ -94,6 +113,23 @@ func (o *indexUpdateOptions) RunIndexUpdate(ctx context.Context, args []string)
 		}
 
 		indexConfigEntry.UpdatedTimestamp = ts
+
+		if o.compatibility {
+			indexMatrix, err := o.fetchCompatibility(ctx, indexConfigEntry.URL)
+			if err != nil {
+				o.Printer.Warning.Printfln("Unable to update the compatibility matrix from index %q: %s", name, err.Error())
+			} else if indexMatrix != nil {
+				matrix.Merge(indexMatrix)
+				matrixUpdated = true
+			}
+		}
+	}
+
+	if matrixUpdated {
+		if err := matrix.Write(compatibilityFile); err != nil {
+			return fmt.Errorf("cannot write compatibility matrix %q: %w", compatibilityFile, err)
+		}
+		o.Printer.Verbosef("Compatibility matrix %q updated", compatibilityFile)
 	}
 
 	if err := o.indexConfig.Write(indexesFile); err != nil {
@@ -102,3 +138,26 @@ func (o *indexUpdateOptions) RunIndexUpdate(ctx context.Context, args []string)
 
 	return nil
 }
+
+// fetchCompatibility fetches the "compatibility.yaml" file published next to the index. It returns
+// nil if the index does not publish any.
+func (o *indexUpdateOptions) fetchCompatibility(ctx context.Context, indexURL string) (*compatibility.Matrix, error) {
+	u, err := url.Parse(indexURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(path.Dir(u.Path), "compatibility.yaml")
+
+	matrix, err := compatibility.Fetch(ctx, u.String())
+	if errors.Is(err, os.ErrNotExist) {
+		o.Printer.Verbosef("No compatibility matrix found at %q, skipping", u.String())
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	o.Printer.Verbosef("Compatibility matrix fetched from %q", u.String())
+
+	return matrix, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// Download downloads the file at url to dst and checks that its content matches checksum,
+// in the "sha256:<hex>" format. It returns the number of downloaded bytes.
+func Download(ctx context.Context, url, checksum, dst string) (int64, error) {
+	expected, err := digest.Parse(checksum)
+	if err != nil {
+		return 0, fmt.Errorf("invalid checksum %q: %w", checksum, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("cannot download %q: unexpected status %q", url, resp.Status)
+	}
+
+	f, err := os.Create(filepath.Clean(dst))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	verifier := expected.Verifier()
+	size, err := io.Copy(io.MultiWriter(f, verifier), resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("cannot download %q: %w", url, err)
+	}
+
+	if !verifier.Verified() {
+		return 0, fmt.Errorf("checksum of %q does not match %q", url, checksum)
+	}
+
+	return size, f.Close()
+}
+
+// InstallFile moves the content of a downloaded file to destDir. Archives (*.tar.gz, *.tgz) are extracted,
+// any other file is copied as is. It returns the paths of the installed files.
+func InstallFile(src, destDir string) ([]string, error) {
+	f, err := os.Open(filepath.Clean(src))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.HasSuffix(src, ".tar.gz") || strings.HasSuffix(src, ".tgz") {
+		return ExtractTarGz(f, destDir)
+	}
+
+	path := filepath.Join(destDir, filepath.Base(src))
+	outFile, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	if err = copyInChunks(outFile, f); err != nil {
+		outFile.Close()
+		return nil, err
+	}
+	if err = outFile.Close(); err != nil {
+		return nil, err
+	}
+
+	return []string{path}, nil
+}
@@ -22,13 +22,20 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+// ErrUnsafePath error when an archive entry, or the target of a symlink, would be extracted outside of the destination directory.
+var ErrUnsafePath = errors.New("path outside of the destination directory")
+
 // ExtractTarGz extracts a *.tar.gz compressed archive and moves its content to destDir.
-func ExtractTarGz(gzipStream io.Reader, destDir string) error {
+// It returns the paths of the extracted files. Entries escaping destDir are rejected, as well as symlinks
+// whose target is absolute or escapes destDir. Existing symlinks are replaced rather than written through.
+func ExtractTarGz(gzipStream io.Reader, destDir string) ([]string, error) {
+	var files []string
 	uncompressedStream, err := gzip.NewReader(gzipStream)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	tarReader := tar.NewReader(uncompressedStream)
@@ -41,29 +48,102 @@ func ExtractTarGz(gzipStream io.Reader, destDir string) error {
 		}
 
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		switch header.Typeflag {
 		case tar.TypeDir:
-			return fmt.Errorf("unexepected dir inside the archive, expected to find only files without any tree structure")
+			return nil, fmt.Errorf("unexepected dir inside the archive, expected to find only files without any tree structure")
 		case tar.TypeReg:
-			outFile, err := os.Create(filepath.Clean(filepath.Join(destDir, filepath.Clean(header.Name))))
+			path, err := extractPath(destDir, header.Name)
+			if err != nil {
+				return nil, err
+			}
+			if err = removeSymlink(path); err != nil {
+				return nil, err
+			}
+			outFile, err := os.Create(path)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			if err = copyInChunks(outFile, tarReader); err != nil {
-				return err
+				return nil, err
 			}
 			if err = outFile.Close(); err != nil {
-				return err
+				return nil, err
+			}
+			files = append(files, path)
+		case tar.TypeSymlink:
+			path, err := extractPath(destDir, header.Name)
+			if err != nil {
+				return nil, err
 			}
+			if filepath.IsAbs(header.Linkname) {
+				return nil, fmt.Errorf("symlink %q to absolute path %q: %w", header.Name, header.Linkname, ErrUnsafePath)
+			}
+			if _, err = extractPath(destDir, filepath.Join(filepath.Dir(header.Name), header.Linkname)); err != nil {
+				return nil, fmt.Errorf("symlink %q to %q: %w", header.Name, header.Linkname, ErrUnsafePath)
+			}
+			if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+			if err = os.Symlink(header.Linkname, path); err != nil {
+				return nil, err
+			}
+			files = append(files, path)
 
 		default:
-			return fmt.Errorf("extractTarGz: uknown type: %b in %s", header.Typeflag, header.Name)
+			return nil, fmt.Errorf("extractTarGz: uknown type: %b in %s", header.Typeflag, header.Name)
 		}
 	}
 
+	return files, nil
+}
+
+// extractPath returns the path where the archive entry name is extracted in destDir,
+// or an error wrapping ErrUnsafePath if it is outside of destDir.
+func extractPath(destDir, name string) (string, error) {
+	path := filepath.Join(destDir, filepath.Clean(name))
+	rel, err := filepath.Rel(destDir, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%q: %w", name, ErrUnsafePath)
+	}
+
+	return path, nil
+}
+
+// JoinDestDir joins dir, a directory relative to destDir read e.g. from a lockfile, to destDir. It returns an
+// error wrapping ErrUnsafePath if dir is absolute or points outside of destDir.
+func JoinDestDir(destDir, dir string) (string, error) {
+	if filepath.IsAbs(dir) || filepath.VolumeName(dir) != "" {
+		return "", fmt.Errorf("absolute directory %q: %w", dir, ErrUnsafePath)
+	}
+	if destDir == "" {
+		destDir = "."
+	}
+
+	path := filepath.Join(destDir, dir)
+	rel, err := filepath.Rel(destDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("directory %q: %w", dir, ErrUnsafePath)
+	}
+
+	return path, nil
+}
+
+// removeSymlink removes path if it is a symlink, so that it is not followed when the file is created.
+func removeSymlink(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return os.Remove(path)
+	}
+
 	return nil
 }
 
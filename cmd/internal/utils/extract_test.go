@@ -0,0 +1,58 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestJoinDestDir(t *testing.T) {
+	destDir := t.TempDir()
+
+	testCases := []struct {
+		destDir  string
+		dir      string
+		expected string
+	}{
+		{destDir: destDir, dir: "", expected: destDir},
+		{destDir: destDir, dir: ".", expected: destDir},
+		{destDir: destDir, dir: "linux-amd64", expected: filepath.Join(destDir, "linux-amd64")},
+		{destDir: destDir, dir: "a/../linux-amd64", expected: filepath.Join(destDir, "linux-amd64")},
+		{destDir: destDir, dir: "..plugins", expected: filepath.Join(destDir, "..plugins")},
+		{destDir: "", dir: "linux-amd64", expected: "linux-amd64"},
+		{destDir: "", dir: "", expected: "."},
+	}
+
+	for _, tc := range testCases {
+		got, err := JoinDestDir(tc.destDir, tc.dir)
+		if err != nil {
+			t.Errorf("unexpected error joining %q to %q: %v", tc.dir, tc.destDir, err)
+			continue
+		}
+		if got != tc.expected {
+			t.Errorf("expected %q joining %q to %q, got %q", tc.expected, tc.dir, tc.destDir, got)
+		}
+	}
+
+	for _, dir := range []string{"..", "../etc", "linux-amd64/../../etc", "/etc", filepath.Join(destDir, "linux-amd64")} {
+		for _, base := range []string{destDir, ""} {
+			if got, err := JoinDestDir(base, dir); !errors.Is(err, ErrUnsafePath) {
+				t.Errorf("expected an unsafe path error joining %q to %q, got %q (%v)", dir, base, got, err)
+			}
+		}
+	}
+}
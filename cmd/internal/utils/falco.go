@@ -0,0 +1,46 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+var falcoVersionRegexp = regexp.MustCompile(`(?m)^Falco version:\s*(\S+)`)
+
+// FalcoVersion returns the version of the Falco binary found in PATH, as reported by "falco --version".
+func FalcoVersion(ctx context.Context) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "falco", "--version")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("falco --version: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	matches := falcoVersionRegexp.FindStringSubmatch(stdout.String())
+	if matches == nil {
+		return "", fmt.Errorf("unable to parse falco version from %q", strings.TrimSpace(stdout.String()))
+	}
+
+	return matches[1], nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// ociTagRgx is the grammar of a tag as defined by the OCI distribution spec.
+var ociTagRgx = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127}$`)
+
+// GitTags derives the tags for an artifact from the git repository in the current directory.
+// If HEAD points to a git tag and the working tree is clean, the git tag is returned
+// without its "v" prefix (e.g. "v0.1.0" -> "0.1.0"). Otherwise, "sha-<short commit SHA>"
+// is returned. It fails if the git tag is not a valid OCI tag, e.g. "plugins/foo/v0.1.0".
+func GitTags(ctx context.Context) ([]string, error) {
+	sha, err := git(ctx, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := git(ctx, "status", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+
+	// An error here just means that HEAD does not point to any tag.
+	if tag, err := git(ctx, "describe", "--tags", "--exact-match", "HEAD"); err == nil && status == "" {
+		t, err := ociTagFromGitTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		return []string{t}, nil
+	}
+
+	return []string{"sha-" + sha}, nil
+}
+
+// ociTagFromGitTag trims the "v" prefix from a git tag and checks that the result is a valid OCI tag.
+func ociTagFromGitTag(tag string) (string, error) {
+	t := strings.TrimPrefix(tag, "v")
+	if !ociTagRgx.MatchString(t) {
+		return "", fmt.Errorf("git tag %q is not a valid OCI tag, it must match %s", tag, ociTagRgx.String())
+	}
+	return t, nil
+}
+
+// GitCommitMessages returns the full messages of the commits reachable from HEAD but not from the
+// given git revision, e.g. a tag, of the git repository in the current directory.
+func GitCommitMessages(ctx context.Context, since string) ([]string, error) {
+	if strings.HasPrefix(since, "-") {
+		return nil, fmt.Errorf("invalid git revision %q", since)
+	}
+
+	out, err := git(ctx, "log", "--format=%B%x00", since+"..HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []string
+	for _, m := range strings.Split(out, "\x00") {
+		if m = strings.TrimSpace(m); m != "" {
+			messages = append(messages, m)
+		}
+	}
+
+	return messages, nil
+}
+
+func git(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
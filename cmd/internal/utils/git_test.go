@@ -0,0 +1,58 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOCITagFromGitTag(t *testing.T) {
+	testCases := []struct {
+		tag      string
+		expected string
+		wantErr  bool
+	}{
+		{tag: "v0.1.0", expected: "0.1.0"},
+		{tag: "0.1.0", expected: "0.1.0"},
+		{tag: "v1.2.3-rc.1", expected: "1.2.3-rc.1"},
+		{tag: "vv1", expected: "v1"},
+		{tag: "release_2023", expected: "release_2023"},
+		{tag: "plugins/foo/v0.1.0", wantErr: true},
+		{tag: "v1.0.0+build.1", wantErr: true},
+		{tag: "v.1", wantErr: true},
+		{tag: "-rc", wantErr: true},
+		{tag: "v", wantErr: true},
+		{tag: "v" + strings.Repeat("a", 128), expected: strings.Repeat("a", 128)},
+		{tag: "v" + strings.Repeat("a", 129), wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		got, err := ociTagFromGitTag(tc.tag)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("expected an error for git tag %q, got tag %q", tc.tag, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for git tag %q: %v", tc.tag, err)
+			continue
+		}
+		if got != tc.expected {
+			t.Errorf("expected tag %q for git tag %q, got %q", tc.expected, tc.tag, got)
+		}
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"path"
 	"reflect"
 	"strings"
 
@@ -31,14 +32,31 @@ import (
 	"github.com/falcosecurity/falcoctl/pkg/output"
 )
 
-// GetRegistryFromRef extracts the registry from a ref string.
+// checkRegistry restricts the registries contacted in this invocation, see SetRegistryPolicy.
+var checkRegistry func(host string) error
+
+// SetRegistryPolicy sets the check of the registry policy performed by GetRegistryFromRef, so that
+// disallowed registries are refused before any request is sent to them.
+func SetRegistryPolicy(check func(host string) error) {
+	checkRegistry = check
+}
+
+// GetRegistryFromRef extracts the registry from a ref string. It fails if the registry is not
+// allowed by the registry policy.
 func GetRegistryFromRef(ref string) (string, error) {
 	index := strings.Index(ref, "/")
 	if index <= 0 {
 		return "", fmt.Errorf("cannot extract registry name from ref %q", ref)
 	}
 
-	return ref[0:index], nil
+	reg := ref[0:index]
+	if checkRegistry != nil {
+		if err := checkRegistry(reg); err != nil {
+			return "", err
+		}
+	}
+
+	return reg, nil
 }
 
 // ParseReference is a helper function that parse with the followig logic:
@@ -104,6 +122,42 @@ func ParseReference(mergedIndexes *index.MergedIndexes, name string) (string, er
 	return ref, nil
 }
 
+// RepositoryFromName returns the reference to the repository of an artifact, without tag or digest.
+// The name can be either the name of an artifact in the merged index or a reference.
+func RepositoryFromName(mergedIndexes *index.MergedIndexes, name string) (string, error) {
+	if parsedRef, err := registry.ParseReference(name); err == nil {
+		parsedRef.Reference = ""
+		return parsedRef.String(), nil
+	}
+
+	entry, ok := mergedIndexes.EntryByName(ArtifactName(name))
+	if !ok {
+		return "", fmt.Errorf("cannot find %s among the configured indexes", name)
+	}
+
+	return fmt.Sprintf("%s/%s", entry.Registry, entry.Repository), nil
+}
+
+// ArtifactName returns the name used to track an artifact given the name or the reference
+// used to install it.
+//
+//  1. if name is the name of an artifact, the tag or digest, if any, is stripped.
+//     e.g "cloudtrail:0.5.1" -> "cloudtrail"
+//
+//  2. if name is a reference, the last element of the repository is used.
+//     e.g. "ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.5.1" -> "cloudtrail"
+func ArtifactName(name string) string {
+	if parsedRef, err := registry.ParseReference(name); err == nil {
+		return path.Base(parsedRef.Repository)
+	}
+
+	if i := strings.IndexAny(name, ":@"); i > 0 {
+		return name[:i]
+	}
+
+	return name
+}
+
 // CheckRegistryConnection checks whether the registry implement Docker Registry API V2 or
 // OCI Distribution Specification. It also checks authentication if credentials are not empty.
 func CheckRegistryConnection(ctx context.Context, cred *auth.Credential, regName string, printer *output.Printer) error {
@@ -0,0 +1,62 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+// VersionTags derives the tags for an artifact from its semver version, e.g. "1.2.3" -> "1.2.3", "1.2", "1", "latest".
+// Only the kinds of tags in kinds are returned. Pre-release versions and versions with build metadata only get
+// the full version tag, to not move the tags pointing to stable releases. Since "+" is not allowed in tags, it is
+// replaced by "_" in the full version tag, e.g. "1.2.3+build.1" -> "1.2.3_build.1".
+func VersionTags(version string, kinds []string) ([]string, error) {
+	v, err := semver.Parse(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %q: %w", version, err)
+	}
+
+	floating := len(v.Pre) == 0 && len(v.Build) == 0
+
+	var tags []string
+	for _, kind := range kinds {
+		var tag string
+		switch kind {
+		case options.VersionTagFull:
+			tag = strings.ReplaceAll(v.String(), "+", "_")
+		case options.VersionTagMinor:
+			tag = fmt.Sprintf("%d.%d", v.Major, v.Minor)
+		case options.VersionTagMajor:
+			tag = fmt.Sprintf("%d", v.Major)
+		case options.VersionTagLatest:
+			tag = oci.DefaultTag
+		default:
+			return nil, fmt.Errorf("unknown version tag %q", kind)
+		}
+
+		if kind != options.VersionTagFull && !floating {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+func TestVersionTags(t *testing.T) {
+	all := []string{options.VersionTagFull, options.VersionTagMinor, options.VersionTagMajor, options.VersionTagLatest}
+
+	testCases := []struct {
+		descr    string
+		version  string
+		kinds    []string
+		expected []string
+		wantErr  bool
+	}{
+		{descr: "release", version: "1.2.3", kinds: all, expected: []string{"1.2.3", "1.2", "1", "latest"}},
+		{descr: "zero major", version: "0.1.0", kinds: all, expected: []string{"0.1.0", "0.1", "0", "latest"}},
+		{descr: "subset of kinds", version: "1.2.3", kinds: []string{options.VersionTagFull, options.VersionTagMinor},
+			expected: []string{"1.2.3", "1.2"}},
+		{descr: "order of kinds", version: "1.2.3", kinds: []string{options.VersionTagLatest, options.VersionTagFull},
+			expected: []string{"latest", "1.2.3"}},
+		{descr: "no kinds", version: "1.2.3", kinds: nil, expected: nil},
+		{descr: "prerelease", version: "1.2.3-rc.1", kinds: all, expected: []string{"1.2.3-rc.1"}},
+		{descr: "prerelease without full", version: "1.2.3-rc.1", kinds: []string{options.VersionTagLatest}, expected: nil},
+		{descr: "build metadata", version: "1.2.3+build.1", kinds: all, expected: []string{"1.2.3_build.1"}},
+		{descr: "prerelease and build metadata", version: "1.2.3-alpha+sha.5114f85", kinds: all,
+			expected: []string{"1.2.3-alpha_sha.5114f85"}},
+		{descr: "v prefix", version: "v1.2.3", kinds: all, wantErr: true},
+		{descr: "not semver", version: "1.2", kinds: all, wantErr: true},
+		{descr: "unknown kind", version: "1.2.3", kinds: []string{"patch"}, wantErr: true},
+		{descr: "unknown kind for prerelease", version: "1.2.3-rc.1", kinds: []string{"patch"}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.descr, func(t *testing.T) {
+			tags, err := VersionTags(tc.version, tc.kinds)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got tags %v", tags)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tags, tc.expected) {
+				t.Errorf("expected tags %v, got %v", tc.expected, tags)
+			}
+			for _, tag := range tags {
+				if !ociTagRgx.MatchString(tag) {
+					t.Errorf("tag %q is not a valid OCI tag", tag)
+				}
+			}
+		})
+	}
+}
@@ -19,7 +19,9 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/falcosecurity/falcoctl/pkg/config"
 	commonoptions "github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
 )
 
 // NewRegistryCmd returns the registry command.
@@ -34,7 +36,49 @@ func NewRegistryCmd(ctx context.Context, opt *commonoptions.CommonOptions) *cobr
 	cmd.AddCommand(NewLoginCmd(ctx, opt))
 	cmd.AddCommand(NewLogoutCmd(opt))
 	cmd.AddCommand(NewPushCmd(ctx, opt))
+	cmd.AddCommand(NewDigestCmd(ctx, opt))
+	cmd.AddCommand(NewRegistryIndexCmd(ctx, opt))
 	cmd.AddCommand(NewPullCmd(ctx, opt))
+	cmd.AddCommand(NewResolveDepsCmd(ctx, opt))
+	cmd.AddCommand(NewCopyCmd(ctx, opt))
+	cmd.AddCommand(NewSetVisibilityCmd(ctx, opt))
+	cmd.AddCommand(NewScanCmd(ctx, opt))
+	cmd.AddCommand(NewWatchCmd(ctx, opt))
+	cmd.AddCommand(NewUsageCmd(ctx, opt))
+	cmd.AddCommand(NewRegistryAuthCmd(ctx, opt))
+	cmd.AddCommand(NewRegistryConfigCmd(opt))
 
 	return cmd
 }
+
+// rewriteReference applies the configured registry rewrites to a reference to be pulled.
+// Rewrites are never applied when pushing, to not publish artifacts to unexpected registries.
+func rewriteReference(printer *output.Printer, ref string) (string, error) {
+	cfg, err := config.NewConfig(configFile)
+	if err != nil {
+		return "", err
+	}
+
+	rewritten, ok := cfg.RewriteReference(ref)
+	if ok {
+		printer.Verbosef("Reference %q rewritten to %q", ref, rewritten)
+	}
+
+	return rewritten, nil
+}
+
+// normalizeReference expands short names, e.g. "falcosecurity/rules", to complete references
+// using the default registry, Docker Hub unless configured otherwise.
+func normalizeReference(printer *output.Printer, ref string) (string, error) {
+	cfg, err := config.NewConfig(configFile)
+	if err != nil {
+		return "", err
+	}
+
+	normalized, ok := cfg.NormalizeReference(ref)
+	if ok {
+		printer.Verbosef("Reference %q expanded to %q", ref, normalized)
+	}
+
+	return normalized, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+var longAuthTest = `Validate the credentials stored for a remote registry
+
+The stored credentials are used to perform an authenticated request to the registry.
+If a repository is given, a token for the pull and push actions on the repository is
+requested and the actions actually granted by the registry are reported.
+
+Example - Check that the credentials stored for "ghcr.io" authenticate:
+	falcoctl registry auth test ghcr.io
+
+Example - Check the access granted by the credentials stored for "ghcr.io" on a repository:
+	falcoctl registry auth test ghcr.io --repo falcosecurity/plugins/plugin/cloudtrail
+`
+
+type authTestOptions struct {
+	*options.CommonOptions
+	repo string
+}
+
+// NewRegistryAuthCmd returns the registry auth command.
+func NewRegistryAuthCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "auth",
+		DisableFlagsInUseLine: true,
+		Short:                 "Manage the credentials for remote registries",
+		Long:                  "Manage the credentials for remote registries",
+	}
+
+	cmd.AddCommand(NewAuthTestCmd(ctx, opt))
+
+	return cmd
+}
+
+// NewAuthTestCmd returns the registry auth test command.
+func NewAuthTestCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := authTestOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "test hostname [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Validate the credentials stored for a remote registry",
+		Long:                  longAuthTest,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunAuthTest(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.repo, "repo", "", "repository used to check the access granted by the credentials")
+
+	return cmd
+}
+
+// RunAuthTest executes the business logic for the registry auth test command.
+func (o *authTestOptions) RunAuthTest(ctx context.Context, args []string) error {
+	reg := args[0]
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	o.Printer.Verbosef("Retrieving credentials from local store")
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(cred, auth.EmptyCredential) {
+		o.Printer.Warning.Printfln("No credentials stored for %q, testing anonymous access", reg)
+	} else {
+		o.Printer.Info.Printfln("Found credentials for user %q", cred.Username)
+	}
+
+	client := authn.NewClient(cred)
+
+	registry, err := remote.NewRegistry(reg)
+	if err != nil {
+		return err
+	}
+	registry.Client = client
+
+	if err = registry.Ping(ctx); err != nil {
+		return fmt.Errorf("unable to authenticate to %q: %w", reg, err)
+	}
+	o.Printer.Success.Printfln("Successfully authenticated to %q", reg)
+
+	if o.repo == "" {
+		return nil
+	}
+
+	actions, err := authn.Access(ctx, client.Client, reg, o.repo, cred, false)
+	switch {
+	case errors.Is(err, authn.ErrNoTokenAuth):
+		o.Printer.Warning.Printfln("Unable to determine the access granted on %q: %s", o.repo, err.Error())
+		return nil
+	case err != nil:
+		return fmt.Errorf("unable to request a token for %q: %w", o.repo, err)
+	case len(actions) == 0:
+		o.Printer.Warning.Printfln("No access granted on %q", o.repo)
+	default:
+		o.Printer.Success.Printfln("Access granted on %q: %s", o.repo, strings.Join(actions, ", "))
+	}
+
+	return nil
+}
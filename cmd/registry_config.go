@@ -0,0 +1,158 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/config"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+// configKeysHelp lists the keys managed by the registry config commands.
+func configKeysHelp() string {
+	var b strings.Builder
+	for _, key := range config.Keys() {
+		fmt.Fprintf(&b, "  - %s: %s\n", key.Name, key.Description)
+	}
+	return b.String()
+}
+
+var longConfig = `Get and set the persistent defaults stored in the falcoctl config file
+
+The config file is "~/.config/falcoctl/falcoctl.yaml". Values are validated before being stored and the
+file is written atomically, preserving the other keys and the comments. Environment variables,
+e.g. ` + config.DefaultRegistryEnv + `, are not taken into account. Supported keys:
+` + configKeysHelp()
+
+var longConfigGet = `Print the value of a key of the falcoctl config file
+
+The command exits with code 1 if the key is not set.
+
+Example - Print the default registry:
+	falcoctl registry config get default_registry
+`
+
+var longConfigSet = `Set the value of a key of the falcoctl config file
+
+Example - Expand short names with ghcr.io:
+	falcoctl registry config set default_registry ghcr.io
+
+Example - Pull from ghcr.io through a proxy:
+	falcoctl registry config set registry_rewrites ghcr.io=internal-proxy.corp/upstream/ghcr.io
+`
+
+var longConfigUnset = `Remove a key from the falcoctl config file
+
+Example - Restore the default registry, Docker Hub:
+	falcoctl registry config unset default_registry
+`
+
+type configOptions struct {
+	*options.CommonOptions
+}
+
+// NewRegistryConfigCmd returns the registry config command.
+func NewRegistryConfigCmd(opt *options.CommonOptions) *cobra.Command {
+	o := configOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "config",
+		DisableFlagsInUseLine: true,
+		Short:                 "Get and set the persistent defaults stored in the falcoctl config file",
+		Long:                  longConfig,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:                   "get key",
+		DisableFlagsInUseLine: true,
+		Short:                 "Print the value of a key of the falcoctl config file",
+		Long:                  longConfigGet,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunConfigGet(args))
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:                   "set key value",
+		DisableFlagsInUseLine: true,
+		Short:                 "Set the value of a key of the falcoctl config file",
+		Long:                  longConfigSet,
+		Args:                  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunConfigSet(args))
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:                   "unset key",
+		DisableFlagsInUseLine: true,
+		Short:                 "Remove a key from the falcoctl config file",
+		Long:                  longConfigUnset,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunConfigUnset(args))
+		},
+	})
+
+	return cmd
+}
+
+// RunConfigGet executes the business logic for the registry config get command.
+func (o *configOptions) RunConfigGet(args []string) error {
+	value, err := config.Get(configFile, args[0])
+	if errors.Is(err, config.ErrKeyNotSet) {
+		return output.ErrSilentExit
+	}
+	if err != nil {
+		return err
+	}
+
+	o.Printer.DefaultText.Println(value)
+	return nil
+}
+
+// RunConfigSet executes the business logic for the registry config set command.
+func (o *configOptions) RunConfigSet(args []string) error {
+	if err := config.Set(configFile, args[0], args[1]); err != nil {
+		return err
+	}
+
+	o.Printer.Verbosef("%q set in %q", args[0], configFile)
+	return nil
+}
+
+// RunConfigUnset executes the business logic for the registry config unset command.
+func (o *configOptions) RunConfigUnset(args []string) error {
+	err := config.Unset(configFile, args[0])
+	if errors.Is(err, config.ErrKeyNotSet) {
+		o.Printer.Warning.Printfln("%q is not set in %q", args[0], configFile)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	o.Printer.Verbosef("%q removed from %q", args[0], configFile)
+	return nil
+}
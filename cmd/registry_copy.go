@@ -0,0 +1,196 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+var longCopy = `Copy an artifact, with all its platforms, from a registry to another one
+
+Layers are copied unchanged. With --exclude-annotations, the annotations whose key matches
+one of the given glob patterns are removed from the manifests before pushing them to the
+destination. Since this changes the manifests, the digest of the copied artifact differs
+from the source one and it is reported.
+
+With --all-tags, the references point to repositories, without tag or digest, and all the tags
+of the source repository are copied to the destination one, keeping their names. Nodes shared among
+tags, e.g. common layers, are transferred once. A failure copying a tag does not stop the copy of the
+other ones, the command fails at the end if any tag could not be copied.
+
+Registry rewrites are applied to the source reference only.
+
+Example - Mirror a plugin to a private registry:
+	falcoctl registry copy ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.6.0 registry.corp/falco/cloudtrail:0.6.0
+
+Example - Mirror a rulesfile dropping the internal source-tracking annotations:
+	falcoctl registry copy ghcr.io/myorg/rules:1.0.0 registry.corp/falco/rules:1.0.0 \
+		--exclude-annotations org.opencontainers.image.source,com.myorg.*
+
+Example - Back up all the versions of a plugin to another registry:
+	falcoctl registry copy ghcr.io/falcosecurity/plugins/plugin/cloudtrail registry.corp/backup/cloudtrail --all-tags
+`
+
+type copyOptions struct {
+	*options.CommonOptions
+	excludeAnnotations []string
+	allTags            bool
+}
+
+// NewCopyCmd returns the copy command.
+func NewCopyCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := copyOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "copy src-hostname/repo[:tag|@digest] dst-hostname/repo[:tag] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Copy an artifact from a registry to another one",
+		Long:                  longCopy,
+		Args:                  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunCopy(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&o.excludeAnnotations, "exclude-annotations", nil,
+		"comma separated list of annotation keys, or glob patterns, to be removed from the copied manifests")
+	cmd.Flags().BoolVar(&o.allTags, "all-tags", false, "copy all the tags of the source repository")
+
+	return cmd
+}
+
+// RunCopy executes the business logic for the copy command.
+func (o *copyOptions) RunCopy(ctx context.Context, args []string) error {
+	if o.allTags {
+		o.Printer.Info.Printfln("Preparing to copy all the tags of %q to %q", args[0], args[1])
+	} else {
+		o.Printer.Info.Printfln("Preparing to copy artifact %q to %q", args[0], args[1])
+	}
+
+	src, err := normalizeReference(o.Printer, args[0])
+	if err != nil {
+		return err
+	}
+	if src, err = rewriteReference(o.Printer, src); err != nil {
+		return err
+	}
+
+	dst, err := normalizeReference(o.Printer, args[1])
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	srcClient, err := registryClient(ctx, credentialStore, src)
+	if err != nil {
+		return err
+	}
+
+	dstClient, err := registryClient(ctx, credentialStore, dst)
+	if err != nil {
+		return err
+	}
+
+	if o.allTags {
+		return o.copyAllTags(ctx, src, srcClient, dst, dstClient)
+	}
+
+	srcDesc, dstDesc, err := oci.Copy(ctx, src, srcClient, dst, dstClient, o.excludeAnnotations)
+	if err != nil {
+		return err
+	}
+
+	if srcDesc.Digest != dstDesc.Digest {
+		o.Printer.Warning.Printfln("Annotations removed, the digest of the copied artifact changed from %s to %s",
+			srcDesc.Digest, dstDesc.Digest)
+	}
+
+	o.Printer.Success.Printfln("Artifact copied to %q, digest: %s", dst, dstDesc.Digest)
+
+	return nil
+}
+
+// copyAllTags copies all the tags of the src repository to the dst repository, reporting the progress per tag.
+func (o *copyOptions) copyAllTags(ctx context.Context, src string, srcClient *auth.Client, dst string, dstClient *auth.Client) error {
+	for _, ref := range []string{src, dst} {
+		parsedRef, err := registry.ParseReference(ref)
+		if err != nil {
+			return err
+		}
+		if parsedRef.Reference != "" {
+			return fmt.Errorf("--all-tags requires repository references without tag or digest, got %q", ref)
+		}
+	}
+
+	var failed int
+	results, err := oci.CopyAllTags(ctx, src, srcClient, dst, dstClient, o.excludeAnnotations, func(result oci.TagCopy) {
+		switch {
+		case result.Err != nil:
+			failed++
+			o.Printer.Warning.Printfln("cannot copy tag %q: %s", result.Tag, result.Err.Error())
+		case result.Source.Digest != result.Destination.Digest:
+			o.Printer.Info.Printfln("Tag %q copied, digest changed from %s to %s", result.Tag, result.Source.Digest, result.Destination.Digest)
+		default:
+			o.Printer.Info.Printfln("Tag %q copied, digest: %s", result.Tag, result.Destination.Digest)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		o.Printer.Info.Printfln("No tag found in %q", src)
+		return nil
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("unable to copy %d of %d tags to %q", failed, len(results), dst)
+	}
+
+	o.Printer.Success.Printfln("All the %d tags copied to %q", len(results), dst)
+
+	return nil
+}
+
+// registryClient returns a client authenticated with the stored credentials of the registry of ref.
+func registryClient(ctx context.Context, credentialStore *authn.Store, ref string) (*auth.Client, error) {
+	reg, err := utils.GetRegistryFromRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve credentials for registry %q: %w", reg, err)
+	}
+
+	return authn.NewClient(cred), nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	ocipusher "github.com/falcosecurity/falcoctl/pkg/oci/pusher"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+var longDigest = `Compute the digest of a Falco "rulesfile" or "plugin" OCI artifact without pushing it
+
+The layers, configs and manifests are built exactly as "falcoctl registry push" would, given the same
+files and flags, and the digest of the resulting artifact is printed to stdout. No registry is contacted,
+hence blobs referenced by digest, e.g. "@sha256:123abc...", are not supported.
+
+Manifests record their creation time, set the SOURCE_DATE_EPOCH environment variable, or the
+"org.opencontainers.image.created" annotation, to the same value for both commands to get the same digest.
+
+Example - Compute the digest of artifact "myrulesfile.tar.gz" of type "rulesfile":
+	SOURCE_DATE_EPOCH=$(git log -1 --format=%ct) falcoctl registry digest --type rulesfile myrulesfile.tar.gz
+
+Example - Compute the digest of artifact "myplugin.tar.gz" of type "plugin" for multiple platforms:
+	falcoctl registry digest --type plugin \
+		myplugin-linux-x86_64.tar.gz --platform linux/x86_64 \
+		myplugin-linux-arm64.tar.gz --platform linux/aarch64
+`
+
+type digestOptions struct {
+	*options.CommonOptions
+	*options.ArtifactOptions
+}
+
+// NewDigestCmd returns the digest command.
+func NewDigestCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := digestOptions{
+		CommonOptions:   opt,
+		ArtifactOptions: &options.ArtifactOptions{},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "digest file [file...] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Compute the digest of a Falco OCI artifact without pushing it",
+		Long:                  longDigest,
+		Args:                  cobra.MinimumNArgs(1),
+		SilenceErrors:         true,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.ArtifactOptions.Validate())
+			o.Printer.CheckErr(o.ArtifactOptions.NormalizePlatforms())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			// Keep stdout clean, it only holds the digest.
+			o.Printer.RedirectLogs(os.Stderr)
+			o.Printer.CheckErr(o.RunDigest(ctx, args))
+		},
+	}
+	o.CommonOptions.AddFlags(cmd.Flags())
+	o.Printer.CheckErr(o.ArtifactOptions.AddFlags(cmd))
+
+	return cmd
+}
+
+// RunDigest executes the business logic for the digest command.
+func (o *digestOptions) RunDigest(ctx context.Context, args []string) error {
+	if o.ArtifactType == oci.Collection {
+		return fmt.Errorf("the digest of a collection cannot be computed locally, its members must be resolved in the registry")
+	}
+
+	opts, err := pusherOptions(o.ArtifactOptions, args)
+	if err != nil {
+		return err
+	}
+
+	res, err := ocipusher.Digest(ctx, o.ArtifactType, opts...)
+	if err != nil {
+		return err
+	}
+
+	o.Printer.DefaultText.Print(res.Digest)
+
+	return nil
+}
@@ -0,0 +1,232 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+var longIndexCreate = `Create or update the multi-platform index of a tag from manifests already pushed
+
+Each --add entry adds to the index the manifest with the given digest, already pushed to the same
+repository, for the given platform in OS/ARCH format. If the tag already points to an index, the
+manifests it holds for the other platforms are kept, while the ones for the same platforms are replaced.
+Hence the index can be assembled across several invocations, e.g. one per build of a CI matrix, and
+running the command again with the same entries leaves the index untouched.
+
+Example - Assemble the index of "myplugin" from the manifests pushed by each build:
+	falcoctl registry index create localhost:5000/myplugin:0.1.0 \
+		--add linux/amd64=@sha256:4a5e... --add linux/arm64=@sha256:9c1b...
+
+Example - Add a platform to an existing index:
+	falcoctl registry index create localhost:5000/myplugin:0.1.0 --add linux/arm64=@sha256:9c1b...
+`
+
+var longIndexVerify = `Check that all the manifests referenced by a multi-platform index exist
+
+Each manifest referenced by the index is looked up in the repository, and the dangling ones, e.g.
+deleted by a partial cleanup, are reported along with their platform. The command exits with a
+non-zero exit code if any dangling manifest is found, unless --prune is set: in that case the
+index is rewritten without them, preserving all its other fields, and pushed again. The tag of the
+reference is moved to the pruned index, whose digest is printed.
+
+Example - Check the index of "myplugin":
+	falcoctl registry index verify localhost:5000/myplugin:0.1.0
+
+Example - Remove the dangling manifests from the index of "myplugin":
+	falcoctl registry index verify localhost:5000/myplugin:0.1.0 --prune
+`
+
+type indexCreateOptions struct {
+	*options.CommonOptions
+	add []string
+}
+
+func (o *indexCreateOptions) validate() error {
+	if len(o.add) == 0 {
+		return fmt.Errorf("at least one --add entry must be set")
+	}
+	return nil
+}
+
+// NewRegistryIndexCmd returns the registry index command.
+func NewRegistryIndexCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "index",
+		DisableFlagsInUseLine: true,
+		Short:                 "Manage the multi-platform indexes of artifacts",
+		Long:                  "Manage the multi-platform indexes of artifacts",
+	}
+
+	cmd.AddCommand(NewIndexCreateCmd(ctx, opt))
+	cmd.AddCommand(NewIndexVerifyCmd(ctx, opt))
+
+	return cmd
+}
+
+// NewIndexCreateCmd returns the registry index create command.
+func NewIndexCreateCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := indexCreateOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "create hostname/repo[:tag] --add OS/ARCH=@DIGEST... [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Create or update the multi-platform index of a tag from manifests already pushed",
+		Long:                  longIndexCreate,
+		Args:                  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunIndexCreate(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&o.add, "add", nil,
+		`manifest to add to the index, in OS/ARCH=@DIGEST format, e.g. "linux/amd64=@sha256:4a5e...". Can be repeated multiple times`)
+
+	return cmd
+}
+
+// RunIndexCreate executes the business logic for the registry index create command.
+func (o *indexCreateOptions) RunIndexCreate(ctx context.Context, args []string) error {
+	entries := make([]oci.IndexEntry, 0, len(o.add))
+	platforms := make(map[string]string, len(o.add))
+	for _, add := range o.add {
+		entry, err := oci.ParseIndexEntry(add)
+		if err != nil {
+			return err
+		}
+		if other, ok := platforms[entry.Platform]; ok && other != entry.Digest {
+			return fmt.Errorf("platform %s added twice, with digests %s and %s", entry.Platform, other, entry.Digest)
+		}
+		platforms[entry.Platform] = entry.Digest
+		entries = append(entries, entry)
+	}
+
+	ref, err := normalizeReference(o.Printer, args[0])
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	client, err := registryClient(ctx, credentialStore, ref)
+	if err != nil {
+		return err
+	}
+
+	o.Printer.Info.Printfln("Assembling index %q", ref)
+	oldDigest, newDigest, err := oci.AssembleIndex(ctx, ref, client, entries)
+	switch {
+	case err != nil:
+		return err
+	case oldDigest == "":
+		o.Printer.Success.Printfln("Index %q created: %s", ref, newDigest)
+	case oldDigest == newDigest:
+		o.Printer.Info.Printfln("Index %q already holds the given manifests: %s", ref, newDigest)
+	default:
+		o.Printer.Success.Printfln("Index %q updated: %s -> %s", ref, oldDigest, newDigest)
+	}
+
+	return nil
+}
+
+type indexVerifyOptions struct {
+	*options.CommonOptions
+	prune bool
+}
+
+// NewIndexVerifyCmd returns the registry index verify command.
+func NewIndexVerifyCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := indexVerifyOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "verify hostname/repo[:tag|@digest] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Check that all the manifests referenced by a multi-platform index exist",
+		Long:                  longIndexVerify,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunIndexVerify(ctx, args))
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.prune, "prune", false, "rewrite the index without the dangling manifests")
+
+	return cmd
+}
+
+// RunIndexVerify executes the business logic for the registry index verify command.
+func (o *indexVerifyOptions) RunIndexVerify(ctx context.Context, args []string) error {
+	ref, err := normalizeReference(o.Printer, args[0])
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	client, err := registryClient(ctx, credentialStore, ref)
+	if err != nil {
+		return err
+	}
+
+	result, err := oci.VerifyIndex(ctx, ref, client, o.prune)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Dangling) == 0 {
+		o.Printer.Success.Printfln("All the manifests of index %q (%s) exist", ref, result.Digest)
+		return nil
+	}
+
+	for i := range result.Dangling {
+		platform := "unknown platform"
+		if p := result.Dangling[i].Platform; p != nil {
+			platform = p.OS + "/" + p.Architecture
+		}
+		o.Printer.Warning.Printfln("Dangling manifest %s (%s)", result.Dangling[i].Digest, platform)
+	}
+
+	if result.PrunedDigest == "" {
+		o.Printer.Error.Printfln("Index %q (%s) references %d dangling manifest(s), use --prune to remove them",
+			ref, result.Digest, len(result.Dangling))
+		return output.ErrSilentExit
+	}
+
+	o.Printer.DefaultText.Printfln("old digest: %s\nnew digest: %s", result.Digest, result.PrunedDigest)
+	o.Printer.Success.Printfln("Index %q pruned of %d dangling manifest(s)", ref, len(result.Dangling))
+
+	return nil
+}
@@ -16,13 +16,24 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"runtime"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/opencontainers/go-digest"
 	"github.com/spf13/cobra"
 	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
 
 	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/config"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
 	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
 	ocipuller "github.com/falcosecurity/falcoctl/pkg/oci/puller"
 	"github.com/falcosecurity/falcoctl/pkg/options"
@@ -42,16 +53,105 @@ Example - Pull artifact "myplugin" of type "plugin" for platform "linux/aarch64"
 
 Example - Pull artifact "myrulesfile" of type "rulesfile":
 	falcoctl registry pull localhost:5000/myrulesfile:latest --type rulesfile
+
+Example - Pull artifact "myrulesfile" of type "rulesfile" only if it was created in the last 7 days:
+	falcoctl registry pull localhost:5000/myrulesfile:latest --type rulesfile --max-age 168h --require-created
+
+Example - Pull artifact "myplugin" of type "plugin" through a pull-through proxy:
+	FALCOCTL_REGISTRY_REWRITES=ghcr.io=internal-proxy.corp/upstream/ghcr.io falcoctl registry pull ghcr.io/myorg/myplugin:latest --type plugin
+
+Example - Pull artifact "myplugin" of type "plugin" selecting the platform among the available ones:
+	falcoctl registry pull localhost:5000/myplugin:latest --type plugin --interactive
+
+Example - Pull artifact "myplugin" of type "plugin" for all its platforms, up to 8 at the same time:
+	falcoctl registry pull localhost:5000/myplugin:latest --type plugin --platform all --concurrency 8
+
+Example - Pull artifacts "myrules" and "otherrules" of type "rulesfile" in "rules" directory, renaming files with the same name:
+	falcoctl registry pull localhost:5000/myrules:latest localhost:5000/otherrules:latest --dest-dir rules --on-conflict rename
+
+Example - Pull artifact "myplugin" by tag, failing if it does not point to the expected digest:
+	falcoctl registry pull localhost:5000/myplugin:0.1.0 --expected-digest sha256:<digest>
+
+Example - Pull artifacts "myplugin" and "myrules", recording their digests in "falcoctl.lock.yaml":
+	falcoctl registry pull localhost:5000/myplugin:latest localhost:5000/myrules:latest --lockfile falcoctl.lock.yaml
+
+Example - Pull again the exact artifacts recorded in "falcoctl.lock.yaml":
+	falcoctl registry pull --from-lockfile falcoctl.lock.yaml
+
+Example - Pull the layers of the container image "myimage" in "myDir" directory:
+	falcoctl registry pull localhost:5000/myimage:latest --any-type --dest-dir=./myDir
+
+Example - Pull all the members of the collection "mybundle" in "myDir" directory:
+	falcoctl registry pull localhost:5000/mybundle:latest --dest-dir=./myDir
+
+Example - Pull all the platforms of "myplugin" recording where each file comes from in "myDir/.falcoctl-provenance.yaml":
+	falcoctl registry pull localhost:5000/myplugin:latest --platform all --dest-dir=./myDir --record-provenance
 `
 
 type pullOptions struct {
 	*options.CommonOptions
 	*options.ArtifactOptions
-	destDir string
+	destDir          string
+	maxMetadataSize  int64
+	interactive      bool
+	maxAge           time.Duration
+	requireCreated   bool
+	expectedDigest   string
+	allPlatforms     bool
+	concurrency      int
+	onConflict       string
+	lockfile         string
+	fromLockfile     string
+	anyType          bool
+	recordProvenance bool
+	// locked are the pulled artifacts to be written to the lockfile.
+	locked []oci.LockedArtifact
+	// provenance are the pulled files to be recorded in the provenance file.
+	provenance []oci.FileProvenance
 }
 
+// allPlatforms is the value of --platform pulling all the platforms of an artifact.
+const allPlatforms = "all"
+
+// Policies applied to the files with the same name when pulling multiple artifacts.
+const (
+	conflictError     = "error"
+	conflictOverwrite = "overwrite"
+	conflictRename    = "rename"
+)
+
 func (o *pullOptions) Validate() error {
-	return o.ArtifactOptions.Validate()
+	// "all" is not a platform, do not validate it as such.
+	if len(o.Platforms) == 1 && o.Platforms[0] == allPlatforms {
+		o.allPlatforms = true
+		o.Platforms = nil
+	}
+
+	if o.fromLockfile != "" && (len(o.Platforms) > 0 || o.allPlatforms || o.expectedDigest != "" || o.interactive) {
+		return fmt.Errorf("--platform, --expected-digest and --interactive cannot be used with --from-lockfile")
+	}
+
+	switch o.onConflict {
+	case conflictError, conflictOverwrite, conflictRename:
+	default:
+		return fmt.Errorf("--on-conflict %q not supported, allowed values: %q, %q, %q",
+			o.onConflict, conflictError, conflictOverwrite, conflictRename)
+	}
+
+	// The platforms are not normalized: the puller matches the architectures regardless of their aliases, hence
+	// artifacts pushed e.g. for "linux/x86_64" are pulled as such.
+	if err := o.ArtifactOptions.Validate(); err != nil {
+		return err
+	}
+
+	// The platform of the pulled artifact is selected by OS and ARCH only.
+	for _, platform := range o.Platforms {
+		if strings.Count(platform, "/") > 1 {
+			return fmt.Errorf("platform %q: variants are not supported when pulling, use the OS/ARCH format, e.g. \"linux/arm\"", platform)
+		}
+	}
+
+	return nil
 }
 
 func newPullProgressTracker(printer *output.Printer) ocipuller.ProgressTracker {
@@ -68,11 +168,16 @@ func NewPullCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command
 	}
 
 	cmd := &cobra.Command{
-		Use:                   "pull hostname/repo[:tag|@digest] [flags]",
+		Use:                   "pull hostname/repo[:tag|@digest] [hostname/repo[:tag|@digest]...] [flags]",
 		DisableFlagsInUseLine: true,
 		Short:                 "Pull a Falco OCI artifact from remote registry",
 		Long:                  longPull,
-		Args:                  cobra.ExactArgs(1),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if o.fromLockfile != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
 		PreRun: func(cmd *cobra.Command, args []string) {
 			o.Printer.CheckErr(o.Validate())
 		},
@@ -81,40 +186,96 @@ func NewPullCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command
 		},
 	}
 	o.CommonOptions.AddFlags(cmd.Flags())
+	o.CommonOptions.AddOutputFlags(cmd.Flags())
 	o.Printer.CheckErr(o.ArtifactOptions.AddFlags(cmd))
 	cmd.Flags().StringVarP(&o.destDir, "dest-dir", "o", "", "destination dir where to save the artifacts(default: current directory)")
+	cmd.Flags().Int64Var(&o.maxMetadataSize, "max-manifest-size", oci.DefaultMaxMetadataSize,
+		"maximum size in bytes of the manifests and configs read from the registry")
+	cmd.Flags().BoolVar(&o.interactive, "interactive", false,
+		"prompt for the platform to pull among the available ones when --platform is not set. Ignored if not running in a terminal")
+	cmd.Flags().DurationVar(&o.maxAge, "max-age", 0,
+		`refuse artifacts created longer ago than the given duration, e.g. "72h", based on the "org.opencontainers.image.created" annotation`)
+	cmd.Flags().BoolVar(&o.requireCreated, "require-created", false,
+		"fail instead of warning when the creation time of the artifact is missing or malformed. Used with --max-age")
+	cmd.Flags().IntVar(&o.concurrency, "concurrency", 4,
+		"maximum number of platforms pulled at the same time with --platform all")
+	cmd.Flags().StringVar(&o.expectedDigest, "expected-digest", "",
+		"fail if the digest of the artifact, or of its manifest for the pulled platform, differs from the given one, e.g. \"sha256:...\"")
+	cmd.Flags().StringVar(&o.onConflict, "on-conflict", conflictError,
+		`policy for files with the same name when pulling multiple artifacts. Allowed values: "error", "overwrite", "rename"`)
+	cmd.Flags().StringVar(&o.lockfile, "lockfile", "",
+		"write the pulled artifacts, pinned by digest, along with their type, platform and dependencies to the given file")
+	cmd.Flags().StringVar(&o.fromLockfile, "from-lockfile", "",
+		"pull the exact artifacts recorded in the given lockfile, written by --lockfile, instead of the ones passed as arguments")
+	cmd.Flags().BoolVar(&o.recordProvenance, "record-provenance", false,
+		"record the reference, platform and layer digest of each pulled file in the "+oci.ProvenanceFile+" file of the destination dir")
+	cmd.Flags().BoolVar(&o.anyType, "any-type", false,
+		"pull container images too, saving their layers in the destination directory as they are, instead of refusing them (advanced)")
 	return cmd
 }
 
 // RunPull executes the business logic for the pull command.
 func (o *pullOptions) RunPull(ctx context.Context, args []string) error {
-	ref := args[0]
-	o.Printer.Info.Printfln("Preparing to pull artifact %q", args[0])
+	var err error
+	switch {
+	case o.fromLockfile != "":
+		err = o.pullFromLockfile(ctx)
+	case len(args) > 1:
+		err = o.pullMultiple(ctx, args)
+	default:
+		err = o.pullSingle(ctx, args[0])
+	}
 
-	registry, err := utils.GetRegistryFromRef(ref)
 	if err != nil {
 		return err
 	}
 
-	credentialStore, err := authn.NewStore([]string{}...)
-	if err != nil {
-		return err
+	if o.lockfile != "" {
+		lockfile := oci.Lockfile{Artifacts: o.locked}
+		if err = lockfile.Write(o.lockfile); err != nil {
+			return fmt.Errorf("cannot write lockfile %q: %w", o.lockfile, err)
+		}
+		o.Printer.Info.Printfln("%d pulled artifact(s) recorded in %q", len(o.locked), o.lockfile)
 	}
 
-	o.Printer.Verbosef("Retrieving credentials from local store")
-	cred, err := credentialStore.Credential(ctx, registry)
+	if o.recordProvenance {
+		return o.writeProvenance()
+	}
+
+	return nil
+}
+
+// writeProvenance records the provenance of the pulled files in the provenance file of the destination
+// directory, along with the one of the files previously pulled there.
+func (o *pullOptions) writeProvenance() error {
+	path := filepath.Join(o.destDir, oci.ProvenanceFile)
+	provenance, err := oci.LoadProvenance(path)
 	if err != nil {
 		return err
 	}
 
-	if err := utils.CheckRegistryConnection(ctx, &cred, registry, o.Printer); err != nil {
-		o.Printer.Verbosef("%s", err.Error())
-		return fmt.Errorf("unable to connect to registry %q", registry)
+	for i := range o.provenance {
+		provenance.Upsert(&o.provenance[i])
 	}
 
-	client := authn.NewClient(cred)
+	if err = provenance.Write(path); err != nil {
+		return fmt.Errorf("cannot write provenance file %q: %w", path, err)
+	}
+	o.Printer.Info.Printfln("Provenance of %d pulled file(s) recorded in %q", len(o.provenance), path)
+
+	return nil
+}
+
+// pullSingle pulls a single artifact in the destination directory.
+func (o *pullOptions) pullSingle(ctx context.Context, arg string) error {
+	ref, client, err := o.connect(ctx, arg)
+	if err != nil {
+		return err
+	}
 
 	puller := ocipuller.NewPuller(client, newPullProgressTracker(o.Printer))
+	puller.MaxMetadataSize = o.maxMetadataSize
+	puller.AnyType = o.anyType
 	if o.destDir == "" {
 		o.Printer.Info.Printfln("Pulling artifact in the current directory")
 	} else {
@@ -122,8 +283,30 @@ func (o *pullOptions) RunPull(ctx context.Context, args []string) error {
 	}
 
 	os, arch := runtime.GOOS, runtime.GOARCH
-	if len(o.ArtifactOptions.Platforms) > 0 {
+	switch {
+	case len(o.ArtifactOptions.Platforms) > 0:
 		os, arch = o.OSArch(0)
+	case o.interactive && o.Printer.IsInteractive():
+		if os, arch, err = o.selectPlatform(ctx, ref, client, os, arch); err != nil {
+			return err
+		}
+	}
+
+	if o.expectedDigest != "" {
+		// The checked digest is pulled, so that the reference cannot move in the meantime.
+		if ref, err = o.checkDigest(ctx, ref, client, os, arch); err != nil {
+			return err
+		}
+	}
+
+	if o.allPlatforms {
+		return o.pullAllPlatforms(ctx, arg, ref, client)
+	}
+
+	if o.maxAge > 0 {
+		if err = o.checkAge(ctx, ref, client, os, arch); err != nil {
+			return err
+		}
 	}
 
 	res, err := puller.Pull(ctx, ref, o.destDir, os, arch)
@@ -131,7 +314,537 @@ func (o *pullOptions) RunPull(ctx context.Context, args []string) error {
 		return err
 	}
 
+	if res.Type == oci.Collection {
+		return o.pullCollection(ctx, arg, res)
+	}
+
 	o.Printer.Success.Printfln("Artifact of type %q pulled. Digest: %q", res.Type, res.Digest)
 
+	recordTransferredFiles(o.Printer, filepath.Join(o.destDir, res.Filename))
+
+	if err = o.lock(ctx, arg, ref, client, res, os, arch, ""); err != nil {
+		return err
+	}
+
+	if err = o.trace(ref, res, os, arch, res.Filename); err != nil {
+		return err
+	}
+
+	if o.Output.IsStructured() {
+		return o.Printer.PrintData(o.Output, res)
+	}
+
+	return nil
+}
+
+// connect normalizes and rewrites the reference of the artifact to be pulled and returns it along
+// with a client authenticated to its registry.
+func (o *pullOptions) connect(ctx context.Context, arg string) (string, *auth.Client, error) {
+	o.Printer.Info.Printfln("Preparing to pull artifact %q", arg)
+
+	ref, err := normalizeReference(o.Printer, arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if ref, err = rewriteReference(o.Printer, ref); err != nil {
+		return "", nil, err
+	}
+
+	registry, err := utils.GetRegistryFromRef(ref)
+	if err != nil {
+		return "", nil, err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	o.Printer.Verbosef("Retrieving credentials from local store")
+	cred, err := credentialStore.Credential(ctx, registry)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := utils.CheckRegistryConnection(ctx, &cred, registry, o.Printer); err != nil {
+		o.Printer.Verbosef("%s", err.Error())
+		return "", nil, fmt.Errorf("unable to connect to registry %q", registry)
+	}
+
+	return ref, authn.NewClient(cred), nil
+}
+
+// pullMultiple pulls several artifacts in the destination directory. Each artifact is first pulled in its own
+// temporary directory, so that files with the same name are detected and handled according to --on-conflict
+// before any of them is moved to the destination directory.
+func (o *pullOptions) pullMultiple(ctx context.Context, args []string) error {
+	if o.allPlatforms || o.expectedDigest != "" || o.interactive {
+		return fmt.Errorf("--platform all, --expected-digest and --interactive cannot be used when pulling multiple artifacts")
+	}
+
+	destDir := o.destDir
+	if destDir == "" {
+		destDir = "."
+	}
+	if err := os.MkdirAll(destDir, 0o750); err != nil {
+		return err
+	}
+
+	platformOS, platformArch := runtime.GOOS, runtime.GOARCH
+	if len(o.ArtifactOptions.Platforms) > 0 {
+		platformOS, platformArch = o.OSArch(0)
+	}
+
+	results := make([]*oci.RegistryResult, len(args))
+	refs := make([]string, len(args))
+	tmpDirs := make([]string, len(args))
+	defer func() {
+		for _, tmpDir := range tmpDirs {
+			if tmpDir != "" {
+				_ = os.RemoveAll(tmpDir)
+			}
+		}
+	}()
+
+	for i, arg := range args {
+		ref, client, err := o.connect(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		if o.maxAge > 0 {
+			if err = o.checkAge(ctx, ref, client, platformOS, platformArch); err != nil {
+				return err
+			}
+		}
+
+		if tmpDirs[i], err = os.MkdirTemp(destDir, ".falcoctl-pull-"); err != nil {
+			return err
+		}
+
+		puller := ocipuller.NewPuller(client, newPullProgressTracker(o.Printer))
+		puller.MaxMetadataSize = o.maxMetadataSize
+		puller.AnyType = o.anyType
+		if results[i], err = puller.Pull(ctx, ref, tmpDirs[i], platformOS, platformArch); err != nil {
+			return err
+		}
+		if results[i].Type == oci.Collection {
+			return fmt.Errorf("%q is a collection, pull it on its own to pull its members", arg)
+		}
+		refs[i] = ref
+
+		if err = o.lock(ctx, arg, ref, client, results[i], platformOS, platformArch, ""); err != nil {
+			return err
+		}
+	}
+
+	pulled := make([]string, len(results))
+	for i, res := range results {
+		pulled[i] = res.Filename
+	}
+	filenames, err := o.destFilenames(args, pulled)
+	if err != nil {
+		return err
+	}
+
+	files := make([]string, 0, len(results))
+	for i, res := range results {
+		filename := filenames[i]
+		dst := filepath.Join(destDir, filename)
+		if err := os.Rename(filepath.Join(tmpDirs[i], res.Filename), dst); err != nil {
+			return err
+		}
+		res.Filename = filename
+
+		o.Printer.Success.Printfln("Artifact %q of type %q pulled. Digest: %q", args[i], res.Type, res.Digest)
+		files = append(files, dst)
+
+		if err := o.trace(refs[i], res, platformOS, platformArch, filename); err != nil {
+			return err
+		}
+	}
+
+	recordTransferredFiles(o.Printer, files...)
+
+	if o.Output.IsStructured() {
+		return o.Printer.PrintData(o.Output, results)
+	}
+
+	return nil
+}
+
+// pullCollection pulls the members of a collection, pinned to their digests, in the destination directory as
+// if they were passed on the command line. The collection has no content of its own, hence it is not locked.
+func (o *pullOptions) pullCollection(ctx context.Context, arg string, res *oci.RegistryResult) error {
+	o.Printer.Success.Printfln("Collection %q pulled. Digest: %q", arg, res.Digest)
+
+	refs := make([]string, len(res.Members))
+	for i, member := range res.Members {
+		refs[i] = member.Ref
+		o.Printer.Verbosef("Member %q of type %q, version %q", member.Ref, member.Type, member.Version)
+	}
+	o.Printer.Info.Printfln("Pulling the %d member(s) of the collection", len(refs))
+
+	// The digest of the collection has already been checked, its members are pinned.
+	o.expectedDigest = ""
+	o.interactive = false
+
+	return o.pullMultiple(ctx, refs)
+}
+
+// pullFromLockfile pulls the artifacts recorded in the lockfile, each one for its platform and in its directory.
+// Registry rewrites apply to the recorded references, so that the same lockfile can be used through a mirror.
+func (o *pullOptions) pullFromLockfile(ctx context.Context) error {
+	lockfile, err := oci.LoadLockfile(o.fromLockfile)
+	if err != nil {
+		return err
+	}
+
+	o.Printer.Info.Printfln("Pulling %d artifact(s) recorded in %q", len(lockfile.Artifacts), o.fromLockfile)
+
+	results := make([]*oci.RegistryResult, 0, len(lockfile.Artifacts))
+	files := make([]string, 0, len(lockfile.Artifacts))
+	for i := range lockfile.Artifacts {
+		locked := &lockfile.Artifacts[i]
+
+		// The lockfile may come from an untrusted source, its directories must stay in the destination one.
+		destDir, err := utils.JoinDestDir(o.destDir, locked.Dir)
+		if err != nil {
+			return fmt.Errorf("invalid directory of artifact %q in %q: %w", locked.Ref, o.fromLockfile, err)
+		}
+
+		ref, client, err := o.connect(ctx, locked.Ref)
+		if err != nil {
+			return err
+		}
+		puller := ocipuller.NewPuller(client, newPullProgressTracker(o.Printer))
+		puller.MaxMetadataSize = o.maxMetadataSize
+		puller.AnyType = o.anyType
+		os, arch := locked.OSArch()
+		res, err := puller.Pull(ctx, ref, destDir, os, arch)
+		if err != nil {
+			return err
+		}
+
+		if lockedRef, _ := registry.ParseReference(locked.Ref); res.Digest != lockedRef.Reference {
+			return fmt.Errorf("artifact %q pulled with digest %q, expected %q", locked.Ref, res.Digest, lockedRef.Reference)
+		}
+		if res.Type != locked.Type {
+			return fmt.Errorf("artifact %q is of type %q, expected %q", locked.Ref, res.Type, locked.Type)
+		}
+
+		o.Printer.Success.Printfln("Artifact %q of type %q pulled for platform %s", locked.Ref, res.Type, locked.Platform)
+		results = append(results, res)
+		files = append(files, filepath.Join(destDir, res.Filename))
+		o.locked = append(o.locked, *locked)
+
+		if err = o.trace(ref, res, os, arch, filepath.Join(locked.Dir, res.Filename)); err != nil {
+			return err
+		}
+	}
+
+	recordTransferredFiles(o.Printer, files...)
+
+	if o.Output.IsStructured() {
+		return o.Printer.PrintData(o.Output, results)
+	}
+
 	return nil
 }
+
+// lock records a pulled artifact to be written to the lockfile, if requested. The artifact is recorded with
+// the normalized reference given by the user, before registry rewrites, pinned to the pulled digest.
+func (o *pullOptions) lock(ctx context.Context, arg, ref string, client *auth.Client, res *oci.RegistryResult, os, arch, dir string) error {
+	if o.lockfile == "" {
+		return nil
+	}
+
+	cfg, err := config.NewConfig(configFile)
+	if err != nil {
+		return err
+	}
+	normalized, _ := cfg.NormalizeReference(arg)
+
+	lockedRef, err := oci.PinReference(normalized, res.Digest)
+	if err != nil {
+		return err
+	}
+
+	pulledRef, err := oci.PinReference(ref, res.Digest)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := oci.FetchManifest(ctx, pulledRef, client, os, arch)
+	if err != nil {
+		return err
+	}
+
+	artifactConfig, err := oci.FetchArtifactConfig(ctx, pulledRef, client, manifest)
+	if err != nil {
+		return err
+	}
+
+	o.locked = append(o.locked, oci.LockedArtifact{
+		Ref:          lockedRef,
+		Type:         res.Type,
+		Platform:     os + "/" + arch,
+		Dir:          dir,
+		Dependencies: artifactConfig.Dependencies,
+	})
+
+	return nil
+}
+
+// trace records the provenance of a pulled file, if requested. file is the path of the file relative to the
+// destination directory.
+func (o *pullOptions) trace(ref string, res *oci.RegistryResult, os, arch, file string) error {
+	if !o.recordProvenance {
+		return nil
+	}
+
+	pinnedRef, err := oci.PinReference(ref, res.Digest)
+	if err != nil {
+		return err
+	}
+
+	o.provenance = append(o.provenance, oci.FileProvenance{
+		File:        filepath.ToSlash(file),
+		Ref:         pinnedRef,
+		Platform:    os + "/" + arch,
+		LayerDigest: res.LayerDigest,
+		PulledAt:    time.Now().Format(timeFormat),
+	})
+
+	return nil
+}
+
+// destFilenames returns the names, in the destination directory, of the files pulled from args, handling the files
+// with the same name according to the --on-conflict policy.
+func (o *pullOptions) destFilenames(args, filenames []string) ([]string, error) {
+	owners := make(map[string][]string)
+	for i, filename := range filenames {
+		owners[filename] = append(owners[filename], args[i])
+	}
+
+	var conflicts []string
+	for filename, refs := range owners {
+		if len(refs) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("%q from %s", filename, strings.Join(refs, ", ")))
+		}
+	}
+	sort.Strings(conflicts)
+
+	if len(conflicts) > 0 {
+		if o.onConflict == conflictError {
+			return nil, fmt.Errorf("file name conflicts between the pulled artifacts, use --on-conflict to handle them: %s",
+				strings.Join(conflicts, "; "))
+		}
+		for _, c := range conflicts {
+			o.Printer.Warning.Printfln("File name conflict: %s, applying %q policy", c, o.onConflict)
+		}
+	}
+
+	taken := make(map[string]bool, len(filenames))
+	dest := make([]string, len(filenames))
+	for i, filename := range filenames {
+		if taken[filename] && o.onConflict == conflictRename {
+			filename = uniqueFilename(filename, taken)
+			o.Printer.Info.Printfln("Renaming %q from %q to %q", filenames[i], args[i], filename)
+		}
+		taken[filename] = true
+		dest[i] = filename
+	}
+
+	return dest, nil
+}
+
+// uniqueFilename returns filename with a numeric suffix, e.g. "rules-1.tar.gz", not already taken.
+func uniqueFilename(filename string, taken map[string]bool) string {
+	ext := filepath.Ext(filename)
+	if strings.HasSuffix(filename, ".tar.gz") {
+		ext = ".tar.gz"
+	}
+	stem := strings.TrimSuffix(filename, ext)
+
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s-%d%s", stem, i, ext)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+// pullAllPlatforms concurrently pulls the artifact for all the platforms available in the index pointed
+// by ref, each one in a subdirectory of the destination directory named OS-ARCH. arg is the reference given by the user.
+func (o *pullOptions) pullAllPlatforms(ctx context.Context, arg, ref string, client *auth.Client) error {
+	parsedRef, err := registry.ParseReference(ref)
+	if err != nil {
+		return err
+	}
+	if parsedRef.Reference == "" {
+		parsedRef.Reference = oci.DefaultTag
+	}
+	ref = parsedRef.String()
+
+	available, err := oci.Platforms(ctx, ref, client)
+	if err != nil {
+		return fmt.Errorf("unable to list the platforms of %q: %w", ref, err)
+	}
+
+	platforms := make([]string, 0, len(available))
+	for platform := range available {
+		// Platforms are returned in OS-ARCH format.
+		platforms = append(platforms, strings.Replace(platform, "-", "/", 1))
+	}
+	sort.Strings(platforms)
+
+	if o.maxAge > 0 {
+		for _, platform := range platforms {
+			os, arch, _ := strings.Cut(platform, "/")
+			if err = o.checkAge(ctx, ref, client, os, arch); err != nil {
+				return err
+			}
+		}
+	}
+
+	o.Printer.Info.Printfln("Pulling %d platform(s): %s", len(platforms), strings.Join(platforms, ", "))
+
+	// Concurrent downloads are rendered in a single progress bar.
+	shared := output.NewSharedProgress(o.Printer, "Pulling")
+	puller := ocipuller.NewPuller(client, func(target oras.Target) oras.Target {
+		return shared.Tracker(target)
+	})
+	puller.MaxMetadataSize = o.maxMetadataSize
+	puller.AnyType = o.anyType
+	puller.Concurrency = o.concurrency
+
+	results, err := puller.PullPlatforms(ctx, ref, o.destDir, platforms)
+	if err != nil {
+		return err
+	}
+
+	files := make([]string, 0, len(results))
+	for i, res := range results {
+		o.Printer.Success.Printfln("Artifact of type %q pulled for platform %s. Digest: %q", res.Type, platforms[i], res.Digest)
+		dir := strings.Replace(platforms[i], "/", "-", 1)
+		files = append(files, filepath.Join(o.destDir, dir, res.Filename))
+
+		os, arch, _ := strings.Cut(platforms[i], "/")
+		if err = o.lock(ctx, arg, ref, client, res, os, arch, dir); err != nil {
+			return err
+		}
+
+		if err = o.trace(ref, res, os, arch, filepath.Join(dir, res.Filename)); err != nil {
+			return err
+		}
+	}
+
+	recordTransferredFiles(o.Printer, files...)
+
+	if o.Output.IsStructured() {
+		return o.Printer.PrintData(o.Output, results)
+	}
+
+	return nil
+}
+
+// selectPlatform prompts the user to select the platform to pull among the ones available in the index
+// pointed by ref. The given default platform is returned if ref does not point to an index.
+func (o *pullOptions) selectPlatform(ctx context.Context, ref string, client *auth.Client,
+	defaultOS, defaultArch string) (os, arch string, err error) {
+	platforms, err := oci.Platforms(ctx, ref, client)
+	if err != nil {
+		o.Printer.Verbosef("Unable to list the available platforms, using %s/%s: %s", defaultOS, defaultArch, err.Error())
+		return defaultOS, defaultArch, nil
+	}
+
+	choices := make([]string, 0, len(platforms))
+	for platform := range platforms {
+		// Platforms are returned in OS-ARCH format.
+		choices = append(choices, strings.Replace(platform, "-", "/", 1))
+	}
+	sort.Strings(choices)
+
+	selected, err := o.Printer.Select("Select the platform to pull", choices, defaultOS+"/"+defaultArch)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to select the platform: %w", err)
+	}
+
+	os, arch, _ = strings.Cut(selected, "/")
+
+	return os, arch, nil
+}
+
+// checkAge returns an error if the artifact is older than the maximum age. A missing or malformed
+// creation time only causes a warning, unless it is required.
+func (o *pullOptions) checkAge(ctx context.Context, ref string, client *auth.Client, os, arch string) error {
+	parsedRef, err := registry.ParseReference(ref)
+	if err != nil {
+		return err
+	}
+	if parsedRef.Reference == "" {
+		parsedRef.Reference = oci.DefaultTag
+	}
+
+	manifest, err := oci.FetchManifest(ctx, parsedRef.String(), client, os, arch)
+	if err != nil {
+		return err
+	}
+
+	err = oci.CheckAge(manifest, o.maxAge, time.Now())
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, oci.ErrArtifactTooOld), o.requireCreated:
+		return fmt.Errorf("artifact %q: %w", ref, err)
+	default:
+		o.Printer.Warning.Printfln("Unable to check the age of artifact %q: %s", ref, err.Error())
+		return nil
+	}
+}
+
+// checkDigest returns an error if neither the digest ref resolves to nor, for multi-platform artifacts, the one of
+// the manifest for the given platform matches the expected digest. It returns ref pinned to the expected digest.
+func (o *pullOptions) checkDigest(ctx context.Context, ref string, client *auth.Client, os, arch string) (string, error) {
+	expected, err := digest.Parse(o.expectedDigest)
+	if err != nil {
+		return "", fmt.Errorf("invalid expected digest %q: %w", o.expectedDigest, err)
+	}
+
+	parsedRef, err := registry.ParseReference(ref)
+	if err != nil {
+		return "", err
+	}
+	if parsedRef.Reference == "" {
+		parsedRef.Reference = oci.DefaultTag
+	}
+
+	desc, err := oci.Resolve(ctx, parsedRef.String(), client)
+	if err != nil {
+		return "", err
+	}
+	actual := desc.Digest.String()
+
+	if desc.Digest != expected && oci.IsIndex(desc.MediaType) {
+		platformDesc, err := oci.ResolvePlatform(ctx, parsedRef.String(), client, os, arch)
+		if err != nil {
+			return "", err
+		}
+		if platformDesc.Digest == expected {
+			desc = platformDesc
+		} else {
+			actual = fmt.Sprintf("%s (manifest for %s/%s: %s)", desc.Digest, os, arch, platformDesc.Digest)
+		}
+	}
+
+	if desc.Digest != expected {
+		return "", fmt.Errorf("digest mismatch for artifact %q: expected %s, got %s", ref, expected, actual)
+	}
+
+	o.Printer.Verbosef("Digest of artifact %q matches the expected one %s", ref, expected)
+	parsedRef.Reference = expected.String()
+
+	return parsedRef.String(), nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+func TestUniqueFilename(t *testing.T) {
+	testCases := []struct {
+		filename string
+		taken    map[string]bool
+		expected string
+	}{
+		{filename: "rules.tar.gz", taken: map[string]bool{"rules.tar.gz": true}, expected: "rules-1.tar.gz"},
+		{filename: "rules.tar.gz", taken: map[string]bool{"rules.tar.gz": true, "rules-1.tar.gz": true}, expected: "rules-2.tar.gz"},
+		{filename: "plugin.so", taken: map[string]bool{"plugin.so": true}, expected: "plugin-1.so"},
+		{filename: "README", taken: map[string]bool{"README": true}, expected: "README-1"},
+	}
+
+	for _, tc := range testCases {
+		if got := uniqueFilename(tc.filename, tc.taken); got != tc.expected {
+			t.Errorf("expected unique file name %q for %q, got %q", tc.expected, tc.filename, got)
+		}
+	}
+}
+
+func TestDestFilenames(t *testing.T) {
+	args := []string{
+		"localhost:5000/myrules:latest",
+		"localhost:5000/otherrules:latest",
+		"localhost:5000/plugin:latest",
+		"localhost:5000/thirdrules:latest",
+	}
+	pulled := []string{"rules.tar.gz", "rules.tar.gz", "plugin.tar.gz", "rules.tar.gz"}
+
+	testCases := []struct {
+		onConflict string
+		expected   []string
+		wantErr    bool
+	}{
+		{onConflict: conflictError, wantErr: true},
+		{onConflict: conflictOverwrite, expected: pulled},
+		{onConflict: conflictRename, expected: []string{"rules.tar.gz", "rules-1.tar.gz", "plugin.tar.gz", "rules-2.tar.gz"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.onConflict, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			o := &pullOptions{
+				CommonOptions: &options.CommonOptions{Printer: output.NewPrinter("", false, out)},
+				onConflict:    tc.onConflict,
+			}
+
+			filenames, err := o.destFilenames(args, pulled)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got file names %v", filenames)
+				}
+				// The error names the conflicting file and all the references producing it.
+				for _, s := range []string{"rules.tar.gz", args[0], args[1], args[3]} {
+					if !strings.Contains(err.Error(), s) {
+						t.Errorf("expected the error to contain %q, got %q", s, err.Error())
+					}
+				}
+				if strings.Contains(err.Error(), args[2]) {
+					t.Errorf("expected the error not to contain %q, got %q", args[2], err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(filenames, tc.expected) {
+				t.Errorf("expected file names %v, got %v", tc.expected, filenames)
+			}
+			if !strings.Contains(out.String(), "File name conflict") {
+				t.Errorf("expected the conflict to be reported, got output %q", out.String())
+			}
+		})
+	}
+
+	// Without conflicts the file names are kept, whatever the policy.
+	o := &pullOptions{
+		CommonOptions: &options.CommonOptions{Printer: output.NewPrinter("", false, &bytes.Buffer{})},
+		onConflict:    conflictError,
+	}
+	filenames, err := o.destFilenames(args[1:3], pulled[1:3])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(filenames, pulled[1:3]) {
+		t.Errorf("expected file names %v, got %v", pulled[1:3], filenames)
+	}
+}
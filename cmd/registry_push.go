@@ -16,17 +16,27 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
 
 	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/metrics"
 	"github.com/falcosecurity/falcoctl/pkg/oci"
 	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
 	ocipusher "github.com/falcosecurity/falcoctl/pkg/oci/pusher"
 	"github.com/falcosecurity/falcoctl/pkg/options"
 	"github.com/falcosecurity/falcoctl/pkg/output"
+	"github.com/falcosecurity/falcoctl/pkg/signature"
 )
 
 var longPush = `Push Falco "rulefile" or "plugin" OCI artifacts to remote registry
@@ -45,6 +55,24 @@ Example - Push artifact "myplugin.tar.gz" of type "plugin" for multiple platform
 Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile":
 	falcoctl registry push --type rulesfile localhost:5000/myrulesfile:latest myrulesfile.tar.gz
 
+Example - Push the rules files contained in the "rules" directory as artifact of type "rulesfile":
+	falcoctl registry push --type rulesfile localhost:5000/myrulesfile:latest rules/
+
+Example - Push the rules files matching "rules/*.yaml" as artifact of type "rulesfile":
+	falcoctl registry push --type rulesfile localhost:5000/myrulesfile:latest "rules/*.yaml"
+
+Example - Push artifact of type "rulesfile" reusing as layer the blob "sha256:123abc..." already stored in the repository:
+	falcoctl registry push --type rulesfile localhost:5000/myrulesfile:latest @sha256:123abc...
+
+Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" with an additional tag derived from the git repository in the current directory:
+	falcoctl registry push --type rulesfile localhost:5000/myrulesfile:latest myrulesfile.tar.gz --tags-from-git
+
+Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" tagged as "1.2.3", "1.2", "1" and "latest":
+	falcoctl registry push --type rulesfile localhost:5000/myrulesfile:1.2.3 myrulesfile.tar.gz --version 1.2.3
+
+Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" tagged as "1.2.3" and "1.2" only:
+	falcoctl registry push --type rulesfile localhost:5000/myrulesfile:1.2.3 myrulesfile.tar.gz --version 1.2.3 --version-tags full,minor
+
 Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" with a dependency "myplugin:1.2.3":
 	falcoctl registry push --type rulesfile localhost:5000/myrulesfile:latest myrulesfile.tar.gz --depends-on myplugin:1.2.3
 
@@ -55,15 +83,65 @@ Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" with multiple d
     falcoctl registry push --type rulesfile localhost:5000/myrulesfile:latest myrulesfile.tar.gz \
 		--depends-on myplugin:1.2.3 \
 		--depends-on otherplugin:3.2.1
+
+Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" verifying that its dependency "myplugin:1.2.3" exists:
+	falcoctl registry push --type rulesfile localhost:5000/myrulesfile:latest myrulesfile.tar.gz --depends-on myplugin:1.2.3 --check-deps
+
+Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" with the annotations in "annotations.yaml" and a description:
+	falcoctl registry push --type rulesfile localhost:5000/myrulesfile:latest myrulesfile.tar.gz \
+		--annotation-file annotations.yaml \
+		--annotation "org.opencontainers.image.description=Rules built by ${CI_JOB_ID}"
+
+Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" and print the result in YAML format:
+	falcoctl registry push --type rulesfile localhost:5000/myrulesfile:latest myrulesfile.tar.gz --output yaml
+
+Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" using the docker media types:
+	falcoctl registry push --type rulesfile localhost:5000/myrulesfile:latest myrulesfile.tar.gz --media-type-set docker
+
+Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" using sha512 digests, if supported by the registry:
+	falcoctl registry push --type rulesfile localhost:5000/myrulesfile:latest myrulesfile.tar.gz --digest-algorithm sha512
+
+Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" attaching the signature in "rules.sig", computed out of falcoctl:
+	falcoctl registry push --type rulesfile localhost:5000/myrulesfile:1.2.3 myrulesfile.tar.gz --signature rules.sig
+
+Example - Push artifact "myrulesfile.tar.gz" of type "rulesfile" and save the push as a spec in "push-spec.yaml":
+	falcoctl registry push --type rulesfile localhost:5000/myrulesfile:1.2.3 myrulesfile.tar.gz --version 1.2.3 --emit-spec push-spec.yaml
+
+Example - Push the artifact described by the spec in "push-spec.yaml", e.g. saved by a previous push with --emit-spec:
+	falcoctl registry push --spec push-spec.yaml
+
+Example - Push the collection "mybundle" referencing, by digest, the artifacts "myrules:1.0.0" and "otherrules:2.0.0":
+	falcoctl registry push --type collection localhost:5000/mybundle:latest localhost:5000/myrules:1.0.0 localhost:5000/otherrules:2.0.0
 `
 
 type pushOptions struct {
 	*options.CommonOptions
 	*options.ArtifactOptions
+	signature string
+	spec      string
+	emitSpec  string
+	// specArgs are the reference and the files read from the spec.
+	specArgs []string
 }
 
-func (o pushOptions) validate() error {
-	return o.ArtifactOptions.Validate()
+func (o *pushOptions) validate(cmd *cobra.Command) error {
+	if o.spec != "" {
+		if err := o.loadSpec(cmd); err != nil {
+			return err
+		}
+	} else if !cmd.Flags().Changed("type") {
+		return fmt.Errorf("required flag \"type\" not set")
+	}
+
+	if o.signature != "" && o.FallbackPerPlatform {
+		return fmt.Errorf("--signature cannot be used with --fallback-per-platform, since the platforms are not pushed under a single digest")
+	}
+
+	if err := o.ArtifactOptions.Validate(); err != nil {
+		return err
+	}
+
+	return o.ArtifactOptions.NormalizePlatforms()
 }
 
 func newPushProgressTracker(printer *output.Printer) ocipusher.ProgressTracker {
@@ -84,27 +162,59 @@ func NewPushCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command
 		DisableFlagsInUseLine: true,
 		Short:                 "Push a Falco OCI artifact to remote registry",
 		Long:                  longPush,
-		Args:                  cobra.MinimumNArgs(2),
-		SilenceErrors:         true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if o.spec != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.MinimumNArgs(2)(cmd, args)
+		},
+		SilenceErrors: true,
 		PreRun: func(cmd *cobra.Command, args []string) {
-			o.Printer.CheckErr(o.validate())
+			o.Printer.CheckErr(o.validate(cmd))
 		},
 		Run: func(cmd *cobra.Command, args []string) {
+			if o.spec != "" {
+				args = o.specArgs
+			}
 			o.Printer.CheckErr(o.RunPush(ctx, args))
 		},
 	}
 	o.CommonOptions.AddFlags(cmd.Flags())
+	o.CommonOptions.AddOutputFlags(cmd.Flags())
 	o.Printer.CheckErr(o.ArtifactOptions.AddFlags(cmd))
+	cmd.Flags().StringVar(&o.signature, "signature", "",
+		"file holding a signature, computed out of falcoctl, of the pushed artifact, either a DSSE envelope or a cosign signature "+
+			"in the format printed by \"cosign download signature\". It is attached to the artifact as a referrer")
+	cmd.Flags().StringVar(&o.spec, "spec", "",
+		"push the artifact described by the given spec file, as written by --emit-spec, instead of the arguments and flags")
+	cmd.Flags().StringVar(&o.emitSpec, "emit-spec", "",
+		"write the spec of the push, i.e. reference, files, type, platforms, tags, annotations, dependencies and signature, "+
+			"to the given file once the artifact is pushed. The spec can be pushed again with --spec")
 
 	return cmd
 }
 
 // RunPush executes the business logic for the push command.
 func (o *pushOptions) RunPush(ctx context.Context, args []string) error {
-	ref := args[0]
 	paths := args[1:]
 	o.Printer.Info.Printfln("Preparing to push artifact %q of type %q", args[0], o.ArtifactType)
 
+	var detached *signature.Detached
+	if o.signature != "" {
+		data, err := os.ReadFile(filepath.Clean(o.signature))
+		if err != nil {
+			return fmt.Errorf("unable to read signature: %w", err)
+		}
+		if detached, err = signature.ParseDetached(data); err != nil {
+			return fmt.Errorf("invalid signature %q: %w", o.signature, err)
+		}
+	}
+
+	ref, err := normalizeReference(o.Printer, args[0])
+	if err != nil {
+		return err
+	}
+
 	registry, err := utils.GetRegistryFromRef(ref)
 	if err != nil {
 		return err
@@ -127,26 +237,278 @@ func (o *pushOptions) RunPush(ctx context.Context, args []string) error {
 
 	client := authn.NewClient(cred)
 
+	if o.CheckDeps {
+		if err = o.checkDependencies(ctx, credentialStore); err != nil {
+			return err
+		}
+	}
+
 	pusher := ocipusher.NewPusher(client, false, newPushProgressTracker(o.Printer))
 
+	tags := o.Tags
+	if o.TagsFromGit {
+		gitTags, err := utils.GitTags(ctx)
+		if err != nil {
+			o.Printer.Warning.Printfln("Unable to derive tags from git, skipping: %s", err.Error())
+		}
+		for _, t := range gitTags {
+			if !contains(tags, t) {
+				tags = append(tags, t)
+			}
+		}
+		o.Printer.Verbosef("Tags derived from git: %v", gitTags)
+	}
+
+	if o.Version != "" {
+		versionTags, err := utils.VersionTags(o.Version, o.VersionTags)
+		if err != nil {
+			return err
+		}
+		for _, t := range versionTags {
+			if !contains(tags, t) {
+				tags = append(tags, t)
+			}
+		}
+		o.Printer.Verbosef("Tags derived from version: %v", versionTags)
+	}
+
+	opts, err := pusherOptions(o.ArtifactOptions, paths)
+	if err != nil {
+		return err
+	}
+
+	if o.ArtifactType == oci.Collection {
+		members, err := o.collectionMembers(ctx, credentialStore, paths)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, ocipusher.WithCollectionMembers(members...))
+	}
+
+	opts = append(opts, ocipusher.WithTags(tags...), ocipusher.WithFallbackPerPlatform(o.FallbackPerPlatform))
+
+	res, err := pusher.Push(ctx, o.ArtifactType, ref, opts...)
+	if errors.Is(err, ocipusher.ErrIndexNotSupported) && !o.FallbackPerPlatform {
+		o.Printer.Info.Println("Use --fallback-per-platform to push each platform under its own tags")
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(res.Platforms) > 0 {
+		o.Printer.Warning.Println("The registry does not support OCI image indexes, each platform has been pushed under its own tags")
+		for _, platform := range res.Platforms {
+			o.Printer.Success.Printfln("Platform %s pushed. Digest: %q, tags: %s", platform.Platform, platform.Digest, strings.Join(platform.Tags, ", "))
+		}
+	} else {
+		o.Printer.Success.Printfln("Artifact pushed. Digest: %q", res.Digest)
+	}
+
+	if detached != nil {
+		if err = o.attachSignature(ctx, ref, client, res.Digest, detached); err != nil {
+			return err
+		}
+	}
+
+	recordMetrics(o.Printer, func(m *metrics.Metrics) {
+		m.AddBytesTransferred(res.Transferred)
+	})
+
+	if o.emitSpec != "" {
+		spec, err := options.NewPushSpec(o.ArtifactOptions, ref, paths, o.signature, o.emitSpec)
+		if err != nil {
+			return fmt.Errorf("artifact pushed, but the spec cannot be built: %w", err)
+		}
+		if err = spec.Write(o.emitSpec); err != nil {
+			return fmt.Errorf("artifact pushed, but the spec cannot be written: %w", err)
+		}
+		o.Printer.Success.Printfln("Push spec written to %q", o.emitSpec)
+	}
+
+	if o.Output.IsStructured() {
+		return o.Printer.PrintData(o.Output, res)
+	}
+
+	return nil
+}
+
+// loadSpec sets the options and the arguments of the push to the ones of the spec file. Since the spec
+// describes the whole push, it cannot be combined with the flags shaping the artifact.
+func (o *pushOptions) loadSpec(cmd *cobra.Command) error {
+	var conflicting []string
+	cmd.LocalNonPersistentFlags().Visit(func(f *pflag.Flag) {
+		switch f.Name {
+		case "spec", "output", "verbose":
+		default:
+			conflicting = append(conflicting, "--"+f.Name)
+		}
+	})
+	if len(conflicting) > 0 {
+		return fmt.Errorf("--spec cannot be used with %s, set them in the spec file instead", strings.Join(conflicting, ", "))
+	}
+
+	spec, err := options.LoadPushSpec(o.spec)
+	if err != nil {
+		return err
+	}
+
+	ref, files, sig, err := spec.Apply(o.ArtifactOptions, o.spec)
+	if err != nil {
+		return fmt.Errorf("invalid push spec %q: %w", o.spec, err)
+	}
+	o.specArgs = append([]string{ref}, files...)
+	o.signature = sig
+
+	return nil
+}
+
+// attachSignature attaches the detached signature to the artifact pushed to ref with the given digest.
+func (o *pushOptions) attachSignature(ctx context.Context, ref string, client *auth.Client, d string, detached *signature.Detached) error {
+	pinned, err := oci.PinReference(ref, d)
+	if err != nil {
+		return err
+	}
+
+	subject, err := oci.Resolve(ctx, pinned, client)
+	if err != nil {
+		return err
+	}
+
+	desc, err := signature.AttachDetached(ctx, ref, client, *subject, detached)
+	if err != nil {
+		return fmt.Errorf("artifact pushed, but the signature cannot be attached: %w", err)
+	}
+
+	o.Printer.Success.Printfln("Signature %q attached as referrer %s", o.signature, desc.Digest)
+
+	return nil
+}
+
+// pusherOptions returns the options shaping the artifact built from paths, shared by the
+// push and digest commands so that the latter computes the digest the former would produce.
+func pusherOptions(art *options.ArtifactOptions, paths []string) (ocipusher.Options, error) {
+	annotations, err := art.LoadAnnotations()
+	if err != nil {
+		return nil, err
+	}
+
 	opts := ocipusher.Options{
-		ocipusher.WithTags(o.Tags...),
-		ocipusher.WithAnnotationSource(o.AnnotationSource),
+		ocipusher.WithAnnotationSource(art.AnnotationSource),
+		ocipusher.WithAnnotations(annotations),
+		ocipusher.WithLayerAnnotationsFromFilename(art.LayerTitleFromFilename),
+		ocipusher.WithMediaTypeSet(art.MediaTypeSet),
+		ocipusher.WithAllowEmpty(art.AllowEmpty),
+		ocipusher.WithSymlinks(art.Symlinks),
+		ocipusher.WithIncludeHidden(art.IncludeHidden),
+		ocipusher.WithCompressionLevel(art.CompressionLevel),
+		ocipusher.WithDigestAlgorithm(art.DigestAlgorithm),
 	}
 
-	switch o.ArtifactType {
+	switch art.ArtifactType {
 	case oci.Plugin:
-		opts = append(opts, ocipusher.WithFilepathsAndPlatforms(paths, o.Platforms), ocipusher.WithDependencies(o.Dependencies...))
+		opts = append(opts, ocipusher.WithFilepathsAndPlatforms(paths, art.Platforms), ocipusher.WithDependencies(art.Dependencies...))
 	case oci.Rulesfile:
 		opts = append(opts, ocipusher.WithFilepaths(paths))
 	}
 
-	res, err := pusher.Push(ctx, o.ArtifactType, ref, opts...)
+	return opts, nil
+}
+
+// collectionMembers resolves the references of the members of a collection to their digests, so that the
+// collection keeps pointing to the same content even if their tags are moved, and records their types.
+func (o *pushOptions) collectionMembers(ctx context.Context, credentialStore *authn.Store, args []string) ([]oci.CollectionMember, error) {
+	members := make([]oci.CollectionMember, 0, len(args))
+	for _, arg := range args {
+		ref, err := normalizeReference(o.Printer, arg)
+		if err != nil {
+			return nil, err
+		}
+
+		parsedRef, err := registry.ParseReference(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		var version string
+		if _, err = parsedRef.Digest(); err != nil {
+			version = parsedRef.ReferenceOrDefault()
+			parsedRef.Reference = version
+			ref = parsedRef.String()
+		}
+
+		client, err := registryClient(ctx, credentialStore, ref)
+		if err != nil {
+			return nil, err
+		}
+
+		desc, err := oci.Resolve(ctx, ref, client)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve member %q: %w", arg, err)
+		}
+
+		pinned, err := oci.PinReference(ref, desc.Digest.String())
+		if err != nil {
+			return nil, err
+		}
+
+		// Only plugins are pushed as indexes, the type of the other members is read from their manifest.
+		artifactType := oci.Plugin
+		if !oci.IsIndex(desc.MediaType) {
+			manifest, err := oci.FetchManifest(ctx, pinned, client, "", "")
+			if err != nil {
+				return nil, fmt.Errorf("unable to fetch the manifest of member %q: %w", arg, err)
+			}
+			if artifactType, err = oci.ArtifactTypeOf(manifest); err != nil {
+				return nil, fmt.Errorf("member %q: %w", arg, err)
+			}
+		}
+
+		o.Printer.Verbosef("Member %q resolved to %q", arg, pinned)
+		members = append(members, oci.CollectionMember{Ref: pinned, Version: version, Type: artifactType})
+	}
+
+	return members, nil
+}
+
+// checkDependencies verifies that each dependency, or at least one of its alternatives, can be resolved
+// through the configured indexes, with the same rule applied when installing the artifact.
+func (o *pushOptions) checkDependencies(ctx context.Context, credentialStore *authn.Store) error {
+	if len(o.Dependencies) == 0 {
+		return nil
+	}
+
+	var config oci.ArtifactConfig
+	if err := config.ParseDependencies(o.Dependencies...); err != nil {
+		return fmt.Errorf("%s: %w", err.Error(), ocipusher.ErrInvalidDependenciesFormat)
+	}
+
+	indexConfig, err := index.NewConfig(indexesFile)
+	if err != nil {
+		return err
+	}
+
+	mergedIndexes, err := utils.Indexes(indexConfig, falcoctlPath)
 	if err != nil {
 		return err
 	}
 
-	o.Printer.Success.Printfln("Artifact pushed. Digest: %q", res.Digest)
+	resolver := newDependencyResolver(o.Printer, credentialStore, mergedIndexes)
+	for i := range config.Dependencies {
+		resolution := resolver.resolveDependency(ctx, &config.Dependencies[i])
+		if resolution.Status == dependencyUnsatisfiable {
+			return fmt.Errorf("unable to resolve dependency %q", resolution.Dependency)
+		}
+		o.Printer.Verbosef("Dependency %q resolved to %s:%s", resolution.Dependency, resolution.Selected, resolution.Version)
+	}
 
 	return nil
 }
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,280 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+const (
+	// dependencySatisfied is the status of a dependency satisfied by the artifact it names.
+	dependencySatisfied = "satisfied"
+	// dependencyAlternative is the status of a dependency satisfied by one of its alternatives.
+	dependencyAlternative = "alternative"
+	// dependencyUnsatisfiable is the status of a dependency satisfied neither by the artifact it names nor by its alternatives.
+	dependencyUnsatisfiable = "unsatisfiable"
+)
+
+var longResolveDeps = `Preview how the dependencies of an artifact would be resolved
+
+The dependencies stored in the config of the artifact, for the platform where falcoctl is running or
+the one set with --platform, are resolved against the artifacts of the configured indexes, without
+installing anything. For each dependency, the artifact it names and then its alternatives, in the
+order they are declared, are looked up: the first one available in a compatible version is selected.
+A version is compatible with the required one if it is the same or a greater one with the same major
+version or, for 0.x versions, the same minor version, the highest compatible version being selected.
+
+The selected artifact and version are reported for each dependency, marked as "satisfied" if it is the
+artifact named by the dependency, "alternative" if it is one of its alternatives. The command exits
+with a non-zero exit code if any dependency is "unsatisfiable".
+
+Example - Preview the resolution of the dependencies of a rulesfile before publishing it:
+	falcoctl registry resolve-deps localhost:5000/myrulesfile:0.1.0
+`
+
+type resolveDepsOptions struct {
+	*options.CommonOptions
+	platform string
+}
+
+// dependencyResolution is the resolution of a dependency, as reported by the registry resolve-deps command.
+type dependencyResolution struct {
+	Dependency string `json:"dependency" yaml:"dependency"`
+	Selected   string `json:"selected,omitempty" yaml:"selected,omitempty"`
+	Version    string `json:"version,omitempty" yaml:"version,omitempty"`
+	Status     string `json:"status" yaml:"status"`
+}
+
+// NewResolveDepsCmd returns the registry resolve-deps command.
+func NewResolveDepsCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := resolveDepsOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "resolve-deps name|ref [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Preview how the dependencies of an artifact would be resolved",
+		Long:                  longResolveDeps,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunResolveDeps(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.platform, "platform", "",
+		"os and architecture of the artifact in OS/ARCH format. Defaults to the platform where falcoctl is running")
+	o.CommonOptions.AddOutputFlags(cmd.Flags())
+
+	return cmd
+}
+
+// RunResolveDeps executes the business logic for the registry resolve-deps command.
+func (o *resolveDepsOptions) RunResolveDeps(ctx context.Context, args []string) error {
+	goos, goarch := runtime.GOOS, runtime.GOARCH
+	if o.platform != "" {
+		platform, err := oci.ParsePlatform(o.platform)
+		if err != nil {
+			return err
+		}
+		goos, goarch = platform.OS, platform.Architecture
+	}
+
+	indexConfig, err := index.NewConfig(indexesFile)
+	if err != nil {
+		return err
+	}
+
+	mergedIndexes, err := utils.Indexes(indexConfig, falcoctlPath)
+	if err != nil {
+		return err
+	}
+
+	ref, err := utils.ParseReference(mergedIndexes, args[0])
+	if err != nil {
+		return err
+	}
+
+	if ref, err = rewriteReference(o.Printer, ref); err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	client, err := registryClient(ctx, credentialStore, ref)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := oci.FetchManifest(ctx, ref, client, goos, goarch)
+	if err != nil {
+		return err
+	}
+
+	config, err := oci.FetchArtifactConfig(ctx, ref, client, manifest)
+	if err != nil {
+		return err
+	}
+
+	resolver := newDependencyResolver(o.Printer, credentialStore, mergedIndexes)
+
+	resolutions := make([]dependencyResolution, 0, len(config.Dependencies))
+	var unsatisfiable int
+	for i := range config.Dependencies {
+		resolution := resolver.resolveDependency(ctx, &config.Dependencies[i])
+		if resolution.Status == dependencyUnsatisfiable {
+			unsatisfiable++
+		}
+		resolutions = append(resolutions, resolution)
+	}
+
+	if o.Output.IsStructured() {
+		if err = o.Printer.PrintData(o.Output, resolutions); err != nil {
+			return err
+		}
+	} else if len(resolutions) == 0 {
+		o.Printer.Info.Printfln("%q has no dependencies", ref)
+	} else {
+		data := make([][]string, 0, len(resolutions))
+		for _, r := range resolutions {
+			data = append(data, []string{r.Dependency, r.Selected, r.Version, r.Status})
+		}
+		if err = o.Printer.PrintTable(output.DependencyResolution, data); err != nil {
+			return err
+		}
+	}
+
+	if unsatisfiable > 0 {
+		o.Printer.Error.Printfln("%d dependency(ies) of %q cannot be satisfied", unsatisfiable, ref)
+		return output.ErrSilentExit
+	}
+
+	return nil
+}
+
+// dependencyResolver resolves the dependencies against the configured indexes, listing the versions of each
+// artifact once. It is shared by all the commands checking dependencies, so that they apply the same rule.
+type dependencyResolver struct {
+	printer         *output.Printer
+	credentialStore *authn.Store
+	mergedIndexes   *index.MergedIndexes
+	versions        map[string][]string
+}
+
+func newDependencyResolver(printer *output.Printer, credentialStore *authn.Store, mergedIndexes *index.MergedIndexes) *dependencyResolver {
+	return &dependencyResolver{
+		printer:         printer,
+		credentialStore: credentialStore,
+		mergedIndexes:   mergedIndexes,
+		versions:        make(map[string][]string),
+	}
+}
+
+// dependencyCandidate is an artifact, along with the required version, satisfying a dependency.
+type dependencyCandidate struct {
+	name, version string
+}
+
+// dependencyCandidates returns the artifact required by dep followed by its alternatives.
+func dependencyCandidates(dep *oci.ArtifactDependency) []dependencyCandidate {
+	candidates := []dependencyCandidate{{dep.Name, dep.Version}}
+	for _, alt := range dep.Alternatives {
+		candidates = append(candidates, dependencyCandidate{alt.Name, alt.Version})
+	}
+	return candidates
+}
+
+// resolveDependency resolves dep to the first of its candidates having a version that would be selected.
+func (r *dependencyResolver) resolveDependency(ctx context.Context, dep *oci.ArtifactDependency) dependencyResolution {
+	candidates := dependencyCandidates(dep)
+	names := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		names = append(names, c.name+":"+c.version)
+	}
+	resolution := dependencyResolution{Dependency: strings.Join(names, "|"), Status: dependencyUnsatisfiable}
+
+	for i, c := range candidates {
+		version, ok := r.resolve(ctx, c.name, c.version)
+		if !ok {
+			continue
+		}
+		resolution.Selected, resolution.Version, resolution.Status = c.name, version, dependencySatisfied
+		if i > 0 {
+			resolution.Status = dependencyAlternative
+		}
+		break
+	}
+
+	return resolution
+}
+
+// resolve returns the version of the artifact name that would be selected for a dependency on the required version.
+func (r *dependencyResolver) resolve(ctx context.Context, name, required string) (string, bool) {
+	versions, ok := r.versions[name]
+	if !ok {
+		var err error
+		if versions, err = r.listVersions(ctx, name); err != nil {
+			r.printer.Verbosef("Unable to list the versions of %q: %s", name, err.Error())
+		}
+		r.versions[name] = versions
+	}
+
+	version, ok := oci.SelectVersion(required, versions)
+	if !ok {
+		r.printer.Verbosef("No version of %q compatible with %s", name, required)
+	}
+
+	return version, ok
+}
+
+func (r *dependencyResolver) listVersions(ctx context.Context, name string) ([]string, error) {
+	ref, err := utils.ParseReference(r.mergedIndexes, name)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedRef, err := registry.ParseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	parsedRef.Reference = ""
+
+	repo, err := rewriteReference(r.printer, parsedRef.String())
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := registryClient(ctx, r.credentialStore, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return oci.Versions(ctx, repo, client)
+}
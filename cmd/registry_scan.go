@@ -0,0 +1,210 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/oci/registryapi"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+// scanPollInterval is the interval between two checks of the status of a scan.
+const scanPollInterval = 5 * time.Second
+
+// severities are the vulnerability severities accepted by --fail-on, from the highest.
+var severities = []string{"critical", "high", "medium", "low"}
+
+var longScan = `Trigger the vulnerability scan of an artifact stored in a Harbor registry
+
+The credentials stored by "falcoctl registry login" for the registry are used for the Harbor API.
+With --wait, the command waits for the scan to complete, up to --timeout, and prints the number of
+vulnerabilities found for each severity. With --fail-on, it exits with code 1 if vulnerabilities
+of the given severity or higher are found, which makes it suitable as a gate before promoting an artifact.
+
+Example - Trigger the scan of a plugin:
+	falcoctl registry scan harbor.corp/myproject/myplugin:0.1.0
+
+Example - Scan a plugin and fail if any high or critical vulnerability is found:
+	falcoctl registry scan harbor.corp/myproject/myplugin:0.1.0 --wait --timeout 5m --fail-on high
+`
+
+type scanOptions struct {
+	*options.CommonOptions
+	wait    bool
+	timeout time.Duration
+	failOn  string
+}
+
+func (o *scanOptions) validate() error {
+	if o.failOn == "" {
+		return nil
+	}
+
+	if !o.wait {
+		return fmt.Errorf("--fail-on requires --wait")
+	}
+
+	for _, s := range severities {
+		if o.failOn == s {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("severity %q not supported, allowed values: %q", o.failOn, severities)
+}
+
+// NewScanCmd returns the scan command.
+func NewScanCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := scanOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "scan hostname/repo[:tag|@digest] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Trigger the vulnerability scan of an artifact stored in a Harbor registry",
+		Long:                  longScan,
+		Args:                  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunScan(ctx, args))
+		},
+	}
+
+	o.CommonOptions.AddOutputFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&o.wait, "wait", false, "wait for the scan to complete and print its results")
+	cmd.Flags().DurationVar(&o.timeout, "timeout", 5*time.Minute, "maximum time to wait for the scan to complete. Used with --wait")
+	cmd.Flags().StringVar(&o.failOn, "fail-on", "",
+		`exit with code 1 if vulnerabilities of the given severity or higher are found. Allowed values: "critical", "high", "medium", "low"`)
+
+	return cmd
+}
+
+// RunScan executes the business logic for the scan command.
+func (o *scanOptions) RunScan(ctx context.Context, args []string) error {
+	parsedRef, err := registry.ParseReference(args[0])
+	if err != nil {
+		return err
+	}
+	if parsedRef.Reference == "" {
+		parsedRef.Reference = oci.DefaultTag
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	cred, err := credentialStore.Credential(ctx, parsedRef.Registry)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve credentials for registry %q: %w", parsedRef.Registry, err)
+	}
+
+	client := registryapi.NewClient("https://"+parsedRef.Registry, registryapi.Harbor, cred)
+
+	if err = client.Scan(ctx, parsedRef.Repository, parsedRef.Reference); err != nil {
+		return fmt.Errorf("unable to trigger the scan of %q: %w", args[0], err)
+	}
+	o.Printer.Success.Printfln("Scan of %q triggered", args[0])
+
+	if !o.wait {
+		return nil
+	}
+
+	summary, err := o.waitScan(ctx, client, parsedRef.Repository, parsedRef.Reference)
+	if err != nil {
+		return fmt.Errorf("scan of %q: %w", args[0], err)
+	}
+
+	if o.Output.IsStructured() {
+		if err = o.Printer.PrintData(o.Output, summary); err != nil {
+			return err
+		}
+	} else {
+		data := [][]string{
+			{"critical", strconv.Itoa(summary.Critical)},
+			{"high", strconv.Itoa(summary.High)},
+			{"medium", strconv.Itoa(summary.Medium)},
+			{"low", strconv.Itoa(summary.Low)},
+			{"unknown", strconv.Itoa(summary.Unknown)},
+		}
+		if err = o.Printer.PrintTable(output.VulnerabilitySummary, data); err != nil {
+			return err
+		}
+	}
+
+	if o.failOn != "" && vulnerabilitiesAtLeast(summary, o.failOn) > 0 {
+		o.Printer.Error.Printfln("Vulnerabilities of severity %q or higher found in %q", o.failOn, args[0])
+		return output.ErrSilentExit
+	}
+
+	return nil
+}
+
+// waitScan polls the status of the scan until it is over or the timeout expires.
+func (o *scanOptions) waitScan(ctx context.Context, client *registryapi.Client, repository, reference string) (*registryapi.ScanSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(scanPollInterval)
+	defer ticker.Stop()
+
+	for {
+		summary, err := client.ScanSummary(ctx, repository, reference)
+		switch {
+		case err != nil:
+			o.Printer.Verbosef("Unable to get the scan status, retrying: %s", err.Error())
+		case summary.Status == registryapi.ScanStatusSuccess:
+			return summary, nil
+		case summary.Done():
+			return nil, fmt.Errorf("scan ended with status %q", summary.Status)
+		default:
+			o.Printer.Verbosef("Scan status: %s", summary.Status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("scan not completed within %s: %w", o.timeout, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// vulnerabilitiesAtLeast returns the number of vulnerabilities of the given severity or higher.
+func vulnerabilitiesAtLeast(summary *registryapi.ScanSummary, severity string) int {
+	counts := []int{summary.Critical, summary.High, summary.Medium, summary.Low}
+
+	var total int
+	for i, s := range severities {
+		total += counts[i]
+		if s == severity {
+			break
+		}
+	}
+
+	return total
+}
@@ -0,0 +1,134 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/oci/registryapi"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+// Repository visibilities accepted by the set-visibility command.
+const (
+	visibilityPublic  = "public"
+	visibilityPrivate = "private"
+)
+
+var longSetVisibility = `Change the visibility of a repository through the registry admin API
+
+The credentials stored by "falcoctl registry login" for the registry are used, they must grant
+admin permissions on the repository. Harbor uses them for basic authentication, while Quay expects
+an OAuth access token stored as password. Once changed, the visibility is read back to verify it.
+
+On Harbor the visibility is set on the project, i.e. the first component of the repository, hence
+it applies to all the repositories of the project. Supported registry types: "harbor", "quay".
+
+Example - Make a plugin hosted on Harbor public:
+	falcoctl registry set-visibility harbor.corp/myproject/myplugin public --registry-type harbor
+
+Example - Make a plugin hosted on Quay private:
+	falcoctl registry set-visibility quay.io/myorg/myplugin private --registry-type quay
+`
+
+type setVisibilityOptions struct {
+	*options.CommonOptions
+	registryType string
+}
+
+func (o *setVisibilityOptions) validate(args []string) error {
+	if o.registryType == "" {
+		return fmt.Errorf("--registry-type must be set")
+	}
+
+	if args[1] != visibilityPublic && args[1] != visibilityPrivate {
+		return fmt.Errorf("visibility %q not supported, allowed values: %q, %q", args[1], visibilityPublic, visibilityPrivate)
+	}
+
+	return nil
+}
+
+// NewSetVisibilityCmd returns the set-visibility command.
+func NewSetVisibilityCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := setVisibilityOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "set-visibility hostname/repo public|private [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Change the visibility of a repository",
+		Long:                  longSetVisibility,
+		Args:                  cobra.ExactArgs(2),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate(args))
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunSetVisibility(ctx, args))
+		},
+	}
+
+	cmd.Flags().StringVar(&o.registryType, "registry-type", "", `type of the registry. Allowed values: "harbor", "quay"`)
+
+	return cmd
+}
+
+// RunSetVisibility executes the business logic for the set-visibility command.
+func (o *setVisibilityOptions) RunSetVisibility(ctx context.Context, args []string) error {
+	parsedRef, err := registry.ParseReference(args[0])
+	if err != nil {
+		return err
+	}
+	public := args[1] == visibilityPublic
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	cred, err := credentialStore.Credential(ctx, parsedRef.Registry)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve credentials for registry %q: %w", parsedRef.Registry, err)
+	}
+
+	client := registryapi.NewClient("https://"+parsedRef.Registry, o.registryType, cred)
+
+	o.Printer.Info.Printfln("Setting visibility of %q to %s", args[0], args[1])
+	err = client.SetVisibility(ctx, parsedRef.Repository, public)
+	if errors.Is(err, registryapi.ErrUnsupportedRegistry) {
+		return fmt.Errorf("changing the visibility of %q registries is not supported, use the registry UI instead", o.registryType)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to set visibility of %q: %w", args[0], err)
+	}
+
+	current, err := client.Visibility(ctx, parsedRef.Repository)
+	if err != nil {
+		return fmt.Errorf("unable to verify visibility of %q: %w", args[0], err)
+	}
+	if current != public {
+		return fmt.Errorf("visibility of %q was not changed, check the permissions of the credentials", args[0])
+	}
+
+	o.Printer.Success.Printfln("Repository %q is now %s", args[0], args[1])
+
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+	"github.com/falcosecurity/falcoctl/pkg/output"
+)
+
+var longUsage = `Report the storage occupied by the artifacts of a repository
+
+All the tags of the repository are enumerated, along with their platforms and referrers, such as
+signatures and SBOMs. The size of each tag is the sum of the sizes of the unique blobs reachable
+from it, manifests included. The total size counts the blobs shared by several tags only once,
+hence it is usually lower than the sum of the sizes of the tags. Untagged manifests not referring
+to a tagged one cannot be enumerated and are not counted.
+
+Example - Report the storage usage of a plugin repository:
+	falcoctl registry usage ghcr.io/falcosecurity/plugins/plugin/cloudtrail
+
+Example - Report the storage usage in json format:
+	falcoctl registry usage ghcr.io/falcosecurity/plugins/plugin/cloudtrail --output json
+`
+
+type usageOptions struct {
+	*options.CommonOptions
+}
+
+// NewUsageCmd returns the usage command.
+func NewUsageCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := usageOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "usage hostname/repo [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Report the storage occupied by the artifacts of a repository",
+		Long:                  longUsage,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunUsage(ctx, args))
+		},
+	}
+
+	o.CommonOptions.AddOutputFlags(cmd.Flags())
+
+	return cmd
+}
+
+// RunUsage executes the business logic for the usage command.
+func (o *usageOptions) RunUsage(ctx context.Context, args []string) error {
+	ref := args[0]
+
+	reg, err := utils.GetRegistryFromRef(ref)
+	if err != nil {
+		return err
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	cred, err := credentialStore.Credential(ctx, reg)
+	if err != nil {
+		return err
+	}
+
+	usage, err := oci.Usage(ctx, ref, authn.NewClient(cred))
+	if err != nil && !errors.Is(err, oci.ErrIncompleteReferrers) {
+		return err
+	}
+	if err != nil {
+		o.Printer.Warning.Printfln("the referrers of some tags could not be retrieved, the usage may be underestimated: %s", err.Error())
+	}
+
+	if o.Output.IsStructured() {
+		return o.Printer.PrintData(o.Output, usage)
+	}
+
+	if len(usage.Tags) == 0 {
+		o.Printer.Info.Printfln("No tag found in %q", usage.Repository)
+		return nil
+	}
+
+	var data [][]string
+	for _, tag := range usage.Tags {
+		data = append(data, []string{tag.Tag, tag.Digest, strconv.Itoa(tag.Blobs), output.FormatSize(tag.Size)})
+	}
+	if err = o.Printer.PrintTable(output.RepositoryUsage, data); err != nil {
+		return err
+	}
+
+	o.Printer.DefaultText.Printfln("Total: %s in %d unique blobs", output.FormatSize(usage.Size), usage.Blobs)
+
+	return nil
+}
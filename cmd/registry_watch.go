@@ -0,0 +1,237 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
+	ocipuller "github.com/falcosecurity/falcoctl/pkg/oci/puller"
+	"github.com/falcosecurity/falcoctl/pkg/options"
+)
+
+var longWatch = `Watch a tag and react when the digest it points to changes
+
+The tag is resolved every --interval. When its digest changes, an event is printed, in the format
+set by --output, and the configured actions are run: with --pull the new content is pulled, by
+digest, in --dest-dir for the platform where falcoctl is running, and with --exec the given command
+is run by "sh -c" with the FALCOCTL_WATCH_REF, FALCOCTL_WATCH_DIGEST and FALCOCTL_WATCH_PREVIOUS_DIGEST
+environment variables set. The digest found when the command starts is not an event.
+
+Errors resolving the tag, e.g. registry outages, and failed actions are reported as warnings and
+the tag keeps being watched. The command runs until interrupted.
+
+Example - Print an event each time the "latest" tag of a rulesfile moves:
+	falcoctl registry watch ghcr.io/falcosecurity/rules/falco-rules:latest --interval 1m
+
+Example - Pull the new content of a tag and reload Falco:
+	falcoctl registry watch ghcr.io/myorg/rules/custom-rules:1 --pull --dest-dir /etc/falco/rules.d \
+		--exec "systemctl reload falco"
+`
+
+// minWatchInterval is the minimum interval between two resolutions of the watched tag.
+const minWatchInterval = time.Second
+
+type watchOptions struct {
+	*options.CommonOptions
+	interval time.Duration
+	exec     string
+	pull     bool
+	destDir  string
+}
+
+// watchEvent is the change of the digest pointed by a watched tag.
+type watchEvent struct {
+	Ref            string `json:"ref" yaml:"ref"`
+	PreviousDigest string `json:"previousDigest" yaml:"previousDigest"`
+	Digest         string `json:"digest" yaml:"digest"`
+	Timestamp      string `json:"timestamp" yaml:"timestamp"`
+}
+
+func (o *watchOptions) validate() error {
+	if o.interval < minWatchInterval {
+		return fmt.Errorf("--interval must be at least %s", minWatchInterval)
+	}
+
+	return nil
+}
+
+// NewWatchCmd returns the watch command.
+func NewWatchCmd(ctx context.Context, opt *options.CommonOptions) *cobra.Command {
+	o := watchOptions{
+		CommonOptions: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "watch hostname/repo[:tag] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Watch a tag and react when the digest it points to changes",
+		Long:                  longWatch,
+		Args:                  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.validate())
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Printer.CheckErr(o.RunWatch(ctx, args))
+		},
+	}
+
+	o.CommonOptions.AddOutputFlags(cmd.Flags())
+	cmd.Flags().DurationVar(&o.interval, "interval", 30*time.Second, "interval between two resolutions of the tag")
+	cmd.Flags().StringVar(&o.exec, "exec", "", "command run by \"sh -c\" when the digest changes")
+	cmd.Flags().BoolVar(&o.pull, "pull", false, "pull the new content when the digest changes")
+	cmd.Flags().StringVarP(&o.destDir, "dest-dir", "o", "",
+		"destination dir where to pull the new content, used with --pull (default: current directory)")
+
+	return cmd
+}
+
+// RunWatch executes the business logic for the watch command.
+func (o *watchOptions) RunWatch(ctx context.Context, args []string) error {
+	ref, err := normalizeReference(o.Printer, args[0])
+	if err != nil {
+		return err
+	}
+	if ref, err = rewriteReference(o.Printer, ref); err != nil {
+		return err
+	}
+
+	parsedRef, err := registry.ParseReference(ref)
+	if err != nil {
+		return err
+	}
+	if _, err = parsedRef.Digest(); err == nil {
+		return fmt.Errorf("%q is pinned by digest, which never changes: watch a tag instead", args[0])
+	}
+	if parsedRef.Reference == "" {
+		parsedRef.Reference = oci.DefaultTag
+		ref = parsedRef.String()
+	}
+
+	credentialStore, err := authn.NewStore([]string{}...)
+	if err != nil {
+		return err
+	}
+
+	client, err := registryClient(ctx, credentialStore, ref)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	var current string
+	var failures int
+	for {
+		desc, err := oci.Resolve(ctx, ref, client)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			// Interrupted, not a registry failure.
+		case err != nil:
+			failures++
+			o.Printer.Warning.Printfln("Unable to resolve %q (%d consecutive failures), retrying in %s: %s", ref, failures, o.interval, err.Error())
+		case current == "":
+			failures = 0
+			current = desc.Digest.String()
+			o.Printer.Info.Printfln("Watching %q every %s, current digest: %s", ref, o.interval, current)
+		case desc.Digest.String() != current:
+			failures = 0
+			event := watchEvent{
+				Ref:            ref,
+				PreviousDigest: current,
+				Digest:         desc.Digest.String(),
+				Timestamp:      time.Now().Format(timeFormat),
+			}
+			current = event.Digest
+			if err = o.onChange(ctx, client, &event); err != nil {
+				return err
+			}
+		default:
+			failures = 0
+			o.Printer.Verbosef("Digest of %q unchanged: %s", ref, current)
+		}
+
+		select {
+		case <-ctx.Done():
+			o.Printer.Info.Printfln("Stopped watching %q", ref)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// onChange reports the event and runs the configured actions. Failed actions are reported as warnings, only
+// an error printing the event is returned.
+func (o *watchOptions) onChange(ctx context.Context, client *auth.Client, event *watchEvent) error {
+	if o.Output.IsStructured() {
+		if err := o.Printer.PrintData(o.Output, event); err != nil {
+			return err
+		}
+	} else {
+		o.Printer.Info.Printfln("Digest of %q changed from %s to %s", event.Ref, event.PreviousDigest, event.Digest)
+	}
+
+	if o.pull {
+		if err := o.pullDigest(ctx, client, event); err != nil {
+			o.Printer.Warning.Printfln("Unable to pull %s: %s", event.Digest, err.Error())
+		}
+	}
+
+	if o.exec != "" {
+		cmd := exec.CommandContext(ctx, "sh", "-c", o.exec) //nolint:gosec // the command is set by the user
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Env = append(os.Environ(),
+			"FALCOCTL_WATCH_REF="+event.Ref,
+			"FALCOCTL_WATCH_DIGEST="+event.Digest,
+			"FALCOCTL_WATCH_PREVIOUS_DIGEST="+event.PreviousDigest,
+		)
+		if err := cmd.Run(); err != nil && ctx.Err() == nil {
+			o.Printer.Warning.Printfln("Command %q failed: %s", o.exec, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// pullDigest pulls the content of the event by digest, so that it matches the reported digest even if the tag
+// has moved again in the meantime.
+func (o *watchOptions) pullDigest(ctx context.Context, client *auth.Client, event *watchEvent) error {
+	pinned, err := oci.PinReference(event.Ref, event.Digest)
+	if err != nil {
+		return err
+	}
+
+	puller := ocipuller.NewPuller(client, newPullProgressTracker(o.Printer))
+	res, err := puller.Pull(ctx, pinned, o.destDir, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+
+	o.Printer.Success.Printfln("Artifact of type %q pulled. Digest: %q", res.Type, res.Digest)
+
+	return nil
+}
@@ -16,11 +16,17 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/spf13/cobra"
 
+	"github.com/falcosecurity/falcoctl/cmd/internal/utils"
+	"github.com/falcosecurity/falcoctl/pkg/config"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/authn"
 	"github.com/falcosecurity/falcoctl/pkg/options"
 	"github.com/falcosecurity/falcoctl/pkg/output"
 	"github.com/falcosecurity/falcoctl/pkg/version"
@@ -43,11 +49,25 @@ func New(ctx context.Context) *cobra.Command {
 			// Initializing the options. Subcommands can overwrite configs for the options
 			// by calling the initialize function.
 			opt.Initialize()
+			opt.Printer.CheckErr(setHeaders(opt))
+			if err := setRegistryPolicy(cmd, opt); err != nil {
+				// Fail closed, only when a registry is about to be contacted.
+				utils.SetRegistryPolicy(func(string) error { return fmt.Errorf("invalid registry policy: %w", err) })
+			}
+			oci.SetRegistryFlavor(opt.RegistryFlavor, opt.Printer.Verbosef)
+		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			// Commands failing exit before getting here, only the warnings of successful ones are checked.
+			opt.Printer.CheckErr(opt.Printer.CheckWarnings())
 		},
 	}
 
 	// Global flags
 	opt.AddFlags(rootCmd.Flags())
+	opt.AddHeaderFlags(rootCmd.PersistentFlags())
+	opt.AddStrictFlags(rootCmd.PersistentFlags())
+	opt.AddRegistryFlavorFlags(rootCmd.PersistentFlags())
+	opt.AddRegistryPolicyFlags(rootCmd.PersistentFlags())
 
 	// Commands
 	rootCmd.AddCommand(NewTLSCmd())
@@ -59,6 +79,48 @@ func New(ctx context.Context) *cobra.Command {
 	return rootCmd
 }
 
+// setHeaders configures the additional headers sent to the registry hosts in this invocation.
+func setHeaders(opt *options.CommonOptions) error {
+	if err := authn.SetHeaders(opt.Headers); err != nil {
+		return err
+	}
+
+	for _, h := range opt.Headers {
+		key, value, _ := strings.Cut(h, "=")
+		opt.Printer.Verbosef("Adding header %q to registry requests: %s", key, authn.RedactHeader(key, value))
+	}
+
+	return nil
+}
+
+// setRegistryPolicy configures the registries allowed in this invocation, read from the config file
+// unless explicitly overridden by the flags.
+func setRegistryPolicy(cmd *cobra.Command, opt *options.CommonOptions) error {
+	cfg, err := config.NewConfig(configFile)
+	if err != nil {
+		return err
+	}
+
+	policy := cfg.RegistryPolicy()
+	if cmd.Flags().Changed("allowed-registries") {
+		policy.Allowed = opt.AllowedRegistries
+	}
+	if cmd.Flags().Changed("denied-registries") {
+		policy.Denied = opt.DeniedRegistries
+	}
+
+	if err = policy.Validate(); err != nil {
+		return err
+	}
+
+	if len(policy.Allowed) > 0 || len(policy.Denied) > 0 {
+		opt.Printer.Verbosef("Registry policy: allowed %q, denied %q", policy.Allowed, policy.Denied)
+	}
+	utils.SetRegistryPolicy(policy.Check)
+
+	return nil
+}
+
 // Execute creates the root command and runs it.
 func Execute() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGKILL)
@@ -0,0 +1,224 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compatibility
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed matrix.yaml
+var defaultMatrix []byte
+
+const (
+	// FetchTimeout is the maximum time spent fetching a remote compatibility matrix.
+	FetchTimeout = 30 * time.Second
+	// MaxSize is the maximum size, in bytes, of a remote compatibility matrix.
+	MaxSize = 1 << 20
+)
+
+// ErrNotFound is returned when a Falco version is not present in the compatibility matrix.
+var ErrNotFound = errors.New("falco version not found in the compatibility matrix")
+
+// Entry maps a Falco minor release to the plugin API version it provides.
+type Entry struct {
+	Falco            string `yaml:"falco"`
+	PluginAPIVersion string `yaml:"plugin_api_version"`
+}
+
+// Matrix is the compatibility matrix between Falco releases and plugin API versions.
+type Matrix struct {
+	Entries []Entry `yaml:"entries"`
+}
+
+// Default returns the compatibility matrix bundled with falcoctl.
+func Default() (*Matrix, error) {
+	return parse(defaultMatrix)
+}
+
+// Load reads the compatibility matrix stored at path. If the file does not exist, the bundled
+// matrix is returned.
+func Load(path string) (*Matrix, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return Default()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read compatibility matrix %q: %w", path, err)
+	}
+
+	return parse(data)
+}
+
+// Fetch retrieves a remote compatibility matrix using its URL.
+func Fetch(ctx context.Context, url string) (*Matrix, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch compatibility matrix: %w", err)
+	}
+
+	client := &http.Client{Timeout: FetchTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch compatibility matrix: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s: %w", url, os.ErrNotExist)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot fetch compatibility matrix: unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read bytes from response body: %w", err)
+	}
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("compatibility matrix %s exceeds the maximum size of %d bytes", url, MaxSize)
+	}
+
+	return parse(data)
+}
+
+// Write writes the compatibility matrix to path, creating the parent directory if needed.
+func (m *Matrix) Write(path string) error {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Merge adds the entries of other to the compatibility matrix. The entries of other replace the
+// ones for the same Falco release. The entries are kept sorted by Falco release.
+func (m *Matrix) Merge(other *Matrix) {
+	for _, e := range other.Entries {
+		found := false
+		for i := range m.Entries {
+			if m.Entries[i].Falco == e.Falco {
+				m.Entries[i] = e
+				found = true
+				break
+			}
+		}
+		if !found {
+			m.Entries = append(m.Entries, e)
+		}
+	}
+
+	sort.SliceStable(m.Entries, func(i, j int) bool {
+		vi, _ := semver.ParseTolerant(m.Entries[i].Falco)
+		vj, _ := semver.ParseTolerant(m.Entries[j].Falco)
+		return vi.LT(vj)
+	})
+}
+
+// PluginAPIVersion returns the plugin API version provided by the given Falco version.
+// Only the major and minor components of the Falco version are taken into account.
+func (m *Matrix) PluginAPIVersion(falcoVersion string) (string, error) {
+	v, err := semver.ParseTolerant(falcoVersion)
+	if err != nil {
+		return "", fmt.Errorf("invalid falco version %q: %w", falcoVersion, err)
+	}
+	minor := fmt.Sprintf("%d.%d", v.Major, v.Minor)
+
+	for _, e := range m.Entries {
+		if e.Falco == minor {
+			return e.PluginAPIVersion, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s: %w", falcoVersion, ErrNotFound)
+}
+
+// Compatible reports whether a plugin requiring the plugin API version required can be loaded
+// by a Falco release providing the plugin API version provided. The major versions must be equal
+// and the provided version must be greater than or equal to the required one.
+func Compatible(required, provided string) (bool, error) {
+	req, err := semver.ParseTolerant(required)
+	if err != nil {
+		return false, fmt.Errorf("invalid required plugin API version %q: %w", required, err)
+	}
+
+	prov, err := semver.ParseTolerant(provided)
+	if err != nil {
+		return false, fmt.Errorf("invalid provided plugin API version %q: %w", provided, err)
+	}
+
+	return req.Major == prov.Major && prov.GTE(req), nil
+}
+
+// SatisfiesFalcoVersion reports whether falcoVersion satisfies requirement, which is either a minimum
+// version, e.g. "0.35.0", or a range, e.g. ">=0.35.0 <0.37.0", as supported by semver.ParseRange.
+func SatisfiesFalcoVersion(requirement, falcoVersion string) (bool, error) {
+	v, err := semver.ParseTolerant(falcoVersion)
+	if err != nil {
+		return false, fmt.Errorf("invalid falco version %q: %w", falcoVersion, err)
+	}
+
+	if minimum, err := semver.ParseTolerant(requirement); err == nil {
+		return v.GTE(minimum), nil
+	}
+
+	expectedRange, err := semver.ParseRange(requirement)
+	if err != nil {
+		return false, fmt.Errorf("invalid falco version requirement %q: %w", requirement, err)
+	}
+
+	return expectedRange(v), nil
+}
+
+func parse(data []byte) (*Matrix, error) {
+	var m Matrix
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal compatibility matrix: %w", err)
+	}
+
+	if len(m.Entries) == 0 {
+		return nil, errors.New("compatibility matrix has no entries")
+	}
+
+	for i := range m.Entries {
+		e := &m.Entries[i]
+		e.Falco = strings.TrimPrefix(e.Falco, "v")
+		if _, err := semver.ParseTolerant(e.Falco); err != nil {
+			return nil, fmt.Errorf("invalid falco version %q in compatibility matrix: %w", e.Falco, err)
+		}
+		if _, err := semver.ParseTolerant(e.PluginAPIVersion); err != nil {
+			return nil, fmt.Errorf("invalid plugin API version %q for falco %s in compatibility matrix: %w", e.PluginAPIVersion, e.Falco, err)
+		}
+	}
+
+	return &m, nil
+}
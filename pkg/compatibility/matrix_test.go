@@ -0,0 +1,188 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compatibility
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPluginAPIVersion(t *testing.T) {
+	m, err := Default()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	v, err := m.PluginAPIVersion("0.34.1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != "2.0.0" {
+		t.Errorf("expected plugin API version 2.0.0, got %s", v)
+	}
+
+	if _, err = m.PluginAPIVersion("0.1.0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if _, err = m.PluginAPIVersion("not-a-version"); err == nil {
+		t.Errorf("expected error for invalid version")
+	}
+}
+
+func TestCompatible(t *testing.T) {
+	tests := []struct {
+		required string
+		provided string
+		expected bool
+	}{
+		{"2.0.0", "2.0.0", true},
+		{"2.0.0", "2.1.0", true},
+		{"2.1.0", "2.0.0", false},
+		{"2.0.0", "3.0.0", false},
+		{"3.0.0", "2.0.0", false},
+	}
+
+	for _, tt := range tests {
+		got, err := Compatible(tt.required, tt.provided)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tt.expected {
+			t.Errorf("Compatible(%s, %s): expected %v, got %v", tt.required, tt.provided, tt.expected, got)
+		}
+	}
+}
+
+func TestSatisfiesFalcoVersion(t *testing.T) {
+	tests := []struct {
+		requirement string
+		expected    bool
+	}{
+		{"0.35.0", true},
+		{"0.36.1", false},
+		{">=0.35.0 <0.37.0", true},
+		{">=0.30.0 <0.36.0", false},
+	}
+
+	for _, tt := range tests {
+		got, err := SatisfiesFalcoVersion(tt.requirement, "0.36.0")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tt.expected {
+			t.Errorf("SatisfiesFalcoVersion(%q, 0.36.0): expected %v, got %v", tt.requirement, tt.expected, got)
+		}
+	}
+
+	if _, err := SatisfiesFalcoVersion("not a range", "0.36.0"); err == nil {
+		t.Errorf("expected error for invalid requirement")
+	}
+}
+
+func TestLoadWrite(t *testing.T) {
+	// The parent directory is created if needed.
+	path := filepath.Join(t.TempDir(), "falcoctl", "compatibility.yaml")
+
+	// A missing file falls back to the bundled matrix.
+	m, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(m.Entries) == 0 {
+		t.Fatalf("expected the bundled matrix")
+	}
+
+	m = &Matrix{Entries: []Entry{{Falco: "9.9", PluginAPIVersion: "9.0.0"}}}
+	if err = m.Write(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	m, err = Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, err := m.PluginAPIVersion("9.9.0"); err != nil || v != "9.0.0" {
+		t.Errorf("expected plugin API version 9.0.0, got %q (%v)", v, err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	m := &Matrix{Entries: []Entry{
+		{Falco: "0.35", PluginAPIVersion: "3.0.0"},
+		{Falco: "0.36", PluginAPIVersion: "3.1.0"},
+	}}
+
+	m.Merge(&Matrix{Entries: []Entry{
+		{Falco: "0.37", PluginAPIVersion: "3.2.0"},
+		{Falco: "0.36", PluginAPIVersion: "3.1.1"},
+	}})
+	m.Merge(&Matrix{Entries: []Entry{
+		{Falco: "0.34", PluginAPIVersion: "2.0.0"},
+	}})
+
+	expected := []Entry{
+		{Falco: "0.34", PluginAPIVersion: "2.0.0"},
+		{Falco: "0.35", PluginAPIVersion: "3.0.0"},
+		{Falco: "0.36", PluginAPIVersion: "3.1.1"},
+		{Falco: "0.37", PluginAPIVersion: "3.2.0"},
+	}
+	if !reflect.DeepEqual(m.Entries, expected) {
+		t.Errorf("expected entries %v, got %v", expected, m.Entries)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	testCases := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"valid", "entries:\n  - falco: \"0.37\"\n    plugin_api_version: 3.2.0\n", false},
+		{"empty", "", true},
+		{"no entries", "entries: []\n", true},
+		{"invalid version", "entries:\n  - falco: latest\n    plugin_api_version: 3.2.0\n", true},
+		{"too large", "# " + strings.Repeat("x", MaxSize) + "\n", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			m, err := Fetch(context.Background(), server.URL)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", m)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if v, err := m.PluginAPIVersion("0.37.1"); err != nil || v != "3.2.0" {
+				t.Errorf("expected plugin API version 3.2.0, got %q (%v)", v, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Framework is a regulatory or security framework.
+type Framework string
+
+const (
+	// PCIDSS is the Payment Card Industry Data Security Standard, version 4.0.
+	PCIDSS Framework = "pci-dss"
+	// SOC2 is the AICPA SOC 2 Trust Services Criteria.
+	SOC2 Framework = "soc2"
+	// NISTCSF is the NIST Cybersecurity Framework, version 1.1.
+	NISTCSF Framework = "nist-csf"
+)
+
+// Frameworks are the supported frameworks.
+var Frameworks = []Framework{PCIDSS, SOC2, NISTCSF}
+
+// ParseFramework returns the framework with the given name.
+func ParseFramework(name string) (Framework, error) {
+	for _, f := range Frameworks {
+		if string(f) == strings.ToLower(name) {
+			return f, nil
+		}
+	}
+
+	return "", fmt.Errorf("framework %q not supported, allowed values: %q", name, Frameworks)
+}
+
+// EvidenceKind is a kind of evidence available for an artifact.
+type EvidenceKind string
+
+const (
+	// EvidenceSignature is the signature of the artifact.
+	EvidenceSignature EvidenceKind = "signature"
+	// EvidenceSBOM is the SBOM attached to the artifact.
+	EvidenceSBOM EvidenceKind = "sbom"
+	// EvidenceProvenance is the SLSA provenance attestation attached to the artifact.
+	EvidenceProvenance EvidenceKind = "provenance"
+	// EvidenceVulnerabilityScan is the result of the vulnerability scan of the artifact.
+	EvidenceVulnerabilityScan EvidenceKind = "vulnerability-scan"
+)
+
+// EvidenceStatus is the outcome of the collection of a piece of evidence.
+type EvidenceStatus string
+
+const (
+	// EvidencePass means that the evidence satisfies the controls relying on it.
+	EvidencePass EvidenceStatus = "pass"
+	// EvidenceFail means that the evidence is missing or invalid.
+	EvidenceFail EvidenceStatus = "fail"
+	// EvidenceUnknown means that the evidence could not be assessed, e.g. a signature not verified for lack of a key.
+	EvidenceUnknown EvidenceStatus = "unknown"
+)
+
+// Evidence is a piece of evidence collected for an artifact.
+type Evidence struct {
+	Kind    EvidenceKind   `json:"kind" yaml:"kind"`
+	Status  EvidenceStatus `json:"status" yaml:"status"`
+	Details string         `json:"details" yaml:"details"`
+}
+
+// ControlStatus is the compliance status of a control.
+type ControlStatus string
+
+const (
+	// Compliant means that all the evidence required by the control passed.
+	Compliant ControlStatus = "compliant"
+	// NonCompliant means that at least one piece of evidence required by the control failed.
+	NonCompliant ControlStatus = "non-compliant"
+	// NotAssessed means that some evidence required by the control could not be assessed, and none failed.
+	NotAssessed ControlStatus = "not-assessed"
+)
+
+// Control is a security control of a framework, along with the evidence supporting it.
+type Control struct {
+	ID       string
+	Title    string
+	Evidence []EvidenceKind
+}
+
+// controls are the controls of each framework supported by the evidence available for artifacts.
+var controls = map[Framework][]Control{
+	PCIDSS: {
+		{ID: "6.2.1", Title: "Bespoke and custom software is developed securely", Evidence: []EvidenceKind{EvidenceProvenance}},
+		{ID: "6.3.1", Title: "Security vulnerabilities are identified and managed", Evidence: []EvidenceKind{EvidenceVulnerabilityScan}},
+		{ID: "6.3.2", Title: "An inventory of bespoke and custom software and third-party components is maintained",
+			Evidence: []EvidenceKind{EvidenceSBOM}},
+		{ID: "11.5.2", Title: "Unauthorized modification of critical files is detected", Evidence: []EvidenceKind{EvidenceSignature}},
+	},
+	SOC2: {
+		{ID: "CC6.8", Title: "The entity prevents or detects the introduction of unauthorized or malicious software",
+			Evidence: []EvidenceKind{EvidenceSignature}},
+		{ID: "CC7.1", Title: "The entity detects newly discovered vulnerabilities", Evidence: []EvidenceKind{EvidenceVulnerabilityScan}},
+		{ID: "CC8.1", Title: "The entity authorizes, designs, develops, tests and implements changes",
+			Evidence: []EvidenceKind{EvidenceProvenance}},
+		{ID: "CC9.2", Title: "The entity assesses and manages risks associated with vendors and business partners",
+			Evidence: []EvidenceKind{EvidenceSBOM}},
+	},
+	NISTCSF: {
+		{ID: "ID.AM-2", Title: "Software platforms and applications are inventoried", Evidence: []EvidenceKind{EvidenceSBOM}},
+		{ID: "ID.RA-1", Title: "Asset vulnerabilities are identified and documented", Evidence: []EvidenceKind{EvidenceVulnerabilityScan}},
+		{ID: "PR.DS-6", Title: "Integrity checking mechanisms are used to verify software integrity",
+			Evidence: []EvidenceKind{EvidenceSignature, EvidenceProvenance}},
+		{ID: "PR.IP-2", Title: "A System Development Life Cycle to manage systems is implemented",
+			Evidence: []EvidenceKind{EvidenceProvenance}},
+	},
+}
+
+// Controls returns the controls of the framework supported by the evidence available for artifacts.
+func (f Framework) Controls() []Control {
+	return controls[f]
+}
+
+// ControlResult is the compliance status of a control, along with the evidence it was assessed on.
+type ControlResult struct {
+	ID       string        `json:"id" yaml:"id"`
+	Title    string        `json:"title" yaml:"title"`
+	Status   ControlStatus `json:"status" yaml:"status"`
+	Evidence []Evidence    `json:"evidence" yaml:"evidence"`
+}
+
+// Report is the compliance report of an artifact for a framework.
+type Report struct {
+	Framework   Framework       `json:"framework" yaml:"framework"`
+	Ref         string          `json:"ref" yaml:"ref"`
+	Digest      string          `json:"digest" yaml:"digest"`
+	GeneratedAt string          `json:"generatedAt" yaml:"generatedAt"`
+	Status      ControlStatus   `json:"status" yaml:"status"`
+	Controls    []ControlResult `json:"controls" yaml:"controls"`
+}
+
+// NewReport assesses the controls of the framework on the collected evidence. Evidence required by a control
+// but not collected is considered unknown. The status of the report is the worst one among its controls.
+func NewReport(framework Framework, ref, digest, generatedAt string, evidence []Evidence) *Report {
+	byKind := make(map[EvidenceKind]Evidence, len(evidence))
+	for _, e := range evidence {
+		byKind[e.Kind] = e
+	}
+
+	report := &Report{
+		Framework:   framework,
+		Ref:         ref,
+		Digest:      digest,
+		GeneratedAt: generatedAt,
+		Status:      Compliant,
+	}
+
+	for _, control := range framework.Controls() {
+		result := ControlResult{ID: control.ID, Title: control.Title, Status: Compliant}
+		for _, kind := range control.Evidence {
+			e, ok := byKind[kind]
+			if !ok {
+				e = Evidence{Kind: kind, Status: EvidenceUnknown, Details: "not collected"}
+			}
+			result.Evidence = append(result.Evidence, e)
+			result.Status = worst(result.Status, statusOf(e.Status))
+		}
+
+		report.Status = worst(report.Status, result.Status)
+		report.Controls = append(report.Controls, result)
+	}
+
+	return report
+}
+
+// statusOf returns the status of a control relying on evidence with the given status.
+func statusOf(status EvidenceStatus) ControlStatus {
+	switch status {
+	case EvidencePass:
+		return Compliant
+	case EvidenceFail:
+		return NonCompliant
+	default:
+		return NotAssessed
+	}
+}
+
+// worst returns the worst of the two statuses.
+func worst(a, b ControlStatus) ControlStatus {
+	rank := map[ControlStatus]int{Compliant: 0, NotAssessed: 1, NonCompliant: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,105 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"testing"
+)
+
+func TestParseFramework(t *testing.T) {
+	f, err := ParseFramework("SOC2")
+	if err != nil || f != SOC2 {
+		t.Errorf("expected soc2, got %q, %v", f, err)
+	}
+
+	if _, err = ParseFramework("iso27001"); err == nil {
+		t.Errorf("expected error for an unsupported framework")
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		evidence []Evidence
+		expected ControlStatus
+		control  ControlStatus
+	}{
+		{
+			name: "all evidence passed",
+			evidence: []Evidence{
+				{Kind: EvidenceSignature, Status: EvidencePass},
+				{Kind: EvidenceSBOM, Status: EvidencePass},
+				{Kind: EvidenceProvenance, Status: EvidencePass},
+				{Kind: EvidenceVulnerabilityScan, Status: EvidencePass},
+			},
+			expected: Compliant,
+			control:  Compliant,
+		},
+		{
+			name: "provenance not verified",
+			evidence: []Evidence{
+				{Kind: EvidenceSignature, Status: EvidencePass},
+				{Kind: EvidenceSBOM, Status: EvidencePass},
+				{Kind: EvidenceProvenance, Status: EvidenceUnknown},
+				{Kind: EvidenceVulnerabilityScan, Status: EvidencePass},
+			},
+			expected: NotAssessed,
+			control:  NotAssessed,
+		},
+		{
+			name: "signature missing",
+			evidence: []Evidence{
+				{Kind: EvidenceSignature, Status: EvidenceFail},
+				{Kind: EvidenceProvenance, Status: EvidenceUnknown},
+			},
+			expected: NonCompliant,
+			control:  NonCompliant,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewReport(NISTCSF, "ghcr.io/falcosecurity/rules/falco-rules:3", "sha256:123", "", tt.evidence)
+			if report.Status != tt.expected {
+				t.Errorf("expected report status %q, got %q", tt.expected, report.Status)
+			}
+			if len(report.Controls) != len(NISTCSF.Controls()) {
+				t.Fatalf("expected %d controls, got %d", len(NISTCSF.Controls()), len(report.Controls))
+			}
+
+			// PR.DS-6 relies on both the signature and the provenance.
+			for _, c := range report.Controls {
+				if c.ID != "PR.DS-6" {
+					continue
+				}
+				if c.Status != tt.control || len(c.Evidence) != 2 {
+					t.Errorf("unexpected PR.DS-6 result %+v", c)
+				}
+			}
+		})
+	}
+}
+
+func TestNewReportEvidenceNotCollected(t *testing.T) {
+	report := NewReport(PCIDSS, "ref", "sha256:123", "", nil)
+	if report.Status != NotAssessed {
+		t.Errorf("expected status %q, got %q", NotAssessed, report.Status)
+	}
+	for _, c := range report.Controls {
+		if c.Evidence[0].Status != EvidenceUnknown {
+			t.Errorf("control %s: expected unknown evidence, got %+v", c.ID, c.Evidence[0])
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RegistryRewritesEnv is the environment variable holding additional registry rewrites,
+// in the "from=to,from=to" format. They take precedence over the ones in the config file.
+const RegistryRewritesEnv = "FALCOCTL_REGISTRY_REWRITES"
+
+// DefaultRegistryEnv is the environment variable overriding the registry used to expand short names.
+const DefaultRegistryEnv = "FALCOCTL_DEFAULT_REGISTRY"
+
+const (
+	// DockerHubRegistry is the registry used to expand short names, unless configured otherwise.
+	DockerHubRegistry = "docker.io"
+	// dockerHubHost is the host actually serving the Docker Hub registry API.
+	dockerHubHost = "registry-1.docker.io"
+	// dockerHubLibrary is the namespace of the Docker Hub official repositories.
+	dockerHubLibrary = "library"
+)
+
+// RegistryRewrite maps a prefix of the references, starting with the registry host,
+// to the one to be used instead, e.g. a pull-through proxy.
+type RegistryRewrite struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// Config is the falcoctl configuration.
+type Config struct {
+	RegistryRewrites []RegistryRewrite `yaml:"registry_rewrites"`
+	// DefaultRegistry is the registry used to expand references without a registry host. Defaults to Docker Hub.
+	DefaultRegistry string `yaml:"default_registry"`
+	// AllowedRegistries and DeniedRegistries are the patterns of the registry policy, see RegistryPolicy.
+	AllowedRegistries []string `yaml:"allowed_registries"`
+	DeniedRegistries  []string `yaml:"denied_registries"`
+}
+
+// NewConfig loads the config from a file, if it exists, and from the environment.
+func NewConfig(path string) (*Config, error) {
+	var config Config
+	file, err := os.ReadFile(filepath.Clean(path))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		if err = yaml.Unmarshal(file, &config); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal config file %q: %w", path, err)
+		}
+	}
+
+	rewrites, err := parseRegistryRewrites(os.Getenv(RegistryRewritesEnv))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", RegistryRewritesEnv, err)
+	}
+	config.RegistryRewrites = append(rewrites, config.RegistryRewrites...)
+
+	if defaultRegistry := os.Getenv(DefaultRegistryEnv); defaultRegistry != "" {
+		config.DefaultRegistry = defaultRegistry
+	}
+
+	return &config, nil
+}
+
+// RegistryPolicy returns the registry policy of the config.
+func (c *Config) RegistryPolicy() *RegistryPolicy {
+	return &RegistryPolicy{Allowed: c.AllowedRegistries, Denied: c.DeniedRegistries}
+}
+
+// RewriteReference applies to ref the registry rewrite with the longest matching prefix.
+// A prefix matches only if it is followed by a path, tag or digest separator, or by nothing.
+// It returns the rewritten reference and whether a rewrite was applied.
+func (c *Config) RewriteReference(ref string) (string, bool) {
+	var match *RegistryRewrite
+	for i := range c.RegistryRewrites {
+		rewrite := &c.RegistryRewrites[i]
+		from := strings.TrimSuffix(rewrite.From, "/")
+		if !strings.HasPrefix(ref, from) {
+			continue
+		}
+		if rest := ref[len(from):]; rest != "" && !strings.ContainsAny(rest[:1], "/:@") {
+			continue
+		}
+		if match == nil || len(from) > len(strings.TrimSuffix(match.From, "/")) {
+			match = rewrite
+		}
+	}
+
+	if match == nil {
+		return ref, false
+	}
+
+	from := strings.TrimSuffix(match.From, "/")
+	return strings.TrimSuffix(match.To, "/") + ref[len(from):], true
+}
+
+// NormalizeReference expands short names the same way the docker CLI does. A reference whose first
+// path component is not a registry host, i.e. it contains neither "." nor ":" and it is not "localhost",
+// is prefixed with the default registry. If the default registry is Docker Hub, single component names
+// are also prefixed with "library/", e.g. "falco" -> "docker.io/library/falco". Docker Hub references
+// are finally pointed to the host serving its registry API.
+// It returns the normalized reference and whether it differs from ref.
+func (c *Config) NormalizeReference(ref string) (string, bool) {
+	defaultRegistry := strings.TrimSuffix(c.DefaultRegistry, "/")
+	if defaultRegistry == "" {
+		defaultRegistry = DockerHubRegistry
+	}
+
+	normalized := ref
+	first, _, hasPath := strings.Cut(ref, "/")
+	switch {
+	case !hasPath && isDockerHub(defaultRegistry):
+		normalized = defaultRegistry + "/" + dockerHubLibrary + "/" + ref
+	case !hasPath || !isRegistryHost(first):
+		normalized = defaultRegistry + "/" + ref
+	}
+
+	if host, rest, _ := strings.Cut(normalized, "/"); isDockerHub(host) {
+		normalized = dockerHubHost + "/" + rest
+	}
+
+	return normalized, normalized != ref
+}
+
+func isRegistryHost(component string) bool {
+	return strings.ContainsAny(component, ".:") || component == "localhost"
+}
+
+func isDockerHub(host string) bool {
+	return host == DockerHubRegistry || host == "index.docker.io"
+}
+
+func parseRegistryRewrites(value string) ([]RegistryRewrite, error) {
+	var rewrites []RegistryRewrite
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+
+		from, to, ok := strings.Cut(pair, "=")
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("rewrite %q not in the \"from=to\" format", pair)
+		}
+		rewrites = append(rewrites, RegistryRewrite{From: from, To: to})
+	}
+
+	return rewrites, nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "falcoctl.yaml")
+	data := []byte(`registry_rewrites:
+  - from: ghcr.io
+    to: internal-proxy.corp/upstream/ghcr.io
+`)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(RegistryRewritesEnv, "ghcr.io/falcosecurity=mirror.corp/falcosecurity")
+
+	config, err := NewConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(config.RegistryRewrites) != 2 {
+		t.Fatal("unexpected rewrites, got:", config.RegistryRewrites)
+	}
+
+	t.Setenv(RegistryRewritesEnv, "ghcr.io")
+	if _, err = NewConfig(path); err == nil {
+		t.Fatal("expected error for malformed rewrite")
+	}
+}
+
+func TestRewriteReference(t *testing.T) {
+	config := &Config{RegistryRewrites: []RegistryRewrite{
+		{From: "ghcr.io", To: "internal-proxy.corp/upstream/ghcr.io"},
+		{From: "ghcr.io/falcosecurity/", To: "mirror.corp/falcosecurity/"},
+	}}
+
+	tests := []struct {
+		ref       string
+		expected  string
+		rewritten bool
+	}{
+		{"ghcr.io/myorg/myplugin:0.1.0", "internal-proxy.corp/upstream/ghcr.io/myorg/myplugin:0.1.0", true},
+		{"ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.6.0", "mirror.corp/falcosecurity/plugins/plugin/cloudtrail:0.6.0", true},
+		{"ghcr.io.example.com/myplugin:0.1.0", "ghcr.io.example.com/myplugin:0.1.0", false},
+		{"docker.io/myplugin:0.1.0", "docker.io/myplugin:0.1.0", false},
+	}
+
+	for _, test := range tests {
+		ref, rewritten := config.RewriteReference(test.ref)
+		if ref != test.expected || rewritten != test.rewritten {
+			t.Errorf("rewriting %q: expected %q (%t), got %q (%t)", test.ref, test.expected, test.rewritten, ref, rewritten)
+		}
+	}
+}
+
+func TestNormalizeReference(t *testing.T) {
+	tests := []struct {
+		defaultRegistry string
+		ref             string
+		expected        string
+		normalized      bool
+	}{
+		{"", "falco:0.33.0", "registry-1.docker.io/library/falco:0.33.0", true},
+		{"", "falcosecurity/rules:latest", "registry-1.docker.io/falcosecurity/rules:latest", true},
+		{"", "docker.io/falcosecurity/rules:latest", "registry-1.docker.io/falcosecurity/rules:latest", true},
+		{"", "ghcr.io/falcosecurity/rules:latest", "ghcr.io/falcosecurity/rules:latest", false},
+		{"", "localhost/rules:latest", "localhost/rules:latest", false},
+		{"", "localhost:5000/rules:latest", "localhost:5000/rules:latest", false},
+		{"ghcr.io", "falcosecurity/rules:latest", "ghcr.io/falcosecurity/rules:latest", true},
+		{"ghcr.io", "rules:latest", "ghcr.io/rules:latest", true},
+	}
+
+	for _, test := range tests {
+		config := &Config{DefaultRegistry: test.defaultRegistry}
+		ref, normalized := config.NormalizeReference(test.ref)
+		if ref != test.expected || normalized != test.normalized {
+			t.Errorf("normalizing %q: expected %q (%t), got %q (%t)", test.ref, test.expected, test.normalized, ref, normalized)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnknownKey error when a key is not a known config key.
+var ErrUnknownKey = errors.New("unknown config key")
+
+// ErrKeyNotSet error when a key is not set in the config file.
+var ErrKeyNotSet = errors.New("config key not set")
+
+// filePermissions are the permissions of the config file when created.
+const filePermissions = 0o600
+
+// Key describes a key of the config file that can be managed with Get, Set and Unset.
+type Key struct {
+	// Name is the name of the key in the config file, e.g. "default_registry".
+	Name string
+	// Description is a short description of the key and of the format of its value.
+	Description string
+	// parse validates a value and converts it to the value stored in the config file.
+	parse func(value string) (interface{}, error)
+	// format converts the value stored in a config to the format accepted by parse.
+	format func(c *Config) string
+}
+
+var keys = []Key{
+	{
+		Name:        "default_registry",
+		Description: `registry used to expand references without a registry host, e.g. "ghcr.io"`,
+		parse: func(value string) (interface{}, error) {
+			value = strings.TrimSuffix(value, "/")
+			host, _, _ := strings.Cut(value, "/")
+			if !isRegistryHost(host) || strings.Contains(value, "://") || strings.ContainsAny(value, " \t") {
+				return nil, fmt.Errorf("%q is not a registry host, e.g. \"ghcr.io\" or \"localhost:5000\"", value)
+			}
+			return value, nil
+		},
+		format: func(c *Config) string {
+			return c.DefaultRegistry
+		},
+	},
+	{
+		Name:        "registry_rewrites",
+		Description: `registry rewrites applied to the pulled references, in the "from=to,from=to" format`,
+		parse: func(value string) (interface{}, error) {
+			rewrites, err := parseRegistryRewrites(value)
+			if err != nil {
+				return nil, err
+			}
+			if len(rewrites) == 0 {
+				return nil, errors.New("no rewrite given")
+			}
+			return rewrites, nil
+		},
+		format: func(c *Config) string {
+			pairs := make([]string, 0, len(c.RegistryRewrites))
+			for _, r := range c.RegistryRewrites {
+				pairs = append(pairs, r.From+"="+r.To)
+			}
+			return strings.Join(pairs, ",")
+		},
+	},
+	{
+		Name:        "allowed_registries",
+		Description: `patterns of the only registries that can be contacted, in the "pattern,pattern" format, e.g. "ghcr.io,*.corp"`,
+		parse: func(value string) (interface{}, error) {
+			return parseRegistryPatterns(value)
+		},
+		format: func(c *Config) string {
+			return strings.Join(c.AllowedRegistries, ",")
+		},
+	},
+	{
+		Name:        "denied_registries",
+		Description: `patterns of the registries that cannot be contacted, even if allowed, in the "pattern,pattern" format`,
+		parse: func(value string) (interface{}, error) {
+			return parseRegistryPatterns(value)
+		},
+		format: func(c *Config) string {
+			return strings.Join(c.DeniedRegistries, ",")
+		},
+	},
+}
+
+// parseRegistryPatterns parses and validates comma separated registry patterns.
+func parseRegistryPatterns(value string) (interface{}, error) {
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		return nil, errors.New("no pattern given")
+	}
+
+	if err := (&RegistryPolicy{Allowed: patterns}).Validate(); err != nil {
+		return nil, err
+	}
+
+	return patterns, nil
+}
+
+// Keys returns the keys that can be managed with Get, Set and Unset, sorted by name.
+func Keys() []Key {
+	result := append([]Key(nil), keys...)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func lookupKey(name string) (*Key, error) {
+	for i := range keys {
+		if keys[i].Name == name {
+			return &keys[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%q: %w", name, ErrUnknownKey)
+}
+
+// Get returns the value of a key in the config file, in the format accepted by Set. The environment
+// is not taken into account. ErrKeyNotSet is returned if the key is not set.
+func Get(path, name string) (string, error) {
+	key, err := lookupKey(name)
+	if err != nil {
+		return "", err
+	}
+
+	doc, err := readDocument(path)
+	if err != nil {
+		return "", err
+	}
+	if findKey(doc.Content[0], name) < 0 {
+		return "", fmt.Errorf("%q: %w", name, ErrKeyNotSet)
+	}
+
+	var config Config
+	if err = doc.Decode(&config); err != nil {
+		return "", fmt.Errorf("cannot unmarshal config file %q: %w", path, err)
+	}
+
+	return key.format(&config), nil
+}
+
+// Set validates value and stores it in the config file under the given key, creating the file if needed.
+// The other keys and the comments of the file are preserved.
+func Set(path, name, value string) error {
+	key, err := lookupKey(name)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := key.parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid value for %q: %w", name, err)
+	}
+
+	var valueNode yaml.Node
+	if err = valueNode.Encode(parsed); err != nil {
+		return err
+	}
+
+	doc, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+
+	mapping := doc.Content[0]
+	if i := findKey(mapping, name); i >= 0 {
+		// Keep the comments attached to the previous value.
+		valueNode.HeadComment = mapping.Content[i+1].HeadComment
+		valueNode.LineComment = mapping.Content[i+1].LineComment
+		mapping.Content[i+1] = &valueNode
+	} else {
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, &valueNode)
+	}
+
+	return writeDocument(path, doc)
+}
+
+// Unset removes a key from the config file. ErrKeyNotSet is returned if the key is not set.
+func Unset(path, name string) error {
+	if _, err := lookupKey(name); err != nil {
+		return err
+	}
+
+	doc, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+
+	mapping := doc.Content[0]
+	i := findKey(mapping, name)
+	if i < 0 {
+		return fmt.Errorf("%q: %w", name, ErrKeyNotSet)
+	}
+	mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+
+	return writeDocument(path, doc)
+}
+
+// readDocument reads the config file as a YAML document holding a mapping, empty if the file does not exist.
+func readDocument(path string) (*yaml.Node, error) {
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	switch {
+	case os.IsNotExist(err):
+		return doc, nil
+	case err != nil:
+		return nil, err
+	}
+
+	var parsed yaml.Node
+	if err = yaml.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal config file %q: %w", path, err)
+	}
+	if len(parsed.Content) == 0 {
+		return doc, nil
+	}
+	if parsed.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("config file %q is not a YAML map", path)
+	}
+
+	return &parsed, nil
+}
+
+// findKey returns the index of a key in the content of a mapping, -1 if the key is not present.
+func findKey(mapping *yaml.Node, name string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// writeDocument checks that doc is a valid config and writes it to path atomically, replacing the file
+// with a complete temporary one, so that the config is never left half written.
+func writeDocument(path string, doc *yaml.Node) error {
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	var config Config
+	if err = yaml.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err = os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+
+	perm := os.FileMode(filePermissions)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("cannot write config file %q: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetGetUnset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "falcoctl", "falcoctl.yaml")
+
+	if _, err := Get(path, "default_registry"); !errors.Is(err, ErrKeyNotSet) {
+		t.Fatalf("expected ErrKeyNotSet without config file, got %v", err)
+	}
+
+	if err := Set(path, "default_registry", "ghcr.io/"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Set(path, "registry_rewrites", "ghcr.io=mirror.corp/ghcr.io, docker.io=mirror.corp/docker.io"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	config, err := NewConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.DefaultRegistry != "ghcr.io" || len(config.RegistryRewrites) != 2 || config.RegistryRewrites[1].To != "mirror.corp/docker.io" {
+		t.Errorf("unexpected config %+v", config)
+	}
+
+	value, err := Get(path, "registry_rewrites")
+	if err != nil || value != "ghcr.io=mirror.corp/ghcr.io,docker.io=mirror.corp/docker.io" {
+		t.Errorf("unexpected value %q (%v)", value, err)
+	}
+
+	if err = Unset(path, "default_registry"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err = Get(path, "default_registry"); !errors.Is(err, ErrKeyNotSet) {
+		t.Errorf("expected ErrKeyNotSet after unset, got %v", err)
+	}
+	if err = Unset(path, "default_registry"); !errors.Is(err, ErrKeyNotSet) {
+		t.Errorf("expected ErrKeyNotSet unsetting twice, got %v", err)
+	}
+}
+
+func TestSetPreservesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "falcoctl.yaml")
+	data := []byte(`# proxies of the upstream registries
+registry_rewrites:
+  - from: ghcr.io
+    to: internal-proxy.corp/upstream/ghcr.io
+default_registry: docker.io
+`)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Set(path, "default_registry", "localhost:5000"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(written), "# proxies of the upstream registries") ||
+		!strings.Contains(string(written), "internal-proxy.corp/upstream/ghcr.io") ||
+		!strings.Contains(string(written), "default_registry: localhost:5000") {
+		t.Errorf("unexpected config file:\n%s", written)
+	}
+}
+
+func TestSetInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "falcoctl.yaml")
+
+	tests := []struct {
+		key   string
+		value string
+		err   error
+	}{
+		{"default_registry", "https://ghcr.io", nil},
+		{"default_registry", "falcosecurity", nil},
+		{"registry_rewrites", "ghcr.io", nil},
+		{"registry_rewrites", "", nil},
+		{"concurrency", "4", ErrUnknownKey},
+	}
+
+	for _, test := range tests {
+		err := Set(path, test.key, test.value)
+		if err == nil || (test.err != nil && !errors.Is(err, test.err)) {
+			t.Errorf("setting %q to %q: unexpected error %v", test.key, test.value, err)
+		}
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no config file to be written")
+	}
+}
+
+func TestSetRegistryPatterns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "falcoctl.yaml")
+
+	if err := Set(path, "allowed_registries", "ghcr.io, *.corp"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Set(path, "denied_registries", "[untrusted.corp"); err == nil {
+		t.Error("expected error for malformed pattern")
+	}
+
+	config, err := NewConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = config.RegistryPolicy().Check("quay.io"); err == nil {
+		t.Error("expected quay.io not to be allowed")
+	}
+
+	value, err := Get(path, "allowed_registries")
+	if err != nil || value != "ghcr.io,*.corp" {
+		t.Errorf("unexpected value %q (%v)", value, err)
+	}
+}
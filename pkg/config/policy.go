@@ -0,0 +1,75 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+)
+
+// ErrRegistryNotAllowed error when a registry is not allowed by the registry policy.
+var ErrRegistryNotAllowed = errors.New("registry not allowed")
+
+// RegistryPolicy restricts the registries falcoctl can contact. The patterns are matched against the
+// registry host, including the port if any, with the syntax of path.Match, e.g. "*.corp" or "localhost:*".
+type RegistryPolicy struct {
+	// Allowed are the patterns of the only allowed registries. If empty, all the registries not denied are allowed.
+	Allowed []string
+	// Denied are the patterns of the denied registries. They take precedence over the allowed ones.
+	Denied []string
+}
+
+// Validate checks the syntax of the patterns of the policy.
+func (p *RegistryPolicy) Validate() error {
+	for _, pattern := range append(append([]string{}, p.Allowed...), p.Denied...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid registry pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// Check returns ErrRegistryNotAllowed if the policy does not allow contacting host. Docker Hub
+// patterns, i.e. "docker.io", also match the host serving its registry API.
+func (p *RegistryPolicy) Check(host string) error {
+	hosts := []string{host}
+	if host == dockerHubHost {
+		hosts = append(hosts, DockerHubRegistry)
+	}
+
+	if pattern, ok := matchRegistry(p.Denied, hosts); ok {
+		return fmt.Errorf("%w: %q is denied by pattern %q", ErrRegistryNotAllowed, host, pattern)
+	}
+
+	if _, ok := matchRegistry(p.Allowed, hosts); len(p.Allowed) > 0 && !ok {
+		return fmt.Errorf("%w: %q does not match any of the allowed registries %q", ErrRegistryNotAllowed, host, p.Allowed)
+	}
+
+	return nil
+}
+
+func matchRegistry(patterns, hosts []string) (string, bool) {
+	for _, pattern := range patterns {
+		for _, host := range hosts {
+			if ok, _ := path.Match(pattern, host); ok {
+				return pattern, true
+			}
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,57 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegistryPolicy(t *testing.T) {
+	policy := &RegistryPolicy{
+		Allowed: []string{"ghcr.io", "*.corp", "docker.io"},
+		Denied:  []string{"untrusted.corp"},
+	}
+	if err := policy.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		"ghcr.io":              true,
+		"registry.corp":        true,
+		"registry-1.docker.io": true,
+		"untrusted.corp":       false,
+		"quay.io":              false,
+		"ghcr.io.example.com":  false,
+	}
+
+	for host, allowed := range tests {
+		err := policy.Check(host)
+		if allowed && err != nil {
+			t.Errorf("%s: unexpected error: %v", host, err)
+		}
+		if !allowed && !errors.Is(err, ErrRegistryNotAllowed) {
+			t.Errorf("%s: expected %v, got %v", host, ErrRegistryNotAllowed, err)
+		}
+	}
+
+	if err := (&RegistryPolicy{}).Check("quay.io"); err != nil {
+		t.Errorf("empty policy: unexpected error: %v", err)
+	}
+
+	if err := (&RegistryPolicy{Denied: []string{"[ghcr.io"}}).Validate(); err == nil {
+		t.Error("expected error for malformed pattern")
+	}
+}
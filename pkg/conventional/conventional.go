@@ -0,0 +1,114 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conventional
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// Bump is the kind of version increment required by a set of commits.
+type Bump int
+
+const (
+	// None means that no commit requires a release.
+	None Bump = iota
+	// Patch is required by fixes, "fix:" commits.
+	Patch
+	// Minor is required by new features, "feat:" commits.
+	Minor
+	// Major is required by breaking changes, "type!:" commits or commits with a "BREAKING CHANGE:" footer.
+	Major
+)
+
+// String returns the name of the bump.
+func (b Bump) String() string {
+	switch b {
+	case Patch:
+		return "patch"
+	case Minor:
+		return "minor"
+	case Major:
+		return "major"
+	default:
+		return "none"
+	}
+}
+
+var (
+	headerRgx   = regexp.MustCompile(`^(\w+)(\([^)]*\))?(!)?:\s`)
+	breakingRgx = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:\s`)
+)
+
+// Classify returns the bump required by a single commit message, made of a header and an optional body.
+func Classify(message string) Bump {
+	header, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+
+	groups := headerRgx.FindStringSubmatch(header)
+	if groups == nil {
+		return None
+	}
+
+	switch {
+	case groups[3] == "!" || breakingRgx.MatchString(body):
+		return Major
+	case strings.EqualFold(groups[1], "feat"):
+		return Minor
+	case strings.EqualFold(groups[1], "fix"):
+		return Patch
+	default:
+		return None
+	}
+}
+
+// Analyze returns the highest bump required by the given commit messages.
+func Analyze(messages []string) Bump {
+	bump := None
+	for _, m := range messages {
+		if b := Classify(m); b > bump {
+			bump = b
+		}
+	}
+
+	return bump
+}
+
+// Next returns the version following version according to bump. A leading "v", as in git tags, is ignored.
+// Pre-release and build metadata are dropped.
+func Next(version string, bump Bump) (string, error) {
+	v, err := semver.Parse(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return "", fmt.Errorf("%q is not a valid semver version: %w", version, err)
+	}
+	v.Pre = nil
+	v.Build = nil
+
+	switch bump {
+	case Major:
+		v.Major++
+		v.Minor = 0
+		v.Patch = 0
+	case Minor:
+		v.Minor++
+		v.Patch = 0
+	case Patch:
+		v.Patch++
+	}
+
+	return v.String(), nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conventional
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tests := map[string]Bump{
+		"fix: handle empty rules":                           Patch,
+		"fix(plugins): handle empty config":                 Patch,
+		"feat: add cloudtrail rules":                        Minor,
+		"feat(api)!: drop the old fields":                   Major,
+		"chore: update deps\n\nBREAKING CHANGE: needs 0.36": Major,
+		"chore: update deps":                                None,
+		"Merge branch 'main'":                               None,
+		"docs: mention fix: in the readme":                  None,
+	}
+
+	for message, expected := range tests {
+		if bump := Classify(message); bump != expected {
+			t.Errorf("%q: expected %s, got %s", message, expected, bump)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	messages := []string{"chore: tidy", "fix: typo", "feat: new output"}
+	if bump := Analyze(messages); bump != Minor {
+		t.Fatalf("expected minor bump, got %s", bump)
+	}
+
+	tests := []struct {
+		version  string
+		bump     Bump
+		expected string
+	}{
+		{"v1.2.3", Major, "2.0.0"},
+		{"1.2.3", Minor, "1.3.0"},
+		{"1.2.3", Patch, "1.2.4"},
+		{"1.2.3", None, "1.2.3"},
+	}
+
+	for _, tt := range tests {
+		next, err := Next(tt.version, tt.bump)
+		if err != nil || next != tt.expected {
+			t.Errorf("Next(%q, %s) = %q, %v, expected %q", tt.version, tt.bump, next, err, tt.expected)
+		}
+	}
+
+	if _, err := Next("main", Patch); err == nil {
+		t.Error("expected an error for an invalid version")
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package falcoconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+)
+
+// Plugin is an entry of the plugins list of the Falco configuration.
+type Plugin struct {
+	Name        string `yaml:"name"`
+	LibraryPath string `yaml:"library_path"`
+}
+
+// Config is the Falco configuration. Only the fields referencing artifacts are decoded.
+type Config struct {
+	RulesFile  []string `yaml:"rules_file"`
+	RulesFiles []string `yaml:"rules_files"`
+	Plugins    []Plugin `yaml:"plugins"`
+	// LoadPlugins is nil if load_plugins is not set, in which case all the plugins are loaded.
+	LoadPlugins *[]string `yaml:"load_plugins"`
+}
+
+// Load reads the Falco configuration file at path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read Falco configuration: %w", err)
+	}
+
+	var cfg Config
+	if err = yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal Falco configuration %q: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+// Rules returns the rules files and directories loaded by Falco, from both rules_file and its newer form rules_files.
+func (c *Config) Rules() []string {
+	return append(append([]string{}, c.RulesFile...), c.RulesFiles...)
+}
+
+// LoadedPlugins returns the plugins loaded by Falco: the ones listed in load_plugins, if set, otherwise all of them.
+// The names listed in load_plugins without a plugin entry are returned with no library path.
+func (c *Config) LoadedPlugins() []Plugin {
+	if c.LoadPlugins == nil {
+		return c.Plugins
+	}
+
+	var plugins []Plugin
+	for _, name := range *c.LoadPlugins {
+		plugin := Plugin{Name: name}
+		for _, p := range c.Plugins {
+			if p.Name == name {
+				plugin = p
+				break
+			}
+		}
+		plugins = append(plugins, plugin)
+	}
+
+	return plugins
+}
+
+// Resolution is the result of the lookup in the indexes of an artifact referenced by the Falco configuration.
+type Resolution struct {
+	// Reference is the value of the configuration referencing the artifact, i.e. a plugin name or a rules file.
+	Reference string
+	Type      oci.ArtifactType
+	// Name is the name of the matching index entry, empty if none or several of them match.
+	Name string
+	// Candidates are the names of the index entries matching the reference, sorted, if more than one.
+	Candidates []string
+	// ByFilename is true if the entry was matched by the name of the referenced file rather than by the plugin name.
+	ByFilename bool
+}
+
+// Resolve looks up in the index entries the artifacts referenced by the configuration: the loaded plugins and the
+// rules files. Plugins are matched by name, falling back to the name of their library, e.g. "libk8saudit.so"
+// matches "k8saudit". Rules files are matched by their base name, e.g. "k8s_audit_rules.yaml" matches
+// "k8saudit-rules". Names are compared ignoring case and non-alphanumeric characters. Rules directories
+// are not resolved.
+func (c *Config) Resolve(entries []*index.Entry) []Resolution {
+	var resolutions []Resolution
+
+	for _, p := range c.LoadedPlugins() {
+		res := Resolution{Reference: p.Name, Type: oci.Plugin}
+		if res.Reference == "" {
+			res.Reference = p.LibraryPath
+		}
+
+		var exact bool
+		for _, e := range entries {
+			if e.Type == string(oci.Plugin) && p.Name != "" && e.Name == p.Name {
+				res.Name, exact = e.Name, true
+				break
+			}
+		}
+
+		if !exact && p.LibraryPath != "" {
+			filename := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(p.LibraryPath), "lib"), ".so")
+			res.ByFilename = true
+			res.resolve(entries, filename)
+		} else if !exact {
+			res.resolve(entries, p.Name)
+		}
+
+		resolutions = append(resolutions, res)
+	}
+
+	for _, path := range c.Rules() {
+		ext := filepath.Ext(path)
+		if ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+
+		res := Resolution{Reference: path, Type: oci.Rulesfile, ByFilename: true}
+		res.resolve(entries, strings.TrimSuffix(filepath.Base(path), ext))
+		resolutions = append(resolutions, res)
+	}
+
+	return resolutions
+}
+
+// resolve sets the name of the entry of the same type matching name, or the candidates if several of them do.
+func (r *Resolution) resolve(entries []*index.Entry, name string) {
+	key := normalizeName(name)
+	if key == "" {
+		return
+	}
+
+	var matches []string
+	for _, e := range entries {
+		if e.Type == string(r.Type) && normalizeName(e.Name) == key {
+			matches = append(matches, e.Name)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+	case 1:
+		r.Name = matches[0]
+	default:
+		sort.Strings(matches)
+		r.Candidates = matches
+	}
+}
+
+// normalizeName returns name in lower case, without the characters other than letters and digits.
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return -1
+		}
+	}, name)
+}
@@ -0,0 +1,105 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package falcoconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/falcosecurity/falcoctl/pkg/index"
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+)
+
+const falcoYAML = `
+rules_file:
+  - /etc/falco/falco_rules.yaml
+  - /etc/falco/k8s_audit_rules.yaml
+  - /etc/falco/falco_rules.local.yaml
+  - /etc/falco/rules.d
+plugins:
+  - name: k8saudit
+    library_path: libk8saudit.so
+  - name: cloudtrail
+    library_path: libcloudtrail.so
+  - name: my-json
+    library_path: /opt/plugins/libjson.so
+  - name: github
+    library_path: libgithub.so
+load_plugins: [k8saudit, my-json, github]
+`
+
+func TestLoadResolve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "falco.yaml")
+	if err := os.WriteFile(path, []byte(falcoYAML), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries := []*index.Entry{
+		{Name: "k8saudit", Type: string(oci.Plugin)},
+		{Name: "cloudtrail", Type: string(oci.Plugin)},
+		{Name: "json", Type: string(oci.Plugin)},
+		{Name: "github", Type: string(oci.Plugin)},
+		{Name: "git-hub", Type: string(oci.Plugin)},
+		{Name: "falco-rules", Type: string(oci.Rulesfile)},
+		{Name: "k8saudit-rules", Type: string(oci.Rulesfile)},
+	}
+
+	expected := []Resolution{
+		{Reference: "k8saudit", Type: oci.Plugin, Name: "k8saudit"},
+		{Reference: "my-json", Type: oci.Plugin, Name: "json", ByFilename: true},
+		{Reference: "github", Type: oci.Plugin, Name: "github"},
+		{Reference: "/etc/falco/falco_rules.yaml", Type: oci.Rulesfile, Name: "falco-rules", ByFilename: true},
+		{Reference: "/etc/falco/k8s_audit_rules.yaml", Type: oci.Rulesfile, Name: "k8saudit-rules", ByFilename: true},
+		{Reference: "/etc/falco/falco_rules.local.yaml", Type: oci.Rulesfile, ByFilename: true},
+	}
+
+	if got := cfg.Resolve(entries); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected resolutions:\n got %+v\nwant %+v", got, expected)
+	}
+}
+
+func TestResolveAmbiguous(t *testing.T) {
+	cfg := &Config{Plugins: []Plugin{{LibraryPath: "libgithub.so"}}}
+	entries := []*index.Entry{
+		{Name: "git-hub", Type: string(oci.Plugin)},
+		{Name: "github", Type: string(oci.Rulesfile)},
+		{Name: "GitHub", Type: string(oci.Plugin)},
+	}
+
+	res := cfg.Resolve(entries)
+	if len(res) != 1 || res[0].Name != "" || !reflect.DeepEqual(res[0].Candidates, []string{"GitHub", "git-hub"}) {
+		t.Errorf("unexpected resolutions %+v", res)
+	}
+}
+
+func TestLoadedPluginsDefault(t *testing.T) {
+	cfg := &Config{Plugins: []Plugin{{Name: "a"}, {Name: "b"}}}
+	if got := cfg.LoadedPlugins(); len(got) != 2 {
+		t.Errorf("expected all the plugins to be loaded, got %v", got)
+	}
+
+	none := []string{}
+	cfg.LoadPlugins = &none
+	if got := cfg.LoadedPlugins(); len(got) != 0 {
+		t.Errorf("expected no plugin to be loaded, got %v", got)
+	}
+}
@@ -28,20 +28,23 @@ import (
 // Entry describes an entry of the index stored remotely and cached locally.
 type Entry struct {
 	// Mandatory fields
-	Name       string `yaml:"name"`
-	Type       string `yaml:"type"`
-	Registry   string `yaml:"registry"`
-	Repository string `yaml:"repository"`
+	Name       string `yaml:"name" json:"name"`
+	Type       string `yaml:"type" json:"type"`
+	Registry   string `yaml:"registry" json:"registry"`
+	Repository string `yaml:"repository" json:"repository"`
 	// Optional fields
-	Description string   `yaml:"description"`
-	Home        string   `yaml:"home"`
-	Keywords    []string `yaml:"keywords"`
-	License     string   `yaml:"license"`
-	Maintainers []struct {
-		Email string `yaml:"email"`
-		Name  string `yaml:"name"`
-	} `yaml:"maintainers"`
-	Sources []string `yaml:"sources"`
+	Description string       `yaml:"description" json:"description"`
+	Home        string       `yaml:"home" json:"home"`
+	Keywords    []string     `yaml:"keywords" json:"keywords"`
+	License     string       `yaml:"license" json:"license"`
+	Maintainers []Maintainer `yaml:"maintainers" json:"maintainers"`
+	Sources     []string     `yaml:"sources" json:"sources"`
+}
+
+// Maintainer describes a maintainer of an artifact in an Entry.
+type Maintainer struct {
+	Email string `yaml:"email" json:"email"`
+	Name  string `yaml:"name" json:"name"`
 }
 
 // Index represents an index.
@@ -217,6 +220,43 @@ func (i *Index) SearchByKeywords(minScore float64, keywords ...string) []*Entry
 	return result
 }
 
+// SimilarNames returns the names of the entries similar to the given one, e.g. to suggest the right name of a
+// mistyped artifact, sorted by decreasing similarity. minScore is the minimum score of a similar name and at
+// most limit names are returned. The given name itself is never returned.
+func (i *Index) SimilarNames(name string, minScore float64, limit int) []string {
+	type match struct {
+		name  string
+		score float64
+	}
+
+	var matches []match
+	for _, entry := range i.Entries {
+		if entry.Name == name {
+			continue
+		}
+		if s := score(entry.Name, name); s >= minScore {
+			matches = append(matches, match{entry.Name, s})
+		}
+	}
+
+	sort.Slice(matches, func(k, j int) bool {
+		if matches[k].score != matches[j].score {
+			return matches[k].score > matches[j].score
+		}
+		return matches[k].name < matches[j].name
+	})
+
+	names := make([]string, 0, limit)
+	for _, m := range matches {
+		if len(names) == limit {
+			break
+		}
+		names = append(names, m.name)
+	}
+
+	return names
+}
+
 // IndexByEntry is used to retrieve the original index from an entry in MergedIndexes.
 func (m *MergedIndexes) IndexByEntry(entry *Entry) *Index {
 	return m.indexByEntry[entry]
@@ -180,6 +180,26 @@ func TestSearchByKeywords(t *testing.T) {
 
 }
 
+func TestSimilarNames(t *testing.T) {
+	i := New("name")
+	for _, name := range []string{"cloudtrail", "cloudtrail-rules", "k8saudit", "json"} {
+		i.Upsert(&Entry{Name: name})
+	}
+
+	similar := i.SimilarNames("cloudtrial", 0.6, 3)
+	if len(similar) != 1 || similar[0] != "cloudtrail" {
+		t.Errorf("error in SimilarNames, expected [cloudtrail], got %v", similar)
+	}
+
+	if similar = i.SimilarNames("cloudtrail", 0.6, 3); len(similar) != 1 || similar[0] != "cloudtrail-rules" {
+		t.Errorf("error in SimilarNames, expected [cloudtrail-rules] not including the name itself, got %v", similar)
+	}
+
+	if similar = i.SimilarNames("okta", 0.6, 3); len(similar) != 0 {
+		t.Errorf("error in SimilarNames, expected no similar names, got %v", similar)
+	}
+}
+
 func TestNormalize(t *testing.T) {
 	i := Index{
 		Name:        "name",
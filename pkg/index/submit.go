@@ -0,0 +1,192 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// KeywordsAnnotation is the manifest annotation holding the comma separated keywords of an artifact,
+// used to fill the keywords of its index entry.
+const KeywordsAnnotation = "io.falcosecurity.keywords"
+
+// Review statuses of a submission.
+const (
+	SubmissionPending  = "pending"
+	SubmissionApproved = "approved"
+	SubmissionRejected = "rejected"
+)
+
+// Submission reports the review status of an entry submitted to an index.
+type Submission struct {
+	ID      string `json:"id" yaml:"id"`
+	Status  string `json:"status" yaml:"status"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	URL     string `json:"url,omitempty" yaml:"url,omitempty"`
+}
+
+// NewEntry returns the index entry of the artifact stored in registry/repository, filled from the annotations
+// of its manifest. The name of the entry is the last component of the repository.
+func NewEntry(registry, repository, artifactType string, annotations map[string]string) *Entry {
+	entry := &Entry{
+		Name:        path.Base(repository),
+		Type:        artifactType,
+		Registry:    registry,
+		Repository:  repository,
+		Description: annotations[v1.AnnotationDescription],
+		Home:        annotations[v1.AnnotationURL],
+		License:     annotations[v1.AnnotationLicenses],
+		Keywords:    splitList(annotations[KeywordsAnnotation]),
+		Maintainers: parseAuthors(annotations[v1.AnnotationAuthors]),
+	}
+
+	if entry.Home == "" {
+		entry.Home = annotations[v1.AnnotationDocumentation]
+	}
+
+	if source := annotations[v1.AnnotationSource]; source != "" {
+		entry.Sources = []string{source}
+	}
+
+	return entry
+}
+
+// parseAuthors parses the authors annotation, a comma separated list of "Name <email>", in maintainers.
+func parseAuthors(authors string) []Maintainer {
+	var maintainers []Maintainer
+	for _, author := range splitList(authors) {
+		name, email, ok := strings.Cut(author, "<")
+		if !ok {
+			maintainers = append(maintainers, Maintainer{Name: author})
+			continue
+		}
+		maintainers = append(maintainers, Maintainer{
+			Name:  strings.TrimSpace(name),
+			Email: strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(email), ">")),
+		})
+	}
+
+	return maintainers
+}
+
+// splitList splits a comma separated list, dropping the empty elements.
+func splitList(list string) []string {
+	var result []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+// SubmissionClient interacts with the API of an index accepting the submission of new entries.
+type SubmissionClient struct {
+	// BaseURL is the URL of the index API, e.g. "https://index.example.com/api/v1".
+	BaseURL string
+	// Token is the bearer token used to authenticate to the API, if not empty.
+	Token string
+	// HTTPClient is the client used to send requests. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// NewSubmissionClient returns a new client for the index API at baseURL.
+func NewSubmissionClient(baseURL, token string) *SubmissionClient {
+	return &SubmissionClient{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Token:   token,
+	}
+}
+
+// Submit submits an entry for review and returns the submission, whose ID can be used to check its status.
+func (c *SubmissionClient) Submit(ctx context.Context, entry *Entry) (*Submission, error) {
+	var submission Submission
+	if err := c.do(ctx, http.MethodPost, "/submissions", entry, &submission); err != nil {
+		return nil, err
+	}
+
+	if submission.ID == "" {
+		return nil, fmt.Errorf("no submission ID returned by %s", c.BaseURL)
+	}
+
+	return &submission, nil
+}
+
+// Status returns the submission with the given ID, reporting its review status.
+func (c *SubmissionClient) Status(ctx context.Context, id string) (*Submission, error) {
+	var submission Submission
+	if err := c.do(ctx, http.MethodGet, "/submissions/"+url.PathEscape(id), nil, &submission); err != nil {
+		return nil, err
+	}
+
+	return &submission, nil
+}
+
+// do sends a request with the given JSON body, if not nil, and decodes the JSON response in out.
+func (c *SubmissionClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: unexpected status %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: unable to decode response: %w", method, path, err)
+	}
+
+	return nil
+}
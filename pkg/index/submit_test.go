@@ -0,0 +1,96 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestNewEntry(t *testing.T) {
+	entry := NewEntry("ghcr.io", "falcosecurity/plugins/plugin/cloudtrail", "plugin", map[string]string{
+		v1.AnnotationDescription:   "Reads Cloudtrail JSON logs from files/S3",
+		v1.AnnotationDocumentation: "https://falco.org/docs",
+		v1.AnnotationLicenses:      "Apache-2.0",
+		v1.AnnotationSource:        "https://github.com/falcosecurity/plugins",
+		v1.AnnotationAuthors:       "The Falco Authors <cncf-falco-dev@lists.cncf.io>, Jane",
+		KeywordsAnnotation:         "audit, aws,,cloudtrail",
+	})
+
+	if entry.Name != "cloudtrail" || entry.Type != "plugin" || entry.Registry != "ghcr.io" {
+		t.Errorf("unexpected mandatory fields %+v", entry)
+	}
+	if entry.Home != "https://falco.org/docs" {
+		t.Errorf("expected home to fall back to the documentation, got %q", entry.Home)
+	}
+	if len(entry.Keywords) != 3 || entry.Keywords[1] != "aws" {
+		t.Errorf("unexpected keywords %q", entry.Keywords)
+	}
+	if len(entry.Sources) != 1 || entry.License != "Apache-2.0" {
+		t.Errorf("unexpected sources or license %+v", entry)
+	}
+	expected := []Maintainer{{Name: "The Falco Authors", Email: "cncf-falco-dev@lists.cncf.io"}, {Name: "Jane"}}
+	if len(entry.Maintainers) != 2 || entry.Maintainers[0] != expected[0] || entry.Maintainers[1] != expected[1] {
+		t.Errorf("unexpected maintainers %+v", entry.Maintainers)
+	}
+}
+
+func TestSubmissionClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/submissions":
+			var entry Entry
+			if err := json.NewDecoder(r.Body).Decode(&entry); err != nil || entry.Name != "cloudtrail" {
+				t.Errorf("unexpected entry %+v (%v)", entry, err)
+			}
+			_, _ = w.Write([]byte(`{"id":"42","status":"pending"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/submissions/42":
+			_, _ = w.Write([]byte(`{"id":"42","status":"rejected","message":"missing license"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := NewSubmissionClient(server.URL+"/api/", "secret")
+	submission, err := c.Submit(context.Background(), &Entry{Name: "cloudtrail"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if submission.ID != "42" || submission.Status != SubmissionPending {
+		t.Errorf("unexpected submission %+v", submission)
+	}
+
+	if submission, err = c.Status(context.Background(), "42"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if submission.Status != SubmissionRejected || submission.Message != "missing license" {
+		t.Errorf("unexpected submission %+v", submission)
+	}
+
+	if _, err = NewSubmissionClient(server.URL+"/api", "wrong").Status(context.Background(), "42"); err == nil {
+		t.Errorf("expected error with a wrong token")
+	}
+}
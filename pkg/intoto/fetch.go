@@ -0,0 +1,54 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intoto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+)
+
+// LinkMediaTypes are the media types of the layers holding in-toto link metadata.
+var LinkMediaTypes = []string{"application/vnd.in-toto.link+json", "application/vnd.in-toto+json"}
+
+// FetchLinks returns the in-toto link metadata attached to the artifact pointed by ref through the referrers.
+// Layers which are not signed in-toto metadata are ignored. If the referrers could be retrieved only partially,
+// the links found are returned along with an error wrapping oci.ErrIncompleteReferrers.
+func FetchLinks(ctx context.Context, ref string, client *auth.Client) ([]*Metablock, error) {
+	layers, incomplete := oci.AttachedLayers(ctx, ref, client, "", LinkMediaTypes...)
+	if incomplete != nil && !errors.Is(incomplete, oci.ErrIncompleteReferrers) {
+		return nil, incomplete
+	}
+
+	var links []*Metablock
+	for i := range layers {
+		data, err := oci.FetchBlob(ctx, ref, client, &layers[i], oci.DefaultMaxMetadataSize)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch link %s: %w", layers[i].Digest, err)
+		}
+
+		link, err := ParseMetablock(data)
+		if err != nil {
+			continue
+		}
+		links = append(links, link)
+	}
+
+	return links, incomplete
+}
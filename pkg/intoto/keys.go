@@ -0,0 +1,159 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intoto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/falcosecurity/falcoctl/pkg/signature"
+)
+
+// ErrInvalidSignature error when a signature cannot be verified with the given key.
+var ErrInvalidSignature = signature.ErrInvalidSignature
+
+// Key is a public key in the in-toto format.
+type Key struct {
+	KeyID               string   `json:"keyid"`
+	KeyIDHashAlgorithms []string `json:"keyid_hash_algorithms,omitempty"`
+	KeyType             string   `json:"keytype"`
+	Scheme              string   `json:"scheme"`
+	KeyVal              struct {
+		Public string `json:"public"`
+	} `json:"keyval"`
+}
+
+// ID computes the key ID of the key as the in-toto implementations do: the hex encoded sha256 digest
+// of the canonical json of its type, scheme, hash algorithms and public part.
+func (k *Key) ID() (string, error) {
+	hashed := map[string]interface{}{
+		"keytype": k.KeyType,
+		"scheme":  k.Scheme,
+		"keyval":  map[string]string{"public": k.KeyVal.Public},
+	}
+	if len(k.KeyIDHashAlgorithms) > 0 {
+		hashed["keyid_hash_algorithms"] = k.KeyIDHashAlgorithms
+	}
+
+	raw, err := json.Marshal(hashed)
+	if err != nil {
+		return "", err
+	}
+	data, err := canonicalJSON(raw)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// checkID checks that id is the key ID of the key, so that a key cannot be listed under the ID of another one.
+func (k *Key) checkID(id string) error {
+	computed, err := k.ID()
+	if err != nil {
+		return fmt.Errorf("unable to compute the ID of key %s: %w", id, err)
+	}
+	if computed != id {
+		return fmt.Errorf("key ID %s does not match its key, whose ID is %s", id, computed)
+	}
+	return nil
+}
+
+// LoadKeys loads the keys from a json file, containing either a map of key IDs to keys, as in the "keys"
+// field of the layouts, or an array of keys.
+func LoadKeys(path string) (map[string]Key, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read keys: %w", err)
+	}
+
+	keys := make(map[string]Key)
+	if err = json.Unmarshal(data, &keys); err != nil {
+		var list []Key
+		if err = json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal keys %q: %w", path, err)
+		}
+		for i := range list {
+			keys[list[i].KeyID] = list[i]
+		}
+	}
+
+	for id, k := range keys {
+		if id == "" {
+			return nil, fmt.Errorf("key without ID in %q", path)
+		}
+		k.KeyID = id
+		keys[id] = k
+	}
+
+	return keys, nil
+}
+
+// Verify verifies the hex encoded signature of data. The "ecdsa-sha2-nistp256", "rsassa-pss-sha256"
+// and "ed25519" schemes are supported.
+func (k *Key) Verify(data []byte, sig string) error {
+	decoded, err := hex.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("malformed signature: %w", err)
+	}
+
+	public, err := k.publicKey()
+	if err != nil {
+		return err
+	}
+
+	return signature.VerifyMessage(public, data, decoded, signature.RSAPaddingPSS)
+}
+
+// publicKey parses the public key, checking that its type matches the scheme. Ed25519 keys are hex
+// encoded, the other ones are PEM encoded.
+func (k *Key) publicKey() (crypto.PublicKey, error) {
+	if k.Scheme == "ed25519" {
+		public, err := hex.DecodeString(k.KeyVal.Public)
+		if err != nil || len(public) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("malformed ed25519 key %s", k.KeyID)
+		}
+		return ed25519.PublicKey(public), nil
+	}
+
+	public, err := signature.ParsePublicKey([]byte(k.KeyVal.Public))
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", k.KeyID, err)
+	}
+
+	switch public.(type) {
+	case *ecdsa.PublicKey:
+		if k.Scheme != "ecdsa-sha2-nistp256" {
+			return nil, fmt.Errorf("unsupported scheme %q for ecdsa key %s", k.Scheme, k.KeyID)
+		}
+	case *rsa.PublicKey:
+		if k.Scheme != "rsassa-pss-sha256" {
+			return nil, fmt.Errorf("unsupported scheme %q for rsa key %s", k.Scheme, k.KeyID)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key type %T for key %s", public, k.KeyID)
+	}
+
+	return public, nil
+}
@@ -0,0 +1,263 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intoto
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// HashObj maps the names of hash algorithms to the hex encoded digests of an artifact.
+type HashObj map[string]string
+
+const (
+	ruleMatch    = "MATCH"
+	ruleAllow    = "ALLOW"
+	ruleDisallow = "DISALLOW"
+	ruleRequire  = "REQUIRE"
+	ruleCreate   = "CREATE"
+	ruleDelete   = "DELETE"
+	ruleModify   = "MODIFY"
+
+	materials = "MATERIALS"
+	products  = "PRODUCTS"
+)
+
+// artifactRule is a parsed artifact rule of a step, e.g. ["MATCH", "*", "WITH", "PRODUCTS", "FROM", "build"].
+type artifactRule struct {
+	raw       []string
+	kind      string
+	pattern   string
+	srcPrefix string
+	// The following fields are set for MATCH rules only.
+	dstType   string
+	dstPrefix string
+	dstStep   string
+}
+
+func (r *artifactRule) String() string {
+	return strings.Join(r.raw, " ")
+}
+
+// parseRule parses an artifact rule, whose keywords are case insensitive:
+//
+//	MATCH <pattern> [IN <source-path-prefix>] WITH (MATERIALS|PRODUCTS) [IN <destination-path-prefix>] FROM <step>
+//	CREATE|DELETE|MODIFY|ALLOW|DISALLOW|REQUIRE <pattern>
+func parseRule(raw []string) (*artifactRule, error) {
+	if len(raw) < 2 {
+		return nil, fmt.Errorf("malformed artifact rule %q", strings.Join(raw, " "))
+	}
+
+	r := &artifactRule{raw: raw, kind: strings.ToUpper(raw[0]), pattern: raw[1]}
+	switch r.kind {
+	case ruleCreate, ruleDelete, ruleModify, ruleAllow, ruleDisallow, ruleRequire:
+		if len(raw) != 2 {
+			return nil, fmt.Errorf("malformed artifact rule %q", r)
+		}
+		return r, nil
+	case ruleMatch:
+	default:
+		return nil, fmt.Errorf("unknown artifact rule %q", r)
+	}
+
+	token := func(i int) string {
+		if i < len(raw) {
+			return strings.ToUpper(raw[i])
+		}
+		return ""
+	}
+
+	i := 2
+	if token(i) == "IN" && i+1 < len(raw) {
+		r.srcPrefix = raw[i+1]
+		i += 2
+	}
+	if token(i) != "WITH" || (token(i+1) != materials && token(i+1) != products) {
+		return nil, fmt.Errorf("malformed artifact rule %q", r)
+	}
+	r.dstType = token(i + 1)
+	i += 2
+	if token(i) == "IN" && i+1 < len(raw) {
+		r.dstPrefix = raw[i+1]
+		i += 2
+	}
+	if token(i) != "FROM" || i+2 != len(raw) {
+		return nil, fmt.Errorf("malformed artifact rule %q", r)
+	}
+	r.dstStep = raw[i+1]
+
+	return r, nil
+}
+
+// parseRules parses the artifact rules of a step.
+func parseRules(raw [][]string) ([]*artifactRule, error) {
+	rules := make([]*artifactRule, 0, len(raw))
+	for _, r := range raw {
+		rule, err := parseRule(r)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// verifyArtifacts applies the rules, in order, to the artifacts, i.e. the materials or the products of link.
+// Each rule consumes the artifacts it matches, so that they are not matched by the following rules, while
+// DISALLOW and REQUIRE rules fail the verification. The artifacts left after the last rule are allowed.
+// links are the links of the steps of the layout, used by MATCH rules.
+func verifyArtifacts(rules []*artifactRule, artifacts map[string]HashObj, link *Link, links map[string]*Link) error {
+	queue := make(map[string]struct{}, len(artifacts))
+	for p := range artifacts {
+		queue[p] = struct{}{}
+	}
+
+	for _, r := range rules {
+		pattern := r.pattern
+		if r.srcPrefix != "" {
+			pattern = path.Join(r.srcPrefix, pattern)
+		}
+		filtered, err := filterArtifacts(queue, path.Clean(pattern))
+		if err != nil {
+			return fmt.Errorf("rule %q: %w", r, err)
+		}
+
+		var consumed []string
+		switch r.kind {
+		case ruleMatch:
+			consumed = matchArtifacts(r, artifacts, filtered, links)
+		case ruleAllow:
+			consumed = filtered
+		case ruleCreate:
+			for _, p := range filtered {
+				if _, ok := link.Materials[p]; !ok {
+					consumed = append(consumed, p)
+				}
+			}
+		case ruleDelete:
+			for _, p := range filtered {
+				if _, ok := link.Products[p]; !ok {
+					consumed = append(consumed, p)
+				}
+			}
+		case ruleModify:
+			for _, p := range filtered {
+				material, inMaterials := link.Materials[p]
+				product, inProducts := link.Products[p]
+				if inMaterials && inProducts && !reflect.DeepEqual(material, product) {
+					consumed = append(consumed, p)
+				}
+			}
+		case ruleDisallow:
+			if len(filtered) > 0 {
+				return fmt.Errorf("artifact %q disallowed by rule %q", filtered[0], r)
+			}
+		case ruleRequire:
+			if _, ok := queue[r.pattern]; !ok {
+				return fmt.Errorf("artifact %q required by rule %q not found", r.pattern, r)
+			}
+		}
+
+		for _, p := range consumed {
+			delete(queue, p)
+		}
+	}
+
+	return nil
+}
+
+// matchArtifacts returns the artifacts matching, with the same hashes, the materials or the products of the
+// destination step of a MATCH rule, once the source prefix is replaced by the destination one.
+func matchArtifacts(r *artifactRule, artifacts map[string]HashObj, filtered []string, links map[string]*Link) []string {
+	dstLink, ok := links[r.dstStep]
+	if !ok {
+		return nil
+	}
+	dstArtifacts := dstLink.Products
+	if r.dstType == materials {
+		dstArtifacts = dstLink.Materials
+	}
+
+	var consumed []string
+	for _, p := range filtered {
+		base := p
+		if r.srcPrefix != "" {
+			base = strings.TrimPrefix(p, path.Clean(r.srcPrefix)+"/")
+		}
+		dst, ok := dstArtifacts[path.Clean(path.Join(r.dstPrefix, base))]
+		if ok && reflect.DeepEqual(dst, artifacts[p]) {
+			consumed = append(consumed, p)
+		}
+	}
+
+	return consumed
+}
+
+// filterArtifacts returns the sorted paths in queue matching the unix shell-style pattern, where "*" also
+// matches path separators.
+func filterArtifacts(queue map[string]struct{}, pattern string) ([]string, error) {
+	rgx, err := patternRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []string
+	for p := range queue {
+		if rgx.MatchString(p) {
+			filtered = append(filtered, p)
+		}
+	}
+	sort.Strings(filtered)
+
+	return filtered, nil
+}
+
+// patternRegexp translates a unix shell-style pattern, supporting "*", "?", "[seq]" and "[!seq]", to a regexp.
+func patternRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			seq := pattern[i+1 : i+1+end]
+			b.WriteString("[")
+			if strings.HasPrefix(seq, "!") {
+				b.WriteString("^")
+				seq = seq[1:]
+			}
+			b.WriteString(strings.ReplaceAll(seq, `\`, `\\`))
+			b.WriteString("]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
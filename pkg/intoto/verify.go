@@ -0,0 +1,287 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intoto
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Signature is a signature of a metablock.
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// Metablock is a signed in-toto metadata, either a layout or a link.
+type Metablock struct {
+	Signed     json.RawMessage `json:"signed"`
+	Signatures []Signature     `json:"signatures"`
+}
+
+// Layout is the supply chain layout defining the steps to be verified.
+type Layout struct {
+	Type    string            `json:"_type"`
+	Expires string            `json:"expires"`
+	Keys    map[string]Key    `json:"keys"`
+	Steps   []Step            `json:"steps"`
+	Inspect []json.RawMessage `json:"inspect,omitempty"`
+}
+
+// Step is a step of the supply chain, performed by the functionaries owning the keys in PubKeys.
+type Step struct {
+	Name              string     `json:"name"`
+	PubKeys           []string   `json:"pubkeys"`
+	Threshold         int        `json:"threshold"`
+	ExpectedMaterials [][]string `json:"expected_materials,omitempty"`
+	ExpectedProducts  [][]string `json:"expected_products,omitempty"`
+	ExpectedCommand   []string   `json:"expected_command,omitempty"`
+}
+
+// Link is the metadata recorded by a functionary when performing a step.
+type Link struct {
+	Type      string             `json:"_type"`
+	Name      string             `json:"name"`
+	Command   []string           `json:"command,omitempty"`
+	Materials map[string]HashObj `json:"materials,omitempty"`
+	Products  map[string]HashObj `json:"products,omitempty"`
+}
+
+// ParseMetablock parses a signed in-toto metadata in json format.
+func ParseMetablock(data []byte) (*Metablock, error) {
+	var m Metablock
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal in-toto metadata: %w", err)
+	}
+	if len(m.Signed) == 0 {
+		return nil, fmt.Errorf("malformed in-toto metadata: missing signed field")
+	}
+	return &m, nil
+}
+
+// LoadMetablock loads a signed in-toto metadata from a json file.
+func LoadMetablock(path string) (*Metablock, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	return ParseMetablock(data)
+}
+
+// VerifySignature verifies that the metablock holds a valid signature by key.
+func (m *Metablock) VerifySignature(key *Key) error {
+	data, err := canonicalJSON(m.Signed)
+	if err != nil {
+		return fmt.Errorf("unable to canonicalize metadata: %w", err)
+	}
+
+	for _, s := range m.Signatures {
+		if s.KeyID == key.KeyID {
+			return key.Verify(data, s.Sig)
+		}
+	}
+
+	return fmt.Errorf("no signature by key %s", key.KeyID)
+}
+
+// StepResult is the result of the verification of a step of the layout.
+type StepResult struct {
+	Name   string `json:"name" yaml:"name"`
+	Passed bool   `json:"passed" yaml:"passed"`
+	// Links is the number of links whose signature was verified.
+	Links int    `json:"links" yaml:"links"`
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+	// Warnings are the discrepancies not failing the step, e.g. a command differing from the expected one.
+	Warnings []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// Verify verifies the layout against the layout keys, which must all have signed it, and the link metadata
+// against the steps of the layout: the signatures and thresholds of the links, then the artifact rules of
+// the steps against the materials and products of the links. An error is returned if the layout cannot be
+// verified, otherwise the result of each step is returned. Layouts with inspections are rejected, since
+// they would require running the inspection commands.
+func Verify(layoutBlock *Metablock, layoutKeys map[string]Key, links []*Metablock, now time.Time) ([]StepResult, error) {
+	if len(layoutKeys) == 0 {
+		return nil, fmt.Errorf("no layout key")
+	}
+	for id := range layoutKeys {
+		key := layoutKeys[id]
+		if err := key.checkID(id); err != nil {
+			return nil, fmt.Errorf("layout key: %w", err)
+		}
+		if err := layoutBlock.VerifySignature(&key); err != nil {
+			return nil, fmt.Errorf("layout signature by key %s: %w", id, err)
+		}
+	}
+
+	var layout Layout
+	if err := json.Unmarshal(layoutBlock.Signed, &layout); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal layout: %w", err)
+	}
+	if layout.Type != "layout" {
+		return nil, fmt.Errorf("unexpected metadata type %q, expected a layout", layout.Type)
+	}
+
+	expires, err := time.Parse(time.RFC3339, layout.Expires)
+	if err != nil {
+		return nil, fmt.Errorf("invalid layout expiration %q: %w", layout.Expires, err)
+	}
+	if now.After(expires) {
+		return nil, fmt.Errorf("layout expired on %s", layout.Expires)
+	}
+
+	for id := range layout.Keys {
+		key := layout.Keys[id]
+		if err = key.checkID(id); err != nil {
+			return nil, fmt.Errorf("functionary key: %w", err)
+		}
+	}
+
+	if len(layout.Inspect) > 0 {
+		return nil, fmt.Errorf("layout has %d inspection(s), inspections are not supported", len(layout.Inspect))
+	}
+
+	materialRules := make([][]*artifactRule, len(layout.Steps))
+	productRules := make([][]*artifactRule, len(layout.Steps))
+	for i := range layout.Steps {
+		step := &layout.Steps[i]
+		if materialRules[i], err = parseRules(step.ExpectedMaterials); err != nil {
+			return nil, fmt.Errorf("expected materials of step %q: %w", step.Name, err)
+		}
+		if productRules[i], err = parseRules(step.ExpectedProducts); err != nil {
+			return nil, fmt.Errorf("expected products of step %q: %w", step.Name, err)
+		}
+	}
+
+	linksByStep := make(map[string][]*Metablock)
+	for _, l := range links {
+		var link Link
+		if err = json.Unmarshal(l.Signed, &link); err != nil || link.Type != "link" {
+			continue
+		}
+		linksByStep[link.Name] = append(linksByStep[link.Name], l)
+	}
+
+	results := make([]StepResult, len(layout.Steps))
+	stepLinks := make(map[string]*Link)
+	for i := range layout.Steps {
+		step := &layout.Steps[i]
+		result, link := verifyStep(&layout, step, linksByStep[step.Name])
+		results[i] = result
+		if link != nil {
+			stepLinks[step.Name] = link
+		}
+	}
+
+	// The artifact rules are verified once the links of all the steps are known, since they can match
+	// the artifacts of other steps.
+	for i := range layout.Steps {
+		link, ok := stepLinks[layout.Steps[i].Name]
+		if !ok {
+			continue
+		}
+		if err = verifyArtifacts(materialRules[i], link.Materials, link, stepLinks); err != nil {
+			results[i].Passed = false
+			results[i].Error = fmt.Sprintf("expected materials: %s", err.Error())
+			continue
+		}
+		if err = verifyArtifacts(productRules[i], link.Products, link, stepLinks); err != nil {
+			results[i].Passed = false
+			results[i].Error = fmt.Sprintf("expected products: %s", err.Error())
+		}
+	}
+
+	return results, nil
+}
+
+// verifyStep verifies that at least threshold links of the step are signed by distinct functionaries and
+// that they record the same materials and products. It returns the result of the step and, if it passed,
+// the link of the step, whose command is compared to the expected one.
+func verifyStep(layout *Layout, step *Step, links []*Metablock) (StepResult, *Link) {
+	result := StepResult{Name: step.Name}
+	threshold := step.Threshold
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	if len(links) == 0 {
+		result.Error = "no link metadata found"
+		return result, nil
+	}
+
+	signers := make(map[string]struct{})
+	var verifiedLinks []*Link
+	var failures []string
+	for _, link := range links {
+		verified := false
+		for _, id := range step.PubKeys {
+			key, ok := layout.Keys[id]
+			if !ok {
+				continue
+			}
+			key.KeyID = id
+			if err := link.VerifySignature(&key); err == nil {
+				if _, ok := signers[id]; !ok {
+					var l Link
+					if err = json.Unmarshal(link.Signed, &l); err != nil {
+						continue
+					}
+					verifiedLinks = append(verifiedLinks, &l)
+				}
+				signers[id] = struct{}{}
+				verified = true
+				break
+			}
+		}
+		if !verified {
+			ids := make([]string, 0, len(link.Signatures))
+			for _, s := range link.Signatures {
+				ids = append(ids, s.KeyID)
+			}
+			failures = append(failures, fmt.Sprintf("link signed by %s not verified by any functionary key", strings.Join(ids, ",")))
+		} else {
+			result.Links++
+		}
+	}
+
+	if len(signers) < threshold {
+		result.Error = fmt.Sprintf("%d verified functionaries, threshold is %d", len(signers), threshold)
+		if len(failures) > 0 {
+			result.Error += ": " + strings.Join(failures, "; ")
+		}
+		return result, nil
+	}
+
+	link := verifiedLinks[0]
+	for _, l := range verifiedLinks[1:] {
+		if !reflect.DeepEqual(l.Materials, link.Materials) || !reflect.DeepEqual(l.Products, link.Products) {
+			result.Error = "the links of the functionaries record different materials or products"
+			return result, nil
+		}
+	}
+
+	if len(step.ExpectedCommand) > 0 && !reflect.DeepEqual(link.Command, step.ExpectedCommand) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("command %q differs from the expected command %q",
+			strings.Join(link.Command, " "), strings.Join(step.ExpectedCommand, " ")))
+	}
+
+	result.Passed = true
+	return result, link
+}
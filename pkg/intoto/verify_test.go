@@ -0,0 +1,410 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intoto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newKey(t *testing.T) (Key, ed25519.PrivateKey) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := Key{KeyType: "ed25519", Scheme: "ed25519", KeyIDHashAlgorithms: []string{"sha256", "sha512"}}
+	key.KeyVal.Public = hex.EncodeToString(public)
+	if key.KeyID, err = key.ID(); err != nil {
+		t.Fatal(err)
+	}
+	return key, private
+}
+
+func sign(t *testing.T, signed interface{}, keyID string, private ed25519.PrivateKey) *Metablock {
+	raw, err := json.Marshal(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := canonicalJSON(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Metablock{
+		Signed:     raw,
+		Signatures: []Signature{{KeyID: keyID, Sig: hex.EncodeToString(ed25519.Sign(private, data))}},
+	}
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	data, err := canonicalJSON([]byte(`{"b": [1, true, null], "a": "quote \" and \\ and\nnewline"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "{\"a\":\"quote \\\" and \\\\ and\nnewline\",\"b\":[1,true,null]}"
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	if _, err = canonicalJSON([]byte(`{"a": 1.5}`)); err == nil {
+		t.Error("expected error for a non integer number")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	ownerKey, ownerPrivate := newKey(t)
+	builderKey, builderPrivate := newKey(t)
+	_, otherPrivate := newKey(t)
+
+	layout := Layout{
+		Type:    "layout",
+		Expires: time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+		Keys:    map[string]Key{builderKey.KeyID: builderKey},
+		Steps: []Step{
+			{Name: "build", PubKeys: []string{builderKey.KeyID}, Threshold: 1},
+			{Name: "test", PubKeys: []string{builderKey.KeyID}, Threshold: 1},
+			{Name: "package", PubKeys: []string{builderKey.KeyID}, Threshold: 1},
+		},
+	}
+	layoutBlock := sign(t, layout, ownerKey.KeyID, ownerPrivate)
+
+	links := []*Metablock{
+		sign(t, Link{Type: "link", Name: "build"}, builderKey.KeyID, builderPrivate),
+		sign(t, Link{Type: "link", Name: "test"}, builderKey.KeyID, otherPrivate),
+	}
+
+	layoutKeys := map[string]Key{ownerKey.KeyID: ownerKey}
+	results, err := Verify(layoutBlock, layoutKeys, links, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 step results, got %d", len(results))
+	}
+
+	if !results[0].Passed {
+		t.Errorf("expected step build to pass: %s", results[0].Error)
+	}
+	if results[1].Passed || !strings.Contains(results[1].Error, "threshold") {
+		t.Errorf("expected step test to fail for the threshold, got %+v", results[1])
+	}
+	if results[2].Passed || results[2].Error != "no link metadata found" {
+		t.Errorf("expected step package to fail for the missing link, got %+v", results[2])
+	}
+
+	// A layout not signed by the layout keys is rejected.
+	if _, err = Verify(layoutBlock, map[string]Key{builderKey.KeyID: builderKey}, links, time.Now()); err == nil {
+		t.Error("expected error for a layout not signed by the layout keys")
+	}
+
+	// An expired layout is rejected.
+	if _, err = Verify(layoutBlock, layoutKeys, links, time.Now().Add(2*time.Hour)); err == nil {
+		t.Error("expected error for an expired layout")
+	}
+}
+
+func TestVerifyKeyIDs(t *testing.T) {
+	ownerKey, ownerPrivate := newKey(t)
+	builderKey, builderPrivate := newKey(t)
+	otherKey, _ := newKey(t)
+
+	// A layout key listed under the ID of another key is rejected, even if the signature matches the ID.
+	layout := Layout{Type: "layout", Expires: time.Now().Add(time.Hour).UTC().Format(time.RFC3339)}
+	layoutBlock := sign(t, layout, otherKey.KeyID, ownerPrivate)
+	_, err := Verify(layoutBlock, map[string]Key{otherKey.KeyID: ownerKey}, nil, time.Now())
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected error for a layout key not matching its ID, got %v", err)
+	}
+
+	// A functionary key listed under the ID of another key is rejected.
+	layout.Keys = map[string]Key{otherKey.KeyID: builderKey}
+	layout.Steps = []Step{{Name: "build", PubKeys: []string{otherKey.KeyID}, Threshold: 1}}
+	layoutBlock = sign(t, layout, ownerKey.KeyID, ownerPrivate)
+	links := []*Metablock{sign(t, Link{Type: "link", Name: "build"}, otherKey.KeyID, builderPrivate)}
+	_, err = Verify(layoutBlock, map[string]Key{ownerKey.KeyID: ownerKey}, links, time.Now())
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected error for a functionary key not matching its ID, got %v", err)
+	}
+}
+
+var (
+	sourceHash = HashObj{"sha256": "aa"}
+	binaryHash = HashObj{"sha256": "bb"}
+	tarHash    = HashObj{"sha256": "cc"}
+)
+
+// verifyRules verifies a layout whose "build" step turns the source into a binary and whose "package" step
+// packs the binary, with the given rules for the "package" step.
+func verifyRules(t *testing.T, expectedMaterials, expectedProducts [][]string, packageLink Link) (StepResult, error) {
+	t.Helper()
+
+	ownerKey, ownerPrivate := newKey(t)
+	builderKey, builderPrivate := newKey(t)
+
+	layout := Layout{
+		Type:    "layout",
+		Expires: time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+		Keys:    map[string]Key{builderKey.KeyID: builderKey},
+		Steps: []Step{
+			{Name: "build", PubKeys: []string{builderKey.KeyID}, Threshold: 1},
+			{Name: "package", PubKeys: []string{builderKey.KeyID}, Threshold: 1,
+				ExpectedMaterials: expectedMaterials, ExpectedProducts: expectedProducts,
+				ExpectedCommand: []string{"tar", "czf", "plugin.tar.gz", "out/plugin.so"}},
+		},
+	}
+
+	packageLink.Type = "link"
+	packageLink.Name = "package"
+	links := []*Metablock{
+		sign(t, Link{
+			Type:      "link",
+			Name:      "build",
+			Materials: map[string]HashObj{"src/plugin.c": sourceHash},
+			Products:  map[string]HashObj{"src/plugin.c": sourceHash, "out/plugin.so": binaryHash},
+		}, builderKey.KeyID, builderPrivate),
+		sign(t, packageLink, builderKey.KeyID, builderPrivate),
+	}
+
+	results, err := Verify(sign(t, layout, ownerKey.KeyID, ownerPrivate), map[string]Key{ownerKey.KeyID: ownerKey}, links, time.Now())
+	if err != nil {
+		return StepResult{}, err
+	}
+	if !results[0].Passed {
+		t.Fatalf("expected step build to pass: %s", results[0].Error)
+	}
+	return results[1], nil
+}
+
+func TestVerifyArtifactRules(t *testing.T) {
+	packageLink := Link{
+		Command:   []string{"tar", "czf", "plugin.tar.gz", "out/plugin.so"},
+		Materials: map[string]HashObj{"out/plugin.so": binaryHash},
+		Products:  map[string]HashObj{"out/plugin.so": binaryHash, "plugin.tar.gz": tarHash},
+	}
+	tamperedLink := Link{
+		Command:   packageLink.Command,
+		Materials: map[string]HashObj{"out/plugin.so": tarHash},
+		Products:  map[string]HashObj{"out/plugin.so": tarHash, "plugin.tar.gz": tarHash},
+	}
+
+	testCases := []struct {
+		descr             string
+		expectedMaterials [][]string
+		expectedProducts  [][]string
+		link              Link
+		errContains       string
+	}{
+		{
+			descr:             "all rules satisfied",
+			expectedMaterials: [][]string{{"MATCH", "out/*", "WITH", "PRODUCTS", "FROM", "build"}, {"DISALLOW", "*"}},
+			expectedProducts: [][]string{
+				{"REQUIRE", "plugin.tar.gz"}, {"CREATE", "*.tar.gz"}, {"ALLOW", "out/*"}, {"DISALLOW", "*"},
+			},
+			link: packageLink,
+		},
+		{
+			descr: "match with prefixes",
+			expectedMaterials: [][]string{
+				{"match", "plugin.so", "in", "out", "with", "products", "in", "out", "from", "build"}, {"disallow", "*"},
+			},
+			link: packageLink,
+		},
+		{
+			descr:             "match with different hashes",
+			expectedMaterials: [][]string{{"MATCH", "out/*", "WITH", "PRODUCTS", "FROM", "build"}, {"DISALLOW", "*"}},
+			link:              tamperedLink,
+			errContains:       `expected materials: artifact "out/plugin.so" disallowed`,
+		},
+		{
+			descr:             "match with a step without links",
+			expectedMaterials: [][]string{{"MATCH", "out/*", "WITH", "PRODUCTS", "FROM", "unknown"}, {"DISALLOW", "*"}},
+			link:              packageLink,
+			errContains:       "disallowed",
+		},
+		{
+			descr:            "disallow",
+			expectedProducts: [][]string{{"DISALLOW", "*.tar.gz"}},
+			link:             packageLink,
+			errContains:      `expected products: artifact "plugin.tar.gz" disallowed`,
+		},
+		{
+			descr:            "require",
+			expectedProducts: [][]string{{"REQUIRE", "plugin.zip"}},
+			link:             packageLink,
+			errContains:      `artifact "plugin.zip" required`,
+		},
+		{
+			descr:            "create of an existing material",
+			expectedProducts: [][]string{{"CREATE", "out/plugin.so"}, {"DISALLOW", "out/*"}},
+			link:             packageLink,
+			errContains:      `artifact "out/plugin.so" disallowed`,
+		},
+		{
+			descr:            "delete of an existing product",
+			expectedProducts: [][]string{{"DELETE", "*"}, {"DISALLOW", "*"}},
+			link:             packageLink,
+			errContains:      "disallowed",
+		},
+		{
+			descr:             "modify of an unchanged material",
+			expectedMaterials: [][]string{{"MODIFY", "out/plugin.so"}, {"DISALLOW", "*"}},
+			link:              packageLink,
+			errContains:       `artifact "out/plugin.so" disallowed`,
+		},
+		{
+			descr:            "modify of a changed product",
+			expectedProducts: [][]string{{"MODIFY", "out/plugin.so"}, {"ALLOW", "plugin.tar.gz"}, {"DISALLOW", "*"}},
+			link: Link{
+				Materials: map[string]HashObj{"out/plugin.so": binaryHash},
+				Products:  map[string]HashObj{"out/plugin.so": tarHash, "plugin.tar.gz": tarHash},
+			},
+		},
+		{
+			descr:            "allow consumes before disallow",
+			expectedProducts: [][]string{{"ALLOW", "*"}, {"DISALLOW", "*"}},
+			link:             packageLink,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.descr, func(t *testing.T) {
+			result, err := verifyRules(t, tc.expectedMaterials, tc.expectedProducts, tc.link)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.errContains == "" {
+				if !result.Passed {
+					t.Errorf("expected step package to pass: %s", result.Error)
+				}
+				return
+			}
+			if result.Passed || !strings.Contains(result.Error, tc.errContains) {
+				t.Errorf("expected step package to fail with %q, got %+v", tc.errContains, result)
+			}
+		})
+	}
+}
+
+func TestVerifyMalformedRules(t *testing.T) {
+	for _, rule := range [][]string{
+		{"MATCH", "*", "WITH", "PRODUCTS"},
+		{"MATCH", "*", "WITH", "ARTIFACTS", "FROM", "build"},
+		{"MATCH", "*", "IN", "src", "WITH", "PRODUCTS", "FROM", "build", "extra"},
+		{"ALLOW", "*", "extra"},
+		{"COPY", "*"},
+		{"ALLOW"},
+	} {
+		if _, err := verifyRules(t, [][]string{rule}, nil, Link{}); err == nil {
+			t.Errorf("expected error for the malformed rule %v", rule)
+		}
+	}
+}
+
+func TestVerifyExpectedCommand(t *testing.T) {
+	result, err := verifyRules(t, nil, nil, Link{Command: []string{"tar", "czf", "plugin.tar.gz", "."}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// A different command is reported but does not fail the step.
+	if !result.Passed || len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "differs from the expected command") {
+		t.Errorf("expected step package to pass with a warning for the command, got %+v", result)
+	}
+
+	result, err = verifyRules(t, nil, nil, Link{Command: []string{"tar", "czf", "plugin.tar.gz", "out/plugin.so"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Passed || len(result.Warnings) != 0 {
+		t.Errorf("expected step package to pass without warnings, got %+v", result)
+	}
+}
+
+func TestVerifyThresholdLinksDisagree(t *testing.T) {
+	ownerKey, ownerPrivate := newKey(t)
+	builder1Key, builder1Private := newKey(t)
+	builder2Key, builder2Private := newKey(t)
+
+	layout := Layout{
+		Type:    "layout",
+		Expires: time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+		Keys:    map[string]Key{builder1Key.KeyID: builder1Key, builder2Key.KeyID: builder2Key},
+		Steps:   []Step{{Name: "build", PubKeys: []string{builder1Key.KeyID, builder2Key.KeyID}, Threshold: 2}},
+	}
+
+	links := []*Metablock{
+		sign(t, Link{Type: "link", Name: "build", Products: map[string]HashObj{"plugin.so": binaryHash}},
+			builder1Key.KeyID, builder1Private),
+		sign(t, Link{Type: "link", Name: "build", Products: map[string]HashObj{"plugin.so": tarHash}},
+			builder2Key.KeyID, builder2Private),
+	}
+
+	results, err := Verify(sign(t, layout, ownerKey.KeyID, ownerPrivate), map[string]Key{ownerKey.KeyID: ownerKey}, links, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results[0].Passed || !strings.Contains(results[0].Error, "different materials or products") {
+		t.Errorf("expected step build to fail for the different products, got %+v", results[0])
+	}
+}
+
+func TestVerifyInspections(t *testing.T) {
+	ownerKey, ownerPrivate := newKey(t)
+
+	layout := Layout{
+		Type:    "layout",
+		Expires: time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+		Inspect: []json.RawMessage{json.RawMessage(`{"_type":"inspection","name":"untar","run":["tar","xzf","plugin.tar.gz"]}`)},
+	}
+
+	_, err := Verify(sign(t, layout, ownerKey.KeyID, ownerPrivate), map[string]Key{ownerKey.KeyID: ownerKey}, nil, time.Now())
+	if err == nil || !strings.Contains(err.Error(), "inspections are not supported") {
+		t.Errorf("expected error for a layout with inspections, got %v", err)
+	}
+}
+
+func TestPatternRegexp(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		name    string
+		matches bool
+	}{
+		{pattern: "*", name: "out/plugin.so", matches: true},
+		{pattern: "out/*.so", name: "out/lib/plugin.so", matches: true},
+		{pattern: "*.so", name: "plugin.so.1", matches: false},
+		{pattern: "plugin.?o", name: "plugin.so", matches: true},
+		{pattern: "plugin.so", name: "pluginXso", matches: false},
+		{pattern: "[abc].txt", name: "b.txt", matches: true},
+		{pattern: "[!abc].txt", name: "b.txt", matches: false},
+		{pattern: "[!abc].txt", name: "d.txt", matches: true},
+		{pattern: "[.txt", name: "[.txt", matches: true},
+	}
+
+	for _, tc := range testCases {
+		rgx, err := patternRegexp(tc.pattern)
+		if err != nil {
+			t.Fatalf("unexpected error for pattern %q: %v", tc.pattern, err)
+		}
+		if rgx.MatchString(tc.name) != tc.matches {
+			t.Errorf("expected pattern %q matching %q to be %t", tc.pattern, tc.name, tc.matches)
+		}
+	}
+}
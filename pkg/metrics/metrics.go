@@ -0,0 +1,165 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	writePermissions = 0o600
+	// UpdatesWindow is the window used to count the recent updates.
+	UpdatesWindow = 30 * 24 * time.Hour
+)
+
+// Metrics holds the counters accumulated by falcoctl operations.
+type Metrics struct {
+	// Updates contains the timestamps, in RFC3339 format, of the artifact updates.
+	Updates             []string `yaml:"updates"`
+	VerificationsPassed int64    `yaml:"verifications_passed"`
+	VerificationsFailed int64    `yaml:"verifications_failed"`
+	BytesTransferred    int64    `yaml:"bytes_transferred"`
+}
+
+// Snapshot is the view of the metrics exported to the users.
+type Snapshot struct {
+	InstalledArtifacts  int   `json:"installed_artifacts"`
+	RecentUpdates       int   `json:"updates_last_30_days"`
+	VerificationsPassed int64 `json:"verifications_passed"`
+	VerificationsFailed int64 `json:"verifications_failed"`
+	BytesTransferred    int64 `json:"bytes_transferred"`
+}
+
+// New loads the metrics from a file. Empty metrics are returned if the file does not exist.
+func New(path string) (*Metrics, error) {
+	var m Metrics
+	file, err := os.ReadFile(filepath.Clean(path))
+	if os.IsNotExist(err) {
+		return &m, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err = yaml.Unmarshal(file, &m); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal metrics file %q: %w", path, err)
+	}
+
+	return &m, nil
+}
+
+// Update loads the metrics from a file, applies fn and writes them back.
+func Update(path string, fn func(m *Metrics)) error {
+	m, err := New(path)
+	if err != nil {
+		return err
+	}
+
+	fn(m)
+
+	return m.Write(path)
+}
+
+// Write writes the metrics to disk, creating the parent directory if needed.
+func (m *Metrics) Write(path string) error {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, writePermissions)
+}
+
+// RecordUpdate records an artifact update happened at time t.
+func (m *Metrics) RecordUpdate(t time.Time) {
+	m.Updates = append(m.Updates, t.UTC().Format(time.RFC3339))
+}
+
+// RecordVerification records the result of an artifact verification.
+func (m *Metrics) RecordVerification(passed bool) {
+	if passed {
+		m.VerificationsPassed++
+	} else {
+		m.VerificationsFailed++
+	}
+}
+
+// AddBytesTransferred adds n to the number of bytes transferred from and to remote registries.
+func (m *Metrics) AddBytesTransferred(n int64) {
+	m.BytesTransferred += n
+}
+
+// Snapshot returns the current view of the metrics. Updates older than UpdatesWindow are discarded.
+func (m *Metrics) Snapshot(installedArtifacts int, now time.Time) *Snapshot {
+	var recent []string
+	for _, u := range m.Updates {
+		t, err := time.Parse(time.RFC3339, u)
+		if err != nil || now.Sub(t) > UpdatesWindow {
+			continue
+		}
+		recent = append(recent, u)
+	}
+	m.Updates = recent
+
+	return &Snapshot{
+		InstalledArtifacts:  installedArtifacts,
+		RecentUpdates:       len(recent),
+		VerificationsPassed: m.VerificationsPassed,
+		VerificationsFailed: m.VerificationsFailed,
+		BytesTransferred:    m.BytesTransferred,
+	}
+}
+
+// WritePrometheus writes the snapshot in the Prometheus text exposition format.
+func (s *Snapshot) WritePrometheus(w io.Writer) error {
+	metrics := []struct {
+		name, help, kind string
+		samples          []string
+	}{
+		{"falcoctl_installed_artifacts", "Number of artifacts installed by falcoctl.", "gauge",
+			[]string{fmt.Sprintf("falcoctl_installed_artifacts %d", s.InstalledArtifacts)}},
+		{"falcoctl_updates_last_30_days", "Number of artifact updates in the last 30 days.", "gauge",
+			[]string{fmt.Sprintf("falcoctl_updates_last_30_days %d", s.RecentUpdates)}},
+		{"falcoctl_verifications_total", "Number of artifact verifications by result.", "counter",
+			[]string{
+				fmt.Sprintf(`falcoctl_verifications_total{result="pass"} %d`, s.VerificationsPassed),
+				fmt.Sprintf(`falcoctl_verifications_total{result="fail"} %d`, s.VerificationsFailed),
+			}},
+		{"falcoctl_transferred_bytes_total", "Number of bytes transferred from and to remote registries.", "counter",
+			[]string{fmt.Sprintf("falcoctl_transferred_bytes_total %d", s.BytesTransferred)}},
+	}
+
+	for _, m := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind); err != nil {
+			return err
+		}
+		for _, sample := range m.samples {
+			if _, err := fmt.Fprintln(w, sample); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
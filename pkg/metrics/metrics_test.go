@@ -0,0 +1,76 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpdateAndSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "falcoctl", "metrics.yaml")
+	now := time.Now()
+
+	err := Update(path, func(m *Metrics) {
+		m.RecordUpdate(now.Add(-40 * 24 * time.Hour))
+		m.RecordUpdate(now.Add(-time.Hour))
+		m.RecordVerification(true)
+		m.RecordVerification(false)
+		m.RecordVerification(true)
+		m.AddBytesTransferred(1024)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Snapshot(2, now)
+	if s.InstalledArtifacts != 2 || s.RecentUpdates != 1 {
+		t.Errorf("unexpected artifacts or updates in snapshot: %+v", s)
+	}
+	if s.VerificationsPassed != 2 || s.VerificationsFailed != 1 {
+		t.Errorf("unexpected verifications in snapshot: %+v", s)
+	}
+	if s.BytesTransferred != 1024 {
+		t.Errorf("unexpected bytes in snapshot: %+v", s)
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	s := &Snapshot{InstalledArtifacts: 3, VerificationsPassed: 4, BytesTransferred: 512}
+
+	var buf bytes.Buffer
+	if err := s.WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"# TYPE falcoctl_installed_artifacts gauge\nfalcoctl_installed_artifacts 3\n",
+		"# TYPE falcoctl_verifications_total counter\n",
+		`falcoctl_verifications_total{result="pass"} 4`,
+		"falcoctl_transferred_bytes_total 512\n",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in output:\n%s", expected, buf.String())
+		}
+	}
+}
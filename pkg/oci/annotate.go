@@ -0,0 +1,172 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// readOnlyFields are the fields of manifests and indexes, or of their descriptors, derived from their content.
+// They cannot be set as annotations, to avoid confusing them with the actual fields.
+var readOnlyFields = map[string]bool{
+	"schemaVersion": true,
+	"mediaType":     true,
+	"artifactType":  true,
+	"config":        true,
+	"layers":        true,
+	"manifests":     true,
+	"subject":       true,
+	"digest":        true,
+	"size":          true,
+}
+
+// SetAnnotation sets the annotation key to value in the manifest or index encoded in data. All the other fields are
+// preserved as they are. It returns the encoded manifest and false if the annotation was already set to value.
+func SetAnnotation(data []byte, key, value string) ([]byte, bool, error) {
+	if key == "" {
+		return nil, false, fmt.Errorf("annotation keys cannot be empty")
+	}
+	if readOnlyFields[key] {
+		return nil, false, fmt.Errorf("%q is derived from the content of the artifact and cannot be set as annotation", key)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, false, fmt.Errorf("unable to unmarshal manifest: %w", err)
+	}
+
+	annotations := make(map[string]string)
+	if raw, ok := fields["annotations"]; ok {
+		if err := json.Unmarshal(raw, &annotations); err != nil {
+			return nil, false, fmt.Errorf("unable to unmarshal manifest annotations: %w", err)
+		}
+	}
+
+	if current, ok := annotations[key]; ok && current == value {
+		return data, false, nil
+	}
+	annotations[key] = value
+
+	raw, err := json.Marshal(annotations)
+	if err != nil {
+		return nil, false, err
+	}
+	fields["annotations"] = raw
+
+	if data, err = json.Marshal(fields); err != nil {
+		return nil, false, fmt.Errorf("unable to marshal manifest: %w", err)
+	}
+
+	return data, true, nil
+}
+
+// FetchAnnotations returns the descriptor and the annotations of the manifest, or the index, referenced by ref.
+func FetchAnnotations(ctx context.Context, ref string, client *auth.Client) (*v1.Descriptor, map[string]string, error) {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create new repository with ref %s: %w", ref, err)
+	}
+	repo.Client = client
+
+	if repo.Reference.Reference == "" {
+		repo.Reference.Reference = DefaultTag
+	}
+
+	desc, reader, err := repo.FetchReference(ctx, repo.Reference.Reference)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer reader.Close()
+
+	if !IsManifest(desc.MediaType) && !IsIndex(desc.MediaType) {
+		return nil, nil, fmt.Errorf("unable to read annotations of %s of media type %q", desc.Digest, desc.MediaType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, DefaultMaxMetadataSize))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fields struct {
+		Annotations map[string]string `json:"annotations"`
+	}
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, nil, fmt.Errorf("unable to unmarshal manifest: %w", err)
+	}
+
+	return &desc, fields.Annotations, nil
+}
+
+// UpdateAnnotation sets the annotation key to value in the manifest, or the index, referenced by ref and pushes it
+// again. Since the digest depends on the content, the updated manifest has a new digest: if ref holds a tag, the tag
+// is moved to it, otherwise it is only pushed by digest. It returns the old and the new digest, the same if the
+// annotation was already set to value.
+func UpdateAnnotation(ctx context.Context, ref string, client *auth.Client, key, value string) (oldDigest, newDigest string, err error) {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to create new repository with ref %s: %w", ref, err)
+	}
+	repo.Client = client
+
+	if repo.Reference.Reference == "" {
+		repo.Reference.Reference = DefaultTag
+	}
+
+	desc, reader, err := repo.FetchReference(ctx, repo.Reference.Reference)
+	if err != nil {
+		return "", "", err
+	}
+	defer reader.Close()
+
+	if !IsManifest(desc.MediaType) && !IsIndex(desc.MediaType) {
+		return "", "", fmt.Errorf("unable to set annotations on %s of media type %q", desc.Digest, desc.MediaType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, DefaultMaxMetadataSize))
+	if err != nil {
+		return "", "", err
+	}
+
+	data, changed, err := SetAnnotation(data, key, value)
+	if err != nil || !changed {
+		return desc.Digest.String(), desc.Digest.String(), err
+	}
+
+	newDesc := v1.Descriptor{
+		MediaType: desc.MediaType,
+		Digest:    desc.Digest.Algorithm().FromBytes(data),
+		Size:      int64(len(data)),
+	}
+
+	// References by digest cannot be moved, the updated manifest is only pushed.
+	if _, err = repo.Reference.Digest(); err == nil {
+		err = repo.Push(ctx, newDesc, bytes.NewReader(data))
+	} else {
+		err = repo.PushReference(ctx, newDesc, bytes.NewReader(data), repo.Reference.Reference)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("unable to push the updated manifest: %w", err)
+	}
+
+	return desc.Digest.String(), newDesc.Digest.String(), nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSetAnnotation(t *testing.T) {
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json",` +
+		`"config":{"mediaType":"application/vnd.cncf.falco.rulesfile.config.v1+json","digest":"` + testDigest + `","size":2},` +
+		`"layers":[],"annotations":{"org.opencontainers.image.created":"2023-01-01T00:00:00Z"},"unknown":"kept"}`)
+
+	data, changed, err := SetAnnotation(manifest, "io.falcosecurity.advisory", "CVE-2023-0001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Fatal("expected the manifest to change")
+	}
+
+	var updated struct {
+		SchemaVersion int               `json:"schemaVersion"`
+		Annotations   map[string]string `json:"annotations"`
+		Unknown       string            `json:"unknown"`
+	}
+	if err = json.Unmarshal(data, &updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.Annotations["io.falcosecurity.advisory"] != "CVE-2023-0001" {
+		t.Errorf("annotation not set, got %v", updated.Annotations)
+	}
+	if updated.Annotations["org.opencontainers.image.created"] == "" || updated.Unknown != "kept" || updated.SchemaVersion != 2 {
+		t.Errorf("existing fields not preserved, got %s", data)
+	}
+
+	if _, changed, err = SetAnnotation(data, "io.falcosecurity.advisory", "CVE-2023-0001"); err != nil || changed {
+		t.Errorf("expected no change setting the same value, got changed %v, error %v", changed, err)
+	}
+
+	if _, _, err = SetAnnotation(manifest, "digest", "sha256:123"); err == nil {
+		t.Error("expected an error setting a field derived from the content")
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// attachedTagRgx matches the tags of the manifests attached by cosign, i.e. "<alg>-<hex>.<suffix>".
+var attachedTagRgx = regexp.MustCompile(`^[a-z0-9]+-[a-f0-9]{32,}\.[a-z]+$`)
+
+// IsAttachedTag returns true if tag is the one of a manifest attached by cosign to an artifact, e.g. a
+// signature in "sha256-123abc.sig", rather than a version of the artifact.
+func IsAttachedTag(tag string) bool {
+	return attachedTagRgx.MatchString(tag)
+}
+
+// AttachedLayers returns the layers with one of the given media types of the manifests attached to the
+// artifact pointed by ref: first the ones of the manifest stored by cosign in the "<alg>-<hex>.<suffix>" tag,
+// e.g. "sha256-123abc.sbom", then the ones of the referrers. If suffix is empty, only the referrers are considered.
+// If the referrers could be retrieved only partially, the layers found are returned along with an error wrapping
+// ErrIncompleteReferrers.
+func AttachedLayers(ctx context.Context, ref string, client *auth.Client, suffix string, mediaTypes ...string) ([]v1.Descriptor, error) {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new repository with ref %s: %w", ref, err)
+	}
+	repo.Client = client
+
+	desc, err := repo.Resolve(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifests []v1.Descriptor
+	if suffix != "" {
+		cosignTag := strings.Replace(desc.Digest.String(), ":", "-", 1) + "." + suffix
+		cosignDesc, err := repo.Resolve(ctx, cosignTag)
+		switch {
+		case err == nil:
+			manifests = append(manifests, cosignDesc)
+		case !errors.Is(err, errdef.ErrNotFound):
+			return nil, fmt.Errorf("unable to resolve tag %s: %w", cosignTag, err)
+		}
+	}
+
+	referrers, incomplete := Referrers(ctx, client, ref, desc.Digest, false)
+	if incomplete != nil && !errors.Is(incomplete, ErrIncompleteReferrers) {
+		return nil, incomplete
+	}
+	manifests = append(manifests, referrers...)
+
+	var layers []v1.Descriptor
+	for i := range manifests {
+		if !IsManifest(manifests[i].MediaType) {
+			continue
+		}
+
+		data, err := fetchBlob(ctx, repo, &manifests[i], DefaultMaxMetadataSize)
+		if err != nil {
+			return nil, err
+		}
+
+		var manifest v1.Manifest
+		if err = json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal manifest %s: %w", manifests[i].Digest, err)
+		}
+
+		for j := range manifest.Layers {
+			for _, mediaType := range mediaTypes {
+				if manifest.Layers[j].MediaType == mediaType {
+					layers = append(layers, manifest.Layers[j])
+					break
+				}
+			}
+		}
+	}
+
+	return layers, incomplete
+}
+
+// FetchBlob fetches the content of desc from the repository pointed by ref, reading up to maxSize bytes.
+func FetchBlob(ctx context.Context, ref string, client *auth.Client, desc *v1.Descriptor, maxSize int64) ([]byte, error) {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new repository with ref %s: %w", ref, err)
+	}
+	repo.Client = client
+
+	return fetchBlob(ctx, repo, desc, maxSize)
+}
+
+func fetchBlob(ctx context.Context, fetcher content.Fetcher, desc *v1.Descriptor, maxSize int64) ([]byte, error) {
+	reader, err := fetcher.Fetch(ctx, *desc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch %s: %w", desc.Digest, err)
+	}
+	defer reader.Close()
+
+	return io.ReadAll(io.LimitReader(reader, maxSize))
+}
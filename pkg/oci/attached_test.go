@@ -0,0 +1,34 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import "testing"
+
+func TestIsAttachedTag(t *testing.T) {
+	tests := map[string]bool{
+		"sha256-6c3c624b58dbbcd3c0dd82b4c53f04194d1247c6eebdaab7c610cf7d66709b3b.sig":  true,
+		"sha256-6c3c624b58dbbcd3c0dd82b4c53f04194d1247c6eebdaab7c610cf7d66709b3b.att":  true,
+		"sha256-6c3c624b58dbbcd3c0dd82b4c53f04194d1247c6eebdaab7c610cf7d66709b3b.sbom": true,
+		"0.1.0":        false,
+		"latest":       false,
+		"sha256-1.sig": false,
+	}
+
+	for tag, expected := range tests {
+		if got := IsAttachedTag(tag); got != expected {
+			t.Errorf("%s: expected %v, got %v", tag, expected, got)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authn
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+var (
+	// ErrNoTokenAuth error when the registry does not use token authentication,
+	// hence the granted access cannot be determined.
+	ErrNoTokenAuth = errors.New("registry does not use token authentication")
+
+	challengeParamRgx = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+type tokenResponse struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+}
+
+type tokenClaims struct {
+	Access []struct {
+		Type    string   `json:"type"`
+		Name    string   `json:"name"`
+		Actions []string `json:"actions"`
+	} `json:"access"`
+}
+
+// Access requests a token for the pull and push actions on a repository and returns the actions
+// actually granted by the registry to the given credentials.
+func Access(ctx context.Context, client *http.Client, registry, repository string,
+	cred auth.Credential, plainHTTP bool) ([]string, error) {
+	scheme := "https"
+	if plainHTTP {
+		scheme = "http"
+	}
+
+	realm, service, err := bearerChallenge(ctx, client, fmt.Sprintf("%s://%s/v2/", scheme, registry))
+	if err != nil {
+		return nil, err
+	}
+
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return nil, fmt.Errorf("invalid realm %q: %w", realm, err)
+	}
+	query := tokenURL.Query()
+	if service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull,push", repository))
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	if cred.Username != "" || cred.Password != "" {
+		req.SetBasicAuth(cred.Username, cred.Password)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token request to %q failed: %s", tokenURL.Host, resp.Status)
+	}
+
+	var token tokenResponse
+	if err = json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("unable to decode token response: %w", err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+
+	claims, err := parseClaims(token.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []string
+	for _, access := range claims.Access {
+		if access.Type == "repository" && access.Name == repository {
+			actions = append(actions, access.Actions...)
+		}
+	}
+
+	return actions, nil
+}
+
+// bearerChallenge returns the realm and the service of the bearer challenge sent by the registry.
+func bearerChallenge(ctx context.Context, client *http.Client, endpoint string) (realm, service string, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return "", "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		return "", "", fmt.Errorf("unexpected status %q from %q: %w", resp.Status, endpoint, ErrNoTokenAuth)
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return "", "", fmt.Errorf("challenge %q: %w", challenge, ErrNoTokenAuth)
+	}
+
+	for _, match := range challengeParamRgx.FindAllStringSubmatch(challenge, -1) {
+		switch strings.ToLower(match[1]) {
+		case "realm":
+			realm = match[2]
+		case "service":
+			service = match[2]
+		}
+	}
+
+	if realm == "" {
+		return "", "", fmt.Errorf("no realm in challenge %q", challenge)
+	}
+
+	return realm, service, nil
+}
+
+// parseClaims decodes the claims of a JWT token without verifying its signature.
+func parseClaims(token string) (*tokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("the token returned by the registry is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode token payload: %w", err)
+	}
+
+	var claims tokenClaims
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal token claims: %w", err)
+	}
+
+	return &claims, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authn
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+func newTokenServer(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/token":
+			if r.URL.Query().Get("service") != "test" {
+				t.Errorf("unexpected service %q", r.URL.Query().Get("service"))
+			}
+			actions := `["pull"]`
+			if user, pass, ok := r.BasicAuth(); ok && user == "user" && pass == "pass" {
+				actions = `["pull","push"]`
+			}
+			payload := base64.RawURLEncoding.EncodeToString([]byte(
+				`{"access":[{"type":"repository","name":"falcosecurity/plugins","actions":` + actions + `}]}`))
+			fmt.Fprintf(w, `{"token":"header.%s.signature"}`, payload)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return server
+}
+
+func TestAccess(t *testing.T) {
+	server := newTokenServer(t)
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	actions, err := Access(context.Background(), server.Client(), host, "falcosecurity/plugins",
+		auth.Credential{Username: "user", Password: "pass"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(actions, ",") != "pull,push" {
+		t.Errorf("expected pull and push access, got %v", actions)
+	}
+
+	actions, err = Access(context.Background(), server.Client(), host, "falcosecurity/plugins", auth.EmptyCredential, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(actions, ",") != "pull" {
+		t.Errorf("expected pull access, got %v", actions)
+	}
+
+	actions, err = Access(context.Background(), server.Client(), host, "other/repo", auth.EmptyCredential, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 0 {
+		t.Errorf("expected no access, got %v", actions)
+	}
+}
+
+func TestAccessNoTokenAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	_, err := Access(context.Background(), server.Client(), strings.TrimPrefix(server.URL, "http://"),
+		"falcosecurity/plugins", auth.EmptyCredential, true)
+	if !errors.Is(err, ErrNoTokenAuth) {
+		t.Errorf("expected ErrNoTokenAuth, got %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+const (
+	// defaultTokenExpiration is the lifetime of the tokens issued without "expires_in", as per the
+	// token authentication specification of the distribution registry.
+	defaultTokenExpiration = 60 * time.Second
+	// tokenExpirationMargin is subtracted from the lifetime of the tokens, so that they do not expire
+	// while a request using them is in flight.
+	tokenExpirationMargin = 5 * time.Second
+	// maxTokenResponseSize is the maximum size in bytes of the responses of the authorization servers.
+	maxTokenResponseSize = 128 * 1024
+)
+
+var (
+	cachesMu sync.Mutex
+	// caches are the token caches shared by the clients created by NewClient, one per credential,
+	// so that the tokens issued for a credential are never sent along with another one.
+	caches = map[auth.Credential]*tokenCache{}
+)
+
+// sharedCache returns the token cache for the given credential, creating it if needed.
+func sharedCache(cred auth.Credential) *tokenCache {
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+
+	cache, ok := caches[cred]
+	if !ok {
+		cache = newTokenCache()
+		caches[cred] = cache
+	}
+
+	return cache
+}
+
+type tokenKey struct {
+	registry string
+	scheme   auth.Scheme
+	key      string
+}
+
+type cachedToken struct {
+	token string
+	// expiresAt is zero for the tokens that do not expire, e.g. basic authentication ones.
+	expiresAt time.Time
+}
+
+// tokenFetch is a token fetch in progress, shared by all the callers asking for the same token.
+type tokenFetch struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// tokenCache implements auth.Cache. Unlike the oras one, the bearer tokens are evicted when they expire,
+// according to the "expires_in" field returned by the authorization server. It is safe for concurrent use.
+type tokenCache struct {
+	mu       sync.Mutex
+	schemes  map[string]auth.Scheme
+	tokens   map[tokenKey]cachedToken
+	inflight map[tokenKey]*tokenFetch
+	now      func() time.Time
+}
+
+func newTokenCache() *tokenCache {
+	return &tokenCache{
+		schemes:  make(map[string]auth.Scheme),
+		tokens:   make(map[tokenKey]cachedToken),
+		inflight: make(map[tokenKey]*tokenFetch),
+		now:      time.Now,
+	}
+}
+
+// GetScheme returns the auth scheme discovered for the given registry.
+func (c *tokenCache) GetScheme(_ context.Context, registry string) (auth.Scheme, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	scheme, ok := c.schemes[registry]
+	if !ok {
+		return auth.SchemeUnknown, errdef.ErrNotFound
+	}
+
+	return scheme, nil
+}
+
+// GetToken returns the token cached for the given registry, scheme and scope key, if not expired.
+func (c *tokenCache) GetToken(_ context.Context, registry string, scheme auth.Scheme, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k := tokenKey{registry: registry, scheme: scheme, key: key}
+	cached, ok := c.tokens[k]
+	if !ok {
+		return "", errdef.ErrNotFound
+	}
+
+	if !cached.expiresAt.IsZero() && !c.now().Before(cached.expiresAt) {
+		delete(c.tokens, k)
+		return "", errdef.ErrNotFound
+	}
+
+	return cached.token, nil
+}
+
+// Set fetches the token using the fetch function and caches it. Concurrent calls for the same token
+// share a single fetch.
+func (c *tokenCache) Set(ctx context.Context, registry string, scheme auth.Scheme, key string,
+	fetch func(context.Context) (string, error)) (string, error) {
+	k := tokenKey{registry: registry, scheme: scheme, key: key}
+
+	c.mu.Lock()
+	if f, ok := c.inflight[k]; ok {
+		c.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.token, f.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	f := &tokenFetch{done: make(chan struct{})}
+	c.inflight[k] = f
+	c.mu.Unlock()
+
+	recorder := &expirationRecorder{}
+	f.token, f.err = fetch(context.WithValue(ctx, expirationRecorderKey{}, recorder))
+
+	c.mu.Lock()
+	delete(c.inflight, k)
+	if f.err == nil {
+		// A scheme change is not expected, drop the tokens of the old one.
+		if old, ok := c.schemes[registry]; ok && old != scheme {
+			for cached := range c.tokens {
+				if cached.registry == registry {
+					delete(c.tokens, cached)
+				}
+			}
+		}
+		c.schemes[registry] = scheme
+
+		cached := cachedToken{token: f.token}
+		if scheme == auth.SchemeBearer {
+			expiresIn := defaultTokenExpiration
+			if recorder.expiresIn > 0 {
+				expiresIn = recorder.expiresIn
+			}
+			cached.expiresAt = c.now().Add(expiresIn - tokenExpirationMargin)
+		}
+		c.tokens[k] = cached
+	}
+	c.mu.Unlock()
+	close(f.done)
+
+	return f.token, f.err
+}
+
+type expirationRecorderKey struct{}
+
+// expirationRecorder receives the lifetime of the token fetched with the context it is attached to.
+type expirationRecorder struct {
+	expiresIn time.Duration
+}
+
+// expirationTransport records the lifetime of the tokens issued by the authorization servers, since the
+// oras client only returns the tokens themselves.
+type expirationTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *expirationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	recorder, ok := req.Context().Value(expirationRecorderKey{}).(*expirationRecorder)
+	if err != nil || !ok || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenResponseSize))
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	var token struct {
+		ExpiresIn int64 `json:"expires_in"`
+	}
+	if err = json.Unmarshal(body, &token); err == nil && token.ExpiresIn > 0 {
+		recorder.expiresIn = time.Duration(token.ExpiresIn) * time.Second
+	}
+
+	return resp, nil
+}
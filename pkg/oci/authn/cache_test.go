@@ -0,0 +1,130 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+func TestTokenCacheExpiration(t *testing.T) {
+	var issued int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&issued, 1)
+		fmt.Fprintf(w, `{"token":"token-%d","expires_in":300}`, n)
+	}))
+	defer server.Close()
+
+	httpClient := &http.Client{Transport: &expirationTransport{base: http.DefaultTransport}}
+	fetch := func(ctx context.Context) (string, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, http.NoBody)
+		if err != nil {
+			return "", err
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		var body struct {
+			Token string `json:"token"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		return body.Token, err
+	}
+
+	now := time.Now()
+	cache := newTokenCache()
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := cache.GetScheme(ctx, "registry"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("expected no scheme, got %v", err)
+	}
+
+	token, err := cache.Set(ctx, "registry", auth.SchemeBearer, "repository:falco:pull", fetch)
+	if err != nil || token != "token-1" {
+		t.Fatalf("unexpected token %q, %v", token, err)
+	}
+
+	if scheme, err := cache.GetScheme(ctx, "registry"); err != nil || scheme != auth.SchemeBearer {
+		t.Fatalf("unexpected scheme %v, %v", scheme, err)
+	}
+
+	now = now.Add(4 * time.Minute)
+	if token, err = cache.GetToken(ctx, "registry", auth.SchemeBearer, "repository:falco:pull"); err != nil || token != "token-1" {
+		t.Fatalf("expected cached token, got %q, %v", token, err)
+	}
+	if _, err = cache.GetToken(ctx, "registry", auth.SchemeBearer, "repository:falco:push"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("expected no token for another scope, got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err = cache.GetToken(ctx, "registry", auth.SchemeBearer, "repository:falco:pull"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("expected expired token, got %v", err)
+	}
+}
+
+func TestTokenCacheConcurrentSet(t *testing.T) {
+	var fetched int32
+	release := make(chan struct{})
+	fetch := func(ctx context.Context) (string, error) {
+		atomic.AddInt32(&fetched, 1)
+		<-release
+		return "token", nil
+	}
+
+	cache := newTokenCache()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if token, err := cache.Set(context.Background(), "registry", auth.SchemeBearer, "scope", fetch); err != nil || token != "token" {
+				t.Errorf("unexpected token %q, %v", token, err)
+			}
+		}()
+	}
+
+	// Let the callers queue up behind the first fetch.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&fetched); n != 1 {
+		t.Errorf("expected a single fetch, got %d", n)
+	}
+}
+
+func TestSharedCache(t *testing.T) {
+	cred := auth.Credential{Username: "user", Password: "pass"}
+	if sharedCache(cred) != sharedCache(cred) {
+		t.Errorf("expected the same cache for the same credential")
+	}
+	if sharedCache(cred) == sharedCache(auth.EmptyCredential) {
+		t.Errorf("expected different caches for different credentials")
+	}
+}
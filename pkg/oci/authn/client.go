@@ -28,24 +28,29 @@ const (
 )
 
 // NewClient creates a new authenticated client to interact with a remote registry.
+// The clients created with the same credential share the discovered auth schemes and the issued
+// tokens, which are reused until they expire. The headers set by SetHeaders are only sent to the
+// registry hosts.
 func NewClient(cred auth.Credential) *auth.Client {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		// TODO(loresuso, alacuku): tls config.
+	}
+
 	client := &auth.Client{
 		Client: &http.Client{
-			Transport: &http.Transport{
-				Proxy: http.ProxyFromEnvironment,
-				DialContext: (&net.Dialer{
-					Timeout:   30 * time.Second,
-					KeepAlive: 30 * time.Second,
-				}).DialContext,
-				ForceAttemptHTTP2:     true,
-				MaxIdleConns:          100,
-				IdleConnTimeout:       90 * time.Second,
-				TLSHandshakeTimeout:   10 * time.Second,
-				ExpectContinueTimeout: 1 * time.Second,
-				// TODO(loresuso, alacuku): tls config.
-			},
+			Transport: newHeaderTransport(&expirationTransport{base: transport}, headers),
 		},
-		Cache: auth.NewCache(),
+		Cache: sharedCache(cred),
 		Credential: func(ctx context.Context, registry string) (auth.Credential, error) {
 			return cred, nil
 		},
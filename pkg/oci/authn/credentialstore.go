@@ -29,6 +29,9 @@ import (
 	"oras.land/oras-go/v2/registry/remote/auth"
 )
 
+// dockerHubServerAddress is the key of the Docker Hub credentials saved by "docker login".
+const dockerHubServerAddress = "https://index.docker.io/v1/"
+
 // Store provides credential CRUD operations.
 type Store struct {
 	configs []*configfile.ConfigFile
@@ -118,19 +121,33 @@ func (s *Store) Erase(registry string) error {
 // credential in a best-effort way.
 func (s *Store) Credential(ctx context.Context, registry string) (auth.Credential, error) {
 	for _, c := range s.configs {
-		authConf, err := c.GetCredentialsStore(registry).Get(registry)
-		if err != nil {
-			return auth.EmptyCredential, err
-		}
-		cred := auth.Credential{
-			Username:     authConf.Username,
-			Password:     authConf.Password,
-			AccessToken:  authConf.RegistryToken,
-			RefreshToken: authConf.IdentityToken,
-		}
-		if cred != auth.EmptyCredential {
-			return cred, nil
+		for _, key := range credentialKeys(registry) {
+			authConf, err := c.GetCredentialsStore(key).Get(key)
+			if err != nil {
+				return auth.EmptyCredential, err
+			}
+			cred := auth.Credential{
+				Username:     authConf.Username,
+				Password:     authConf.Password,
+				AccessToken:  authConf.RegistryToken,
+				RefreshToken: authConf.IdentityToken,
+			}
+			if cred != auth.EmptyCredential {
+				return cred, nil
+			}
 		}
 	}
 	return auth.EmptyCredential, nil
 }
+
+// credentialKeys returns the keys the credentials of registry may be stored under. The docker CLI
+// stores the Docker Hub credentials under its legacy index address, while references to Docker Hub
+// are resolved against the host serving its registry API.
+func credentialKeys(registry string) []string {
+	switch registry {
+	case "docker.io", "index.docker.io", "registry-1.docker.io":
+		return []string{dockerHubServerAddress, "docker.io", "index.docker.io"}
+	default:
+		return []string{registry}
+	}
+}
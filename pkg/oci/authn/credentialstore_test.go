@@ -0,0 +1,58 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authn
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+func TestCredentialDockerHub(t *testing.T) {
+	// The config file written by "docker login" for Docker Hub.
+	path := filepath.Join(t.TempDir(), "config.json")
+	encoded := base64.StdEncoding.EncodeToString([]byte("user:secret"))
+	config := `{"auths":{"https://index.docker.io/v1/":{"auth":"` + encoded + `"}}}`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, registry := range []string{"registry-1.docker.io", "docker.io", "index.docker.io"} {
+		cred, err := store.Credential(context.Background(), registry)
+		if err != nil {
+			t.Fatalf("%s: %v", registry, err)
+		}
+		if cred.Username != "user" || cred.Password != "secret" {
+			t.Errorf("%s: unexpected credential %+v", registry, cred)
+		}
+	}
+
+	cred, err := store.Credential(context.Background(), "ghcr.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cred != auth.EmptyCredential {
+		t.Errorf("expected no credential for ghcr.io, got %+v", cred)
+	}
+}
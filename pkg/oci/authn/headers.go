@@ -0,0 +1,110 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authn
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// headers are the additional headers set on the registry requests sent by the clients created by NewClient.
+var headers = http.Header{}
+
+// sensitiveHeaderKeywords identify, case insensitively, the headers whose values must not be printed.
+var sensitiveHeaderKeywords = []string{"authorization", "cookie", "token", "secret", "key", "password"}
+
+// SetHeaders sets the additional headers, in KEY=VALUE format, added to the registry requests sent by the
+// clients created afterwards by NewClient. It is meant to be called once, before any client is created.
+// The headers are only sent to the registry hosts, see headerTransport.
+func SetHeaders(values []string) error {
+	h := http.Header{}
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("header %q seems to be in the wrong format: needs to be in KEY=VALUE", v)
+		}
+		h.Add(key, value)
+	}
+
+	headers = h
+
+	return nil
+}
+
+// RedactHeader returns the value of the header key, replaced by a placeholder if the header is sensitive.
+func RedactHeader(key, value string) string {
+	lower := strings.ToLower(key)
+	for _, keyword := range sensitiveHeaderKeywords {
+		if strings.Contains(lower, keyword) {
+			return "<redacted>"
+		}
+	}
+
+	return value
+}
+
+// headerTransport adds the additional headers to the requests sent to the registry hosts, i.e. the hosts
+// serving the /v2/ registry API. The other requests sent to those hosts, e.g. to get a token, carry them as
+// well, while authorization servers and blob storages on other hosts, including redirect targets, never do.
+type headerTransport struct {
+	base   http.RoundTripper
+	header http.Header
+
+	mu         sync.Mutex
+	registries map[string]struct{}
+}
+
+func newHeaderTransport(base http.RoundTripper, header http.Header) *headerTransport {
+	return &headerTransport{
+		base:       base,
+		header:     header,
+		registries: make(map[string]struct{}),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.header) == 0 || !t.isRegistry(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	// A RoundTripper must not modify the request.
+	req = req.Clone(req.Context())
+	for key, values := range t.header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// isRegistry reports whether req is sent to a registry host, recording the host of the registry API
+// requests not resulting from a redirect.
+func (t *headerTransport) isRegistry(req *http.Request) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if req.Response == nil && (req.URL.Path == "/v2" || strings.HasPrefix(req.URL.Path, "/v2/")) {
+		t.registries[req.URL.Host] = struct{}{}
+		return true
+	}
+
+	_, ok := t.registries[req.URL.Host]
+	return ok
+}
@@ -0,0 +1,86 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authn
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+func TestSetHeaders(t *testing.T) {
+	t.Cleanup(func() {
+		if err := SetHeaders(nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if err := SetHeaders([]string{"X-Gateway-Route=falco", "X-Tenant=a=b"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	received := map[string]http.Header{}
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received[name+r.URL.Path] = r.Header.Clone()
+		})
+	}
+	registry := httptest.NewServer(handler("registry"))
+	defer registry.Close()
+	other := httptest.NewServer(handler("other"))
+	defer other.Close()
+
+	client := NewClient(auth.EmptyCredential)
+	for _, u := range []string{registry.URL + "/v2/", registry.URL + "/token", other.URL + "/token"} {
+		resp, err := client.Client.Get(u)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	for _, path := range []string{"registry/v2/", "registry/token"} {
+		if got := received[path].Get("X-Gateway-Route"); got != "falco" {
+			t.Errorf("%s: expected header value %q, got %q", path, "falco", got)
+		}
+		if got := received[path].Get("X-Tenant"); got != "a=b" {
+			t.Errorf("%s: expected header value %q, got %q", path, "a=b", got)
+		}
+	}
+	if got := received["other/token"].Get("X-Gateway-Route"); got != "" {
+		t.Errorf("expected no header sent to other hosts, got %q", got)
+	}
+	if got := client.Header.Get("User-Agent"); got != falcoctlUserAgent {
+		t.Errorf("expected user agent %q, got %q", falcoctlUserAgent, got)
+	}
+
+	if err := SetHeaders([]string{"no-value"}); err == nil {
+		t.Errorf("expected error for malformed header")
+	}
+}
+
+func TestRedactHeader(t *testing.T) {
+	if got := RedactHeader("X-Api-Key", "s3cr3t"); got != "<redacted>" {
+		t.Errorf("expected redacted value, got %q", got)
+	}
+	if got := RedactHeader("Authorization", "Bearer abc"); got != "<redacted>" {
+		t.Errorf("expected redacted value, got %q", got)
+	}
+	if got := RedactHeader("X-Gateway-Route", "falco"); got != "falco" {
+		t.Errorf("expected plain value, got %q", got)
+	}
+}
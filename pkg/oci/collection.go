@@ -0,0 +1,71 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"fmt"
+
+	"oras.land/oras-go/v2/registry"
+)
+
+// CollectionConfig is the struct stored in the config layer of collection artifacts. Collections do not embed the
+// content of their members: they reference them by digest, so that curated bundles do not duplicate any blob.
+type CollectionConfig struct {
+	Members []CollectionMember `json:"members" yaml:"members"`
+}
+
+// CollectionMember is an artifact referenced by a collection.
+type CollectionMember struct {
+	// Ref is the reference of the member pinned to its digest, e.g. "ghcr.io/org/rules@sha256:123abc...".
+	Ref string `json:"ref" yaml:"ref"`
+	// Version is the tag the member was resolved from, if any.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Type is the type of the member, either a rulesfile or a plugin.
+	Type ArtifactType `json:"type" yaml:"type"`
+}
+
+// Refs returns the references of the members of the collection.
+func (c *CollectionConfig) Refs() []string {
+	refs := make([]string, len(c.Members))
+	for i := range c.Members {
+		refs[i] = c.Members[i].Ref
+	}
+	return refs
+}
+
+// Validate checks that the collection has members, that each of them is pinned to a digest and that
+// none of them is a collection itself.
+func (c *CollectionConfig) Validate() error {
+	if len(c.Members) == 0 {
+		return fmt.Errorf("a collection must have at least one member")
+	}
+
+	for _, member := range c.Members {
+		parsedRef, err := registry.ParseReference(member.Ref)
+		if err != nil {
+			return fmt.Errorf("member %q: %w", member.Ref, err)
+		}
+		if _, err = parsedRef.Digest(); err != nil {
+			return fmt.Errorf("member %q is not pinned to a digest", member.Ref)
+		}
+		switch member.Type {
+		case Rulesfile, Plugin:
+		default:
+			return fmt.Errorf("member %q of type %q: only rulesfiles and plugins can be members of a collection", member.Ref, member.Type)
+		}
+	}
+
+	return nil
+}
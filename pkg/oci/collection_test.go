@@ -0,0 +1,75 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const testDigest = "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
+
+func TestCollectionConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		members []CollectionMember
+		valid   bool
+	}{
+		{
+			name: "pinned members",
+			members: []CollectionMember{
+				{Ref: "ghcr.io/org/rules@" + testDigest, Version: "1.0.0", Type: Rulesfile},
+				{Ref: "ghcr.io/org/plugin@" + testDigest, Type: Plugin},
+			},
+			valid: true,
+		},
+		{
+			name: "no members",
+		},
+		{
+			name:    "member not pinned",
+			members: []CollectionMember{{Ref: "ghcr.io/org/rules:1.0.0", Type: Rulesfile}},
+		},
+		{
+			name:    "nested collection",
+			members: []CollectionMember{{Ref: "ghcr.io/org/bundle@" + testDigest, Type: Collection}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := CollectionConfig{Members: tt.members}
+			if err := config.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate() error = %v, expected valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestArtifactTypeOfCollection(t *testing.T) {
+	manifest := v1.Manifest{
+		Config: v1.Descriptor{MediaType: FalcoCollectionConfigMediaType},
+		Layers: []v1.Descriptor{{MediaType: EmptyJSONMediaType}},
+	}
+
+	artifactType, err := ArtifactTypeOf(&manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artifactType != Collection {
+		t.Errorf("expected type %q, got %q", Collection, artifactType)
+	}
+}
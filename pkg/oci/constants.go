@@ -28,9 +28,68 @@ const (
 	// FalcoPluginLayerMediaType is the MediaType for plugins.
 	FalcoPluginLayerMediaType = "application/vnd.cncf.falco.plugin.layer.v1+tar.gz"
 
+	// FalcoCollectionConfigMediaType is the MediaType for collection's config layer, listing the members.
+	FalcoCollectionConfigMediaType = "application/vnd.cncf.falco.collection.config.v1+json"
+
+	// EmptyJSONMediaType is the MediaType of the empty JSON object "{}", used as the only layer of collections.
+	EmptyJSONMediaType = "application/vnd.oci.empty.v1+json"
+
+	// MarkdownMediaType is the MediaType of the layers holding the documentation of an artifact, e.g. its README.
+	MarkdownMediaType = "text/markdown"
+
+	// DockerManifestMediaType is the MediaType for docker manifests.
+	DockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+
+	// DockerManifestListMediaType is the MediaType for docker manifest lists.
+	DockerManifestListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// DockerConfigMediaType is the MediaType for docker config layers.
+	DockerConfigMediaType = "application/vnd.docker.container.image.v1+json"
+
+	// DockerLayerMediaType is the MediaType for docker layers.
+	DockerLayerMediaType = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+
+	// ArtifactTypeAnnotation is the manifest annotation holding the artifact type when
+	// docker media types are used, since they do not carry it.
+	ArtifactTypeAnnotation = "io.falcosecurity.artifact.type"
+
+	// ReservedAnnotationPrefix is the prefix of the annotations managed by falcoctl itself.
+	ReservedAnnotationPrefix = "io.falcosecurity.artifact."
+
+	// PluginAPIVersionAnnotation is the manifest annotation holding the plugin API version required by a plugin.
+	PluginAPIVersionAnnotation = "io.falcosecurity.plugin.api-version"
+
+	// RequiresFalcoVersionAnnotation is the manifest annotation holding the Falco versions supported
+	// by an artifact, either as a minimum version, e.g. "0.35.0", or as a range, e.g. ">=0.35.0 <0.37.0".
+	RequiresFalcoVersionAnnotation = "io.falcosecurity.requires-falco-version"
+
+	// DeprecatedAnnotation is the manifest annotation marking an artifact as deprecated. Its value is the
+	// deprecation message shown to the users.
+	DeprecatedAnnotation = "io.falcosecurity.deprecated"
+
+	// ReplacedByAnnotation is the manifest annotation holding the replacement of a deprecated artifact,
+	// either the name of an artifact in the index or a reference.
+	ReplacedByAnnotation = "io.falcosecurity.replaced-by"
+
+	// AnnotationsLockedAnnotation is the manifest annotation marking the annotations of an artifact as locked,
+	// see "artifact lock-annotations".
+	AnnotationsLockedAnnotation = "org.falcosecurity.artifact.annotations-locked"
+
+	// ForkedFromAnnotation is the manifest annotation holding the reference, pinned by digest, of the artifact
+	// an artifact has been forked from, see "artifact fork".
+	ForkedFromAnnotation = "org.falcosecurity.artifact.forked-from"
+
+	// PluginAPIVersionRequirement is the name of the requirement, in the config layer, holding the
+	// plugin API version required by a plugin.
+	PluginAPIVersionRequirement = "plugin_api_version"
+
 	// DefaultRegistry is the default container registry to use.
 	DefaultRegistry = "ghcr.io"
 
 	// DefaultTag is the default tag reference to be used when none is provided.
 	DefaultTag = "latest"
+
+	// DefaultMaxMetadataSize is the default maximum size in bytes of the manifests, indexes
+	// and configs read from remote registries.
+	DefaultMaxMetadataSize int64 = 4 * 1024 * 1024
 )
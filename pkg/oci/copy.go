@@ -0,0 +1,397 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// Copy copies the artifact pointed by srcRef, including all its platforms, to dstRef. The annotations whose
+// key matches one of the excludeAnnotations glob patterns are removed from the manifests and the indexes
+// before pushing them, changing their digests. Layers and configs are copied unchanged.
+// It returns the descriptors of the source and of the copied artifact.
+func Copy(ctx context.Context, srcRef string, srcClient *auth.Client, dstRef string, dstClient *auth.Client,
+	excludeAnnotations []string) (src, dst *v1.Descriptor, err error) {
+	for _, pattern := range excludeAnnotations {
+		if _, err = path.Match(pattern, ""); err != nil {
+			return nil, nil, fmt.Errorf("invalid annotation pattern %q: %w", pattern, err)
+		}
+	}
+
+	return copyRef(ctx, srcRef, srcClient, dstRef, dstClient, excludeAnnotations, nil)
+}
+
+// Fork copies the artifact pointed by srcRef, including all its platforms, to dstRef like Copy, setting the given
+// annotations on all its manifests and indexes. Layers and configs are copied unchanged.
+// It returns the descriptors of the source and of the forked artifact.
+func Fork(ctx context.Context, srcRef string, srcClient *auth.Client, dstRef string, dstClient *auth.Client,
+	annotations map[string]string) (src, dst *v1.Descriptor, err error) {
+	for key := range annotations {
+		if readOnlyFields[key] || key == "" {
+			return nil, nil, fmt.Errorf("invalid annotation key %q", key)
+		}
+	}
+
+	return copyRef(ctx, srcRef, srcClient, dstRef, dstClient, nil, annotations)
+}
+
+func copyRef(ctx context.Context, srcRef string, srcClient *auth.Client, dstRef string, dstClient *auth.Client,
+	excludeAnnotations []string, setAnnotations map[string]string) (src, dst *v1.Descriptor, err error) {
+	srcRepo, err := remote.NewRepository(srcRef)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create new repository with ref %s: %w", srcRef, err)
+	}
+	srcRepo.Client = srcClient
+
+	dstRepo, err := remote.NewRepository(dstRef)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create new repository with ref %s: %w", dstRef, err)
+	}
+	dstRepo.Client = dstClient
+
+	srcDesc, err := srcRepo.Resolve(ctx, srcRef)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c := newCopier(srcRepo, dstRepo, excludeAnnotations)
+	c.setAnnotations = setAnnotations
+	dstDesc, err := c.copyNode(ctx, srcDesc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = dstRepo.Tag(ctx, dstDesc, dstRef); err != nil {
+		return nil, nil, fmt.Errorf("unable to tag %s: %w", dstRef, err)
+	}
+
+	return &srcDesc, &dstDesc, nil
+}
+
+// TagCopy is the result of the copy of a single tag by CopyAllTags.
+type TagCopy struct {
+	Tag         string
+	Source      *v1.Descriptor
+	Destination *v1.Descriptor
+	Err         error
+}
+
+// CopyAllTags copies all the tags of the repository pointed by srcRef to the repository pointed by dstRef,
+// keeping the same tag names. The tag or digest of the references, if any, is ignored. Nodes shared among
+// tags, e.g. common layers, are transferred once. A failure copying a tag does not stop the copy of the
+// other ones: onCopied, if not nil, is called after each tag with its result, and the results of all the
+// tags are returned. An error is returned only if the tags cannot be listed.
+func CopyAllTags(ctx context.Context, srcRef string, srcClient *auth.Client, dstRef string, dstClient *auth.Client,
+	excludeAnnotations []string, onCopied func(TagCopy)) ([]TagCopy, error) {
+	for _, pattern := range excludeAnnotations {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid annotation pattern %q: %w", pattern, err)
+		}
+	}
+
+	srcRepo, err := remote.NewRepository(srcRef)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new repository with ref %s: %w", srcRef, err)
+	}
+	srcRepo.Client = srcClient
+
+	dstRepo, err := remote.NewRepository(dstRef)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new repository with ref %s: %w", dstRef, err)
+	}
+	dstRepo.Client = dstClient
+
+	tags, err := ListTags(ctx, srcRef, srcClient)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list tags of %s: %w", srcRef, err)
+	}
+
+	c := newCopier(srcRepo, dstRepo, excludeAnnotations)
+	results := make([]TagCopy, 0, len(tags))
+	for _, tag := range tags {
+		result := c.copyTag(ctx, tag)
+		if onCopied != nil {
+			onCopied(result)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// DeleteManifests deletes the manifests described by descs from the repository pointed by ref. Manifests shared
+// by several descriptors, e.g. tags pointing to the same digest, are deleted once, removing all their tags. The
+// nodes they refer to are left to the garbage collection of the registry.
+func DeleteManifests(ctx context.Context, ref string, client *auth.Client, descs []v1.Descriptor) error {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return fmt.Errorf("unable to create new repository with ref %s: %w", ref, err)
+	}
+	repo.Client = client
+
+	deleted := make(map[digest.Digest]bool, len(descs))
+	for _, desc := range descs {
+		if deleted[desc.Digest] {
+			continue
+		}
+		if err := repo.Delete(ctx, desc); err != nil {
+			return fmt.Errorf("unable to delete manifest %s from %s: %w", desc.Digest, ref, err)
+		}
+		deleted[desc.Digest] = true
+	}
+
+	return nil
+}
+
+type copier struct {
+	src                *remote.Repository
+	dst                *remote.Repository
+	excludeAnnotations []string
+	// setAnnotations are set on all the manifests and indexes.
+	setAnnotations map[string]string
+	// copied maps the digests of the nodes already copied to their descriptors in the destination.
+	copied map[digest.Digest]v1.Descriptor
+}
+
+func newCopier(src, dst *remote.Repository, excludeAnnotations []string) *copier {
+	return &copier{
+		src:                src,
+		dst:                dst,
+		excludeAnnotations: excludeAnnotations,
+		copied:             make(map[digest.Digest]v1.Descriptor),
+	}
+}
+
+// copyTag copies a tag of the source repository to the same tag of the destination repository.
+func (c *copier) copyTag(ctx context.Context, tag string) TagCopy {
+	result := TagCopy{Tag: tag}
+
+	srcDesc, err := c.src.Resolve(ctx, tag)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Source = &srcDesc
+
+	dstDesc, err := c.copyNode(ctx, srcDesc)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	if err = c.dst.Tag(ctx, dstDesc, tag); err != nil {
+		result.Err = fmt.Errorf("unable to tag %s: %w", tag, err)
+		return result
+	}
+	result.Destination = &dstDesc
+
+	return result
+}
+
+// copyNode copies a node of the artifact graph and, for manifests and indexes, all its successors.
+// It returns the descriptor of the copied node.
+func (c *copier) copyNode(ctx context.Context, desc v1.Descriptor) (v1.Descriptor, error) {
+	if copied, ok := c.copied[desc.Digest]; ok {
+		newDesc := desc
+		newDesc.Digest = copied.Digest
+		newDesc.Size = copied.Size
+		return newDesc, nil
+	}
+
+	if !IsIndex(desc.MediaType) && !IsManifest(desc.MediaType) {
+		if err := oras.CopyGraph(ctx, c.src, c.dst, desc, oras.DefaultCopyGraphOptions); err != nil {
+			return desc, err
+		}
+		c.copied[desc.Digest] = desc
+		return desc, nil
+	}
+
+	reader, err := c.src.Fetch(ctx, desc)
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("unable to fetch %s: %w", desc.Digest, err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+
+	var successors []v1.Descriptor
+	var successorsKey string
+	if IsIndex(desc.MediaType) {
+		var index v1.Index
+		if err = json.Unmarshal(data, &index); err != nil {
+			return v1.Descriptor{}, fmt.Errorf("unable to unmarshal index %s: %w", desc.Digest, err)
+		}
+		successors, successorsKey = index.Manifests, "manifests"
+	} else {
+		var manifest v1.Manifest
+		if err = json.Unmarshal(data, &manifest); err != nil {
+			return v1.Descriptor{}, fmt.Errorf("unable to unmarshal manifest %s: %w", desc.Digest, err)
+		}
+		successors = append([]v1.Descriptor{manifest.Config}, manifest.Layers...)
+	}
+
+	copied := make([]v1.Descriptor, 0, len(successors))
+	for i := range successors {
+		successor, err := c.copyNode(ctx, successors[i])
+		if err != nil {
+			return v1.Descriptor{}, err
+		}
+		if successorsKey != "" {
+			successor.Annotations, _ = stripAnnotations(successor.Annotations, c.excludeAnnotations)
+		}
+		copied = append(copied, successor)
+	}
+
+	if data, err = rewriteNode(data, successorsKey, copied, c.excludeAnnotations); err != nil {
+		return v1.Descriptor{}, fmt.Errorf("unable to rewrite %s: %w", desc.Digest, err)
+	}
+
+	keys := make([]string, 0, len(c.setAnnotations))
+	for key := range c.setAnnotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if data, _, err = SetAnnotation(data, key, c.setAnnotations[key]); err != nil {
+			return v1.Descriptor{}, fmt.Errorf("unable to set annotation %q on %s: %w", key, desc.Digest, err)
+		}
+	}
+
+	newDesc := desc
+	newDesc.Digest = digest.FromBytes(data)
+	newDesc.Size = int64(len(data))
+
+	exists, err := c.dst.Exists(ctx, newDesc)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	if !exists {
+		if err = c.dst.Push(ctx, newDesc, bytes.NewReader(data)); err != nil {
+			return v1.Descriptor{}, fmt.Errorf("unable to push %s: %w", newDesc.Digest, err)
+		}
+	}
+	c.copied[desc.Digest] = newDesc
+
+	return newDesc, nil
+}
+
+// rewriteNode replaces the successors stored under successorsKey, if any, and removes the excluded annotations
+// from a manifest or an index. The other fields are preserved. If nothing changed, data is returned unchanged
+// to keep the same digest.
+func rewriteNode(data []byte, successorsKey string, successors []v1.Descriptor, excludeAnnotations []string) ([]byte, error) {
+	var node map[string]json.RawMessage
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+
+	var original []v1.Descriptor
+	if successorsKey != "" {
+		if err := json.Unmarshal(node[successorsKey], &original); err != nil {
+			return nil, err
+		}
+	}
+	successorsChanged := successorsKey != "" && !sameDescriptors(original, successors)
+
+	var annotations map[string]string
+	if raw, ok := node["annotations"]; ok {
+		if err := json.Unmarshal(raw, &annotations); err != nil {
+			return nil, err
+		}
+	}
+	annotations, annotationsChanged := stripAnnotations(annotations, excludeAnnotations)
+
+	if !successorsChanged && !annotationsChanged {
+		return data, nil
+	}
+
+	if successorsChanged {
+		raw, err := json.Marshal(successors)
+		if err != nil {
+			return nil, err
+		}
+		node[successorsKey] = raw
+	}
+
+	if annotationsChanged {
+		delete(node, "annotations")
+		if len(annotations) > 0 {
+			raw, err := json.Marshal(annotations)
+			if err != nil {
+				return nil, err
+			}
+			node["annotations"] = raw
+		}
+	}
+
+	return json.Marshal(node)
+}
+
+// stripAnnotations returns annotations without the keys matching one of the patterns,
+// and whether any key was removed.
+func stripAnnotations(annotations map[string]string, patterns []string) (map[string]string, bool) {
+	var removed bool
+	result := make(map[string]string, len(annotations))
+	for key, value := range annotations {
+		if matchesAny(key, patterns) {
+			removed = true
+			continue
+		}
+		result[key] = value
+	}
+
+	if !removed {
+		return annotations, false
+	}
+
+	return result, true
+}
+
+func matchesAny(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sameDescriptors(a, b []v1.Descriptor) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Digest != b[i].Digest || len(a[i].Annotations) != len(b[i].Annotations) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestStripAnnotations(t *testing.T) {
+	annotations := map[string]string{
+		v1.AnnotationCreated:            "2022-11-10T12:00:00Z",
+		"com.example.internal.source":   "git@internal:rules.git",
+		"com.example.internal.pipeline": "42",
+	}
+
+	stripped, removed := stripAnnotations(annotations, []string{"com.example.internal.*"})
+	if !removed || len(stripped) != 1 || stripped[v1.AnnotationCreated] == "" {
+		t.Errorf("unexpected result stripping annotations: %v (%t)", stripped, removed)
+	}
+
+	if len(annotations) != 3 {
+		t.Errorf("stripAnnotations modified its input")
+	}
+
+	if _, removed = stripAnnotations(annotations, []string{"org.example.*"}); removed {
+		t.Errorf("expected no annotation to be removed")
+	}
+}
+
+func TestRewriteNode(t *testing.T) {
+	manifest := []byte(`{"schemaVersion":2,"config":{"mediaType":"application/vnd.cncf.falco.rulesfile.config.v1+json",` +
+		`"digest":"sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a","size":2},` +
+		`"layers":[],"annotations":{"org.opencontainers.image.source":"https://example.com","com.example.internal":"x"}}`)
+
+	data, err := rewriteNode(manifest, "", nil, []string{"org.example.*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, manifest) {
+		t.Errorf("expected manifest to be unchanged when no annotation matches")
+	}
+
+	data, err = rewriteNode(manifest, "", nil, []string{"com.example.*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rewritten v1.Manifest
+	if err = json.Unmarshal(data, &rewritten); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rewritten.Annotations["com.example.internal"]; ok {
+		t.Errorf("expected annotation to be removed: %v", rewritten.Annotations)
+	}
+	if rewritten.Annotations[v1.AnnotationSource] != "https://example.com" {
+		t.Errorf("expected other annotations to be preserved: %v", rewritten.Annotations)
+	}
+	if rewritten.Config.Size != 2 || rewritten.SchemaVersion != 2 {
+		t.Errorf("expected other fields to be preserved: %+v", rewritten)
+	}
+}
+
+func TestCopyNodeCopiedOnce(t *testing.T) {
+	c := newCopier(nil, nil, nil)
+	src := v1.Descriptor{
+		MediaType: v1.MediaTypeImageManifest,
+		Digest:    "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a",
+		Size:      2,
+		Platform:  &v1.Platform{OS: "linux", Architecture: "amd64"},
+	}
+	c.copied[src.Digest] = v1.Descriptor{
+		MediaType: v1.MediaTypeImageManifest,
+		Digest:    "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+		Size:      3,
+	}
+
+	// The repositories are nil: the node must not be fetched again.
+	dst, err := c.copyNode(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dst.Digest != c.copied[src.Digest].Digest || dst.Size != 3 {
+		t.Errorf("expected the descriptor of the node already copied, got %+v", dst)
+	}
+	if dst.Platform == nil || dst.Platform.Architecture != "amd64" {
+		t.Errorf("expected the platform of the source descriptor to be preserved, got %+v", dst.Platform)
+	}
+}
+
+func TestForkInvalidAnnotations(t *testing.T) {
+	for _, key := range []string{"", "digest", "manifests"} {
+		if _, _, err := Fork(context.Background(), "", nil, "", nil, map[string]string{key: "value"}); err == nil {
+			t.Errorf("expected error forking with annotation %q", key)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ErrNoCreatedAnnotation error when a manifest does not carry the creation time annotation.
+var ErrNoCreatedAnnotation = fmt.Errorf("no %q annotation", v1.AnnotationCreated)
+
+// ErrArtifactTooOld error when an artifact is older than the allowed maximum age.
+var ErrArtifactTooOld = errors.New("artifact too old")
+
+// CreatedAt returns the creation time of an artifact, read from the annotations of its manifest.
+func CreatedAt(manifest *v1.Manifest) (time.Time, error) {
+	created, ok := manifest.Annotations[v1.AnnotationCreated]
+	if !ok {
+		return time.Time{}, ErrNoCreatedAnnotation
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, created)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("malformed %q annotation %q: %w", v1.AnnotationCreated, created, err)
+	}
+
+	return createdAt, nil
+}
+
+// CheckAge returns an error wrapping ErrArtifactTooOld if the artifact was created more than maxAge before now.
+func CheckAge(manifest *v1.Manifest, maxAge time.Duration, now time.Time) error {
+	createdAt, err := CreatedAt(manifest)
+	if err != nil {
+		return err
+	}
+
+	if age := now.Sub(createdAt); age > maxAge {
+		return fmt.Errorf("created at %s, %s ago, maximum age is %s: %w",
+			createdAt.Format(time.RFC3339), age.Truncate(time.Second), maxAge, ErrArtifactTooOld)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestCheckAge(t *testing.T) {
+	now := time.Date(2022, 11, 10, 12, 0, 0, 0, time.UTC)
+	manifest := &v1.Manifest{Annotations: map[string]string{v1.AnnotationCreated: "2022-11-09T12:00:00Z"}}
+
+	if err := CheckAge(manifest, 48*time.Hour, now); err != nil {
+		t.Fatal("unexpected error for fresh artifact:", err)
+	}
+
+	if err := CheckAge(manifest, 12*time.Hour, now); !errors.Is(err, ErrArtifactTooOld) {
+		t.Fatal("expected ErrArtifactTooOld, got:", err)
+	}
+
+	if err := CheckAge(&v1.Manifest{}, time.Hour, now); !errors.Is(err, ErrNoCreatedAnnotation) {
+		t.Fatal("expected ErrNoCreatedAnnotation, got:", err)
+	}
+
+	manifest.Annotations[v1.AnnotationCreated] = "yesterday"
+	if err := CheckAge(manifest, time.Hour, now); err == nil || errors.Is(err, ErrArtifactTooOld) {
+		t.Fatal("expected error for malformed annotation, got:", err)
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Deprecation returns the deprecation message and the replacement of the artifact described by manifest.
+// The artifact is deprecated if it carries either the DeprecatedAnnotation or the ReplacedByAnnotation annotation.
+func Deprecation(manifest *v1.Manifest) (message, replacement string, deprecated bool) {
+	message, hasMessage := manifest.Annotations[DeprecatedAnnotation]
+	replacement, hasReplacement := manifest.Annotations[ReplacedByAnnotation]
+
+	return message, replacement, hasMessage || hasReplacement
+}
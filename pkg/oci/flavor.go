@@ -0,0 +1,196 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// RegistryFlavor identifies a registry implementation, whose quirks are worked around by falcoctl.
+type RegistryFlavor string
+
+const (
+	// FlavorAuto detects the flavor of each registry from its host name and from the response of its /v2/ endpoint.
+	FlavorAuto RegistryFlavor = "auto"
+	// FlavorGeneric is a registry following the OCI distribution specification, without known quirks.
+	FlavorGeneric RegistryFlavor = "generic"
+	// FlavorHarbor is a Harbor registry.
+	FlavorHarbor RegistryFlavor = "harbor"
+	// FlavorGHCR is the GitHub container registry.
+	FlavorGHCR RegistryFlavor = "ghcr"
+	// FlavorECR is the Amazon elastic container registry.
+	FlavorECR RegistryFlavor = "ecr"
+	// FlavorZot is a zot registry.
+	FlavorZot RegistryFlavor = "zot"
+)
+
+// The following functions are necessary to use RegistryFlavor with Cobra.
+
+// String returns a string representation of RegistryFlavor.
+func (f *RegistryFlavor) String() string {
+	return string(*f)
+}
+
+// Set a RegistryFlavor.
+func (f *RegistryFlavor) Set(v string) error {
+	switch RegistryFlavor(v) {
+	case FlavorAuto, FlavorGeneric, FlavorHarbor, FlavorGHCR, FlavorECR, FlavorZot:
+		*f = RegistryFlavor(v)
+		return nil
+	default:
+		return fmt.Errorf("must be one of %q, %q, %q, %q, %q, %q", FlavorAuto, FlavorGeneric, FlavorHarbor, FlavorGHCR, FlavorECR, FlavorZot)
+	}
+}
+
+// Type returns a string representing this type.
+func (f *RegistryFlavor) Type() string {
+	return "RegistryFlavor"
+}
+
+// referrersSources returns where the referrers are looked for on the registries of the flavor: the
+// Referrers API, the fallback tag ("<alg>-<hex>"), or both when the support of the API is unknown.
+func (f RegistryFlavor) referrersSources() (api, fallbackTag bool) {
+	switch f {
+	case FlavorGHCR:
+		// The Referrers API is not implemented.
+		return false, true
+	case FlavorZot:
+		// The Referrers API is implemented natively, the fallback tag is never written.
+		return true, false
+	default:
+		return true, true
+	}
+}
+
+var ecrHostRgx = regexp.MustCompile(`^[0-9]+\.dkr\.ecr(-fips)?\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// detectFlavor returns the flavor of the registry at host, given the headers of the response of its /v2/ endpoint.
+func detectFlavor(host string, header http.Header) RegistryFlavor {
+	hostname := strings.ToLower(host)
+	if h, _, err := net.SplitHostPort(hostname); err == nil {
+		hostname = h
+	}
+
+	switch {
+	case hostname == "ghcr.io":
+		return FlavorGHCR
+	case ecrHostRgx.MatchString(hostname):
+		return FlavorECR
+	}
+
+	challenge := strings.ToLower(header.Get("Www-Authenticate"))
+	switch {
+	case strings.Contains(challenge, `service="harbor-registry"`):
+		return FlavorHarbor
+	case strings.Contains(challenge, `service="ghcr.io"`):
+		return FlavorGHCR
+	case strings.Contains(challenge, `service="ecr.amazonaws.com"`):
+		return FlavorECR
+	case strings.Contains(challenge, `realm="zot"`):
+		return FlavorZot
+	}
+
+	return FlavorGeneric
+}
+
+// flavors holds the flavor hint and the flavors detected in this invocation.
+var flavors = struct {
+	sync.Mutex
+	hint     RegistryFlavor
+	logf     func(format string, args ...interface{})
+	detected map[string]RegistryFlavor
+}{
+	hint:     FlavorAuto,
+	detected: make(map[string]RegistryFlavor),
+}
+
+// SetRegistryFlavor sets the flavor of all the registries contacted afterwards, FlavorAuto to detect it for
+// each registry. The selected or detected flavors are reported, once per registry, through logf, if not nil.
+// It is meant to be called once, before contacting any registry.
+func SetRegistryFlavor(hint RegistryFlavor, logf func(format string, args ...interface{})) {
+	flavors.Lock()
+	defer flavors.Unlock()
+
+	if hint == "" {
+		hint = FlavorAuto
+	}
+	flavors.hint = hint
+	flavors.logf = logf
+	flavors.detected = make(map[string]RegistryFlavor)
+}
+
+// Flavor returns the flavor of the registry at host: the one set by SetRegistryFlavor, if any, otherwise the
+// one detected from its host name and the response of its /v2/ endpoint. Detected flavors are cached for
+// the invocation. If the detection fails, FlavorGeneric is returned.
+func Flavor(ctx context.Context, client remote.Client, host string, plainHTTP bool) RegistryFlavor {
+	flavors.Lock()
+	defer flavors.Unlock()
+
+	if flavor, ok := flavors.detected[host]; ok {
+		return flavor
+	}
+
+	flavor, source := flavors.hint, "selected"
+	if flavor == FlavorAuto {
+		flavor, source = probeFlavor(ctx, client, host, plainHTTP), "detected"
+	}
+	flavors.detected[host] = flavor
+
+	if flavors.logf != nil {
+		flavors.logf("Registry %q: %s flavor %q", host, source, flavor)
+	}
+
+	return flavor
+}
+
+// probeFlavor detects the flavor of the registry at host sending an unauthenticated request to its /v2/ endpoint,
+// whose challenge identifies some of the flavors.
+func probeFlavor(ctx context.Context, client remote.Client, host string, plainHTTP bool) RegistryFlavor {
+	scheme := "https"
+	if plainHTTP {
+		scheme = "http"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s://%s/v2/", scheme, host), http.NoBody)
+	if err != nil {
+		return detectFlavor(host, nil)
+	}
+
+	// The challenge is needed, do not let the auth client answer it.
+	if authClient, ok := client.(*auth.Client); ok {
+		req.Header = authClient.Header.Clone()
+		client = http.DefaultClient
+		if authClient.Client != nil {
+			client = authClient.Client
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return detectFlavor(host, nil)
+	}
+	resp.Body.Close()
+
+	return detectFlavor(host, resp.Header)
+}
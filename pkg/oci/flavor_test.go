@@ -0,0 +1,86 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDetectFlavor(t *testing.T) {
+	challenge := func(value string) http.Header {
+		return http.Header{"Www-Authenticate": []string{value}}
+	}
+
+	tests := []struct {
+		host     string
+		header   http.Header
+		expected RegistryFlavor
+	}{
+		{host: "ghcr.io", expected: FlavorGHCR},
+		{host: "123456789012.dkr.ecr.eu-west-1.amazonaws.com", expected: FlavorECR},
+		{host: "harbor.corp:8443", header: challenge(`Bearer realm="https://harbor.corp/service/token",service="harbor-registry"`),
+			expected: FlavorHarbor},
+		{host: "localhost:5000", header: challenge(`Basic realm="zot"`), expected: FlavorZot},
+		{host: "registry.corp", header: challenge(`Bearer realm="https://registry.corp/token",service="registry.corp"`),
+			expected: FlavorGeneric},
+		{host: "registry.corp", expected: FlavorGeneric},
+	}
+
+	for _, tt := range tests {
+		if got := detectFlavor(tt.host, tt.header); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.host, tt.expected, got)
+		}
+	}
+}
+
+func TestFlavor(t *testing.T) {
+	var probes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes++
+		w.Header().Set("WWW-Authenticate", `Bearer realm="https://harbor.corp/service/token",service="harbor-registry"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	var logs []string
+	logf := func(format string, args ...interface{}) {
+		logs = append(logs, fmt.Sprintf(format, args...))
+	}
+	t.Cleanup(func() { SetRegistryFlavor(FlavorAuto, nil) })
+
+	SetRegistryFlavor(FlavorAuto, logf)
+	for i := 0; i < 2; i++ {
+		if flavor := Flavor(context.Background(), server.Client(), host, true); flavor != FlavorHarbor {
+			t.Fatalf("expected %q, got %q", FlavorHarbor, flavor)
+		}
+	}
+	if probes != 1 || len(logs) != 1 {
+		t.Errorf("expected the flavor to be detected and reported once, got %d probes and logs %v", probes, logs)
+	}
+
+	SetRegistryFlavor(FlavorZot, logf)
+	if flavor := Flavor(context.Background(), server.Client(), host, true); flavor != FlavorZot {
+		t.Fatalf("expected %q, got %q", FlavorZot, flavor)
+	}
+	if probes != 1 {
+		t.Errorf("expected no probe with a selected flavor, got %d", probes)
+	}
+}
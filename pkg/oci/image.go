@@ -0,0 +1,255 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+const (
+	// whiteoutPrefix marks the files of the lower layers deleted by a layer of a container image.
+	whiteoutPrefix = ".wh."
+	// whiteoutOpaque marks a directory whose content in the lower layers is deleted.
+	whiteoutOpaque = whiteoutPrefix + whiteoutPrefix + ".opq"
+)
+
+// labelSchemaAnnotations maps the labels of the deprecated label-schema.org convention
+// to the equivalent OCI annotations.
+var labelSchemaAnnotations = map[string]string{
+	"org.label-schema.name":        v1.AnnotationTitle,
+	"org.label-schema.description": v1.AnnotationDescription,
+	"org.label-schema.url":         v1.AnnotationURL,
+	"org.label-schema.vcs-url":     v1.AnnotationSource,
+	"org.label-schema.vcs-ref":     v1.AnnotationRevision,
+	"org.label-schema.version":     v1.AnnotationVersion,
+	"org.label-schema.vendor":      v1.AnnotationVendor,
+	"org.label-schema.build-date":  v1.AnnotationCreated,
+	"maintainer":                   v1.AnnotationAuthors,
+}
+
+// FetchImageConfig fetches the config of a container image given its manifest.
+func FetchImageConfig(ctx context.Context, ref string, client *auth.Client, manifest *v1.Manifest) (*v1.Image, error) {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new repository with ref %s: %w", ref, err)
+	}
+	repo.Client = client
+
+	configReader, err := repo.Fetch(ctx, manifest.Config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch config %s: %w", manifest.Config.Digest, err)
+	}
+	defer configReader.Close()
+
+	configBytes, err := io.ReadAll(io.LimitReader(configReader, DefaultMaxMetadataSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var image v1.Image
+	if err = json.Unmarshal(configBytes, &image); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal image config: %w", err)
+	}
+
+	return &image, nil
+}
+
+// AnnotationsFromLabels returns the OCI annotations equivalent to the labels of a container image.
+// Labels already using the OCI keys are kept as they are and take precedence over the label-schema.org ones.
+// Labels without an equivalent annotation are ignored.
+func AnnotationsFromLabels(labels map[string]string) map[string]string {
+	annotations := make(map[string]string)
+	for label, value := range labels {
+		if key, ok := labelSchemaAnnotations[label]; ok && value != "" {
+			if _, found := annotations[key]; !found {
+				annotations[key] = value
+			}
+		}
+	}
+
+	for label, value := range labels {
+		if strings.HasPrefix(label, "org.opencontainers.image.") && value != "" {
+			annotations[label] = value
+		}
+	}
+
+	return annotations
+}
+
+// ExtractImageFiles extracts from the layers of a container image the regular files whose base name
+// matches one of the glob patterns, writing them to destDir. Files are written without their directory,
+// an error is returned if two matching files have the same base name. Files added or deleted by upper
+// layers take precedence over the ones in lower layers. It returns the paths of the extracted files.
+func ExtractImageFiles(ctx context.Context, ref string, client *auth.Client, manifest *v1.Manifest,
+	patterns []string, destDir string) ([]string, error) {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new repository with ref %s: %w", ref, err)
+	}
+	repo.Client = client
+
+	x := imageExtractor{
+		patterns: patterns,
+		destDir:  destDir,
+		seen:     make(map[string]struct{}),
+		deleted:  make(map[string]struct{}),
+		names:    make(map[string]string),
+	}
+	for i := len(manifest.Layers) - 1; i >= 0; i-- {
+		layer := manifest.Layers[i]
+		layerReader, err := repo.Fetch(ctx, layer)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch layer %s: %w", layer.Digest, err)
+		}
+
+		err = x.extractLayer(layerReader, layer.MediaType)
+		layerReader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable to extract layer %s: %w", layer.Digest, err)
+		}
+	}
+
+	return x.files, nil
+}
+
+type imageExtractor struct {
+	patterns []string
+	destDir  string
+	// seen holds the paths of the files found in upper layers.
+	seen map[string]struct{}
+	// deleted holds the paths of the files and directories deleted by upper layers.
+	deleted map[string]struct{}
+	// names maps the base names of the extracted files to their path in the image.
+	names map[string]string
+	files []string
+}
+
+// extractLayer extracts the matching files of a single layer, compressed with gzip or uncompressed.
+func (x *imageExtractor) extractLayer(r io.Reader, mediaType string) error {
+	switch {
+	case strings.HasSuffix(mediaType, "gzip"):
+		gzipReader, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		r = gzipReader
+	case strings.HasSuffix(mediaType, ".tar"):
+	default:
+		return fmt.Errorf("unsupported layer media type %q", mediaType)
+	}
+
+	tarReader := tar.NewReader(r)
+	var layerSeen, layerDeleted []string
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean("/" + header.Name)
+		base := path.Base(name)
+		switch {
+		case base == whiteoutOpaque:
+			layerDeleted = append(layerDeleted, path.Dir(name))
+			continue
+		case strings.HasPrefix(base, whiteoutPrefix):
+			layerDeleted = append(layerDeleted, path.Join(path.Dir(name), strings.TrimPrefix(base, whiteoutPrefix)))
+			continue
+		}
+
+		if x.hidden(name) {
+			continue
+		}
+		layerSeen = append(layerSeen, name)
+
+		if header.Typeflag != tar.TypeReg || !matchesAny(base, x.patterns) {
+			continue
+		}
+
+		if other, ok := x.names[base]; ok {
+			return fmt.Errorf("files %q and %q have the same name", other, name)
+		}
+		x.names[base] = name
+
+		dst := filepath.Join(x.destDir, base)
+		if err = writeFile(dst, tarReader); err != nil {
+			return err
+		}
+		x.files = append(x.files, dst)
+	}
+
+	// Files and whiteouts of this layer hide the ones of the lower layers only.
+	for _, name := range layerSeen {
+		x.seen[name] = struct{}{}
+	}
+	for _, name := range layerDeleted {
+		x.deleted[name] = struct{}{}
+	}
+
+	return nil
+}
+
+// hidden returns true if the file at name was added by an upper layer, or if it or one of
+// its parent directories was deleted by an upper layer.
+func (x *imageExtractor) hidden(name string) bool {
+	if _, ok := x.seen[name]; ok {
+		return true
+	}
+
+	for dir := name; dir != "/"; dir = path.Dir(dir) {
+		if _, ok := x.deleted[dir]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeFile(dst string, r io.Reader) error {
+	f, err := os.Create(filepath.Clean(dst))
+	if err != nil {
+		return err
+	}
+
+	for {
+		if _, err = io.CopyN(f, r, 1024); err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, io.EOF) {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
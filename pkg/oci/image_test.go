@@ -0,0 +1,123 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestAnnotationsFromLabels(t *testing.T) {
+	annotations := AnnotationsFromLabels(map[string]string{
+		"org.label-schema.vcs-url":        "https://github.com/example/old",
+		v1.AnnotationSource:               "https://github.com/example/plugin",
+		"org.label-schema.description":    "An example plugin",
+		"maintainer":                      "plugins@example.com",
+		"com.example.build.pipeline":      "42",
+		"org.opencontainers.image.vendor": "Example",
+	})
+
+	expected := map[string]string{
+		v1.AnnotationSource:      "https://github.com/example/plugin",
+		v1.AnnotationDescription: "An example plugin",
+		v1.AnnotationAuthors:     "plugins@example.com",
+		v1.AnnotationVendor:      "Example",
+	}
+
+	if len(annotations) != len(expected) {
+		t.Fatalf("expected %d annotations, got %v", len(expected), annotations)
+	}
+	for key, value := range expected {
+		if annotations[key] != value {
+			t.Errorf("expected annotation %q to be %q, got %q", key, value, annotations[key])
+		}
+	}
+}
+
+func tarGzLayer(t *testing.T, files map[string]string) *bytes.Buffer {
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &buf
+}
+
+func TestExtractLayer(t *testing.T) {
+	destDir := t.TempDir()
+	x := imageExtractor{
+		patterns: []string{"*.so"},
+		destDir:  destDir,
+		seen:     make(map[string]struct{}),
+		deleted:  make(map[string]struct{}),
+		names:    make(map[string]string),
+	}
+
+	// Layers are extracted from the upper to the lower one.
+	upper := tarGzLayer(t, map[string]string{
+		"usr/share/falco/plugins/libexample.so": "new",
+		"usr/share/falco/plugins/.wh.libold.so": "",
+		"opt/.wh..wh..opq":                      "",
+	})
+	lower := tarGzLayer(t, map[string]string{
+		"usr/share/falco/plugins/libexample.so": "old",
+		"usr/share/falco/plugins/libold.so":     "old",
+		"opt/plugins/libopt.so":                 "old",
+		"etc/falco/falco_rules.yaml":            "- rule: example",
+	})
+
+	if err := x.extractLayer(upper, "application/vnd.oci.image.layer.v1.tar+gzip"); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.extractLayer(lower, "application/vnd.docker.image.rootfs.diff.tar.gzip"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(x.files) != 1 || x.files[0] != filepath.Join(destDir, "libexample.so") {
+		t.Fatalf("expected only libexample.so to be extracted, got %v", x.files)
+	}
+
+	content, err := os.ReadFile(x.files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "new" {
+		t.Errorf("expected the file of the upper layer to be extracted, got %q", content)
+	}
+
+	if err = x.extractLayer(tarGzLayer(t, nil), "application/vnd.oci.image.layer.v1.tar+zstd"); err == nil {
+		t.Errorf("expected error for unsupported layer media type")
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// IndexEntry is a manifest, already pushed to the repository, to be added to an index for the given platform.
+type IndexEntry struct {
+	// Platform in OS/ARCH format.
+	Platform string
+	// Digest of the manifest.
+	Digest string
+}
+
+// ParseIndexEntry parses an index entry in the OS/ARCH=@DIGEST format, e.g. "linux/amd64=@sha256:123abc...".
+func ParseIndexEntry(entry string) (IndexEntry, error) {
+	platform, d, ok := strings.Cut(entry, "=")
+	if !ok {
+		return IndexEntry{}, fmt.Errorf("index entry %q not in OS/ARCH=@DIGEST format", entry)
+	}
+	parsed, err := ParsePlatform(platform)
+	if err != nil {
+		return IndexEntry{}, fmt.Errorf("index entry %q: %w", entry, err)
+	}
+	if parsed.Variant != "" {
+		return IndexEntry{}, fmt.Errorf("platform %q of index entry %q not in OS/ARCH format", platform, entry)
+	}
+	if !strings.HasPrefix(d, "@") || len(d) == 1 {
+		return IndexEntry{}, fmt.Errorf("digest %q of index entry %q must be in @DIGEST format", d, entry)
+	}
+
+	return IndexEntry{Platform: platformKey(parsed), Digest: d[1:]}, nil
+}
+
+// MergeIndex adds the manifests to the index, replacing the ones already in it for the same platforms, regardless
+// of the aliases of the architectures, e.g. a "linux/x86_64" manifest is replaced by a "linux/amd64" one. The manifests
+// of the resulting index are sorted by platform, so that merging the same manifests always yields the same index.
+func MergeIndex(index *v1.Index, manifests []v1.Descriptor) {
+	byPlatform := make(map[string]int, len(index.Manifests))
+	for i := range index.Manifests {
+		byPlatform[normalizedPlatformKey(index.Manifests[i].Platform)] = i
+	}
+
+	for i := range manifests {
+		key := normalizedPlatformKey(manifests[i].Platform)
+		if j, ok := byPlatform[key]; ok {
+			index.Manifests[j] = manifests[i]
+			continue
+		}
+		byPlatform[key] = len(index.Manifests)
+		index.Manifests = append(index.Manifests, manifests[i])
+	}
+
+	sort.SliceStable(index.Manifests, func(i, j int) bool {
+		return platformKey(index.Manifests[i].Platform) < platformKey(index.Manifests[j].Platform)
+	})
+}
+
+func platformKey(platform *v1.Platform) string {
+	if platform == nil {
+		return ""
+	}
+	key := platform.OS + "/" + platform.Architecture
+	if platform.Variant != "" {
+		key += "/" + platform.Variant
+	}
+	return key
+}
+
+// normalizedPlatformKey returns the key of the platform with the aliases of the architectures normalized.
+func normalizedPlatformKey(platform *v1.Platform) string {
+	if platform == nil {
+		return ""
+	}
+	normalized := *platform
+	normalized.Architecture = normalizeArch(platform.Architecture)
+	return platformKey(&normalized)
+}
+
+// AssembleIndex creates, or updates, the index tagged by ref with the given manifests, already pushed to the
+// repository. The manifests already in the index for other platforms are kept, hence the index can be assembled
+// across several invocations, e.g. one per platform. It returns the old and the new digest of the index, the same if
+// the index already held the manifests, and an empty old digest if the index has been created.
+func AssembleIndex(ctx context.Context, ref string, client *auth.Client, entries []IndexEntry) (oldDigest, newDigest string, err error) {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to create new repository with ref %s: %w", ref, err)
+	}
+	repo.Client = client
+
+	if len(entries) == 0 {
+		return "", "", fmt.Errorf("no manifest to add to the index")
+	}
+	if _, err = repo.Reference.Digest(); err == nil {
+		return "", "", fmt.Errorf("a reference with a tag is required to assemble an index, got %q", ref)
+	}
+	tag := repo.Reference.Reference
+	if tag == "" {
+		tag = DefaultTag
+	}
+
+	index := v1.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+	}
+	var current []byte
+	desc, reader, err := repo.FetchReference(ctx, tag)
+	switch {
+	case errors.Is(err, errdef.ErrNotFound):
+	case err != nil:
+		return "", "", err
+	default:
+		defer reader.Close()
+		if !IsIndex(desc.MediaType) {
+			return "", "", fmt.Errorf("%q points to %s of media type %q, not to an index", ref, desc.Digest, desc.MediaType)
+		}
+		var data []byte
+		if data, err = io.ReadAll(io.LimitReader(reader, DefaultMaxMetadataSize)); err != nil {
+			return "", "", err
+		}
+		if err = json.Unmarshal(data, &index); err != nil {
+			return "", "", fmt.Errorf("unable to unmarshal index: %w", err)
+		}
+		oldDigest = desc.Digest.String()
+		// The index is encoded again, to compare it with the merged one regardless of its formatting.
+		if current, err = json.Marshal(index); err != nil {
+			return "", "", fmt.Errorf("unable to marshal index: %w", err)
+		}
+	}
+
+	manifests := make([]v1.Descriptor, 0, len(entries))
+	for _, entry := range entries {
+		var manifestDesc v1.Descriptor
+		if manifestDesc, err = repo.Resolve(ctx, entry.Digest); err != nil {
+			return "", "", fmt.Errorf("unable to resolve manifest %s for platform %s: %w", entry.Digest, entry.Platform, err)
+		}
+		if !IsManifest(manifestDesc.MediaType) {
+			return "", "", fmt.Errorf("%s for platform %s is of media type %q, not a manifest",
+				entry.Digest, entry.Platform, manifestDesc.MediaType)
+		}
+
+		goos, goarch, _ := strings.Cut(entry.Platform, "/")
+		manifests = append(manifests, v1.Descriptor{
+			MediaType: manifestDesc.MediaType,
+			Digest:    manifestDesc.Digest,
+			Size:      manifestDesc.Size,
+			Platform:  &v1.Platform{OS: goos, Architecture: goarch},
+		})
+	}
+
+	MergeIndex(&index, manifests)
+	if index.MediaType == "" {
+		index.MediaType = v1.MediaTypeImageIndex
+		if manifests[0].MediaType == DockerManifestMediaType {
+			index.MediaType = DockerManifestListMediaType
+		}
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to marshal index: %w", err)
+	}
+
+	if bytes.Equal(data, current) {
+		return oldDigest, oldDigest, nil
+	}
+
+	newDesc := v1.Descriptor{
+		MediaType: index.MediaType,
+		Digest:    digest.FromBytes(data),
+		Size:      int64(len(data)),
+	}
+
+	if err = repo.PushReference(ctx, newDesc, bytes.NewReader(data), tag); err != nil {
+		return "", "", fmt.Errorf("unable to push the index: %w", err)
+	}
+
+	return oldDigest, newDesc.Digest.String(), nil
+}
+
+// IndexVerification is the result of the verification of an index.
+type IndexVerification struct {
+	// Digest of the verified index.
+	Digest string
+	// Dangling are the manifests referenced by the index that do not exist in the repository.
+	Dangling []v1.Descriptor
+	// PrunedDigest is the digest of the index rewritten without the dangling manifests, if pruned.
+	PrunedDigest string
+}
+
+// VerifyIndex checks that all the manifests referenced by the index pointed by ref exist in the repository.
+// If prune is true and some manifests are dangling, the index is rewritten without them, preserving all its other
+// fields, and pushed again: if ref holds a tag, the tag is moved to the pruned index, otherwise it is only pushed
+// by digest.
+func VerifyIndex(ctx context.Context, ref string, client *auth.Client, prune bool) (*IndexVerification, error) {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new repository with ref %s: %w", ref, err)
+	}
+	repo.Client = client
+
+	if repo.Reference.Reference == "" {
+		repo.Reference.Reference = DefaultTag
+	}
+
+	desc, reader, err := repo.FetchReference(ctx, repo.Reference.Reference)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	if !IsIndex(desc.MediaType) {
+		return nil, fmt.Errorf("%q points to %s of media type %q, not to an index", ref, desc.Digest, desc.MediaType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, DefaultMaxMetadataSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var index v1.Index
+	if err = json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal index: %w", err)
+	}
+
+	result := &IndexVerification{Digest: desc.Digest.String()}
+	if result.Dangling, err = danglingManifests(ctx, repo.Manifests().Exists, index.Manifests); err != nil {
+		return nil, err
+	}
+	if !prune || len(result.Dangling) == 0 {
+		return result, nil
+	}
+
+	if data, err = PruneIndex(data, result.Dangling); err != nil {
+		return nil, err
+	}
+
+	prunedDesc := v1.Descriptor{
+		MediaType: desc.MediaType,
+		Digest:    desc.Digest.Algorithm().FromBytes(data),
+		Size:      int64(len(data)),
+	}
+
+	// References by digest cannot be moved, the pruned index is only pushed.
+	if _, err = repo.Reference.Digest(); err == nil {
+		err = repo.Push(ctx, prunedDesc, bytes.NewReader(data))
+	} else {
+		err = repo.PushReference(ctx, prunedDesc, bytes.NewReader(data), repo.Reference.Reference)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to push the pruned index: %w", err)
+	}
+	result.PrunedDigest = prunedDesc.Digest.String()
+
+	return result, nil
+}
+
+// danglingManifests returns the manifests for which exists returns false.
+func danglingManifests(ctx context.Context, exists func(context.Context, v1.Descriptor) (bool, error),
+	manifests []v1.Descriptor) ([]v1.Descriptor, error) {
+	var dangling []v1.Descriptor
+	for i := range manifests {
+		ok, err := exists(ctx, manifests[i])
+		if err != nil {
+			return nil, fmt.Errorf("unable to check manifest %s: %w", manifests[i].Digest, err)
+		}
+		if !ok {
+			dangling = append(dangling, manifests[i])
+		}
+	}
+
+	return dangling, nil
+}
+
+// PruneIndex removes the dangling manifests from the index encoded in data, preserving all its other fields.
+// An error is returned if no manifest would be left.
+func PruneIndex(data []byte, dangling []v1.Descriptor) ([]byte, error) {
+	var index v1.Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal index: %w", err)
+	}
+
+	removed := make(map[digest.Digest]bool, len(dangling))
+	for i := range dangling {
+		removed[dangling[i].Digest] = true
+	}
+
+	kept := make([]v1.Descriptor, 0, len(index.Manifests))
+	for i := range index.Manifests {
+		if !removed[index.Manifests[i].Digest] {
+			kept = append(kept, index.Manifests[i])
+		}
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("all the manifests of the index are dangling, refusing to prune them all")
+	}
+
+	data, err := rewriteNode(data, "manifests", kept, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to rewrite index: %w", err)
+	}
+
+	return data, nil
+}
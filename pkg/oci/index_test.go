@@ -0,0 +1,137 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestParseIndexEntry(t *testing.T) {
+	entry, err := ParseIndexEntry("linux/amd64=@" + testDigest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Platform != "linux/amd64" || entry.Digest != testDigest {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+
+	for _, invalid := range []string{"linux/amd64", "linux=@" + testDigest, "linux/arm/v7=@" + testDigest,
+		"linux/amd64=" + testDigest, "linux/amd64=@"} {
+		if _, err = ParseIndexEntry(invalid); err == nil {
+			t.Errorf("expected error parsing %q", invalid)
+		}
+	}
+}
+
+func TestMergeIndex(t *testing.T) {
+	manifest := func(goos, goarch, content string) v1.Descriptor {
+		return v1.Descriptor{
+			MediaType: v1.MediaTypeImageManifest,
+			Digest:    digest.FromString(content),
+			Platform:  &v1.Platform{OS: goos, Architecture: goarch},
+		}
+	}
+
+	index := v1.Index{Manifests: []v1.Descriptor{manifest("linux", "arm64", "old-arm64"), manifest("darwin", "arm64", "darwin")}}
+	MergeIndex(&index, []v1.Descriptor{manifest("linux", "amd64", "amd64"), manifest("linux", "arm64", "new-arm64")})
+
+	expected := []v1.Descriptor{manifest("darwin", "arm64", "darwin"), manifest("linux", "amd64", "amd64"),
+		manifest("linux", "arm64", "new-arm64")}
+	if len(index.Manifests) != len(expected) {
+		t.Fatalf("expected %d manifests, got %d", len(expected), len(index.Manifests))
+	}
+	for i := range expected {
+		if index.Manifests[i].Digest != expected[i].Digest || platformKey(index.Manifests[i].Platform) != platformKey(expected[i].Platform) {
+			t.Errorf("manifest %d: expected %s (%s), got %s (%s)", i, expected[i].Digest, platformKey(expected[i].Platform),
+				index.Manifests[i].Digest, platformKey(index.Manifests[i].Platform))
+		}
+	}
+}
+
+func TestMergeIndexArchAliases(t *testing.T) {
+	manifest := func(goos, goarch, content string) v1.Descriptor {
+		return v1.Descriptor{
+			MediaType: v1.MediaTypeImageManifest,
+			Digest:    digest.FromString(content),
+			Platform:  &v1.Platform{OS: goos, Architecture: goarch},
+		}
+	}
+
+	// The manifests pushed with the aliases of the architectures are replaced by the normalized ones.
+	index := v1.Index{Manifests: []v1.Descriptor{manifest("linux", "x86_64", "old-amd64"), manifest("linux", "aarch64", "old-arm64")}}
+	MergeIndex(&index, []v1.Descriptor{manifest("linux", "amd64", "new-amd64"), manifest("linux", "arm64", "new-arm64")})
+
+	expected := []v1.Descriptor{manifest("linux", "amd64", "new-amd64"), manifest("linux", "arm64", "new-arm64")}
+	if len(index.Manifests) != len(expected) {
+		t.Fatalf("expected %d manifests, got %d", len(expected), len(index.Manifests))
+	}
+	for i := range expected {
+		if index.Manifests[i].Digest != expected[i].Digest || platformKey(index.Manifests[i].Platform) != platformKey(expected[i].Platform) {
+			t.Errorf("manifest %d: expected %s (%s), got %s (%s)", i, expected[i].Digest, platformKey(expected[i].Platform),
+				index.Manifests[i].Digest, platformKey(index.Manifests[i].Platform))
+		}
+	}
+}
+
+func TestDanglingManifests(t *testing.T) {
+	present := v1.Descriptor{MediaType: v1.MediaTypeImageManifest, Digest: digest.FromString("present"),
+		Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}
+	deleted := v1.Descriptor{MediaType: v1.MediaTypeImageManifest, Digest: digest.FromString("deleted"),
+		Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}
+	exists := func(_ context.Context, desc v1.Descriptor) (bool, error) {
+		return desc.Digest == present.Digest, nil
+	}
+
+	dangling, err := danglingManifests(context.Background(), exists, []v1.Descriptor{present, deleted})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dangling) != 1 || dangling[0].Digest != deleted.Digest {
+		t.Fatalf("expected %s to be dangling, got %v", deleted.Digest, dangling)
+	}
+
+	index, err := json.Marshal(v1.Index{
+		MediaType:   v1.MediaTypeImageIndex,
+		Manifests:   []v1.Descriptor{present, deleted},
+		Annotations: map[string]string{"org.opencontainers.image.source": "https://github.com/myorg/myplugin"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := PruneIndex(index, dangling)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pruned v1.Index
+	if err = json.Unmarshal(data, &pruned); err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned.Manifests) != 1 || pruned.Manifests[0].Digest != present.Digest {
+		t.Errorf("expected only %s to be kept, got %v", present.Digest, pruned.Manifests)
+	}
+	if pruned.Annotations["org.opencontainers.image.source"] == "" || pruned.MediaType != v1.MediaTypeImageIndex {
+		t.Errorf("existing fields not preserved, got %s", data)
+	}
+
+	if _, err = PruneIndex(index, []v1.Descriptor{present, deleted}); err == nil {
+		t.Error("expected error pruning all the manifests")
+	}
+}
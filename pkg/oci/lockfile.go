@@ -0,0 +1,101 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"oras.land/oras-go/v2/registry"
+)
+
+// LockedArtifact is an artifact recorded in a Lockfile, pinned to the digest of its manifest.
+type LockedArtifact struct {
+	// Ref is the reference of the artifact pinned by digest, e.g. "ghcr.io/falcosecurity/rules/falco-rules@sha256:...".
+	Ref  string       `json:"ref" yaml:"ref"`
+	Type ArtifactType `json:"type" yaml:"type"`
+	// Platform is the platform pulled, in OS/ARCH format.
+	Platform string `json:"platform" yaml:"platform"`
+	// Dir is the directory, relative to the destination one, where the artifact has been pulled, if not the destination one.
+	Dir          string               `json:"dir,omitempty" yaml:"dir,omitempty"`
+	Dependencies []ArtifactDependency `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+}
+
+// OSArch returns the OS and the architecture of the platform of the locked artifact.
+func (l *LockedArtifact) OSArch() (os, arch string) {
+	os, arch, _ = strings.Cut(l.Platform, "/")
+	return os, arch
+}
+
+// Lockfile records the exact artifacts pulled, so that they can be pulled again deterministically.
+type Lockfile struct {
+	Artifacts []LockedArtifact `json:"artifacts" yaml:"artifacts"`
+}
+
+// PinReference returns ref with its tag, if any, replaced by the given digest.
+func PinReference(ref, digest string) (string, error) {
+	parsedRef, err := registry.ParseReference(ref)
+	if err != nil {
+		return "", err
+	}
+	parsedRef.Reference = digest
+
+	return parsedRef.String(), nil
+}
+
+// LoadLockfile reads a lockfile and checks that all its artifacts are pinned by digest.
+func LoadLockfile(path string) (*Lockfile, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var lockfile Lockfile
+	if err = yaml.Unmarshal(data, &lockfile); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal lockfile %q: %w", path, err)
+	}
+
+	for i := range lockfile.Artifacts {
+		artifact := &lockfile.Artifacts[i]
+		parsedRef, err := registry.ParseReference(artifact.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("lockfile %q: %w", path, err)
+		}
+		if _, err = parsedRef.Digest(); err != nil {
+			return nil, fmt.Errorf("lockfile %q: %q is not pinned by digest", path, artifact.Ref)
+		}
+		if os, arch := artifact.OSArch(); os == "" || arch == "" {
+			return nil, fmt.Errorf("lockfile %q: platform %q of %q not in OS/ARCH format", path, artifact.Platform, artifact.Ref)
+		}
+		if filepath.IsAbs(artifact.Dir) || strings.HasPrefix(filepath.Clean(artifact.Dir), "..") {
+			return nil, fmt.Errorf("lockfile %q: directory %q of %q not within the destination directory", path, artifact.Dir, artifact.Ref)
+		}
+	}
+
+	return &lockfile, nil
+}
+
+// Write writes the lockfile to path.
+func (l *Lockfile) Write(path string) error {
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const lockfileDigest = "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
+
+func TestLockfile(t *testing.T) {
+	ref, err := PinReference("ghcr.io/falcosecurity/rules/falco-rules:0.1.0", lockfileDigest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != "ghcr.io/falcosecurity/rules/falco-rules@"+lockfileDigest {
+		t.Fatalf("unexpected pinned reference %q", ref)
+	}
+
+	path := filepath.Join(t.TempDir(), "lock.yaml")
+	lockfile := &Lockfile{Artifacts: []LockedArtifact{{
+		Ref:          ref,
+		Type:         Rulesfile,
+		Platform:     "linux/amd64",
+		Dependencies: []ArtifactDependency{{Name: "cloudtrail", Version: "0.6.0"}},
+	}}}
+	if err = lockfile.Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadLockfile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(loaded.Artifacts) != 1 || loaded.Artifacts[0].Ref != ref || loaded.Artifacts[0].Dependencies[0].Name != "cloudtrail" {
+		t.Errorf("unexpected lockfile %+v", loaded)
+	}
+	if os, arch := loaded.Artifacts[0].OSArch(); os != "linux" || arch != "amd64" {
+		t.Errorf("unexpected platform %s/%s", os, arch)
+	}
+}
+
+func TestLoadLockfileInvalid(t *testing.T) {
+	tests := map[string]string{
+		"tag":      "artifacts:\n  - ref: ghcr.io/falcosecurity/rules/falco-rules:0.1.0\n    type: rulesfile\n    platform: linux/amd64\n",
+		"platform": "artifacts:\n  - ref: ghcr.io/falcosecurity/rules/falco-rules@" + lockfileDigest + "\n    type: rulesfile\n    platform: linux\n",
+		"dir": "artifacts:\n  - ref: ghcr.io/falcosecurity/rules/falco-rules@" + lockfileDigest +
+			"\n    type: rulesfile\n    platform: linux/amd64\n    dir: ../etc\n",
+	}
+
+	for name, content := range tests {
+		path := filepath.Join(t.TempDir(), "lock.yaml")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadLockfile(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"errors"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// MediaTypeSet represents the family of media types used for the manifests, configs and layers of artifacts.
+type MediaTypeSet string
+
+const (
+	// OCIMediaTypes uses the OCI image media types and the Falco specific ones for configs and layers.
+	OCIMediaTypes MediaTypeSet = "oci"
+	// DockerMediaTypes uses the docker distribution schema 2 media types.
+	DockerMediaTypes MediaTypeSet = "docker"
+)
+
+// The following functions are necessary to use MediaTypeSet with Cobra.
+
+// String returns a string representation of MediaTypeSet.
+func (m *MediaTypeSet) String() string {
+	return string(*m)
+}
+
+// Set a MediaTypeSet.
+func (m *MediaTypeSet) Set(v string) error {
+	switch v {
+	case "oci", "docker":
+		*m = MediaTypeSet(v)
+		return nil
+	default:
+		return errors.New(`must be one of "oci", "docker"`)
+	}
+}
+
+// Type returns a string representing this type.
+func (m *MediaTypeSet) Type() string {
+	return "MediaTypeSet"
+}
+
+// MediaTypes groups the media types used to push an artifact.
+type MediaTypes struct {
+	Manifest string
+	Index    string
+	Config   string
+	Layer    string
+}
+
+// MediaTypesFor returns the media types used for an artifact type in the given set.
+func MediaTypesFor(artifactType ArtifactType, set MediaTypeSet) MediaTypes {
+	if set == DockerMediaTypes {
+		return MediaTypes{
+			Manifest: DockerManifestMediaType,
+			Index:    DockerManifestListMediaType,
+			Config:   DockerConfigMediaType,
+			Layer:    DockerLayerMediaType,
+		}
+	}
+
+	mediaTypes := MediaTypes{
+		Manifest: v1.MediaTypeImageManifest,
+		Index:    v1.MediaTypeImageIndex,
+	}
+
+	switch artifactType {
+	case Rulesfile:
+		mediaTypes.Config = FalcoRulesfileConfigMediaType
+		mediaTypes.Layer = FalcoRulesfileLayerMediaType
+	case Plugin:
+		mediaTypes.Config = FalcoPluginConfigMediaType
+		mediaTypes.Layer = FalcoPluginLayerMediaType
+	}
+
+	return mediaTypes
+}
+
+// IsIndex returns true if mediaType is the one of an OCI index or a docker manifest list.
+func IsIndex(mediaType string) bool {
+	return mediaType == v1.MediaTypeImageIndex || mediaType == DockerManifestListMediaType
+}
+
+// IsManifest returns true if mediaType is the one of an OCI or docker manifest.
+func IsManifest(mediaType string) bool {
+	return mediaType == v1.MediaTypeImageManifest || mediaType == DockerManifestMediaType
+}
@@ -0,0 +1,50 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import "testing"
+
+func TestMediaTypesFor(t *testing.T) {
+	mediaTypes := MediaTypesFor(Plugin, OCIMediaTypes)
+	if mediaTypes.Config != FalcoPluginConfigMediaType || mediaTypes.Layer != FalcoPluginLayerMediaType {
+		t.Fatal("unexpected oci media types for plugin, got:", mediaTypes)
+	}
+
+	mediaTypes = MediaTypesFor(Rulesfile, OCIMediaTypes)
+	if mediaTypes.Config != FalcoRulesfileConfigMediaType || mediaTypes.Layer != FalcoRulesfileLayerMediaType {
+		t.Fatal("unexpected oci media types for rulesfile, got:", mediaTypes)
+	}
+
+	mediaTypes = MediaTypesFor(Rulesfile, DockerMediaTypes)
+	if mediaTypes.Manifest != DockerManifestMediaType || mediaTypes.Index != DockerManifestListMediaType ||
+		mediaTypes.Config != DockerConfigMediaType || mediaTypes.Layer != DockerLayerMediaType {
+		t.Fatal("unexpected docker media types, got:", mediaTypes)
+	}
+
+	if !IsIndex(mediaTypes.Index) || !IsManifest(mediaTypes.Manifest) {
+		t.Fatal("docker index and manifest media types not recognized")
+	}
+}
+
+func TestMediaTypeSet(t *testing.T) {
+	var set MediaTypeSet
+	if err := set.Set("docker"); err != nil || set != DockerMediaTypes {
+		t.Fatal("unable to set docker media type set, got:", set, err)
+	}
+
+	if err := set.Set("schema1"); err == nil {
+		t.Fatal("expected error for unknown media type set")
+	}
+}
@@ -18,13 +18,116 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
+	"regexp"
+	"strings"
 
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
 )
 
+// PlatformFormat describes the format of the platforms accepted by ParsePlatform.
+const PlatformFormat = `OS/ARCH[/VARIANT], e.g. "linux/amd64", "linux/arm64" or "linux/arm/v7"`
+
+// archAliases maps the common aliases of the architectures to their GOARCH names, used in the platforms of OCI images.
+var archAliases = map[string]string{
+	"x86_64":  "amd64",
+	"aarch64": "arm64",
+}
+
+var (
+	platformOSRgx   = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+	platformArchRgx = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// ParsePlatform parses a platform in OS/ARCH[/VARIANT] format. The common aliases of the architectures are
+// normalized to their GOARCH names, e.g. "x86_64" to "amd64" and "aarch64" to "arm64".
+func ParsePlatform(platform string) (*v1.Platform, error) {
+	if platform == "" {
+		return nil, fmt.Errorf("platform is not set, expected %s", PlatformFormat)
+	}
+
+	tokens := strings.Split(platform, "/")
+	if len(tokens) == 1 {
+		if strings.ContainsAny(platform, "-_") {
+			return nil, fmt.Errorf("platform %q is malformed: OS, ARCH and VARIANT must be separated by \"/\", expected %s",
+				platform, PlatformFormat)
+		}
+		return nil, fmt.Errorf("platform %q is malformed: ARCH is missing, expected %s", platform, PlatformFormat)
+	}
+	if len(tokens) > 3 {
+		return nil, fmt.Errorf("platform %q is malformed: too many components, expected %s", platform, PlatformFormat)
+	}
+
+	for i, name := range []string{"OS", "ARCH", "VARIANT"}[:len(tokens)] {
+		if tokens[i] == "" {
+			return nil, fmt.Errorf("platform %q is malformed: %s is empty, expected %s", platform, name, PlatformFormat)
+		}
+	}
+
+	p := &v1.Platform{OS: tokens[0], Architecture: tokens[1]}
+	if alias, ok := archAliases[p.Architecture]; ok {
+		p.Architecture = alias
+	}
+	if len(tokens) == 3 {
+		p.Variant = tokens[2]
+	}
+
+	if !platformOSRgx.MatchString(p.OS) {
+		return nil, fmt.Errorf("platform %q is malformed: OS %q must only contain lowercase letters and digits, expected %s",
+			platform, p.OS, PlatformFormat)
+	}
+	if !platformArchRgx.MatchString(p.Architecture) || (p.Variant != "" && !platformArchRgx.MatchString(p.Variant)) {
+		return nil, fmt.Errorf("platform %q is malformed: ARCH and VARIANT must only contain lowercase letters, digits and \"_\", expected %s",
+			platform, PlatformFormat)
+	}
+
+	return p, nil
+}
+
+// NormalizePlatform parses the platform with ParsePlatform and returns it in OS/ARCH[/VARIANT] format.
+func NormalizePlatform(platform string) (string, error) {
+	p, err := ParsePlatform(platform)
+	if err != nil {
+		return "", err
+	}
+
+	return platformKey(p), nil
+}
+
+// MatchPlatform reports whether platform has the given OS and architecture, regardless of the aliases of the
+// architectures, e.g. "linux/x86_64" matches "linux/amd64", since artifacts may have been pushed with either.
+func MatchPlatform(platform *v1.Platform, os, arch string) bool {
+	return platform != nil && platform.OS == os && normalizeArch(platform.Architecture) == normalizeArch(arch)
+}
+
+// SelectPlatform returns the position of the manifest for the given OS and architecture among manifests, or -1 if
+// there is none. A manifest whose platform is exactly the given one is preferred to one matching through an alias.
+func SelectPlatform(manifests []v1.Descriptor, os, arch string) int {
+	selected := -1
+	for i := range manifests {
+		platform := manifests[i].Platform
+		if !MatchPlatform(platform, os, arch) {
+			continue
+		}
+		if platform.Architecture == arch {
+			return i
+		}
+		if selected < 0 {
+			selected = i
+		}
+	}
+
+	return selected
+}
+
+func normalizeArch(arch string) string {
+	if alias, ok := archAliases[arch]; ok {
+		return alias
+	}
+	return arch
+}
+
 // Platforms returns a list of all available platforms for a given ref.
 func Platforms(ctx context.Context, ref string, client *auth.Client) (map[string]struct{}, error) {
 	repo, err := remote.NewRepository(ref)
@@ -38,7 +141,7 @@ func Platforms(ctx context.Context, ref string, client *auth.Client) (map[string
 		return nil, err
 	}
 
-	if refDesc.MediaType != v1.MediaTypeImageIndex {
+	if !IsIndex(refDesc.MediaType) {
 		return nil, fmt.Errorf("reference does not point to an index")
 	}
 
@@ -47,7 +150,7 @@ func Platforms(ctx context.Context, ref string, client *auth.Client) (map[string
 		return nil, fmt.Errorf("unable to fetch descriptor: %w", err)
 	}
 
-	indexBytes, err := io.ReadAll(indexReader)
+	indexBytes, err := readMetadata(indexReader, &refDesc)
 	if err != nil {
 		return nil, err
 	}
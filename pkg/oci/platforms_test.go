@@ -0,0 +1,78 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestNormalizePlatform(t *testing.T) {
+	for platform, expected := range map[string]string{
+		"linux/amd64":   "linux/amd64",
+		"linux/x86_64":  "linux/amd64",
+		"linux/aarch64": "linux/arm64",
+		"darwin/arm64":  "darwin/arm64",
+		"linux/arm/v7":  "linux/arm/v7",
+	} {
+		normalized, err := NormalizePlatform(platform)
+		if err != nil {
+			t.Errorf("unexpected error normalizing %q: %v", platform, err)
+			continue
+		}
+		if normalized != expected {
+			t.Errorf("expected %q normalized to %q, got %q", platform, expected, normalized)
+		}
+	}
+
+	for _, invalid := range []string{"", "linux", "linux-amd64", "linux_amd64", "linux/", "/amd64", "linux/arm/",
+		"linux/arm/v7/extra", "Linux/amd64", "linux/amd-64"} {
+		if _, err := NormalizePlatform(invalid); err == nil {
+			t.Errorf("expected error normalizing %q", invalid)
+		}
+	}
+}
+
+func TestSelectPlatform(t *testing.T) {
+	manifest := func(goos, goarch string) v1.Descriptor {
+		return v1.Descriptor{Platform: &v1.Platform{OS: goos, Architecture: goarch}}
+	}
+
+	aliased := []v1.Descriptor{manifest("linux", "x86_64"), manifest("linux", "aarch64"), {}}
+	both := []v1.Descriptor{manifest("linux", "x86_64"), manifest("linux", "amd64")}
+
+	testCases := []struct {
+		manifests []v1.Descriptor
+		os        string
+		arch      string
+		expected  int
+	}{
+		{manifests: aliased, os: "linux", arch: "amd64", expected: 0},
+		{manifests: aliased, os: "linux", arch: "x86_64", expected: 0},
+		{manifests: aliased, os: "linux", arch: "arm64", expected: 1},
+		{manifests: aliased, os: "darwin", arch: "arm64", expected: -1},
+		{manifests: aliased, os: "linux", arch: "s390x", expected: -1},
+		// The exact platform is preferred to an alias.
+		{manifests: both, os: "linux", arch: "amd64", expected: 1},
+		{manifests: both, os: "linux", arch: "x86_64", expected: 0},
+	}
+
+	for _, tc := range testCases {
+		if got := SelectPlatform(tc.manifests, tc.os, tc.arch); got != tc.expected {
+			t.Errorf("expected manifest %d selected for %s/%s, got %d", tc.expected, tc.os, tc.arch, got)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProvenanceFile is the name of the file, in the destination directory of a pull, recording where
+// each pulled file comes from.
+const ProvenanceFile = ".falcoctl-provenance.yaml"
+
+// FileProvenance records the artifact, the platform and the layer a pulled file comes from.
+type FileProvenance struct {
+	// File is the path of the pulled file, relative to the destination directory.
+	File string `json:"file" yaml:"file"`
+	// Ref is the reference of the artifact pinned by digest.
+	Ref string `json:"ref" yaml:"ref"`
+	// Platform is the platform pulled, in OS/ARCH format.
+	Platform    string `json:"platform" yaml:"platform"`
+	LayerDigest string `json:"layerDigest" yaml:"layerDigest"`
+	PulledAt    string `json:"pulledAt" yaml:"pulledAt"`
+}
+
+// Provenance records the provenance of the files pulled in a directory.
+type Provenance struct {
+	Files []FileProvenance `json:"files" yaml:"files"`
+}
+
+// LoadProvenance reads the provenance of the files pulled in a directory. An empty provenance is
+// returned if the file does not exist.
+func LoadProvenance(path string) (*Provenance, error) {
+	var provenance Provenance
+	data, err := os.ReadFile(filepath.Clean(path))
+	if os.IsNotExist(err) {
+		return &provenance, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err = yaml.Unmarshal(data, &provenance); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal provenance file %q: %w", path, err)
+	}
+
+	return &provenance, nil
+}
+
+// Upsert records the provenance of a file, replacing the previous one of the same file.
+func (p *Provenance) Upsert(file *FileProvenance) {
+	for i := range p.Files {
+		if p.Files[i].File == file.File {
+			p.Files[i] = *file
+			return
+		}
+	}
+
+	p.Files = append(p.Files, *file)
+}
+
+// Write writes the provenance to path, with the files sorted by path.
+func (p *Provenance) Write(path string) error {
+	sort.Slice(p.Files, func(i, j int) bool { return p.Files[i].File < p.Files[j].File })
+
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,44 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestProvenance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ProvenanceFile)
+
+	provenance, err := LoadProvenance(path)
+	if err != nil || len(provenance.Files) != 0 {
+		t.Fatalf("expected an empty provenance, got %+v, %v", provenance, err)
+	}
+
+	provenance.Upsert(&FileProvenance{File: "linux-arm64/plugin.tar.gz", Platform: "linux/arm64", LayerDigest: "sha256:1"})
+	provenance.Upsert(&FileProvenance{File: "linux-amd64/plugin.tar.gz", Platform: "linux/amd64", LayerDigest: "sha256:2"})
+	provenance.Upsert(&FileProvenance{File: "linux-arm64/plugin.tar.gz", Platform: "linux/arm64", LayerDigest: "sha256:3"})
+	if err = provenance.Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadProvenance(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Files) != 2 || loaded.Files[0].File != "linux-amd64/plugin.tar.gz" || loaded.Files[1].LayerDigest != "sha256:3" {
+		t.Errorf("unexpected provenance %+v", loaded)
+	}
+}
@@ -16,13 +16,22 @@ package puller
 
 import (
 	"context"
+	// Link the sha512 implementation, so that artifacts pushed with sha512 digests can be verified.
+	_ "crypto/sha512"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
 
@@ -32,9 +41,24 @@ import (
 // ProgressTracker type of the tracker that the puller accepts. It implements the tracker logic.
 type ProgressTracker func(target oras.Target) oras.Target
 
+// ErrMetadataTooLarge error when a manifest, an index or a config exceeds the maximum allowed size.
+var ErrMetadataTooLarge = errors.New("metadata exceeds the maximum allowed size")
+
+// ErrContainerImage error when the pulled reference is a container image rather than a Falco artifact.
+var ErrContainerImage = errors.New("this reference is a container image, not a Falco artifact")
+
 // Puller implements pull operations.
 type Puller struct {
-	Client  *auth.Client
+	Client *auth.Client
+	// MaxMetadataSize is the maximum size in bytes of the manifests, indexes and configs read
+	// by the puller. If zero, oci.DefaultMaxMetadataSize is used.
+	MaxMetadataSize int64
+	// Concurrency is the maximum number of platforms pulled at the same time by PullPlatforms.
+	// If zero, platforms are pulled one at a time.
+	Concurrency int
+	// AnyType allows pulling container images, whose layers are saved in the destination directory as they are.
+	// If false, pulling a container image fails with ErrContainerImage before any layer is downloaded.
+	AnyType bool
 	tracker ProgressTracker
 }
 
@@ -69,14 +93,26 @@ func (p *Puller) Pull(ctx context.Context, ref, destDir, os, arch string) (*oci.
 		return nil, err
 	}
 
+	if err = p.checkMetadataSize(&refDesc); err != nil {
+		return nil, err
+	}
+
 	copyOpts := oras.CopyOptions{}
 	copyOpts.Concurrency = 1
-	if refDesc.MediaType == v1.MediaTypeImageIndex {
-		plt := &v1.Platform{
-			OS:           os,
-			Architecture: arch,
+	// Check the size of each node before its content is read.
+	copyOpts.FindSuccessors = func(ctx context.Context, fetcher content.Fetcher, desc v1.Descriptor) ([]v1.Descriptor, error) {
+		if err := p.checkMetadataSize(&desc); err != nil {
+			return nil, err
+		}
+		if !oci.IsManifest(desc.MediaType) {
+			return content.Successors(ctx, fetcher, desc)
+		}
+		return p.manifestSuccessors(ctx, fetcher, &desc)
+	}
+	if oci.IsIndex(refDesc.MediaType) {
+		copyOpts.MapRoot = func(ctx context.Context, src content.ReadOnlyStorage, root v1.Descriptor) (v1.Descriptor, error) {
+			return selectManifest(ctx, src, root, os, arch)
 		}
-		copyOpts.WithTargetPlatform(plt)
 	}
 
 	localTarget := oras.Target(fileStore)
@@ -91,31 +127,207 @@ func (p *Puller) Pull(ctx context.Context, ref, destDir, os, arch string) (*oci.
 			repo.Reference.Repository, repo.Reference.Reference, repo.Reference.Repository, err)
 	}
 
-	manifest, err := manifestFromDesc(ctx, localTarget, &desc)
+	manifest, err := manifestFromDesc(ctx, localTarget, &desc, p.maxMetadataSize())
 	if err != nil {
 		return nil, err
 	}
 
-	var artifactType oci.ArtifactType
-	switch manifest.Layers[0].MediaType {
-	case oci.FalcoPluginLayerMediaType:
-		artifactType = oci.Plugin
-	case oci.FalcoRulesfileLayerMediaType:
-		artifactType = oci.Rulesfile
-	default:
-		return nil, fmt.Errorf("unknown media type: %q", manifest.Layers[0].MediaType)
+	if oci.IsContainerImage(manifest) {
+		return &oci.RegistryResult{
+			Digest:      string(desc.Digest),
+			Type:        oci.ContainerImage,
+			Filename:    layerFilename(&manifest.Layers[0]),
+			LayerDigest: manifest.Layers[0].Digest.String(),
+		}, nil
+	}
+
+	artifactType, err := oci.ArtifactTypeOf(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collections have no content of their own, the caller is in charge of pulling their members.
+	if artifactType == oci.Collection {
+		config, err := collectionFromDesc(ctx, localTarget, &manifest.Config, p.maxMetadataSize())
+		if err != nil {
+			return nil, err
+		}
+
+		return &oci.RegistryResult{
+			Digest:  string(desc.Digest),
+			Type:    artifactType,
+			Members: config.Members,
+		}, nil
 	}
 
 	filename := manifest.Layers[0].Annotations[v1.AnnotationTitle]
 
 	return &oci.RegistryResult{
-		Digest:   string(desc.Digest),
-		Type:     artifactType,
-		Filename: filename,
+		Digest:      string(desc.Digest),
+		Type:        artifactType,
+		Filename:    filename,
+		LayerDigest: manifest.Layers[0].Digest.String(),
 	}, nil
 }
 
-func manifestFromDesc(ctx context.Context, target oras.Target, desc *v1.Descriptor) (*v1.Manifest, error) {
+// PullPlatforms pulls the artifact for each of the given platforms, in OS/ARCH format, in a subdirectory
+// of destDir named OS-ARCH. Up to Concurrency platforms are pulled at the same time. The results are
+// returned in the same order of the platforms. After the first failure no other platform is pulled.
+func (p *Puller) PullPlatforms(ctx context.Context, ref, destDir string, platforms []string) ([]*oci.RegistryResult, error) {
+	// Check all the platforms before pulling any of them.
+	for _, platform := range platforms {
+		parsed, err := oci.ParsePlatform(platform)
+		if err != nil {
+			return nil, err
+		}
+		if parsed.Variant != "" {
+			return nil, fmt.Errorf("platform %q: variants are not supported, expected OS/ARCH", platform)
+		}
+	}
+
+	concurrency := p.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]*oci.RegistryResult, len(platforms))
+	errs := make([]error, len(platforms))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, platform := range platforms {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		// The platforms are pulled as given, not normalized, so that the subdirectories match the platforms of the index.
+		goos, goarch, _ := strings.Cut(platform, "/")
+		wg.Add(1)
+		go func(i int, goos, goarch string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			platformDir := filepath.Join(destDir, goos+"-"+goarch)
+			if errs[i] = os.MkdirAll(platformDir, 0o750); errs[i] == nil {
+				results[i], errs[i] = p.Pull(ctx, ref, platformDir, goos, goarch)
+			}
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("platform %s/%s: %w", goos, goarch, errs[i])
+				cancel()
+			}
+		}(i, goos, goarch)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The pull was interrupted before any failure.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// selectManifest returns the manifest of the index root for the given platform. Unlike oras.CopyOptions.WithTargetPlatform,
+// the aliases of the architectures are taken into account, so that artifacts pushed e.g. for "linux/x86_64" can be pulled
+// for "linux/amd64" and vice versa.
+func selectManifest(ctx context.Context, src content.ReadOnlyStorage, root v1.Descriptor, goos, goarch string) (v1.Descriptor, error) {
+	manifests, err := content.Successors(ctx, src, root)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+
+	i := oci.SelectPlatform(manifests, goos, goarch)
+	if i < 0 {
+		return v1.Descriptor{}, fmt.Errorf("%s: %w: no manifest found for platform %s/%s in the index", root.Digest, errdef.ErrNotFound, goos, goarch)
+	}
+
+	return manifests[i], nil
+}
+
+func (p *Puller) maxMetadataSize() int64 {
+	if p.MaxMetadataSize > 0 {
+		return p.MaxMetadataSize
+	}
+	return oci.DefaultMaxMetadataSize
+}
+
+// checkMetadataSize returns an error if desc describes a manifest, an index or a config
+// larger than the maximum allowed size. Layers are not limited.
+func (p *Puller) checkMetadataSize(desc *v1.Descriptor) error {
+	switch desc.MediaType {
+	case v1.MediaTypeImageManifest, v1.MediaTypeImageIndex, oci.FalcoPluginConfigMediaType, oci.FalcoRulesfileConfigMediaType,
+		oci.FalcoCollectionConfigMediaType, oci.DockerManifestMediaType, oci.DockerManifestListMediaType, oci.DockerConfigMediaType:
+	default:
+		return nil
+	}
+
+	if maxSize := p.maxMetadataSize(); desc.Size > maxSize {
+		return fmt.Errorf("%s %s of %d bytes, limit is %d bytes: %w", desc.MediaType, desc.Digest, desc.Size, maxSize, ErrMetadataTooLarge)
+	}
+
+	return nil
+}
+
+// manifestSuccessors returns the config and the layers of the manifest described by desc. Container images are
+// rejected unless AnyType is set, in which case their layers are named so that the file store saves them on disk.
+func (p *Puller) manifestSuccessors(ctx context.Context, fetcher content.Fetcher, desc *v1.Descriptor) ([]v1.Descriptor, error) {
+	manifest, err := manifestFromDesc(ctx, fetcher, desc, p.maxMetadataSize())
+	if err != nil {
+		return nil, err
+	}
+
+	if oci.IsContainerImage(manifest) {
+		if !p.AnyType {
+			return nil, fmt.Errorf("%w (config media type %q), use --any-type to pull it anyway", ErrContainerImage, manifest.Config.MediaType)
+		}
+		for i := range manifest.Layers {
+			layer := &manifest.Layers[i]
+			if _, ok := layer.Annotations[v1.AnnotationTitle]; !ok {
+				annotations := map[string]string{v1.AnnotationTitle: layerFilename(layer)}
+				for k, v := range layer.Annotations {
+					annotations[k] = v
+				}
+				layer.Annotations = annotations
+			}
+		}
+	}
+
+	return append([]v1.Descriptor{manifest.Config}, manifest.Layers...), nil
+}
+
+// layerFilename returns the name of the file where a layer is saved: its title, if any, or its digest
+// followed by an extension matching its media type.
+func layerFilename(layer *v1.Descriptor) string {
+	if title, ok := layer.Annotations[v1.AnnotationTitle]; ok {
+		return title
+	}
+
+	ext := ".tar"
+	switch {
+	case strings.HasSuffix(layer.MediaType, "gzip"):
+		ext = ".tar.gz"
+	case strings.HasSuffix(layer.MediaType, "zstd"):
+		ext = ".tar.zst"
+	}
+
+	return layer.Digest.Encoded() + ext
+}
+
+func manifestFromDesc(ctx context.Context, target content.Fetcher, desc *v1.Descriptor, maxSize int64) (*v1.Manifest, error) {
 	var manifest v1.Manifest
 
 	descReader, err := target.Fetch(ctx, *desc)
@@ -124,7 +336,7 @@ func manifestFromDesc(ctx context.Context, target oras.Target, desc *v1.Descript
 	}
 	defer descReader.Close()
 
-	descBytes, err := io.ReadAll(descReader)
+	descBytes, err := io.ReadAll(io.LimitReader(descReader, maxSize))
 	if err != nil {
 		return nil, fmt.Errorf("unable to read bytes from descriptor: %w", err)
 	}
@@ -139,3 +351,28 @@ func manifestFromDesc(ctx context.Context, target oras.Target, desc *v1.Descript
 
 	return &manifest, nil
 }
+
+func collectionFromDesc(ctx context.Context, target content.Fetcher, desc *v1.Descriptor, maxSize int64) (*oci.CollectionConfig, error) {
+	var config oci.CollectionConfig
+
+	descReader, err := target.Fetch(ctx, *desc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch collection config with digest %q: %w", desc.Digest, err)
+	}
+	defer descReader.Close()
+
+	descBytes, err := io.ReadAll(io.LimitReader(descReader, maxSize))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read bytes from descriptor: %w", err)
+	}
+
+	if err = json.Unmarshal(descBytes, &config); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal collection config: %w", err)
+	}
+
+	if err = config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid collection: %w", err)
+	}
+
+	return &config, nil
+}
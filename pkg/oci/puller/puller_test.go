@@ -0,0 +1,166 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package puller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/distribution/distribution/v3/configuration"
+	"github.com/distribution/distribution/v3/registry/handlers"
+	_ "github.com/distribution/distribution/v3/registry/storage/driver/inmemory"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+)
+
+func TestPullPlatformsMalformed(t *testing.T) {
+	destDir := t.TempDir()
+	p := NewPuller(&auth.Client{}, nil)
+
+	// The malformed platform must be rejected before pulling the valid one.
+	_, err := p.PullPlatforms(context.Background(), "localhost:1/falco:latest", destDir, []string{"linux/amd64", "linux-arm64"})
+	if err == nil {
+		t.Fatal("expected an error for the malformed platform")
+	}
+
+	entries, err := os.ReadDir(destDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no platform to be pulled, found %d entries", len(entries))
+	}
+}
+
+// pushAliasedIndex pushes to an in-memory registry a plugin whose index lists its platforms with the aliases of
+// the architectures, "linux/x86_64" and "linux/aarch64", as done by other tools. It returns the reference of the
+// index and a client for the registry.
+func pushAliasedIndex(t *testing.T) (string, *auth.Client) {
+	t.Helper()
+	ctx := context.Background()
+
+	config := &configuration.Configuration{}
+	config.Storage = configuration.Storage{"inmemory": configuration.Parameters{}}
+	server := httptest.NewTLSServer(handlers.NewApp(ctx, config))
+	t.Cleanup(server.Close)
+
+	ref := strings.TrimPrefix(server.URL, "https://") + "/plugins/cloudtrail:latest"
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &auth.Client{Client: server.Client()}
+	repo.Client = client
+
+	push := func(mediaType string, data []byte, annotations map[string]string) v1.Descriptor {
+		desc := v1.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(data), Size: int64(len(data)), Annotations: annotations}
+		if err := repo.Push(ctx, desc, bytes.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	pushJSON := func(mediaType string, v interface{}) v1.Descriptor {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return push(mediaType, data, nil)
+	}
+
+	index := v1.Index{MediaType: v1.MediaTypeImageIndex}
+	index.SchemaVersion = 2
+	for _, arch := range []string{"x86_64", "aarch64"} {
+		configDesc := pushJSON(oci.FalcoPluginConfigMediaType, map[string]string{"name": "cloudtrail", "version": "0.1.0"})
+		layerDesc := push(oci.FalcoPluginLayerMediaType, []byte("plugin for "+arch),
+			map[string]string{v1.AnnotationTitle: "cloudtrail-linux-" + arch + ".tar.gz"})
+
+		manifest := v1.Manifest{MediaType: v1.MediaTypeImageManifest, Config: configDesc, Layers: []v1.Descriptor{layerDesc}}
+		manifest.SchemaVersion = 2
+		manifestDesc := pushJSON(v1.MediaTypeImageManifest, manifest)
+		manifestDesc.Platform = &v1.Platform{OS: "linux", Architecture: arch}
+		index.Manifests = append(index.Manifests, manifestDesc)
+	}
+
+	indexDesc := pushJSON(v1.MediaTypeImageIndex, index)
+	if err = repo.Tag(ctx, indexDesc, "latest"); err != nil {
+		t.Fatal(err)
+	}
+
+	return ref, client
+}
+
+func TestPullArchAliases(t *testing.T) {
+	ref, client := pushAliasedIndex(t)
+	p := NewPuller(client, nil)
+
+	for arch, expected := range map[string]string{
+		"amd64":   "cloudtrail-linux-x86_64.tar.gz",
+		"x86_64":  "cloudtrail-linux-x86_64.tar.gz",
+		"arm64":   "cloudtrail-linux-aarch64.tar.gz",
+		"aarch64": "cloudtrail-linux-aarch64.tar.gz",
+	} {
+		res, err := p.Pull(context.Background(), ref, t.TempDir(), "linux", arch)
+		if err != nil {
+			t.Errorf("unexpected error pulling linux/%s: %v", arch, err)
+			continue
+		}
+		if res.Filename != expected || res.Type != oci.Plugin {
+			t.Errorf("expected plugin %q pulled for linux/%s, got %+v", expected, arch, res)
+		}
+	}
+
+	if _, err := p.Pull(context.Background(), ref, t.TempDir(), "linux", "s390x"); err == nil {
+		t.Error("expected an error pulling a platform not in the index")
+	}
+}
+
+func TestPullPlatformsArchAliases(t *testing.T) {
+	ref, client := pushAliasedIndex(t)
+	p := NewPuller(client, nil)
+	destDir := t.TempDir()
+
+	// The platforms listed by the index, as done by "--platform all".
+	available, err := oci.Platforms(context.Background(), ref, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	platforms := make([]string, 0, len(available))
+	for platform := range available {
+		platforms = append(platforms, strings.Replace(platform, "-", "/", 1))
+	}
+
+	results, err := p.PullPlatforms(context.Background(), ref, destDir, platforms)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, platform := range platforms {
+		// The subdirectories are named after the platforms of the index.
+		path := filepath.Join(destDir, strings.Replace(platform, "/", "-", 1), results[i].Filename)
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %q pulled for platform %s: %v", path, platform, err)
+		}
+	}
+}
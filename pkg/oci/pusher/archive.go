@@ -0,0 +1,202 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pusher
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dirFiles returns the regular files and the symlinks contained in srcDir. The archives have no tree
+// structure, as expected when installing artifacts, hence nested directories are not allowed. Unless
+// includeHidden is set, the hidden files and directories, whose name begins with ".", are skipped.
+func dirFiles(srcDir string, includeHidden bool) ([]string, error) {
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !includeHidden && isHidden(entry.Name()) {
+			continue
+		}
+		if entry.IsDir() {
+			return nil, fmt.Errorf("unexpected directory %q in %q: only files are allowed", entry.Name(), srcDir)
+		}
+		if !entry.Type().IsRegular() && entry.Type()&os.ModeSymlink == 0 {
+			continue
+		}
+		files = append(files, filepath.Join(srcDir, entry.Name()))
+	}
+
+	return files, nil
+}
+
+// globFiles returns the regular files and the symlinks matching pattern. Matching directories are not allowed.
+// Unless includeHidden is set, the hidden files and directories, whose name begins with ".", are skipped unless
+// the pattern of their name begins with "." too, as in shells.
+func globFiles(pattern string, includeHidden bool) ([]string, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	includeHidden = includeHidden || isHidden(filepath.Base(pattern))
+
+	var files []string
+	for _, match := range matches {
+		if !includeHidden && isHidden(filepath.Base(match)) {
+			continue
+		}
+		info, err := os.Lstat(match)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("unexpected directory %q matching %q: only files are allowed", match, pattern)
+		}
+		if !info.Mode().IsRegular() && info.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+		files = append(files, match)
+	}
+
+	return files, nil
+}
+
+// isHidden returns true if name is the one of a hidden file or directory.
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// createTarGz packs files in a *.tar.gz archive written to dst. Files are stored by their base name.
+// Symlinks are handled according to symlinks. The archive is compressed with the given gzip level.
+func createTarGz(files []string, dst string, symlinks SymlinkMode, level CompressionLevel) (err error) {
+	names := make(map[string]string, len(files))
+	for _, f := range files {
+		name := filepath.Base(f)
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("files %q and %q have the same name", other, f)
+		}
+		names[name] = f
+	}
+
+	out, err := os.Create(filepath.Clean(dst))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	gzipWriter, err := gzip.NewWriterLevel(out, int(level))
+	if err != nil {
+		return err
+	}
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for _, f := range files {
+		if err = addToTar(tarWriter, f, symlinks); err != nil {
+			return err
+		}
+	}
+
+	if err = tarWriter.Close(); err != nil {
+		return err
+	}
+
+	return gzipWriter.Close()
+}
+
+func addToTar(tarWriter *tar.Writer, path string, symlinks SymlinkMode) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		switch symlinks {
+		case SymlinksError:
+			return fmt.Errorf("%q: %w", path, ErrSymlinkNotAllowed)
+		case SymlinksFollow:
+			// Fall through to the regular file handling, opening the file follows the symlink.
+			if info, err = os.Stat(path); err != nil {
+				return fmt.Errorf("unable to follow symlink %q: %w", path, err)
+			}
+			if !info.Mode().IsRegular() {
+				return fmt.Errorf("symlink %q does not point to a regular file", path)
+			}
+		default:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if err = checkSymlinkTarget(path, target); err != nil {
+				return err
+			}
+
+			header, err := tar.FileInfoHeader(info, target)
+			if err != nil {
+				return err
+			}
+			header.Name = filepath.Base(path)
+
+			return tarWriter.WriteHeader(header)
+		}
+	}
+
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.Base(path)
+
+	if err = tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tarWriter, f)
+	return err
+}
+
+// checkSymlinkTarget returns an error wrapping ErrUnsafeSymlink if the target of the symlink at path is
+// absolute or resolves outside of the archive, whose entries are all stored at its root. It is the
+// rule applied when extracting the artifacts.
+func checkSymlinkTarget(path, target string) error {
+	if filepath.IsAbs(target) {
+		return fmt.Errorf("%q points to absolute path %q: %w", path, target, ErrUnsafeSymlink)
+	}
+
+	rel := filepath.Clean(target)
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%q points to %q: %w", path, target, ErrUnsafeSymlink)
+	}
+
+	return nil
+}
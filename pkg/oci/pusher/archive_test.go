@@ -0,0 +1,272 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pusher
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestCreateTarGz(t *testing.T) {
+	srcDir := t.TempDir()
+	for _, name := range []string{"a_rules.yaml", "b_rules.yaml"} {
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte("- rule: "+name+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := dirFiles(srcDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "rules.tar.gz")
+	if err = createTarGz(files, dst, SymlinksPreserve, DefaultCompressionLevel); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gzipReader, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, header.Name)
+	}
+	sort.Strings(names)
+
+	if len(names) != 2 || names[0] != "a_rules.yaml" || names[1] != "b_rules.yaml" {
+		t.Errorf("unexpected archive content %v", names)
+	}
+}
+
+func TestCreateTarGzNestedDir(t *testing.T) {
+	srcDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(srcDir, "nested"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := dirFiles(srcDir, true); err == nil {
+		t.Errorf("expected error packing a directory with nested directories")
+	}
+}
+
+func TestGlobFiles(t *testing.T) {
+	srcDir := t.TempDir()
+	for _, name := range []string{"a_rules.yaml", "b_rules.yaml", "README.md"} {
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte(name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := globFiles(filepath.Join(srcDir, "*.yaml"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("unexpected matching files %v", files)
+	}
+
+	files, err = globFiles(filepath.Join(srcDir, "*.json"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("unexpected matching files %v", files)
+	}
+}
+
+func TestHiddenFiles(t *testing.T) {
+	srcDir := t.TempDir()
+	for _, name := range []string{"rules.yaml", ".editorconfig", ".gitkeep.yaml"} {
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte(name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(srcDir, ".git"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := dirFiles(srcDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || filepath.Base(files[0]) != "rules.yaml" {
+		t.Errorf("expected only the visible file, got %v", files)
+	}
+
+	if _, err = dirFiles(srcDir, true); err == nil {
+		t.Errorf("expected error packing the hidden directory")
+	}
+
+	tests := []struct {
+		pattern       string
+		includeHidden bool
+		expected      int
+	}{
+		{pattern: "*.yaml", includeHidden: false, expected: 1},
+		{pattern: "*.yaml", includeHidden: true, expected: 2},
+		{pattern: ".*.yaml", includeHidden: false, expected: 1},
+		{pattern: ".editorconfig", includeHidden: false, expected: 1},
+	}
+	for _, tt := range tests {
+		files, err := globFiles(filepath.Join(srcDir, tt.pattern), tt.includeHidden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != tt.expected {
+			t.Errorf("pattern %q, include hidden %v: expected %d files, got %v", tt.pattern, tt.includeHidden, tt.expected, files)
+		}
+	}
+}
+
+func TestCreateTarGzSymlinks(t *testing.T) {
+	srcDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(srcDir, "shared.yaml"), []byte("- macro: shared\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("shared.yaml", filepath.Join(srcDir, "include.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := dirFiles(srcDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("unexpected files %v", files)
+	}
+
+	readTar := func(mode SymlinkMode) map[string]*tar.Header {
+		dst := filepath.Join(t.TempDir(), "rules.tar.gz")
+		if err := createTarGz(files, dst, mode, DefaultCompressionLevel); err != nil {
+			t.Fatal(err)
+		}
+
+		f, err := os.Open(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		gzipReader, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		headers := make(map[string]*tar.Header)
+		tarReader := tar.NewReader(gzipReader)
+		for {
+			header, err := tarReader.Next()
+			if err != nil {
+				break
+			}
+			headers[header.Name] = header
+		}
+		return headers
+	}
+
+	if h := readTar(SymlinksPreserve)["include.yaml"]; h == nil || h.Typeflag != tar.TypeSymlink || h.Linkname != "shared.yaml" {
+		t.Errorf("expected include.yaml to be preserved as a symlink to shared.yaml, got %+v", h)
+	}
+
+	if h := readTar(SymlinksFollow)["include.yaml"]; h == nil || h.Typeflag != tar.TypeReg || h.Size != int64(len("- macro: shared\n")) {
+		t.Errorf("expected include.yaml to be stored as a regular file, got %+v", h)
+	}
+
+	if err := createTarGz(files, filepath.Join(t.TempDir(), "rules.tar.gz"), SymlinksError, DefaultCompressionLevel); !errors.Is(err, ErrSymlinkNotAllowed) {
+		t.Errorf("expected ErrSymlinkNotAllowed, got %v", err)
+	}
+}
+
+func TestCreateTarGzUnsafeSymlinks(t *testing.T) {
+	for _, target := range []string{"/etc/falco/falco_rules.yaml", "../falco_rules.yaml", "."} {
+		srcDir := t.TempDir()
+		if err := os.Symlink(target, filepath.Join(srcDir, "include.yaml")); err != nil {
+			t.Fatal(err)
+		}
+
+		files, err := dirFiles(srcDir, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = createTarGz(files, filepath.Join(t.TempDir(), "rules.tar.gz"), SymlinksPreserve, DefaultCompressionLevel)
+		if !errors.Is(err, ErrUnsafeSymlink) {
+			t.Errorf("%s: expected ErrUnsafeSymlink, got %v", target, err)
+		}
+	}
+}
+
+func TestCreateTarGzCompressionLevel(t *testing.T) {
+	srcDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(srcDir, "rules.yaml"), bytes.Repeat([]byte("- rule: test\n"), 1000), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := dirFiles(srcDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pack := func(level CompressionLevel) []byte {
+		dst := filepath.Join(t.TempDir(), "rules.tar.gz")
+		if err := createTarGz(files, dst, SymlinksPreserve, level); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	// The same level always produces the same bytes.
+	if !bytes.Equal(pack(CompressionLevel(gzip.BestCompression)), pack(CompressionLevel(gzip.BestCompression))) {
+		t.Error("expected archives packed with the same level to be identical")
+	}
+
+	if len(pack(CompressionLevel(gzip.NoCompression))) <= len(pack(CompressionLevel(gzip.BestCompression))) {
+		t.Error("expected the uncompressed archive to be larger than the compressed one")
+	}
+
+	var level CompressionLevel
+	for _, v := range []string{"10", "-1", "slow"} {
+		if err = level.Set(v); err == nil {
+			t.Errorf("expected error setting compression level %q", v)
+		}
+	}
+	if err = level.Set("fast"); err != nil || level != CompressionLevel(gzip.BestSpeed) {
+		t.Errorf("unexpected compression level %d, err %v", level, err)
+	}
+}
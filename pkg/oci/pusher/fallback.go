@@ -0,0 +1,101 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pusher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+)
+
+// indexRejectedMarkers are the parts of the errors returned by registries rejecting a manifest because of its
+// media type. The remote package of oras does not export the type of its errors, hence their message is matched.
+var indexRejectedMarkers = []string{
+	"response status code 400",
+	"response status code 415",
+	"MANIFEST_INVALID",
+	"UNSUPPORTED",
+}
+
+// isIndexRejected returns true if err, returned when pushing an index, shows that the registry does not
+// accept indexes. Since the manifests referenced by the index have already been accepted, a rejection
+// of the index itself is due to its media type.
+func isIndexRejected(err error) bool {
+	msg := err.Error()
+	for _, marker := range indexRejectedMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// PlatformTag returns the tag under which the artifact of a platform is pushed, in place of an index,
+// when the fallback per platform is enabled, e.g. "0.1.0-linux-amd64".
+func PlatformTag(tag string, platform *v1.Platform) string {
+	return fmt.Sprintf("%s-%s-%s", tag, platform.OS, platform.Architecture)
+}
+
+// tagPerPlatform tags the manifests referenced by the index, already pushed to the target, with the tags
+// suffixed by their platform.
+func tagPerPlatform(ctx context.Context, fetcher content.Fetcher, target interface {
+	Tag(ctx context.Context, desc v1.Descriptor, reference string) error
+}, indexDesc *v1.Descriptor, tags []string) ([]oci.PlatformResult, error) {
+	reader, err := fetcher.Fetch(ctx, *indexDesc)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var index v1.Index
+	if err = json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal index: %w", err)
+	}
+
+	results := make([]oci.PlatformResult, 0, len(index.Manifests))
+	for i := range index.Manifests {
+		desc := index.Manifests[i]
+		if desc.Platform == nil {
+			return nil, fmt.Errorf("manifest %s has no platform", desc.Digest)
+		}
+
+		result := oci.PlatformResult{
+			Platform: desc.Platform.OS + "/" + desc.Platform.Architecture,
+			Digest:   desc.Digest.String(),
+		}
+		for _, tag := range tags {
+			platformTag := PlatformTag(tag, desc.Platform)
+			if err = target.Tag(ctx, desc, platformTag); err != nil {
+				return nil, fmt.Errorf("unable to tag %s: %w", platformTag, err)
+			}
+			result.Tags = append(result.Tags, platformTag)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
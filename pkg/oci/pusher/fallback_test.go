@@ -0,0 +1,88 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pusher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type fakeTarget struct {
+	index []byte
+	tags  map[string]digest.Digest
+}
+
+func (f *fakeTarget) Fetch(_ context.Context, _ v1.Descriptor) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(f.index)), nil
+}
+
+func (f *fakeTarget) Tag(_ context.Context, desc v1.Descriptor, reference string) error {
+	f.tags[reference] = desc.Digest
+	return nil
+}
+
+func TestIsIndexRejected(t *testing.T) {
+	rejected := errors.New(`PUT "https://registry.example.com/v2/plugin/manifests/latest": response status code 400: ` +
+		`manifest invalid: manifest invalid`)
+	if !isIndexRejected(rejected) {
+		t.Errorf("expected %q to be detected as an index rejection", rejected)
+	}
+
+	unauthorized := errors.New(`PUT "https://registry.example.com/v2/plugin/manifests/latest": response status code 401: unauthorized`)
+	if isIndexRejected(unauthorized) {
+		t.Errorf("expected %q not to be detected as an index rejection", unauthorized)
+	}
+}
+
+func TestTagPerPlatform(t *testing.T) {
+	amd64 := v1.Descriptor{MediaType: v1.MediaTypeImageManifest, Digest: digest.FromString("amd64"),
+		Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}
+	arm64 := v1.Descriptor{MediaType: v1.MediaTypeImageManifest, Digest: digest.FromString("arm64"),
+		Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}
+
+	index, err := json.Marshal(v1.Index{Manifests: []v1.Descriptor{amd64, arm64}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := &fakeTarget{index: index, tags: make(map[string]digest.Digest)}
+
+	results, err := tagPerPlatform(context.Background(), target, target, &v1.Descriptor{}, []string{"0.1.0", "latest"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(results) != 2 || results[1].Platform != "linux/arm64" || len(results[1].Tags) != 2 {
+		t.Errorf("unexpected results %+v", results)
+	}
+
+	expected := map[string]digest.Digest{
+		"0.1.0-linux-amd64":  amd64.Digest,
+		"latest-linux-amd64": amd64.Digest,
+		"0.1.0-linux-arm64":  arm64.Digest,
+		"latest-linux-arm64": arm64.Digest,
+	}
+	for tag, d := range expected {
+		if target.tags[tag] != d {
+			t.Errorf("expected tag %s to point to %s, got %s", tag, d, target.tags[tag])
+		}
+	}
+}
@@ -14,7 +14,14 @@
 
 package pusher
 
-import "fmt"
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+)
 
 type opts struct {
 	Filepaths        []string
@@ -22,6 +29,135 @@ type opts struct {
 	Dependencies     []string
 	Tags             []string
 	AnnotationSource string
+	// Annotations are additional annotations of the manifests.
+	Annotations map[string]string
+	// LayerTitleFromFilename sets the title annotation of the layers to the filename of their source.
+	LayerTitleFromFilename bool
+	MediaTypeSet           oci.MediaTypeSet
+	// AllowEmpty allows directories and glob patterns resolving to no files.
+	AllowEmpty bool
+	// Symlinks controls how symlinks in directories and glob patterns are packed.
+	Symlinks SymlinkMode
+	// CompressionLevel is the gzip level of the archives built from directories and glob patterns.
+	CompressionLevel CompressionLevel
+	// IncludeHidden includes the hidden files, whose name begins with ".", found in directories and glob patterns.
+	IncludeHidden bool
+	// FallbackPerPlatform pushes each platform under its own tags when the registry rejects the index.
+	FallbackPerPlatform bool
+	// Members are the artifacts referenced by a collection.
+	Members []oci.CollectionMember
+	// DigestAlgorithm is the algorithm of the digests of the blobs and manifests. If empty, sha256 is used.
+	DigestAlgorithm DigestAlgorithm
+}
+
+// CompressionLevel is the gzip compression level of the archives built by the pusher, from 0 (no
+// compression) to 9 (best compression).
+type CompressionLevel int
+
+// DefaultCompressionLevel is the compression level used when none is set. It is the level gzip.DefaultCompression
+// stands for, hence the archives are identical to the ones built before the level could be set.
+const DefaultCompressionLevel CompressionLevel = 6
+
+// The following functions are necessary to use CompressionLevel with Cobra.
+
+// String returns a string representation of CompressionLevel.
+func (c *CompressionLevel) String() string {
+	return strconv.Itoa(int(*c))
+}
+
+// Set a CompressionLevel, either a number from 0 to 9 or one of the aliases "fast", "best" and "default".
+func (c *CompressionLevel) Set(v string) error {
+	switch v {
+	case "fast":
+		*c = CompressionLevel(gzip.BestSpeed)
+		return nil
+	case "best":
+		*c = CompressionLevel(gzip.BestCompression)
+		return nil
+	case "default":
+		*c = DefaultCompressionLevel
+		return nil
+	}
+
+	level, err := strconv.Atoi(v)
+	if err != nil || level < gzip.NoCompression || level > gzip.BestCompression {
+		return errors.New(`must be a number from 0 to 9, or one of "fast", "best", "default"`)
+	}
+	*c = CompressionLevel(level)
+	return nil
+}
+
+// Type returns a string representing this type.
+func (c *CompressionLevel) Type() string {
+	return "CompressionLevel"
+}
+
+// SymlinkMode controls how the symlinks found in directories and glob patterns are packed.
+type SymlinkMode string
+
+const (
+	// SymlinksPreserve stores symlinks as symlink entries of the archive. It is the default.
+	SymlinksPreserve SymlinkMode = "preserve"
+	// SymlinksFollow stores the content of the regular files pointed by symlinks.
+	SymlinksFollow SymlinkMode = "follow"
+	// SymlinksError fails when a symlink is found.
+	SymlinksError SymlinkMode = "error"
+)
+
+// The following functions are necessary to use SymlinkMode with Cobra.
+
+// String returns a string representation of SymlinkMode.
+func (m *SymlinkMode) String() string {
+	return string(*m)
+}
+
+// Set a SymlinkMode.
+func (m *SymlinkMode) Set(v string) error {
+	switch v {
+	case "preserve", "follow", "error":
+		*m = SymlinkMode(v)
+		return nil
+	default:
+		return errors.New(`must be one of "preserve", "follow", "error"`)
+	}
+}
+
+// Type returns a string representing this type.
+func (m *SymlinkMode) Type() string {
+	return "SymlinkMode"
+}
+
+// DigestAlgorithm is the algorithm used to compute the digests of the blobs and manifests pushed by the pusher.
+type DigestAlgorithm string
+
+const (
+	// DigestSHA256 is the canonical algorithm, supported by all the registries. It is the default.
+	DigestSHA256 DigestAlgorithm = "sha256"
+	// DigestSHA512 is required by some compliance regimes, but is not supported by all the registries.
+	DigestSHA512 DigestAlgorithm = "sha512"
+)
+
+// The following functions are necessary to use DigestAlgorithm with Cobra.
+
+// String returns a string representation of DigestAlgorithm.
+func (a *DigestAlgorithm) String() string {
+	return string(*a)
+}
+
+// Set a DigestAlgorithm.
+func (a *DigestAlgorithm) Set(v string) error {
+	switch v {
+	case "sha256", "sha512":
+		*a = DigestAlgorithm(v)
+		return nil
+	default:
+		return errors.New(`must be one of "sha256", "sha512"`)
+	}
+}
+
+// Type returns a string representing this type.
+func (a *DigestAlgorithm) Type() string {
+	return "DigestAlgorithm"
 }
 
 // Option is a functional option for pusher.
@@ -93,3 +229,92 @@ func WithAnnotationSource(annotationSource string) Option {
 		return nil
 	}
 }
+
+// WithAnnotations sets additional annotations of the manifests. They take precedence over
+// the ones set by the pusher, such as the creation time.
+func WithAnnotations(annotations map[string]string) Option {
+	return func(o *opts) error {
+		o.Annotations = annotations
+		return nil
+	}
+}
+
+// WithLayerAnnotationsFromFilename sets whether the title annotation of each layer is set to the
+// base filename of its source, which is the default. When disabled, a generic title is used.
+func WithLayerAnnotationsFromFilename(enabled bool) Option {
+	return func(o *opts) error {
+		o.LayerTitleFromFilename = enabled
+		return nil
+	}
+}
+
+// WithMediaTypeSet sets the family of media types used for manifests, configs and layers.
+func WithMediaTypeSet(set oci.MediaTypeSet) Option {
+	return func(o *opts) error {
+		o.MediaTypeSet = set
+		return nil
+	}
+}
+
+// WithAllowEmpty sets whether directories and glob patterns resolving to no files are allowed.
+// When allowed, they are pushed as empty archives.
+func WithAllowEmpty(allowEmpty bool) Option {
+	return func(o *opts) error {
+		o.AllowEmpty = allowEmpty
+		return nil
+	}
+}
+
+// WithSymlinks sets how the symlinks found in directories and glob patterns are packed.
+// By default they are preserved.
+func WithSymlinks(mode SymlinkMode) Option {
+	return func(o *opts) error {
+		o.Symlinks = mode
+		return nil
+	}
+}
+
+// WithIncludeHidden sets whether the hidden files, whose name begins with ".", found in directories and glob
+// patterns are packed. By default they are. When excluded, hidden directories are skipped too, and glob patterns
+// only match hidden files if the pattern of their name begins with ".", as in shells.
+func WithIncludeHidden(includeHidden bool) Option {
+	return func(o *opts) error {
+		o.IncludeHidden = includeHidden
+		return nil
+	}
+}
+
+// WithCompressionLevel sets the gzip compression level of the archives built from directories and
+// glob patterns. Since it changes the bytes of the archives, it changes the digest of the artifact too.
+func WithCompressionLevel(level CompressionLevel) Option {
+	return func(o *opts) error {
+		o.CompressionLevel = level
+		return nil
+	}
+}
+
+// WithFallbackPerPlatform sets whether, when the registry rejects the index of a multi-platform artifact,
+// the artifact of each platform is pushed under the tags suffixed by its platform, e.g. "0.1.0-linux-amd64".
+func WithFallbackPerPlatform(enabled bool) Option {
+	return func(o *opts) error {
+		o.FallbackPerPlatform = enabled
+		return nil
+	}
+}
+
+// WithCollectionMembers sets the artifacts referenced by a collection, each one pinned to its digest.
+func WithCollectionMembers(members ...oci.CollectionMember) Option {
+	return func(o *opts) error {
+		o.Members = members
+		return nil
+	}
+}
+
+// WithDigestAlgorithm sets the algorithm of the digests of the blobs and manifests. Since it changes the digests,
+// an artifact pushed with an algorithm other than sha256 has a different digest than the same one pushed by default.
+func WithDigestAlgorithm(algorithm DigestAlgorithm) Option {
+	return func(o *opts) error {
+		o.DigestAlgorithm = algorithm
+		return nil
+	}
+}
@@ -23,11 +23,15 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/opencontainers/go-digest"
 	"github.com/opencontainers/image-spec/specs-go"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	logger "github.com/sirupsen/logrus"
 	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/content/file"
+	ocistore "oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
 
@@ -39,6 +43,13 @@ const (
 	ConfigLayerName = "config"
 	// ArtifactsIndexName is the name of the index containing all manifests.
 	ArtifactsIndexName = "index"
+	// ManifestName is the name of manifests built without oras, i.e. using docker media types.
+	ManifestName = "manifest"
+	// CollectionLayerContent is the content of the empty layer of collections.
+	CollectionLayerContent = "{}"
+	// BlobReferencePrefix is the prefix of the filepaths referencing a blob already stored in the
+	// remote repository, e.g. "@sha256:123abc...".
+	BlobReferencePrefix = "@"
 )
 
 var (
@@ -50,6 +61,20 @@ var (
 	ErrInvalidNumberRulesfiles = errors.New("invalid number of rulesfiles")
 	// ErrInvalidDependenciesFormat error when the dependencies are invalid.
 	ErrInvalidDependenciesFormat = errors.New("invalid dependency format")
+	// ErrBlobNotFound error when a blob referenced as layer does not exist in the remote repository.
+	ErrBlobNotFound = errors.New("blob not found")
+	// ErrNoFilesMatched error when a directory or a glob pattern used as layer resolves to no files.
+	ErrNoFilesMatched = errors.New("no files matched")
+	// ErrSymlinkNotAllowed error when a directory or a glob pattern used as layer contains a symlink
+	// and symlinks are not allowed.
+	ErrSymlinkNotAllowed = errors.New("symlink not allowed")
+	// ErrUnsafeSymlink error when a preserved symlink points outside of the artifact, which would be
+	// refused when extracting it.
+	ErrUnsafeSymlink = errors.New("symlink target outside of the artifact")
+	// ErrInvalidCollection error when the members of a collection are invalid.
+	ErrInvalidCollection = errors.New("invalid collection")
+	// ErrIndexNotSupported error when the registry rejects the image index of a multi-platform artifact.
+	ErrIndexNotSupported = errors.New("registry does not support OCI image indexes")
 )
 
 // ProgressTracker type of the tracker that the pusher accepts. It implements the tracker logic.
@@ -78,24 +103,12 @@ func NewPusher(client *auth.Client, plainHTTP bool, tracker ProgressTracker) *Pu
 // ref format follows: REGISTRY/REPO[:TAG|@DIGEST]. Ex. localhost:5000/hello:latest.
 func (p *Pusher) Push(ctx context.Context, artifactType oci.ArtifactType,
 	ref string, options ...Option) (*oci.RegistryResult, error) {
-	var dataDesc, configDesc, rootDesc *v1.Descriptor
-	var err error
-
-	o := &opts{}
+	o := &opts{CompressionLevel: DefaultCompressionLevel, IncludeHidden: true, LayerTitleFromFilename: true}
 	if err := Options(options).apply(o); err != nil {
 		return nil, err
 	}
 
-	// First thing check that we do not have multiple rulesfiles.
-	if artifactType == oci.Rulesfile && len(o.Filepaths) != 1 {
-		return nil, fmt.Errorf("expecting 1 rulesfile object received %d: %w", len(o.Filepaths), ErrInvalidNumberRulesfiles)
-	}
-
 	// Create the object to interact with the remote repo.
-	// If handling plugins check that no dependencies have been configured.
-	if artifactType == oci.Plugin && len(o.Dependencies) != 0 {
-		return nil, fmt.Errorf("expecting no dependencies for plugin artifacts but received %s", o.Dependencies)
-	}
 	repo, err := remote.NewRepository(ref)
 	if err != nil {
 		return nil, err
@@ -118,6 +131,12 @@ func (p *Pusher) Push(ctx context.Context, artifactType oci.ArtifactType,
 
 	defaultCopyOptions := oras.DefaultCopyGraphOptions
 	defaultCopyOptions.Concurrency = 1
+	// PostCopy is not called for the blobs skipped because they already exist in the repository.
+	var transferred int64
+	defaultCopyOptions.PostCopy = func(_ context.Context, desc v1.Descriptor) error {
+		transferred += desc.Size
+		return nil
+	}
 
 	// Initialize the file store for this artifact.
 	tmpDir, err := os.MkdirTemp("", "falcoctl")
@@ -126,6 +145,113 @@ func (p *Pusher) Push(ctx context.Context, artifactType oci.ArtifactType,
 	}
 	defer os.RemoveAll(tmpDir)
 
+	store, rootDesc, err := p.build(ctx, artifactType, repo, tmpDir, o, func(src content.ReadOnlyStorage, manifestDesc *v1.Descriptor) error {
+		return oras.CopyGraph(ctx, src, remoteTarget, *manifestDesc, defaultCopyOptions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rootReader, err := store.Fetch(ctx, *rootDesc)
+	if err != nil {
+		return nil, err
+	}
+	defer rootReader.Close()
+	// Tag the root descriptor remotely.
+	err = repo.PushReference(ctx, *rootDesc, rootReader, repo.Reference.Reference)
+	if err != nil && oci.IsIndex(rootDesc.MediaType) && isIndexRejected(err) {
+		if !o.FallbackPerPlatform {
+			return nil, fmt.Errorf("%w: %s. As a workaround, push each platform to a separate tag, "+
+				"e.g. enabling the fallback per platform", ErrIndexNotSupported, err.Error())
+		}
+
+		// The manifests of the platforms have already been pushed, they only need to be tagged.
+		platforms, err := tagPerPlatform(ctx, store, remoteTarget, rootDesc, append([]string{repo.Reference.Reference}, o.Tags...))
+		if err != nil {
+			return nil, fmt.Errorf("%w, unable to push each platform to a separate tag: %s", ErrIndexNotSupported, err.Error())
+		}
+
+		return &oci.RegistryResult{
+			Platforms:   platforms,
+			Transferred: transferred,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(o.Tags) > 0 {
+		if err = oras.TagN(ctx, remoteTarget, repo.Reference.Reference, o.Tags, oras.DefaultTagNOptions); err != nil {
+			return nil, err
+		}
+	}
+
+	return &oci.RegistryResult{
+		Digest:      string(rootDesc.Digest),
+		Transferred: transferred,
+	}, nil
+}
+
+// Digest computes the digest that an artifact would have once pushed with the same options,
+// without contacting any registry. Blobs referenced by digest are not supported, since they
+// must be resolved in the remote repository.
+func Digest(ctx context.Context, artifactType oci.ArtifactType, options ...Option) (*oci.RegistryResult, error) {
+	o := &opts{CompressionLevel: DefaultCompressionLevel, IncludeHidden: true, LayerTitleFromFilename: true}
+	if err := Options(options).apply(o); err != nil {
+		return nil, err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "falcoctl")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	p := &Pusher{}
+	_, rootDesc, err := p.build(ctx, artifactType, nil, tmpDir, o, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oci.RegistryResult{
+		Digest: string(rootDesc.Digest),
+	}, nil
+}
+
+// build assembles the layers, configs and manifests of an artifact, plus the index for plugins, in
+// file stores backed by tmpDir. Each manifest is passed to copyManifest, if not nil, along with the
+// store holding its content. It returns the store holding the root descriptor. When a digest algorithm
+// other than sha256 is set, the content is redigested in a store backed by tmpDir as well.
+func (p *Pusher) build(ctx context.Context, artifactType oci.ArtifactType, repo *remote.Repository, tmpDir string,
+	o *opts, copyManifest func(src content.ReadOnlyStorage, manifestDesc *v1.Descriptor) error) (content.ReadOnlyStorage, *v1.Descriptor, error) {
+	var dataDesc, configDesc, rootDesc *v1.Descriptor
+	var err error
+
+	// The file stores only compute sha256 digests, the content is then redigested with the algorithm of choice.
+	var redigested content.Storage
+	if algorithm := o.DigestAlgorithm.algorithm(); algorithm != digest.Canonical {
+		if !algorithm.Available() {
+			return nil, nil, fmt.Errorf("digest algorithm %q not available", algorithm)
+		}
+		redigested = ocistore.NewStorage(filepath.Join(tmpDir, ".redigested"))
+	}
+
+	if artifactType == oci.Collection {
+		return p.buildCollection(ctx, tmpDir, o, redigested, copyManifest)
+	}
+
+	// First thing check that we do not have multiple rulesfiles.
+	if artifactType == oci.Rulesfile && len(o.Filepaths) != 1 {
+		return nil, nil, fmt.Errorf("expecting 1 rulesfile object received %d: %w", len(o.Filepaths), ErrInvalidNumberRulesfiles)
+	}
+
+	// If handling plugins check that no dependencies have been configured.
+	if artifactType == oci.Plugin && len(o.Dependencies) != 0 {
+		return nil, nil, fmt.Errorf("expecting no dependencies for plugin artifacts but received %s", o.Dependencies)
+	}
+
+	mediaTypes := oci.MediaTypesFor(artifactType, o.MediaTypeSet)
+
 	manifestDescs := make([]*v1.Descriptor, len(o.Filepaths))
 	var fileStore *file.Store
 	for i, artifactPath := range o.Filepaths {
@@ -136,88 +262,218 @@ func (p *Pusher) Push(ctx context.Context, artifactType oci.ArtifactType,
 			platform = o.Platforms[i]
 		}
 
-		// Prepare data layer.
-		absolutePath, err := filepath.Abs(artifactPath)
-		if err != nil {
-			return nil, err
-		}
-		if dataDesc, err = p.storeMainLayer(ctx, fileStore, artifactType, absolutePath); err != nil {
-			return nil, err
+		// Prepare data layer. Blobs referenced by digest are not uploaded again.
+		if strings.HasPrefix(artifactPath, BlobReferencePrefix) {
+			if redigested != nil {
+				return nil, nil, fmt.Errorf("blob reference %q cannot be used with the %s digest algorithm", artifactPath, o.DigestAlgorithm)
+			}
+			if repo == nil {
+				return nil, nil, fmt.Errorf("blob reference %q cannot be resolved without a remote repository", artifactPath)
+			}
+			if dataDesc, err = p.resolveBlobLayer(ctx, repo, mediaTypes.Layer, strings.TrimPrefix(artifactPath, BlobReferencePrefix)); err != nil {
+				return nil, nil, err
+			}
+		} else {
+			absolutePath, err := p.layerPath(artifactPath, tmpDir, o)
+			if err != nil {
+				return nil, nil, err
+			}
+			title := fmt.Sprintf("%s.tar.gz", artifactType)
+			if o.LayerTitleFromFilename {
+				title = filepath.Base(absolutePath)
+			}
+			if dataDesc, err = p.storeMainLayer(ctx, fileStore, mediaTypes.Layer, title, absolutePath); err != nil {
+				return nil, nil, err
+			}
 		}
 
 		// Prepare configuration layer.
-		if configDesc, err = p.storeConfigLayer(ctx, fileStore, artifactType, o.Dependencies); err != nil {
-			return nil, err
+		if configDesc, err = p.storeConfigLayer(ctx, fileStore, mediaTypes.Config, o.Dependencies); err != nil {
+			return nil, nil, err
 		}
 
 		// Now we can create manifest, using the Config descriptor and principal Layer descriptor.
-		if manifestDescs[i], err = p.packManifest(ctx, fileStore, configDesc,
-			dataDesc, platform, o.AnnotationSource); err != nil {
-			return nil, err
+		if manifestDescs[i], err = p.packManifest(ctx, fileStore, artifactType, mediaTypes, configDesc,
+			dataDesc, platform, o.AnnotationSource, o.Annotations); err != nil {
+			return nil, nil, err
 		}
 
-		if err = oras.CopyGraph(ctx, fileStore, remoteTarget, *manifestDescs[i], defaultCopyOptions); err != nil {
-			return nil, err
+		var src content.ReadOnlyStorage = fileStore
+		if redigested != nil {
+			if manifestDescs[i], err = redigest(ctx, fileStore, redigested, manifestDescs[i], o.DigestAlgorithm.algorithm()); err != nil {
+				return nil, nil, err
+			}
+			src = redigested
+		}
+
+		if copyManifest != nil {
+			if err = copyManifest(src, manifestDescs[i]); err != nil {
+				return nil, nil, err
+			}
 		}
 	}
 
 	if artifactType == oci.Rulesfile {
 		// We should have only one manifestDesc.
 		rootDesc = manifestDescs[0]
-	} else {
-		// Here we are in the case when we are dealing with a plugin.
-		// Assuming this filestore to be memory only (size of the index should be less than 4MiB)
-		fileStore = file.New("")
-		if rootDesc, err = p.storeArtifactsIndex(ctx, fileStore, manifestDescs, o.AnnotationSource); err != nil {
-			return nil, err
+		if redigested != nil {
+			return redigested, rootDesc, nil
 		}
+		return fileStore, rootDesc, nil
+	}
+
+	// Here we are in the case when we are dealing with a plugin.
+	// Assuming this filestore to be memory only (size of the index should be less than 4MiB)
+	fileStore = file.New("")
+	if rootDesc, err = p.storeArtifactsIndex(ctx, fileStore, mediaTypes.Index, manifestDescs, o.AnnotationSource); err != nil {
+		return nil, nil, err
+	}
+
+	if redigested != nil {
+		if rootDesc, err = redigest(ctx, fileStore, redigested, rootDesc, o.DigestAlgorithm.algorithm()); err != nil {
+			return nil, nil, err
+		}
+		return redigested, rootDesc, nil
+	}
+
+	return fileStore, rootDesc, nil
+}
+
+// buildCollection assembles the manifest of a collection in a file store backed by tmpDir. Its config lists the
+// members, referenced by digest, and its only layer is the empty JSON object, since it has no content of its own.
+// A manifest is used rather than an index because registries only accept indexes whose manifests are stored in
+// the same repository, while the members of a collection usually live in different ones.
+func (p *Pusher) buildCollection(ctx context.Context, tmpDir string, o *opts, redigested content.Storage,
+	copyManifest func(src content.ReadOnlyStorage, manifestDesc *v1.Descriptor) error) (content.ReadOnlyStorage, *v1.Descriptor, error) {
+	config := oci.CollectionConfig{Members: o.Members}
+	if err := config.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", err.Error(), ErrInvalidCollection)
+	}
+
+	mediaTypes := oci.MediaTypes{
+		Manifest: v1.MediaTypeImageManifest,
+		Config:   oci.FalcoCollectionConfigMediaType,
+		Layer:    oci.EmptyJSONMediaType,
 	}
 
-	rootReader, err := fileStore.Fetch(ctx, *rootDesc)
+	fileStore := file.New(tmpDir)
+	configDesc, err := p.toFileStore(ctx, fileStore, mediaTypes.Config, ConfigLayerName, config)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer rootReader.Close()
-	// Tag the root descriptor remotely.
-	err = repo.PushReference(ctx, *rootDesc, rootReader, repo.Reference.Reference)
+
+	// The empty layer has no name, so that it is not saved on disk when pulled.
+	layerDesc := v1.Descriptor{
+		MediaType: mediaTypes.Layer,
+		Digest:    digest.FromString(CollectionLayerContent),
+		Size:      int64(len(CollectionLayerContent)),
+	}
+	if err = fileStore.Push(ctx, layerDesc, strings.NewReader(CollectionLayerContent)); err != nil {
+		return nil, nil, fmt.Errorf("unable to store the layer of the collection: %w", err)
+	}
+
+	manifestDesc, err := p.packManifest(ctx, fileStore, oci.Collection, mediaTypes, configDesc, &layerDesc, "", o.AnnotationSource, o.Annotations)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if len(o.Tags) > 0 {
-		if err = oras.TagN(ctx, remoteTarget, repo.Reference.Reference, o.Tags, oras.DefaultTagNOptions); err != nil {
-			return nil, err
+	var src content.ReadOnlyStorage = fileStore
+	if redigested != nil {
+		if manifestDesc, err = redigest(ctx, fileStore, redigested, manifestDesc, o.DigestAlgorithm.algorithm()); err != nil {
+			return nil, nil, err
 		}
+		src = redigested
 	}
 
-	return &oci.RegistryResult{
-		Digest: string(rootDesc.Digest),
-	}, nil
+	if copyManifest != nil {
+		if err = copyManifest(src, manifestDesc); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return src, manifestDesc, nil
 }
 
-func (p *Pusher) storeMainLayer(ctx context.Context, fileStore *file.Store,
-	artifactType oci.ArtifactType, artifactPath string) (*v1.Descriptor, error) {
-	var layerMediaType string
+// layerPath returns the absolute path of the file to be used as principal layer. Directories and
+// glob patterns are packed in a *.tar.gz archive, named after the directory, created in tmpDir.
+// Unless o.AllowEmpty is set, an error is returned if they resolve to no files. Symlinks, compression level and
+// hidden files are handled according to o.Symlinks, o.CompressionLevel and o.IncludeHidden.
+func (p *Pusher) layerPath(artifactPath, tmpDir string, o *opts) (string, error) {
+	absolutePath, err := filepath.Abs(artifactPath)
+	if err != nil {
+		return "", err
+	}
 
-	switch artifactType {
-	case oci.Rulesfile:
-		layerMediaType = oci.FalcoRulesfileLayerMediaType
-	case oci.Plugin:
-		layerMediaType = oci.FalcoPluginLayerMediaType
+	var files []string
+	var dir string
+	info, err := os.Stat(absolutePath)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && strings.ContainsAny(artifactPath, "*?["):
+		if files, err = globFiles(absolutePath, o.IncludeHidden); err != nil {
+			return "", err
+		}
+		dir = filepath.Dir(absolutePath)
+	case err != nil:
+		return "", fmt.Errorf("unable to store artifact %s: %w", artifactPath, err)
+	case !info.IsDir():
+		return absolutePath, nil
+	default:
+		if files, err = dirFiles(absolutePath, o.IncludeHidden); err != nil {
+			return "", fmt.Errorf("unable to pack directory %s: %w", artifactPath, err)
+		}
+		dir = absolutePath
 	}
 
+	if len(files) == 0 && !o.AllowEmpty {
+		return "", fmt.Errorf("input %q: %w", artifactPath, ErrNoFilesMatched)
+	}
+
+	archivePath := filepath.Join(tmpDir, filepath.Base(dir)+".tar.gz")
+	if err = createTarGz(files, archivePath, o.Symlinks, o.CompressionLevel); err != nil {
+		return "", fmt.Errorf("unable to pack %s: %w", artifactPath, err)
+	}
+
+	return archivePath, nil
+}
+
+func (p *Pusher) storeMainLayer(ctx context.Context, fileStore *file.Store,
+	layerMediaType, title, artifactPath string) (*v1.Descriptor, error) {
 	// Add the content of the principal layer to the file store.
-	desc, err := fileStore.Add(ctx, filepath.Base(artifactPath), layerMediaType, filepath.Clean(artifactPath))
+	desc, err := fileStore.Add(ctx, title, layerMediaType, filepath.Clean(artifactPath))
 	if err != nil {
-		return nil, fmt.Errorf("unable to store artifact %s of type %s: %w", artifactPath, artifactType, err)
+		return nil, fmt.Errorf("unable to store artifact %s of media type %s: %w", artifactPath, layerMediaType, err)
 	}
 
 	return &desc, nil
 }
 
+// resolveBlobLayer returns the descriptor of the principal layer for a blob already stored in the remote repository.
+func (p *Pusher) resolveBlobLayer(ctx context.Context, repo *remote.Repository,
+	layerMediaType, blobDigest string) (*v1.Descriptor, error) {
+	d, err := digest.Parse(blobDigest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blob reference %q: %w", blobDigest, err)
+	}
+
+	desc, err := repo.Blobs().Resolve(ctx, d.String())
+	if errors.Is(err, errdef.ErrNotFound) {
+		return nil, fmt.Errorf("%s in repository %s: %w", d, repo.Reference.Repository, ErrBlobNotFound)
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to resolve blob %s: %w", d, err)
+	}
+
+	return &v1.Descriptor{
+		MediaType: layerMediaType,
+		Digest:    desc.Digest,
+		Size:      desc.Size,
+		Annotations: map[string]string{
+			v1.AnnotationTitle: d.Encoded() + ".tar.gz",
+		},
+	}, nil
+}
+
 func (p *Pusher) storeConfigLayer(ctx context.Context, fileStore *file.Store,
-	artifactType oci.ArtifactType, dependencies []string) (*v1.Descriptor, error) {
-	var layerMediaType string
+	configMediaType string, dependencies []string) (*v1.Descriptor, error) {
 	// Create config and fill common fields of the config (empty for now).
 	artifactConfig := oci.ArtifactConfig{}
 
@@ -226,22 +482,15 @@ func (p *Pusher) storeConfigLayer(ctx context.Context, fileStore *file.Store,
 		return nil, fmt.Errorf("%s: %w", err.Error(), ErrInvalidDependenciesFormat)
 	}
 
-	switch artifactType {
-	case oci.Rulesfile:
-		layerMediaType = oci.FalcoRulesfileConfigMediaType
-	case oci.Plugin:
-		layerMediaType = oci.FalcoPluginConfigMediaType
-	}
-
-	return p.toFileStore(ctx, fileStore, layerMediaType, ConfigLayerName, artifactConfig)
+	return p.toFileStore(ctx, fileStore, configMediaType, ConfigLayerName, artifactConfig)
 }
 
 func (p *Pusher) storeArtifactsIndex(ctx context.Context, fileStore *file.Store,
-	manifestDescs []*v1.Descriptor, annotationSource string) (*v1.Descriptor, error) {
+	indexMediaType string, manifestDescs []*v1.Descriptor, annotationSource string) (*v1.Descriptor, error) {
 	// fat manifest
 	index := &v1.Index{
 		Versioned: specs.Versioned{SchemaVersion: 2},
-		MediaType: v1.MediaTypeImageIndex,
+		MediaType: indexMediaType,
 	}
 
 	if annotationSource != "" {
@@ -285,33 +534,53 @@ func (p *Pusher) toFileStore(ctx context.Context, fileStore *file.Store, mediaTy
 	return &desc, nil
 }
 
-func (p *Pusher) packManifest(ctx context.Context, fileStore *file.Store,
-	configDesc, dataDesc *v1.Descriptor, platform, annotationSource string) (*v1.Descriptor, error) {
+func (p *Pusher) packManifest(ctx context.Context, fileStore *file.Store, artifactType oci.ArtifactType, mediaTypes oci.MediaTypes,
+	configDesc, dataDesc *v1.Descriptor, platform, annotationSource string, extraAnnotations map[string]string) (*v1.Descriptor, error) {
 	// Now we can create manifest, using the Config descriptor and principal Layer descriptor.
 	// In case annotation source is passed, we put it in the ManifestAnnotations.
-	var packOptions oras.PackOptions
+	annotations := make(map[string]string)
 	if annotationSource != "" {
-		annotations := make(map[string]string)
 		annotations[v1.AnnotationSource] = annotationSource
-		packOptions = oras.PackOptions{ConfigDescriptor: configDesc, ManifestAnnotations: annotations}
-	} else {
-		packOptions = oras.PackOptions{ConfigDescriptor: configDesc}
 	}
-
-	desc, err := oras.Pack(ctx, fileStore, []v1.Descriptor{*dataDesc}, packOptions)
-	if err != nil {
-		return nil, fmt.Errorf("unable to generate manifest for config layer %s and data layer %s: %w", configDesc.MediaType, dataDesc.MediaType, err)
+	for key, value := range extraAnnotations {
+		annotations[key] = value
 	}
 
-	if dataDesc.MediaType == oci.FalcoPluginLayerMediaType {
-		tokens := strings.Split(platform, "/")
-		if len(tokens) != 2 {
-			return nil, fmt.Errorf("platform %q: %w", platform, ErrInvalidPlatformFormat)
+	var desc v1.Descriptor
+	if mediaTypes.Manifest == v1.MediaTypeImageManifest {
+		packOptions := oras.PackOptions{ConfigDescriptor: configDesc}
+		if len(annotations) > 0 {
+			packOptions.ManifestAnnotations = annotations
+		}
+
+		var err error
+		if desc, err = oras.Pack(ctx, fileStore, []v1.Descriptor{*dataDesc}, packOptions); err != nil {
+			return nil, fmt.Errorf("unable to generate manifest for config layer %s and data layer %s: %w", configDesc.MediaType, dataDesc.MediaType, err)
 		}
-		desc.Platform = &v1.Platform{
-			OS:           tokens[0],
-			Architecture: tokens[1],
+	} else {
+		// Layers with docker media types do not carry the artifact type, store it in the manifest.
+		annotations[oci.ArtifactTypeAnnotation] = artifactType.String()
+		manifest := v1.Manifest{
+			Versioned:   specs.Versioned{SchemaVersion: 2},
+			MediaType:   mediaTypes.Manifest,
+			Config:      *configDesc,
+			Layers:      []v1.Descriptor{*dataDesc},
+			Annotations: annotations,
+		}
+
+		manifestDesc, err := p.toFileStore(ctx, fileStore, mediaTypes.Manifest, ManifestName, manifest)
+		if err != nil {
+			return nil, err
+		}
+		desc = *manifestDesc
+	}
+
+	if artifactType == oci.Plugin {
+		parsed, err := oci.ParsePlatform(platform)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPlatformFormat, err.Error())
 		}
+		desc.Platform = parsed
 	}
 
 	return &desc, nil
@@ -42,6 +42,7 @@ var (
 	testPluginPlatform1 = "linux/amd64"
 	testPluginPlatform2 = "windows/amd64"
 	testPluginPlatform3 = "linux/aarch64"
+	testDigest          = "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
 	ctx                 = context.Background()
 )
 
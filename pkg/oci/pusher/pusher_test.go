@@ -15,11 +15,16 @@
 package pusher_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/registry"
@@ -181,7 +186,8 @@ var _ = Describe("Pusher", func() {
 						Expect(index.Manifests).To(HaveLen(3))
 						Expect(fmt.Sprintf("%s/%s", index.Manifests[0].Platform.OS, index.Manifests[0].Platform.Architecture)).To(Equal(testPluginPlatform1))
 						Expect(fmt.Sprintf("%s/%s", index.Manifests[1].Platform.OS, index.Manifests[1].Platform.Architecture)).To(Equal(testPluginPlatform2))
-						Expect(fmt.Sprintf("%s/%s", index.Manifests[2].Platform.OS, index.Manifests[2].Platform.Architecture)).To(Equal(testPluginPlatform3))
+						// Architecture aliases are normalized to their GOARCH names.
+						Expect(fmt.Sprintf("%s/%s", index.Manifests[2].Platform.OS, index.Manifests[2].Platform.Architecture)).To(Equal("linux/arm64"))
 					})
 				})
 
@@ -320,6 +326,187 @@ var _ = Describe("Pusher", func() {
 		})
 	})
 
+	Context("handling layers referencing blobs stored in the repository", func() {
+		BeforeEach(func() {
+			artifactType = oci.Rulesfile
+			repoAndTag = "/rulesfile-blob:latest"
+			repo, err = localRegistry.Repository(ctx, "rulesfile-blob")
+			Expect(err).To(BeNil())
+		})
+
+		When("the blob exists", func() {
+			var blobDesc v1.Descriptor
+
+			BeforeEach(func() {
+				data, err := os.ReadFile(testRuleTarball)
+				Expect(err).ToNot(HaveOccurred())
+				blobDesc = v1.Descriptor{
+					MediaType: oci.FalcoRulesfileLayerMediaType,
+					Digest:    digest.FromBytes(data),
+					Size:      int64(len(data)),
+				}
+				Expect(repo.Push(ctx, blobDesc, bytes.NewReader(data))).To(Succeed())
+				options = []ocipusher.Option{ocipusher.WithFilepaths([]string{ocipusher.BlobReferencePrefix + blobDesc.Digest.String()})}
+			})
+
+			It("should reuse it as layer", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).ToNot(BeNil())
+				d, reader, err := repo.FetchReference(ctx, ref)
+				Expect(err).ToNot(HaveOccurred())
+				manifest, err := manifestFromReader(reader)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(manifest.Layers).To(HaveLen(1))
+				Expect(manifest.Layers[0].Digest).To(Equal(blobDesc.Digest))
+				Expect(manifest.Layers[0].Size).To(Equal(blobDesc.Size))
+				// Only the config and the manifest are uploaded.
+				Expect(result.Transferred).To(Equal(manifest.Config.Size + d.Size))
+			})
+		})
+
+		When("the blob is missing", func() {
+			BeforeEach(func() {
+				missing := digest.FromString("missing blob")
+				options = []ocipusher.Option{ocipusher.WithFilepaths([]string{ocipusher.BlobReferencePrefix + missing.String()})}
+			})
+
+			It("should error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(errors.Is(err, ocipusher.ErrBlobNotFound)).To(BeTrue())
+				Expect(result).To(BeNil())
+			})
+		})
+	})
+
+	Context("handling collection artifacts", func() {
+		BeforeEach(func() {
+			artifactType = oci.Collection
+		})
+
+		When("members are pinned to their digests", func() {
+			BeforeEach(func() {
+				members := []oci.CollectionMember{
+					{Ref: localRegistryHost + "/rulesfile-test@" + testDigest, Version: "1.2.3", Type: oci.Rulesfile},
+					{Ref: localRegistryHost + "/plugin-test@" + testDigest, Version: "latest", Type: oci.Plugin},
+				}
+				options = []ocipusher.Option{ocipusher.WithCollectionMembers(members...)}
+				repoAndTag = "/collection-test:latest"
+				repo, err = localRegistry.Repository(ctx, "collection-test")
+				Expect(err).To(BeNil())
+			})
+
+			It("should succeed", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).ToNot(BeNil())
+				d, reader, err := repo.FetchReference(ctx, ref)
+				Expect(err).ToNot(HaveOccurred())
+				manifest, err := manifestFromReader(reader)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(d.Digest.String()).To(Equal(result.Digest))
+				Expect(manifest.Config.MediaType).To(Equal(oci.FalcoCollectionConfigMediaType))
+				// The only layer is empty, the content is held by the members.
+				Expect(manifest.Layers).To(HaveLen(1))
+				Expect(manifest.Layers[0].MediaType).To(Equal(oci.EmptyJSONMediaType))
+				reader, err = repo.Fetch(ctx, manifest.Config)
+				Expect(err).ToNot(HaveOccurred())
+				var config oci.CollectionConfig
+				Expect(json.NewDecoder(reader).Decode(&config)).To(Succeed())
+				Expect(config.Members).To(HaveLen(2))
+				Expect(config.Members[0].Version).To(Equal("1.2.3"))
+			})
+		})
+
+		When("a member is not pinned to its digest", func() {
+			BeforeEach(func() {
+				member := oci.CollectionMember{Ref: localRegistryHost + "/rulesfile-test:1.2.3", Type: oci.Rulesfile}
+				options = []ocipusher.Option{ocipusher.WithCollectionMembers(member)}
+			})
+
+			It("should error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(errors.Is(err, ocipusher.ErrInvalidCollection)).To(BeTrue())
+				Expect(result).To(BeNil())
+			})
+		})
+
+		When("no member is given", func() {
+			BeforeEach(func() {
+				options = []ocipusher.Option{ocipusher.WithCollectionMembers()}
+			})
+
+			It("should error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(errors.Is(err, ocipusher.ErrInvalidCollection)).To(BeTrue())
+				Expect(result).To(BeNil())
+			})
+		})
+	})
+
+	Context("setting the title of the layers", func() {
+		BeforeEach(func() {
+			artifactType = oci.Rulesfile
+			filePaths = ocipusher.WithFilepaths([]string{testRuleTarball})
+			repoAndTag = "/rulesfile-title:latest"
+			repo, err = localRegistry.Repository(ctx, "rulesfile-title")
+			Expect(err).To(BeNil())
+		})
+
+		layerTitle := func() string {
+			_, reader, err := repo.FetchReference(ctx, ref)
+			Expect(err).ToNot(HaveOccurred())
+			manifest, err := manifestFromReader(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(manifest.Layers).To(HaveLen(1))
+			return manifest.Layers[0].Annotations[v1.AnnotationTitle]
+		}
+
+		When("the filename is used", func() {
+			BeforeEach(func() {
+				options = []ocipusher.Option{filePaths}
+			})
+
+			It("should use the filename of the source", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(layerTitle()).To(Equal(filepath.Base(testRuleTarball)))
+			})
+		})
+
+		When("the filename is not used", func() {
+			BeforeEach(func() {
+				options = []ocipusher.Option{filePaths, ocipusher.WithLayerAnnotationsFromFilename(false)}
+			})
+
+			It("should use a generic title", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(layerTitle()).To(Equal("rulesfile.tar.gz"))
+			})
+		})
+	})
+
+	Context("computing the digest locally", func() {
+		BeforeEach(func() {
+			artifactType = oci.Rulesfile
+			filePaths = ocipusher.WithFilepaths([]string{testRuleTarball})
+			options = []ocipusher.Option{filePaths}
+			repoAndTag = "/rulesfile-digest:latest"
+		})
+
+		It("should match the digest of the pushed artifact", func() {
+			Expect(err).ToNot(HaveOccurred())
+			local, err := ocipusher.Digest(ctx, artifactType, options...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(local.Digest).To(Equal(result.Digest))
+		})
+
+		It("should use the digest algorithm of choice", func() {
+			Expect(err).ToNot(HaveOccurred())
+			local, err := ocipusher.Digest(ctx, artifactType, append(options, ocipusher.WithDigestAlgorithm(ocipusher.DigestSHA512))...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(local.Digest).To(HavePrefix("sha512:"))
+			Expect(local.Digest).ToNot(Equal(result.Digest))
+		})
+	})
+
 	Context("generic error handling", func() {
 		When("file does not exist", func() {
 			BeforeEach(func() {
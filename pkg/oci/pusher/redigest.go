@@ -0,0 +1,136 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pusher
+
+import (
+	"bytes"
+	"context"
+	// Link the sha512 implementation, go-digest only knows the name of the algorithm.
+	_ "crypto/sha512"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+)
+
+// algorithm returns the go-digest algorithm matching a, sha256 if a is empty.
+func (a DigestAlgorithm) algorithm() digest.Algorithm {
+	if a == "" {
+		return digest.Canonical
+	}
+	return digest.Algorithm(a)
+}
+
+// redigest stores in dst the node described by desc, fetched from src, with its digest computed using algorithm.
+// For manifests, the config and the layers are stored too and the manifest is rewritten to reference them by their
+// new digests. The manifests referenced by indexes must have been redigested already. It returns the new descriptor.
+func redigest(ctx context.Context, src content.Fetcher, dst content.Storage, desc *v1.Descriptor,
+	algorithm digest.Algorithm) (*v1.Descriptor, error) {
+	if !oci.IsManifest(desc.MediaType) {
+		if !oci.IsIndex(desc.MediaType) {
+			return redigestBlob(ctx, src, dst, desc, algorithm)
+		}
+
+		// The index references the manifests by their new digests, it only needs to be stored.
+		data, err := content.FetchAll(ctx, src, *desc)
+		if err != nil {
+			return nil, err
+		}
+		return pushBytes(ctx, dst, desc, data, algorithm)
+	}
+
+	data, err := content.FetchAll(ctx, src, *desc)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest v1.Manifest
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal manifest: %w", err)
+	}
+
+	configDesc, err := redigestBlob(ctx, src, dst, &manifest.Config, algorithm)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Config = *configDesc
+
+	for i := range manifest.Layers {
+		layerDesc, err := redigestBlob(ctx, src, dst, &manifest.Layers[i], algorithm)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Layers[i] = *layerDesc
+	}
+
+	if data, err = json.Marshal(manifest); err != nil {
+		return nil, fmt.Errorf("unable to marshal manifest: %w", err)
+	}
+
+	return pushBytes(ctx, dst, desc, data, algorithm)
+}
+
+// redigestBlob stores in dst the blob described by desc, fetched from src, with its digest computed using algorithm.
+// The blob is read twice, to compute the digest and to store it, so that it is never held in memory.
+func redigestBlob(ctx context.Context, src content.Fetcher, dst content.Storage, desc *v1.Descriptor,
+	algorithm digest.Algorithm) (*v1.Descriptor, error) {
+	reader, err := src.Fetch(ctx, *desc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch blob %s: %w", desc.Digest, err)
+	}
+	d, err := algorithm.FromReader(reader)
+	_ = reader.Close()
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute the %s digest of blob %s: %w", algorithm, desc.Digest, err)
+	}
+
+	newDesc := *desc
+	newDesc.Digest = d
+	if exists, err := dst.Exists(ctx, newDesc); err != nil || exists {
+		return &newDesc, err
+	}
+
+	if reader, err = src.Fetch(ctx, *desc); err != nil {
+		return nil, fmt.Errorf("unable to fetch blob %s: %w", desc.Digest, err)
+	}
+	defer reader.Close()
+
+	if err = dst.Push(ctx, newDesc, reader); err != nil {
+		return nil, fmt.Errorf("unable to store blob %s: %w", newDesc.Digest, err)
+	}
+
+	return &newDesc, nil
+}
+
+// pushBytes stores data in dst, described by desc with its digest and size computed using algorithm.
+func pushBytes(ctx context.Context, dst content.Storage, desc *v1.Descriptor, data []byte,
+	algorithm digest.Algorithm) (*v1.Descriptor, error) {
+	newDesc := *desc
+	newDesc.Digest = algorithm.FromBytes(data)
+	newDesc.Size = int64(len(data))
+	if exists, err := dst.Exists(ctx, newDesc); err != nil || exists {
+		return &newDesc, err
+	}
+
+	if err := dst.Push(ctx, newDesc, bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("unable to store %s %s: %w", newDesc.MediaType, newDesc.Digest, err)
+	}
+
+	return &newDesc, nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// ErrNoReadme error when an artifact has no layer with the Markdown media type.
+var ErrNoReadme = errors.New("no README layer found")
+
+// ReadmeLayer returns the first layer of the manifest with the Markdown media type, parameters
+// such as the charset are ignored. It returns false if there is none.
+func ReadmeLayer(manifest *v1.Manifest) (v1.Descriptor, bool) {
+	for _, l := range manifest.Layers {
+		if mediaType, _, err := mime.ParseMediaType(l.MediaType); err == nil && mediaType == MarkdownMediaType {
+			return l, true
+		}
+	}
+
+	return v1.Descriptor{}, false
+}
+
+// FetchReadme fetches the raw content of the README layer of an artifact given its manifest.
+// It returns ErrNoReadme if the artifact has no README layer.
+func FetchReadme(ctx context.Context, ref string, client *auth.Client, manifest *v1.Manifest) ([]byte, error) {
+	layer, ok := ReadmeLayer(manifest)
+	if !ok {
+		return nil, ErrNoReadme
+	}
+
+	if layer.Size > DefaultMaxMetadataSize {
+		return nil, fmt.Errorf("README layer %s of %d bytes, limit is %d bytes", layer.Digest, layer.Size, DefaultMaxMetadataSize)
+	}
+
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new repository with ref %s: %w", ref, err)
+	}
+	repo.Client = client
+
+	readmeReader, err := repo.Fetch(ctx, layer)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch README layer %s: %w", layer.Digest, err)
+	}
+	defer readmeReader.Close()
+
+	return io.ReadAll(io.LimitReader(readmeReader, DefaultMaxMetadataSize))
+}
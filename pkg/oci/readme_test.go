@@ -0,0 +1,40 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestReadmeLayer(t *testing.T) {
+	manifest := &v1.Manifest{
+		Layers: []v1.Descriptor{
+			{MediaType: FalcoPluginLayerMediaType, Digest: "sha256:1"},
+			{MediaType: "text/markdown; charset=utf-8", Digest: "sha256:2"},
+			{MediaType: MarkdownMediaType, Digest: "sha256:3"},
+		},
+	}
+
+	layer, ok := ReadmeLayer(manifest)
+	if !ok || layer.Digest != "sha256:2" {
+		t.Errorf("expected the first Markdown layer, got %v, %v", layer.Digest, ok)
+	}
+
+	if _, ok = ReadmeLayer(&v1.Manifest{Layers: manifest.Layers[:1]}); ok {
+		t.Error("expected no README layer")
+	}
+}
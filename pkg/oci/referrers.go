@@ -0,0 +1,304 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// ErrIncompleteReferrers error when the referrers could be retrieved only from some of the sources.
+// The referrers returned along with it may be missing some entries.
+var ErrIncompleteReferrers = errors.New("incomplete referrers")
+
+// maxReferrersPages limits the pages followed when listing referrers, to not loop forever on broken registries.
+const maxReferrersPages = 1000
+
+var linkNextRgx = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+
+// Referrers returns the descriptors of the manifests referring to the manifest with the given digest
+// in the repository pointed by ref. Both the pages returned by the Referrers API and the index stored
+// in the fallback tag ("<alg>-<hex>") are retrieved and merged, de-duplicating them by digest. Only
+// one of the sources is used for the registry flavors known to support only that one.
+// If one of the sources fails, the referrers found in the others are returned along with an error
+// wrapping ErrIncompleteReferrers.
+func Referrers(ctx context.Context, client remote.Client, ref string, subject digest.Digest, plainHTTP bool) ([]v1.Descriptor, error) {
+	parsedRef, err := registry.ParseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := "https"
+	if plainHTTP {
+		scheme = "http"
+	}
+	base := fmt.Sprintf("%s://%s/v2/%s", scheme, parsedRef.Registry, parsedRef.Repository)
+
+	useAPI, useFallbackTag := Flavor(ctx, client, parsedRef.Registry, plainHTTP).referrersSources()
+
+	var failures []string
+	var apiReferrers, tagReferrers []v1.Descriptor
+
+	if useAPI {
+		apiReferrers, err = referrersFromAPI(ctx, client, fmt.Sprintf("%s/referrers/%s", base, subject))
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("referrers API: %s", err))
+		}
+	}
+
+	if useFallbackTag {
+		fallbackTag := strings.Replace(subject.String(), ":", "-", 1)
+		tagReferrers, err = fetchIndex(ctx, client, fmt.Sprintf("%s/manifests/%s", base, fallbackTag))
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("fallback tag %q: %s", fallbackTag, err))
+		}
+	}
+
+	referrers := dedupDescriptors(append(apiReferrers, tagReferrers...))
+
+	if len(failures) > 0 {
+		return referrers, fmt.Errorf("%s: %w", strings.Join(failures, "; "), ErrIncompleteReferrers)
+	}
+
+	return referrers, nil
+}
+
+// referrersFromAPI follows all the pages returned by the Referrers API, starting from endpoint.
+// A registry not supporting the Referrers API is not considered an error.
+func referrersFromAPI(ctx context.Context, client remote.Client, endpoint string) ([]v1.Descriptor, error) {
+	var referrers []v1.Descriptor
+	for page := 0; endpoint != ""; page++ {
+		if page == maxReferrersPages {
+			return referrers, fmt.Errorf("more than %d pages", maxReferrersPages)
+		}
+
+		index, next, err := fetchReferrersPage(ctx, client, endpoint)
+		if err != nil {
+			return referrers, err
+		}
+		if index == nil {
+			break
+		}
+		referrers = append(referrers, index.Manifests...)
+		endpoint = next
+	}
+
+	return referrers, nil
+}
+
+// fetchReferrersPage returns a page of the Referrers API and the URL of the next one, if any.
+// A nil index is returned if the registry does not support the Referrers API.
+func fetchReferrersPage(ctx context.Context, client remote.Client, endpoint string) (index *v1.Index, next string, err error) {
+	resp, err := getIndex(ctx, client, endpoint)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", nil
+	}
+
+	if index, err = decodeIndex(resp); err != nil {
+		return nil, "", err
+	}
+
+	if match := linkNextRgx.FindStringSubmatch(resp.Header.Get("Link")); match != nil {
+		nextURL, err := resp.Request.URL.Parse(match[1])
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid next page link %q: %w", match[1], err)
+		}
+		next = nextURL.String()
+	}
+
+	return index, next, nil
+}
+
+// fetchIndex returns the manifests of the index at endpoint, none if it does not exist.
+func fetchIndex(ctx context.Context, client remote.Client, endpoint string) ([]v1.Descriptor, error) {
+	resp, err := getIndex(ctx, client, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	index, err := decodeIndex(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return index.Manifests, nil
+}
+
+func getIndex(ctx context.Context, client remote.Client, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", v1.MediaTypeImageIndex)
+
+	return client.Do(req)
+}
+
+func decodeIndex(resp *http.Response) (*v1.Index, error) {
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %q from %q", resp.Status, resp.Request.URL)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, DefaultMaxMetadataSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var index v1.Index
+	if err = json.Unmarshal(body, &index); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal index from %q: %w", resp.Request.URL, err)
+	}
+
+	return &index, nil
+}
+
+func dedupDescriptors(descs []v1.Descriptor) []v1.Descriptor {
+	seen := make(map[digest.Digest]struct{}, len(descs))
+	result := make([]v1.Descriptor, 0, len(descs))
+	for i := range descs {
+		if _, ok := seen[descs[i].Digest]; ok {
+			continue
+		}
+		seen[descs[i].Digest] = struct{}{}
+		result = append(result, descs[i])
+	}
+
+	return result
+}
+
+// emptyJSON is the content of the blobs with media type EmptyJSONMediaType.
+var emptyJSON = []byte("{}")
+
+// referrerManifest is an image manifest referring to a subject. The artifactType and subject fields are
+// not known by the image-spec version in use.
+type referrerManifest struct {
+	v1.Manifest
+	ArtifactType string         `json:"artifactType,omitempty"`
+	Subject      *v1.Descriptor `json:"subject,omitempty"`
+}
+
+// newReferrerManifest returns the manifest, with an empty config, holding the given layer and referring to subject.
+// The artifact type of the manifest is the media type of the layer.
+func newReferrerManifest(subject, layer v1.Descriptor) ([]byte, error) {
+	manifest := referrerManifest{
+		Manifest: v1.Manifest{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: v1.MediaTypeImageManifest,
+			Config: v1.Descriptor{
+				MediaType: EmptyJSONMediaType,
+				Digest:    digest.FromBytes(emptyJSON),
+				Size:      int64(len(emptyJSON)),
+			},
+			Layers: []v1.Descriptor{layer},
+		},
+		ArtifactType: layer.MediaType,
+		Subject:      &v1.Descriptor{MediaType: subject.MediaType, Digest: subject.Digest, Size: subject.Size},
+	}
+
+	return json.Marshal(manifest)
+}
+
+// PushReferrer pushes to the repository pointed by ref a manifest holding a layer with the given descriptor and
+// content and referring to subject, so that it is listed among the referrers of subject. Unless the registry
+// flavor is known to support the Referrers API, the manifest is also added to the index stored in the fallback
+// tag ("<alg>-<hex>"). It returns the descriptor of the pushed manifest.
+func PushReferrer(ctx context.Context, ref string, client *auth.Client, subject, layer v1.Descriptor, content []byte) (*v1.Descriptor, error) {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new repository with ref %s: %w", ref, err)
+	}
+	repo.Client = client
+
+	manifestBytes, err := newReferrerManifest(subject, layer)
+	if err != nil {
+		return nil, err
+	}
+	manifestDesc := v1.Descriptor{
+		MediaType: v1.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifestBytes),
+		Size:      int64(len(manifestBytes)),
+	}
+
+	configDesc := v1.Descriptor{MediaType: EmptyJSONMediaType, Digest: digest.FromBytes(emptyJSON), Size: int64(len(emptyJSON))}
+	for _, blob := range []struct {
+		desc    v1.Descriptor
+		content []byte
+	}{{layer, content}, {configDesc, emptyJSON}, {manifestDesc, manifestBytes}} {
+		exists, err := repo.Exists(ctx, blob.desc)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			if err = repo.Push(ctx, blob.desc, bytes.NewReader(blob.content)); err != nil {
+				return nil, fmt.Errorf("unable to push %s: %w", blob.desc.Digest, err)
+			}
+		}
+	}
+
+	if _, useFallbackTag := Flavor(ctx, client, repo.Reference.Registry, false).referrersSources(); !useFallbackTag {
+		return &manifestDesc, nil
+	}
+
+	fallbackTag := strings.Replace(subject.Digest.String(), ":", "-", 1)
+	referrers, err := fetchIndex(ctx, client,
+		fmt.Sprintf("https://%s/v2/%s/manifests/%s", repo.Reference.Registry, repo.Reference.Repository, fallbackTag))
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch fallback tag %s: %w", fallbackTag, err)
+	}
+
+	indexBytes, err := json.Marshal(v1.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: v1.MediaTypeImageIndex,
+		Manifests: dedupDescriptors(append(referrers, manifestDesc)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	indexDesc := v1.Descriptor{
+		MediaType: v1.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(indexBytes),
+		Size:      int64(len(indexBytes)),
+	}
+
+	if err = repo.PushReference(ctx, indexDesc, bytes.NewReader(indexBytes), fallbackTag); err != nil {
+		return nil, fmt.Errorf("unable to push fallback tag %s: %w", fallbackTag, err)
+	}
+
+	return &manifestDesc, nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+var subjectDigest = digest.FromString("subject")
+
+func referrerDesc(content string) v1.Descriptor {
+	return v1.Descriptor{MediaType: v1.MediaTypeImageManifest, Digest: digest.FromString(content), Size: int64(len(content))}
+}
+
+func writeIndex(t *testing.T, w http.ResponseWriter, descs ...v1.Descriptor) {
+	w.Header().Set("Content-Type", v1.MediaTypeImageIndex)
+	if err := json.NewEncoder(w).Encode(v1.Index{MediaType: v1.MediaTypeImageIndex, Manifests: descs}); err != nil {
+		t.Error(err)
+	}
+}
+
+func newReferrersServer(t *testing.T, api, fallbackTag http.HandlerFunc) (ref string, server *httptest.Server) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/repo/referrers/"+subjectDigest.String(), api)
+	mux.HandleFunc("/v2/repo/manifests/"+strings.Replace(subjectDigest.String(), ":", "-", 1), fallbackTag)
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return strings.TrimPrefix(server.URL, "http://") + "/repo@" + subjectDigest.String(), server
+}
+
+func TestReferrersPaginated(t *testing.T) {
+	sig, sbom, attestation, legacy := referrerDesc("sig"), referrerDesc("sbom"), referrerDesc("attestation"), referrerDesc("legacy")
+
+	api := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", `</v2/repo/referrers/`+subjectDigest.String()+`?page=2>; rel="next"`)
+			writeIndex(t, w, sig)
+		case "2":
+			w.Header().Set("Link", `</v2/repo/referrers/`+subjectDigest.String()+`?page=3>; rel="next"`)
+			writeIndex(t, w, sbom)
+		default:
+			writeIndex(t, w, attestation)
+		}
+	}
+	fallbackTag := func(w http.ResponseWriter, r *http.Request) {
+		writeIndex(t, w, sig, legacy)
+	}
+
+	ref, server := newReferrersServer(t, api, fallbackTag)
+
+	referrers, err := Referrers(context.Background(), server.Client(), ref, subjectDigest, true)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expected := []v1.Descriptor{sig, sbom, attestation, legacy}
+	if len(referrers) != len(expected) {
+		t.Fatalf("expected %d referrers, got %d: %v", len(expected), len(referrers), referrers)
+	}
+	for i := range expected {
+		if referrers[i].Digest != expected[i].Digest {
+			t.Errorf("expected referrer %d to be %s, got %s", i, expected[i].Digest, referrers[i].Digest)
+		}
+	}
+}
+
+func TestReferrersPartial(t *testing.T) {
+	sig, legacy := referrerDesc("sig"), referrerDesc("legacy")
+
+	api := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", `</v2/repo/referrers/`+subjectDigest.String()+`?page=2>; rel="next"`)
+			writeIndex(t, w, sig)
+			return
+		}
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}
+	fallbackTag := func(w http.ResponseWriter, r *http.Request) {
+		writeIndex(t, w, legacy)
+	}
+
+	ref, server := newReferrersServer(t, api, fallbackTag)
+
+	referrers, err := Referrers(context.Background(), server.Client(), ref, subjectDigest, true)
+	if !errors.Is(err, ErrIncompleteReferrers) {
+		t.Fatal("expected ErrIncompleteReferrers, got:", err)
+	}
+	if len(referrers) != 2 || referrers[0].Digest != sig.Digest || referrers[1].Digest != legacy.Digest {
+		t.Fatalf("expected the partial referrers to be returned, got %v", referrers)
+	}
+}
+
+func TestReferrersAPIUnsupported(t *testing.T) {
+	legacy := referrerDesc("legacy")
+
+	ref, server := newReferrersServer(t, http.NotFound, func(w http.ResponseWriter, r *http.Request) {
+		writeIndex(t, w, legacy)
+	})
+
+	referrers, err := Referrers(context.Background(), server.Client(), ref, subjectDigest, true)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(referrers) != 1 || referrers[0].Digest != legacy.Digest {
+		t.Fatalf("expected only the fallback tag referrers, got %v", referrers)
+	}
+}
+
+func TestNewReferrerManifest(t *testing.T) {
+	subject := v1.Descriptor{
+		MediaType:   v1.MediaTypeImageIndex,
+		Digest:      subjectDigest,
+		Size:        7,
+		Annotations: map[string]string{"dropped": "true"},
+	}
+	layer := v1.Descriptor{MediaType: "application/vnd.dsse.envelope.v1+json", Digest: digest.FromString("sig"), Size: 3}
+
+	data, err := newReferrerManifest(subject, layer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var manifest referrerManifest
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.ArtifactType != layer.MediaType {
+		t.Errorf("unexpected artifact type %q", manifest.ArtifactType)
+	}
+	if manifest.Subject == nil || manifest.Subject.Digest != subjectDigest || manifest.Subject.Annotations != nil {
+		t.Errorf("unexpected subject %+v", manifest.Subject)
+	}
+	if manifest.Config.MediaType != EmptyJSONMediaType || len(manifest.Layers) != 1 || manifest.Layers[0].Digest != layer.Digest {
+		t.Errorf("unexpected manifest %s", data)
+	}
+}
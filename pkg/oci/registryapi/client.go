@@ -0,0 +1,128 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registryapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// Supported registry types.
+const (
+	// Harbor is the type of Harbor registries.
+	Harbor = "harbor"
+	// Quay is the type of Quay registries.
+	Quay = "quay"
+)
+
+// ErrUnsupportedRegistry error when an operation is not supported for a registry type.
+var ErrUnsupportedRegistry = errors.New("unsupported registry type")
+
+// Client interacts with the API of a registry.
+type Client struct {
+	// BaseURL is the URL of the registry, e.g. "https://harbor.corp".
+	BaseURL string
+	// Type is the type of the registry, e.g. "harbor".
+	Type string
+	// Credential is used to authenticate to the API. Harbor uses it for basic authentication,
+	// while Quay expects an OAuth access token as password.
+	Credential auth.Credential
+	// HTTPClient is the client used to send requests. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// NewClient returns a new client for the API of the registry of the given type at baseURL.
+func NewClient(baseURL, registryType string, cred auth.Credential) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Type:       registryType,
+		Credential: cred,
+	}
+}
+
+// splitRepository splits a repository, e.g. "project/sub/repo", in its first component and the rest.
+func splitRepository(repository string) (namespace, name string, err error) {
+	namespace, name, ok := strings.Cut(repository, "/")
+	if !ok || namespace == "" || name == "" {
+		return "", "", fmt.Errorf("repository %q seems to be in the wrong format: needs to be in NAMESPACE/NAME", repository)
+	}
+
+	return namespace, name, nil
+}
+
+// do sends a request with the given JSON body, if not nil, and decodes the JSON response in out, if not nil.
+func (c *Client) do(ctx context.Context, method, path string, header http.Header, body, out interface{}) error {
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	switch {
+	case c.Type == Quay && c.Credential.Password != "":
+		req.Header.Set("Authorization", "Bearer "+c.Credential.Password)
+	case c.Credential.Username != "":
+		req.SetBasicAuth(c.Credential.Username, c.Credential.Password)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: unexpected status %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: unable to decode response: %w", method, path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registryapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Statuses of a Harbor scan.
+const (
+	ScanStatusSuccess = "Success"
+	ScanStatusError   = "Error"
+	ScanStatusStopped = "Stopped"
+)
+
+// ScanSummary reports the status of the vulnerability scan of an artifact and the number
+// of vulnerabilities found for each severity.
+type ScanSummary struct {
+	Status   string `json:"status" yaml:"status"`
+	Critical int    `json:"critical" yaml:"critical"`
+	High     int    `json:"high" yaml:"high"`
+	Medium   int    `json:"medium" yaml:"medium"`
+	Low      int    `json:"low" yaml:"low"`
+	Unknown  int    `json:"unknown" yaml:"unknown"`
+}
+
+// Done reports whether the scan is over, either successfully or not.
+func (s *ScanSummary) Done() bool {
+	return s.Status == ScanStatusSuccess || s.Status == ScanStatusError || s.Status == ScanStatusStopped
+}
+
+// Scan triggers the vulnerability scan of the artifact identified by reference, a tag or a digest,
+// in the given repository. Only Harbor registries are supported.
+func (c *Client) Scan(ctx context.Context, repository, reference string) error {
+	path, err := c.harborArtifactPath(repository, reference)
+	if err != nil {
+		return err
+	}
+
+	return c.do(ctx, http.MethodPost, path+"/scan", nil, nil, nil)
+}
+
+// ScanSummary returns the summary of the last vulnerability scan of the artifact identified by reference,
+// a tag or a digest, in the given repository. Only Harbor registries are supported.
+func (c *Client) ScanSummary(ctx context.Context, repository, reference string) (*ScanSummary, error) {
+	path, err := c.harborArtifactPath(repository, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	var artifact struct {
+		ScanOverview map[string]struct {
+			ScanStatus string `json:"scan_status"`
+			Summary    struct {
+				Summary map[string]int `json:"summary"`
+			} `json:"summary"`
+		} `json:"scan_overview"`
+	}
+	if err = c.do(ctx, http.MethodGet, path+"?with_scan_overview=true", nil, nil, &artifact); err != nil {
+		return nil, err
+	}
+
+	// The overview is keyed by the media type of the report, only one report is expected.
+	for _, overview := range artifact.ScanOverview {
+		counts := overview.Summary.Summary
+		return &ScanSummary{
+			Status:   overview.ScanStatus,
+			Critical: counts["Critical"],
+			High:     counts["High"],
+			Medium:   counts["Medium"],
+			Low:      counts["Low"],
+			Unknown:  counts["Unknown"],
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no scan found for %s in %s", reference, repository)
+}
+
+// harborArtifactPath returns the API path of an artifact. The repository name within the project
+// must be URL encoded twice, since Harbor decodes it once before routing.
+func (c *Client) harborArtifactPath(repository, reference string) (string, error) {
+	if c.Type != Harbor {
+		return "", fmt.Errorf("%q: %w", c.Type, ErrUnsupportedRegistry)
+	}
+
+	project, name, err := splitRepository(repository)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/repositories/%s/artifacts/%s", harborProjectPath(project),
+		url.PathEscape(url.PathEscape(name)), url.PathEscape(reference)), nil
+}
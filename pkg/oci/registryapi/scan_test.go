@@ -0,0 +1,62 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registryapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+func TestHarborScan(t *testing.T) {
+	const artifactPath = "/api/v2.0/projects/myproject/repositories/plugins%252Fmyplugin/artifacts/0.1.0"
+
+	var scanned bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.EscapedPath() == artifactPath+"/scan":
+			scanned = true
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodGet && r.URL.EscapedPath() == artifactPath:
+			if r.URL.Query().Get("with_scan_overview") != "true" {
+				t.Errorf("expected scan overview to be requested")
+			}
+			_, _ = w.Write([]byte(`{"scan_overview":{"application/vnd.security.vulnerability.report; version=1.1":` +
+				`{"scan_status":"Success","summary":{"total":3,"summary":{"Critical":1,"High":2}}}}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.EscapedPath())
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, Harbor, auth.EmptyCredential)
+	if err := c.Scan(context.Background(), "myproject/plugins/myplugin", "0.1.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !scanned {
+		t.Fatalf("expected the scan to be triggered")
+	}
+
+	summary, err := c.ScanSummary(context.Background(), "myproject/plugins/myplugin", "0.1.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !summary.Done() || summary.Critical != 1 || summary.High != 2 || summary.Medium != 0 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+}
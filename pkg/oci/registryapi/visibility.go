@@ -0,0 +1,93 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registryapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// SetVisibility makes the repository public or private. On Harbor the visibility is set on the project,
+// i.e. the first component of the repository, hence it applies to all its repositories.
+func (c *Client) SetVisibility(ctx context.Context, repository string, public bool) error {
+	namespace, name, err := splitRepository(repository)
+	if err != nil {
+		return err
+	}
+
+	switch c.Type {
+	case Harbor:
+		body := map[string]interface{}{
+			"metadata": map[string]string{"public": strconv.FormatBool(public)},
+		}
+		return c.do(ctx, http.MethodPut, harborProjectPath(namespace), harborHeader(), body, nil)
+	case Quay:
+		visibility := "private"
+		if public {
+			visibility = "public"
+		}
+		body := map[string]string{"visibility": visibility}
+		return c.do(ctx, http.MethodPost, quayRepositoryPath(namespace, name)+"/changevisibility", nil, body, nil)
+	default:
+		return fmt.Errorf("%q: %w", c.Type, ErrUnsupportedRegistry)
+	}
+}
+
+// Visibility returns whether the repository is public.
+func (c *Client) Visibility(ctx context.Context, repository string) (bool, error) {
+	namespace, name, err := splitRepository(repository)
+	if err != nil {
+		return false, err
+	}
+
+	switch c.Type {
+	case Harbor:
+		var project struct {
+			Metadata struct {
+				Public string `json:"public"`
+			} `json:"metadata"`
+		}
+		if err = c.do(ctx, http.MethodGet, harborProjectPath(namespace), harborHeader(), nil, &project); err != nil {
+			return false, err
+		}
+		return project.Metadata.Public == "true", nil
+	case Quay:
+		var repo struct {
+			IsPublic bool `json:"is_public"`
+		}
+		if err = c.do(ctx, http.MethodGet, quayRepositoryPath(namespace, name), nil, nil, &repo); err != nil {
+			return false, err
+		}
+		return repo.IsPublic, nil
+	default:
+		return false, fmt.Errorf("%q: %w", c.Type, ErrUnsupportedRegistry)
+	}
+}
+
+func harborProjectPath(project string) string {
+	return "/api/v2.0/projects/" + url.PathEscape(project)
+}
+
+// harborHeader returns the header telling Harbor that projects are identified by name rather than by ID.
+func harborHeader() http.Header {
+	return http.Header{"X-Is-Resource-Name": []string{"true"}}
+}
+
+func quayRepositoryPath(namespace, name string) string {
+	return fmt.Sprintf("/api/v1/repository/%s/%s", url.PathEscape(namespace), url.PathEscape(name))
+}
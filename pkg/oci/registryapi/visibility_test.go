@@ -0,0 +1,106 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registryapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+func TestHarborVisibility(t *testing.T) {
+	public := "false"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2.0/projects/myproject" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "secret" {
+			t.Errorf("unexpected credentials %q:%q", user, pass)
+		}
+		switch r.Method {
+		case http.MethodPut:
+			var body struct {
+				Metadata map[string]string `json:"metadata"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("unexpected body: %v", err)
+			}
+			public = body.Metadata["public"]
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"name":"myproject","metadata":{"public":"` + public + `"}}`))
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, Harbor, auth.Credential{Username: "admin", Password: "secret"})
+	if err := c.SetVisibility(context.Background(), "myproject/plugins/myplugin", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := c.Visibility(context.Background(), "myproject/plugins/myplugin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got {
+		t.Errorf("expected the repository to be public")
+	}
+}
+
+func TestQuayVisibility(t *testing.T) {
+	isPublic := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		switch r.URL.Path {
+		case "/api/v1/repository/myorg/myplugin/changevisibility":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("unexpected body: %v", err)
+			}
+			isPublic = body["visibility"] == "public"
+		case "/api/v1/repository/myorg/myplugin":
+			_ = json.NewEncoder(w).Encode(map[string]bool{"is_public": isPublic})
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, Quay, auth.Credential{Password: "token"})
+	if err := c.SetVisibility(context.Background(), "myorg/myplugin", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := c.Visibility(context.Background(), "myorg/myplugin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got {
+		t.Errorf("expected the repository to be private")
+	}
+}
+
+func TestUnsupportedRegistry(t *testing.T) {
+	c := NewClient("https://registry.corp", "gcr", auth.EmptyCredential)
+	if err := c.SetVisibility(context.Background(), "myorg/myplugin", true); !errors.Is(err, ErrUnsupportedRegistry) {
+		t.Errorf("expected ErrUnsupportedRegistry, got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// Resolve resolves a reference to the descriptor of the artifact stored in the remote registry.
+func Resolve(ctx context.Context, ref string, client *auth.Client) (*v1.Descriptor, error) {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new repository with ref %s: %w", ref, err)
+	}
+	repo.Client = client
+
+	desc, err := repo.Resolve(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return &desc, nil
+}
+
+// ResolvePlatform resolves a reference to the descriptor of the artifact for the given platform, matched as done
+// by SelectPlatform. If the reference does not point to an index, the descriptor of the referenced manifest is returned.
+func ResolvePlatform(ctx context.Context, ref string, client *auth.Client, os, arch string) (*v1.Descriptor, error) {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new repository with ref %s: %w", ref, err)
+	}
+	repo.Client = client
+
+	desc, indexReader, err := repo.FetchReference(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	defer indexReader.Close()
+
+	if !IsIndex(desc.MediaType) {
+		return &desc, nil
+	}
+
+	indexBytes, err := readMetadata(indexReader, &desc)
+	if err != nil {
+		return nil, err
+	}
+
+	var index v1.Index
+	if err = json.Unmarshal(indexBytes, &index); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal index: %w", err)
+	}
+
+	if i := SelectPlatform(index.Manifests, os, arch); i >= 0 {
+		return &index.Manifests[i], nil
+	}
+
+	return nil, fmt.Errorf("no artifact found for platform %s/%s in %s", os, arch, ref)
+}
+
+// FetchManifest fetches the manifest of the artifact for the given platform.
+func FetchManifest(ctx context.Context, ref string, client *auth.Client, os, arch string) (*v1.Manifest, error) {
+	desc, err := ResolvePlatform(ctx, ref, client, os, arch)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new repository with ref %s: %w", ref, err)
+	}
+	repo.Client = client
+
+	manifestReader, err := repo.Fetch(ctx, *desc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch manifest %s: %w", desc.Digest, err)
+	}
+	defer manifestReader.Close()
+
+	manifestBytes, err := readMetadata(manifestReader, desc)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest v1.Manifest
+	if err = json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal manifest: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+// readMetadata reads the manifest or the index described by desc, refusing the ones larger than DefaultMaxMetadataSize.
+func readMetadata(reader io.Reader, desc *v1.Descriptor) ([]byte, error) {
+	if desc.Size > DefaultMaxMetadataSize {
+		return nil, fmt.Errorf("%s of %d bytes exceeds the maximum size of %d bytes", desc.Digest, desc.Size, DefaultMaxMetadataSize)
+	}
+
+	return io.ReadAll(io.LimitReader(reader, DefaultMaxMetadataSize))
+}
+
+// FetchConfig fetches the raw content of the config layer of an artifact given its manifest.
+func FetchConfig(ctx context.Context, ref string, client *auth.Client, manifest *v1.Manifest) ([]byte, error) {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new repository with ref %s: %w", ref, err)
+	}
+	repo.Client = client
+
+	configReader, err := repo.Fetch(ctx, manifest.Config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch config %s: %w", manifest.Config.Digest, err)
+	}
+	defer configReader.Close()
+
+	return io.ReadAll(io.LimitReader(configReader, DefaultMaxMetadataSize))
+}
+
+// FetchArtifactConfig fetches the config layer of an artifact given its manifest.
+func FetchArtifactConfig(ctx context.Context, ref string, client *auth.Client, manifest *v1.Manifest) (*ArtifactConfig, error) {
+	configBytes, err := FetchConfig(ctx, ref, client, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	var config ArtifactConfig
+	if err = json.Unmarshal(configBytes, &config); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal config: %w", err)
+	}
+
+	return &config, nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestReadMetadata(t *testing.T) {
+	manifest := `{"schemaVersion":2}`
+	desc := &v1.Descriptor{Digest: digest.FromString(manifest), Size: int64(len(manifest))}
+	data, err := readMetadata(strings.NewReader(manifest), desc)
+	if err != nil || string(data) != manifest {
+		t.Errorf("expected %q, got %q, %v", manifest, data, err)
+	}
+
+	desc.Size = DefaultMaxMetadataSize + 1
+	if _, err = readMetadata(strings.NewReader(manifest), desc); err == nil {
+		t.Errorf("expected error reading metadata larger than %d bytes", DefaultMaxMetadataSize)
+	}
+}
@@ -16,9 +16,11 @@ package oci
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/blang/semver"
+	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
 )
@@ -50,6 +52,237 @@ func Tags(ctx context.Context, ref string, client *auth.Client) ([]string, error
 	return result, nil
 }
 
+// ListTags returns all the tags of an artifact given a reference to a repository, including the ones
+// which are not valid semver versions, in the order returned by the registry.
+func ListTags(ctx context.Context, ref string, client *auth.Client) ([]string, error) {
+	repository, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, err
+	}
+	repository.Client = client
+
+	var result []string
+	var tagRetriever = func(tags []string) error {
+		result = append(result, tags...)
+		return nil
+	}
+
+	if err = repository.Tags(ctx, "", tagRetriever); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ErrNoVersionFound error when a repository has no tag which is a valid semver version.
+var ErrNoVersionFound = errors.New("no version found")
+
+// LatestVersion returns the highest semver tag of an artifact given a reference to a repository.
+// Tags that are not valid semver versions are ignored, as well as pre-releases unless preRelease is true.
+func LatestVersion(ctx context.Context, ref string, client *auth.Client, preRelease bool) (string, error) {
+	repository, err := remote.NewRepository(ref)
+	if err != nil {
+		return "", err
+	}
+	repository.Client = client
+
+	var latest *semver.Version
+	var tagRetriever = func(tags []string) error {
+		for _, t := range tags {
+			v, err := semver.Parse(t)
+			if err != nil || (len(v.Pre) > 0 && !preRelease) {
+				continue
+			}
+			if latest == nil || v.GT(*latest) {
+				latest = &v
+			}
+		}
+		return nil
+	}
+
+	if err = repository.Tags(ctx, "", tagRetriever); err != nil {
+		return "", err
+	}
+
+	if latest == nil {
+		return "", fmt.Errorf("%s: %w", ref, ErrNoVersionFound)
+	}
+
+	return latest.String(), nil
+}
+
+// Versions returns the tags of an artifact which are valid semver versions, sorted in ascending order,
+// given a reference to a repository. The other tags are ignored.
+func Versions(ctx context.Context, ref string, client *auth.Client) ([]string, error) {
+	repository, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, err
+	}
+	repository.Client = client
+
+	var versions []semver.Version
+	var tagRetriever = func(tags []string) error {
+		for _, t := range tags {
+			if v, err := semver.Parse(t); err == nil {
+				versions = append(versions, v)
+			}
+		}
+		return nil
+	}
+
+	if err = repository.Tags(ctx, "", tagRetriever); err != nil {
+		return nil, err
+	}
+
+	semver.Sort(versions)
+
+	result := make([]string, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, v.String())
+	}
+
+	return result, nil
+}
+
+// CompatibleVersion returns the highest of versions which is a compatible update of installed: a greater
+// version, not a pre-release, with the same major version or, for 0.x versions, the same minor version.
+// It returns false if installed is not a valid semver version or no compatible update exists.
+func CompatibleVersion(installed string, versions []string) (string, bool) {
+	current, err := semver.Parse(installed)
+	if err != nil {
+		return "", false
+	}
+
+	var best *semver.Version
+	for _, t := range versions {
+		v, err := semver.Parse(t)
+		if err != nil || len(v.Pre) > 0 || !v.GT(current) || v.Major != current.Major {
+			continue
+		}
+		if current.Major == 0 && v.Minor != current.Minor {
+			continue
+		}
+		if best == nil || v.GT(*best) {
+			best = &v
+		}
+	}
+
+	if best == nil {
+		return "", false
+	}
+
+	return best.String(), true
+}
+
+// SelectVersion returns the version of versions satisfying a dependency on required: the highest compatible update
+// of required, as returned by CompatibleVersion, or required itself if no update exists. It returns false if
+// neither is available.
+func SelectVersion(required string, versions []string) (string, bool) {
+	if version, ok := CompatibleVersion(required, versions); ok {
+		return version, true
+	}
+
+	for _, v := range versions {
+		if v == required {
+			return v, true
+		}
+	}
+
+	return "", false
+}
+
+// MoveTag points tag to the manifest tagged as version, given a reference to a repository.
+// It returns the digest previously pointed by tag, empty if the tag did not exist, and the new one.
+func MoveTag(ctx context.Context, ref, version, tag string, client *auth.Client) (oldDigest, newDigest string, err error) {
+	repository, err := remote.NewRepository(ref)
+	if err != nil {
+		return "", "", err
+	}
+	repository.Client = client
+
+	oldDesc, err := repository.Resolve(ctx, tag)
+	switch {
+	case err == nil:
+		oldDigest = oldDesc.Digest.String()
+	case !errors.Is(err, errdef.ErrNotFound):
+		return "", "", fmt.Errorf("unable to resolve tag %q: %w", tag, err)
+	}
+
+	desc, err := repository.Resolve(ctx, version)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to resolve version %q: %w", version, err)
+	}
+
+	if err = repository.Tag(ctx, desc, tag); err != nil {
+		return "", "", fmt.Errorf("unable to tag version %q as %q: %w", version, tag, err)
+	}
+
+	return oldDigest, desc.Digest.String(), nil
+}
+
+// SemverTags returns the tags to be applied to a semver version: the version itself and, unless it is a
+// pre-release, the floating "MAJOR.MINOR" and "MAJOR" tags, plus "latest" if includeLatest is true. The floating
+// tags of the lines having a higher stable version among versions are left out, so that they never move backwards.
+func SemverTags(version string, versions []string, includeLatest bool) ([]string, error) {
+	v, err := semver.Parse(version)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a valid semver version: %w", version, err)
+	}
+
+	tags := []string{v.String()}
+	if len(v.Pre) > 0 {
+		return tags, nil
+	}
+
+	var minorSuperseded, majorSuperseded, latestSuperseded bool
+	for _, t := range versions {
+		other, err := semver.Parse(t)
+		if err != nil || len(other.Pre) > 0 || !other.GT(v) {
+			continue
+		}
+		latestSuperseded = true
+		if other.Major == v.Major {
+			majorSuperseded = true
+			minorSuperseded = minorSuperseded || other.Minor == v.Minor
+		}
+	}
+
+	if !minorSuperseded {
+		tags = append(tags, fmt.Sprintf("%d.%d", v.Major, v.Minor))
+	}
+	if !majorSuperseded {
+		tags = append(tags, fmt.Sprintf("%d", v.Major))
+	}
+	if includeLatest && !latestSuperseded {
+		tags = append(tags, DefaultTag)
+	}
+
+	return tags, nil
+}
+
+// ApplyTags resolves ref and points all the given tags to the resolved manifest or index, so that they
+// refer to the same digest even if ref moves in the meantime. It returns the resolved digest.
+func ApplyTags(ctx context.Context, ref string, tags []string, client *auth.Client) (string, error) {
+	repository, err := remote.NewRepository(ref)
+	if err != nil {
+		return "", err
+	}
+	repository.Client = client
+
+	desc, err := repository.Resolve(ctx, repository.Reference.Reference)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve %q: %w", ref, err)
+	}
+
+	for _, tag := range tags {
+		if err = repository.Tag(ctx, desc, tag); err != nil {
+			return "", fmt.Errorf("unable to tag %s as %q: %w", desc.Digest, tag, err)
+		}
+	}
+
+	return desc.Digest.String(), nil
+}
+
 func sortTags(tags []string) ([]string, error) {
 	var parsedVersions []semver.Version
 	var latest bool
@@ -0,0 +1,96 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompatibleVersion(t *testing.T) {
+	versions := []string{"0.1.0", "0.1.3", "0.2.0", "1.0.0", "1.2.0", "1.3.0-rc1", "2.0.0", "latest"}
+
+	tests := []struct {
+		installed string
+		expected  string
+		found     bool
+	}{
+		{installed: "1.0.0", expected: "1.2.0", found: true},
+		{installed: "0.1.0", expected: "0.1.3", found: true},
+		{installed: "1.2.0", found: false},
+		{installed: "2.0.0", found: false},
+		{installed: "latest", found: false},
+	}
+
+	for _, tt := range tests {
+		version, found := CompatibleVersion(tt.installed, versions)
+		if version != tt.expected || found != tt.found {
+			t.Errorf("CompatibleVersion(%q) = %q, %v, expected %q, %v", tt.installed, version, found, tt.expected, tt.found)
+		}
+	}
+}
+
+func TestSelectVersion(t *testing.T) {
+	versions := []string{"0.1.0", "0.1.3", "1.0.0", "1.2.0", "2.0.0"}
+
+	tests := []struct {
+		required string
+		expected string
+		found    bool
+	}{
+		{required: "1.0.0", expected: "1.2.0", found: true},
+		{required: "0.1.0", expected: "0.1.3", found: true},
+		{required: "2.0.0", expected: "2.0.0", found: true},
+		{required: "1.3.0", found: false},
+		{required: "3.0.0", found: false},
+	}
+
+	for _, tt := range tests {
+		version, found := SelectVersion(tt.required, versions)
+		if version != tt.expected || found != tt.found {
+			t.Errorf("SelectVersion(%q) = %q, %v, expected %q, %v", tt.required, version, found, tt.expected, tt.found)
+		}
+	}
+}
+
+func TestSemverTags(t *testing.T) {
+	versions := []string{"1.1.0", "1.2.0", "1.2.3", "2.0.0-rc1"}
+
+	tests := []struct {
+		version       string
+		includeLatest bool
+		expected      []string
+	}{
+		{version: "1.2.4", includeLatest: true, expected: []string{"1.2.4", "1.2", "1", "latest"}},
+		{version: "1.2.4", expected: []string{"1.2.4", "1.2", "1"}},
+		{version: "1.1.1", includeLatest: true, expected: []string{"1.1.1", "1.1"}},
+		{version: "1.2.1", expected: []string{"1.2.1"}},
+		{version: "2.0.0-rc2", includeLatest: true, expected: []string{"2.0.0-rc2"}},
+	}
+
+	for _, tt := range tests {
+		tags, err := SemverTags(tt.version, versions, tt.includeLatest)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.version, err)
+		}
+		if !reflect.DeepEqual(tags, tt.expected) {
+			t.Errorf("%s: expected tags %v, got %v", tt.version, tt.expected, tags)
+		}
+	}
+
+	if _, err := SemverTags("v1", versions, false); err == nil {
+		t.Error("expected an error for an invalid version")
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"sort"
 	"strings"
 
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+
 	"github.com/falcosecurity/falcoctl/pkg/artifact"
 )
 
@@ -31,6 +33,10 @@ const (
 	Rulesfile ArtifactType = "rulesfile"
 	// Plugin represents a plugin artifact.
 	Plugin ArtifactType = "plugin"
+	// Collection represents an artifact referencing other artifacts by digest, rather than embedding their content.
+	Collection ArtifactType = "collection"
+	// ContainerImage represents a container image, pulled only on explicit request. It cannot be set from the command line.
+	ContainerImage ArtifactType = "image"
 )
 
 // The following functions are necessary to use ArtifactType with Cobra.
@@ -43,11 +49,11 @@ func (e *ArtifactType) String() string {
 // Set an ArtifactType.
 func (e *ArtifactType) Set(v string) error {
 	switch v {
-	case "rulesfile", "plugin":
+	case "rulesfile", "plugin", "collection":
 		*e = ArtifactType(v)
 		return nil
 	default:
-		return errors.New(`must be one of "rulesfile", "plugin"`)
+		return errors.New(`must be one of "rulesfile", "plugin", "collection"`)
 	}
 }
 
@@ -56,30 +62,105 @@ func (e *ArtifactType) Type() string {
 	return "ArtifactType"
 }
 
+// ArtifactTypeOf returns the type of the artifact described by manifest, given by the media type of its first layer
+// or, since docker layers do not carry it, by the ArtifactTypeAnnotation annotation of the manifest. Collections are
+// recognized by the media type of their config, since their only layer is empty.
+func ArtifactTypeOf(manifest *v1.Manifest) (ArtifactType, error) {
+	if manifest.Config.MediaType == FalcoCollectionConfigMediaType {
+		return Collection, nil
+	}
+
+	if len(manifest.Layers) == 0 {
+		return "", errors.New("no layers in the manifest")
+	}
+
+	var artifactType ArtifactType
+	switch manifest.Layers[0].MediaType {
+	case FalcoPluginLayerMediaType:
+		artifactType = Plugin
+	case FalcoRulesfileLayerMediaType:
+		artifactType = Rulesfile
+	case DockerLayerMediaType:
+		if err := artifactType.Set(manifest.Annotations[ArtifactTypeAnnotation]); err != nil {
+			return "", fmt.Errorf("unable to determine the type of the artifact from the manifest annotations: %w", err)
+		}
+	default:
+		return "", fmt.Errorf("unknown media type: %q", manifest.Layers[0].MediaType)
+	}
+
+	return artifactType, nil
+}
+
+// IsContainerImage returns true if manifest describes a container image rather than a Falco artifact, i.e. if
+// its config is an image config and, unlike artifacts pushed with docker media types, it carries no artifact type.
+func IsContainerImage(manifest *v1.Manifest) bool {
+	switch manifest.Config.MediaType {
+	case v1.MediaTypeImageConfig, DockerConfigMediaType:
+		_, ok := manifest.Annotations[ArtifactTypeAnnotation]
+		return !ok
+	default:
+		return false
+	}
+}
+
 // RegistryResult represents a generic result that is generated when
 // interacting with a remote OCI registry.
 type RegistryResult struct {
-	Digest   string
-	Config   ArtifactConfig
-	Type     ArtifactType
-	Filename string
+	Digest   string         `json:"digest" yaml:"digest"`
+	Config   ArtifactConfig `json:"config" yaml:"config"`
+	Type     ArtifactType   `json:"type" yaml:"type"`
+	Filename string         `json:"filename,omitempty" yaml:"filename,omitempty"`
+	// LayerDigest is the digest of the layer saved as Filename.
+	LayerDigest string `json:"layerDigest,omitempty" yaml:"layerDigest,omitempty"`
+	// Members are the artifacts referenced by a pulled collection.
+	Members []CollectionMember `json:"members,omitempty" yaml:"members,omitempty"`
+	// Platforms are the per-platform artifacts pushed in place of an index, when the registry does not support them.
+	Platforms []PlatformResult `json:"platforms,omitempty" yaml:"platforms,omitempty"`
+	// Transferred is the number of bytes uploaded by a push. Blobs already in the repository are not counted.
+	Transferred int64 `json:"transferred,omitempty" yaml:"transferred,omitempty"`
+}
+
+// PlatformResult is the artifact of a platform pushed under its own tags, in place of an index.
+type PlatformResult struct {
+	Platform string   `json:"platform" yaml:"platform"`
+	Digest   string   `json:"digest" yaml:"digest"`
+	Tags     []string `json:"tags" yaml:"tags"`
 }
 
 // ArtifactConfig is the struct stored in the config layer of rulesfile and plugin artifacts. Each type fills only the fields of interest.
 type ArtifactConfig struct {
-	Dependencies []ArtifactDependency `json:"dependencies,omitempty"`
+	Dependencies []ArtifactDependency  `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	Requirements []ArtifactRequirement `json:"requirements,omitempty" yaml:"requirements,omitempty"`
+}
+
+// ArtifactRequirement represents a requirement of an artifact on the environment where it runs,
+// e.g. the plugin API version, to be stored in the config.
+type ArtifactRequirement struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// Requirement returns the version of the requirement with the given name, if any.
+func (rc *ArtifactConfig) Requirement(name string) (string, bool) {
+	for _, r := range rc.Requirements {
+		if r.Name == name {
+			return r.Version, true
+		}
+	}
+
+	return "", false
 }
 
 type dependency struct {
-	Name    string `json:"name"`
-	Version string `json:"version"`
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
 }
 
 // ArtifactDependency represents the artifact's depedendency to be stored in the config.
 type ArtifactDependency struct {
-	Name         string       `json:"name"`
-	Version      string       `json:"version"`
-	Alternatives []dependency `json:"alternatives,omitempty"`
+	Name         string       `json:"name" yaml:"name"`
+	Version      string       `json:"version" yaml:"version"`
+	Alternatives []dependency `json:"alternatives,omitempty" yaml:"alternatives,omitempty"`
 }
 
 // SetAlternative sets an alternative dependency for an artifact dependency.
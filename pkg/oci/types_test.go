@@ -14,7 +14,11 @@
 
 package oci
 
-import "testing"
+import (
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
 
 func TestParseDepedencies(t *testing.T) {
 	ac := ArtifactConfig{}
@@ -45,3 +49,39 @@ func TestParseDepedencies(t *testing.T) {
 		t.Fatal("second dep should have no alternatives, got:", ac.Dependencies[1])
 	}
 }
+
+func TestIsContainerImage(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest v1.Manifest
+		expected bool
+	}{
+		{
+			name:     "oci image",
+			manifest: v1.Manifest{Config: v1.Descriptor{MediaType: v1.MediaTypeImageConfig}},
+			expected: true,
+		},
+		{
+			name:     "docker image",
+			manifest: v1.Manifest{Config: v1.Descriptor{MediaType: DockerConfigMediaType}},
+			expected: true,
+		},
+		{
+			name: "artifact with docker media types",
+			manifest: v1.Manifest{
+				Config:      v1.Descriptor{MediaType: DockerConfigMediaType},
+				Annotations: map[string]string{ArtifactTypeAnnotation: string(Plugin)},
+			},
+		},
+		{
+			name:     "rulesfile",
+			manifest: v1.Manifest{Config: v1.Descriptor{MediaType: FalcoRulesfileConfigMediaType}},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := IsContainerImage(&tt.manifest); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
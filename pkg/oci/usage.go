@@ -0,0 +1,170 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// TagUsage is the storage occupied by a tag of a repository.
+type TagUsage struct {
+	Tag    string `json:"tag" yaml:"tag"`
+	Digest string `json:"digest" yaml:"digest"`
+	// Size is the sum of the sizes of the unique blobs, manifests and indexes included, reachable from the tag
+	// and from its referrers.
+	Size  int64 `json:"size" yaml:"size"`
+	Blobs int   `json:"blobs" yaml:"blobs"`
+}
+
+// RepositoryUsage is the storage occupied by the tagged artifacts of a repository.
+type RepositoryUsage struct {
+	Repository string `json:"repository" yaml:"repository"`
+	// Size is the sum of the sizes of the unique blobs of the repository. Blobs shared by several
+	// tags are counted once, hence it is usually lower than the sum of the sizes of the tags.
+	Size  int64      `json:"size" yaml:"size"`
+	Blobs int        `json:"blobs" yaml:"blobs"`
+	Tags  []TagUsage `json:"tags" yaml:"tags"`
+}
+
+// Usage returns the storage occupied by the tagged artifacts of the repository pointed by ref, including
+// their referrers, such as signatures and SBOMs. Untagged manifests not referring to a tagged one are not
+// counted, since registries do not allow to enumerate them.
+// If the referrers of some tag could not be retrieved, the usage is returned anyway along with an error
+// wrapping ErrIncompleteReferrers.
+func Usage(ctx context.Context, ref string, client *auth.Client) (*RepositoryUsage, error) {
+	repository, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new repository with ref %s: %w", ref, err)
+	}
+	repository.Client = client
+
+	tags, err := ListTags(ctx, ref, client)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &RepositoryUsage{
+		Repository: repository.Reference.Registry + "/" + repository.Reference.Repository,
+		Tags:       []TagUsage{},
+	}
+
+	var incomplete error
+	repoBlobs := make(map[digest.Digest]int64)
+	for _, tag := range tags {
+		desc, err := repository.Resolve(ctx, tag)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve tag %s: %w", tag, err)
+		}
+
+		tagBlobs := make(map[digest.Digest]int64)
+		if err = collectBlobs(ctx, repository, desc, tagBlobs); err != nil {
+			return nil, fmt.Errorf("tag %s: %w", tag, err)
+		}
+
+		referrers, err := Referrers(ctx, client, usage.Repository+"@"+desc.Digest.String(), desc.Digest, false)
+		switch {
+		case errors.Is(err, ErrIncompleteReferrers):
+			incomplete = fmt.Errorf("tag %s: %w", tag, err)
+		case err != nil:
+			return nil, fmt.Errorf("unable to retrieve the referrers of tag %s: %w", tag, err)
+		}
+		for i := range referrers {
+			if err = collectBlobs(ctx, repository, referrers[i], tagBlobs); err != nil {
+				return nil, fmt.Errorf("tag %s, referrer %s: %w", tag, referrers[i].Digest, err)
+			}
+		}
+
+		usage.Tags = append(usage.Tags, TagUsage{
+			Tag:    tag,
+			Digest: desc.Digest.String(),
+			Size:   sumSizes(tagBlobs),
+			Blobs:  len(tagBlobs),
+		})
+
+		for d, size := range tagBlobs {
+			repoBlobs[d] = size
+		}
+	}
+
+	usage.Size = sumSizes(repoBlobs)
+	usage.Blobs = len(repoBlobs)
+
+	return usage, incomplete
+}
+
+// collectBlobs adds to blobs the size of desc and, for manifests and indexes, of all its successors.
+// Nodes already in blobs are not visited again.
+func collectBlobs(ctx context.Context, fetcher content.Fetcher, desc v1.Descriptor, blobs map[digest.Digest]int64) error {
+	if _, ok := blobs[desc.Digest]; ok {
+		return nil
+	}
+	blobs[desc.Digest] = desc.Size
+
+	if !IsIndex(desc.MediaType) && !IsManifest(desc.MediaType) {
+		return nil
+	}
+
+	reader, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return fmt.Errorf("unable to fetch %s: %w", desc.Digest, err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, DefaultMaxMetadataSize))
+	if err != nil {
+		return err
+	}
+
+	var successors []v1.Descriptor
+	if IsIndex(desc.MediaType) {
+		var index v1.Index
+		if err = json.Unmarshal(data, &index); err != nil {
+			return fmt.Errorf("unable to unmarshal index %s: %w", desc.Digest, err)
+		}
+		successors = index.Manifests
+	} else {
+		var manifest v1.Manifest
+		if err = json.Unmarshal(data, &manifest); err != nil {
+			return fmt.Errorf("unable to unmarshal manifest %s: %w", desc.Digest, err)
+		}
+		successors = append([]v1.Descriptor{manifest.Config}, manifest.Layers...)
+	}
+
+	for i := range successors {
+		if err = collectBlobs(ctx, fetcher, successors[i], blobs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sumSizes(blobs map[digest.Digest]int64) int64 {
+	var total int64
+	for _, size := range blobs {
+		total += size
+	}
+	return total
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type memoryFetcher map[digest.Digest][]byte
+
+func (m memoryFetcher) Fetch(_ context.Context, desc v1.Descriptor) (io.ReadCloser, error) {
+	data, ok := m[desc.Digest]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", desc.Digest)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m memoryFetcher) add(t *testing.T, mediaType string, node interface{}) v1.Descriptor {
+	data, err := json.Marshal(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := v1.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(data), Size: int64(len(data))}
+	m[desc.Digest] = data
+	return desc
+}
+
+func TestCollectBlobs(t *testing.T) {
+	fetcher := memoryFetcher{}
+
+	config := v1.Descriptor{MediaType: FalcoPluginConfigMediaType, Digest: digest.FromString("config"), Size: 10}
+	amd64 := v1.Descriptor{MediaType: FalcoPluginLayerMediaType, Digest: digest.FromString("amd64"), Size: 100}
+	arm64 := v1.Descriptor{MediaType: FalcoPluginLayerMediaType, Digest: digest.FromString("arm64"), Size: 200}
+
+	amd64Manifest := fetcher.add(t, v1.MediaTypeImageManifest, v1.Manifest{Config: config, Layers: []v1.Descriptor{amd64}})
+	arm64Manifest := fetcher.add(t, v1.MediaTypeImageManifest, v1.Manifest{Config: config, Layers: []v1.Descriptor{arm64}})
+	index := fetcher.add(t, v1.MediaTypeImageIndex, v1.Index{Manifests: []v1.Descriptor{amd64Manifest, arm64Manifest}})
+
+	blobs := make(map[digest.Digest]int64)
+	if err := collectBlobs(context.Background(), fetcher, index, blobs); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// The config shared by the two manifests is counted once.
+	if len(blobs) != 6 {
+		t.Errorf("expected 6 blobs, got %d", len(blobs))
+	}
+	expected := index.Size + amd64Manifest.Size + arm64Manifest.Size + config.Size + amd64.Size + arm64.Size
+	if size := sumSizes(blobs); size != expected {
+		t.Errorf("expected size %d, got %d", expected, size)
+	}
+
+	// Visiting a manifest already collected does not change the result.
+	if err := collectBlobs(context.Background(), fetcher, amd64Manifest, blobs); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if size := sumSizes(blobs); size != expected {
+		t.Errorf("expected size %d, got %d", expected, size)
+	}
+}
+
+func TestCollectBlobsMissing(t *testing.T) {
+	manifest := v1.Descriptor{MediaType: v1.MediaTypeImageManifest, Digest: digest.FromString("missing"), Size: 1}
+
+	if err := collectBlobs(context.Background(), memoryFetcher{}, manifest, make(map[digest.Digest]int64)); err == nil {
+		t.Error("expected error for a missing manifest")
+	}
+}
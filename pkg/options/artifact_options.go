@@ -16,12 +16,16 @@ package options
 
 import (
 	"fmt"
-	"regexp"
+	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/blang/semver"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 
 	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/oci/pusher"
 )
 
 // ArtifactOptions artifact specific options. Commands that need these options
@@ -31,40 +35,183 @@ type ArtifactOptions struct {
 	Platforms        []string // orders matter (same as args)
 	Dependencies     []string
 	Tags             []string
+	TagsFromGit      bool
+	Version          string
+	VersionTags      []string
+	CheckDeps        bool
 	AnnotationSource string
+	// Annotations are the manifest annotations passed inline, in KEY=VALUE format.
+	Annotations []string
+	// AnnotationFile is a YAML or JSON file holding a map of manifest annotations.
+	AnnotationFile string
+	// ForceAnnotations allows setting annotations reserved to falcoctl.
+	ForceAnnotations bool
+	// LayerTitleFromFilename sets the title annotation of the layers to the base filename of their source.
+	LayerTitleFromFilename bool
+	MediaTypeSet           oci.MediaTypeSet
+	AllowEmpty             bool
+	// Symlinks controls how the symlinks found in directories and glob patterns are packed.
+	Symlinks pusher.SymlinkMode
+	// CompressionLevel is the gzip level of the archives built from directories and glob patterns.
+	CompressionLevel pusher.CompressionLevel
+	// IncludeHidden includes the hidden files found in directories and glob patterns.
+	IncludeHidden bool
+	// FallbackPerPlatform pushes each platform under its own tags when the registry does not support indexes.
+	FallbackPerPlatform bool
+	// DigestAlgorithm is the algorithm of the digests of the blobs and manifests.
+	DigestAlgorithm pusher.DigestAlgorithm
 }
 
-var platformRgx = regexp.MustCompile(`^[a-z]+/[a-z0-9_]+$`)
+// Kinds of tags derived from the version of an artifact.
+const (
+	VersionTagFull   = "full"
+	VersionTagMinor  = "minor"
+	VersionTagMajor  = "major"
+	VersionTagLatest = "latest"
+)
+
+// NormalizePlatforms rewrites the platforms to their normalized form, e.g. "linux/x86_64" to "linux/amd64",
+// so that pushed platforms match the ones of OCI images. It returns an error if a platform is malformed.
+func (art *ArtifactOptions) NormalizePlatforms() error {
+	for i, platform := range art.Platforms {
+		normalized, err := oci.NormalizePlatform(platform)
+		if err != nil {
+			return err
+		}
+		art.Platforms[i] = normalized
+	}
 
-// Validate validates the options passed by the user.
+	return nil
+}
+
+// Validate validates the options passed by the user. The options are not modified.
 func (art *ArtifactOptions) Validate() error {
 	for _, platform := range art.Platforms {
-		if ok := platformRgx.MatchString(platform); !ok {
-			return fmt.Errorf("platform %q seems to be in the wrong format: needs to be in OS/ARCH "+
-				"and to satisfy the following regexp %s", platform, platformRgx.String())
+		if _, err := oci.ParsePlatform(platform); err != nil {
+			return err
 		}
 	}
 	// TODO: cannot check that len(platforms) matches len(filepaths) here
 
+	if art.Version != "" {
+		if _, err := semver.Parse(art.Version); err != nil {
+			return fmt.Errorf("version %q is not a valid semver string: %w", art.Version, err)
+		}
+	}
+
+	for _, kind := range art.VersionTags {
+		switch kind {
+		case VersionTagFull, VersionTagMinor, VersionTagMajor, VersionTagLatest:
+		default:
+			return fmt.Errorf("version tag %q not supported, allowed values: %q, %q, %q, %q",
+				kind, VersionTagFull, VersionTagMinor, VersionTagMajor, VersionTagLatest)
+		}
+	}
+
+	for _, annotation := range art.Annotations {
+		if !strings.Contains(annotation, "=") {
+			return fmt.Errorf("annotation %q seems to be in the wrong format: needs to be in KEY=VALUE", annotation)
+		}
+	}
+
 	return nil
 }
 
+// LoadAnnotations returns the manifest annotations loaded from the annotation file merged with
+// the inline ones, which win on conflicts. Environment variables in the values, in the ${VAR}
+// or $VAR form, are expanded.
+func (art *ArtifactOptions) LoadAnnotations() (map[string]string, error) {
+	annotations, err := art.rawAnnotations()
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range annotations {
+		if key == "" {
+			return nil, fmt.Errorf("annotation keys cannot be empty")
+		}
+		if strings.HasPrefix(key, oci.ReservedAnnotationPrefix) && !art.ForceAnnotations {
+			return nil, fmt.Errorf("annotation %q is reserved to falcoctl, use --force-annotations to set it anyway", key)
+		}
+		annotations[key] = os.ExpandEnv(value)
+	}
+
+	return annotations, nil
+}
+
+// rawAnnotations returns the manifest annotations loaded from the annotation file merged with
+// the inline ones, which win on conflicts, without expanding the environment variables.
+func (art *ArtifactOptions) rawAnnotations() (map[string]string, error) {
+	annotations := make(map[string]string)
+
+	if art.AnnotationFile != "" {
+		data, err := os.ReadFile(filepath.Clean(art.AnnotationFile))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read annotation file: %w", err)
+		}
+
+		// JSON is a subset of YAML, a single decoder handles both formats.
+		if err = yaml.Unmarshal(data, &annotations); err != nil {
+			return nil, fmt.Errorf("annotation file %q is malformed, expected a map of annotation keys to values: %w",
+				art.AnnotationFile, err)
+		}
+	}
+
+	for _, annotation := range art.Annotations {
+		key, value, _ := strings.Cut(annotation, "=")
+		annotations[key] = value
+	}
+
+	return annotations, nil
+}
+
 // AddFlags registers the artifacts flags.
 func (art *ArtifactOptions) AddFlags(cmd *cobra.Command) error {
+	aliases := "The \"x86_64\" and \"aarch64\" architectures are normalized to \"amd64\" and \"arm64\""
+	if cmd.Name() == "pull" {
+		aliases = "The \"x86_64\" and \"aarch64\" architectures also match \"amd64\" and \"arm64\", and vice versa"
+	}
 	cmd.Flags().StringArrayVar(&art.Platforms, "platform", nil,
-		"os and architecture of the artifact in OS/ARCH format (only for plugins artifacts)")
+		"os and architecture of the artifact in OS/ARCH[/VARIANT] format, e.g. \"linux/amd64\". "+aliases+" (only for plugins artifacts)")
 
-	// Add the "depends-on" flag for "push" command only.
+	// Add the flags handling tags and dependencies checks for "push" command only.
 	switch cmd.Name() {
 	case "push":
 		cmd.Flags().StringArrayVarP(&art.Tags, "tag", "t", nil,
 			"additional artifact tag. Can be repeated multiple times")
 
+		cmd.Flags().BoolVar(&art.TagsFromGit, "tags-from-git", false,
+			`derive additional tags from the git repository in the current directory: the git tag for release builds, "sha-<short>" otherwise`)
+
+		cmd.Flags().StringVar(&art.Version, "version", "",
+			`semver version of the artifact, used to derive additional tags, e.g. "1.2.3" -> "1.2.3", "1.2", "1", "latest"`)
+
+		cmd.Flags().StringSliceVar(&art.VersionTags, "version-tags",
+			[]string{VersionTagFull, VersionTagMinor, VersionTagMajor, VersionTagLatest},
+			`tags derived from --version. Allowed values: "full", "minor", "major", "latest"`)
+
+		cmd.Flags().BoolVar(&art.CheckDeps, "check-deps", false,
+			"verify that the artifact dependencies can be resolved against the configured indexes before pushing")
+
+		cmd.Flags().BoolVar(&art.FallbackPerPlatform, "fallback-per-platform", false,
+			`if the registry does not support OCI image indexes, push each platform under the tags suffixed by it, e.g. "0.1.0-linux-amd64"`)
+	case "pull":
+		if len(art.Platforms) > 1 {
+			return fmt.Errorf("--platform can be specified only one time for pull")
+		}
+	}
+
+	// Add the flags shaping the artifact for both "push" and "digest", which must build it the same way.
+	switch cmd.Name() {
+	case "push", "digest":
 		cmd.Flags().Var(&art.ArtifactType, "type",
-			`type of artifact to be pushed. Allowed values: "rulesfile", "plugin"`)
-		if err := cmd.MarkFlagRequired("type"); err != nil {
-			// this should never happen.
-			return fmt.Errorf("unable to mark flag \"type\" as required: %w", err)
+			`type of artifact to be pushed. Allowed values: "rulesfile", "plugin", "collection"`)
+		// The type of pushed artifacts can be set by a spec file too, it is checked by the push command.
+		if cmd.Name() == "digest" {
+			if err := cmd.MarkFlagRequired("type"); err != nil {
+				// this should never happen.
+				return fmt.Errorf("unable to mark flag \"type\" as required: %w", err)
+			}
 		}
 
 		cmd.Flags().StringArrayVarP(&art.Dependencies, "depends-on", "d", nil,
@@ -72,10 +219,43 @@ func (art *ArtifactOptions) AddFlags(cmd *cobra.Command) error {
 
 		cmd.Flags().StringVar(&art.AnnotationSource, "annotation-source", "",
 			`set annotation source for the artifact`)
-	case "pull":
-		if len(art.Platforms) > 1 {
-			return fmt.Errorf("--platform can be specified only one time for pull")
-		}
+
+		cmd.Flags().StringArrayVarP(&art.Annotations, "annotation", "a", nil,
+			`set a manifest annotation in KEY=VALUE format, environment variables in VALUE are expanded. Can be repeated multiple times`)
+
+		cmd.Flags().StringVar(&art.AnnotationFile, "annotation-file", "",
+			"YAML or JSON file holding a map of manifest annotations, merged with --annotation which wins on conflicts")
+
+		cmd.Flags().BoolVar(&art.ForceAnnotations, "force-annotations", false,
+			fmt.Sprintf("allow setting annotations with the %q prefix, reserved to falcoctl", oci.ReservedAnnotationPrefix))
+
+		cmd.Flags().BoolVar(&art.LayerTitleFromFilename, "layer-annotations-from-filename", true,
+			"set the title annotation of each layer to the base filename of its source file or directory")
+
+		cmd.Flags().BoolVar(&art.AllowEmpty, "allow-empty", false,
+			"allow directories and glob patterns resolving to no files, pushing them as empty archives")
+
+		art.Symlinks = pusher.SymlinksPreserve
+		cmd.Flags().Var(&art.Symlinks, "symlinks",
+			`how symlinks in directories and glob patterns are packed: "preserve" stores them as symlinks, `+
+				`"follow" stores the content of their target, "error" fails. Allowed values: "preserve", "follow", "error"`)
+
+		cmd.Flags().BoolVar(&art.IncludeHidden, "include-hidden", true,
+			`include the hidden files, whose name begins with ".", found in directories and glob patterns. When false, `+
+				`hidden directories are skipped too and glob patterns only match hidden files if their name pattern begins with "."`)
+
+		art.CompressionLevel = pusher.DefaultCompressionLevel
+		cmd.Flags().Var(&art.CompressionLevel, "compression-level",
+			`gzip compression level of the archives built from directories and glob patterns, from 0 to 9 or "fast", "best". `+
+				`It changes the digest of the artifact`)
+
+		art.MediaTypeSet = oci.OCIMediaTypes
+		cmd.Flags().Var(&art.MediaTypeSet, "media-type-set",
+			`media types used for the manifests, configs and layers of the artifact. Allowed values: "oci", "docker"`)
+
+		art.DigestAlgorithm = pusher.DigestSHA256
+		cmd.Flags().Var(&art.DigestAlgorithm, "digest-algorithm",
+			`algorithm of the digests of the blobs and manifests, "sha512" is not supported by all the registries. Allowed values: "sha256", "sha512"`)
 	}
 
 	return nil
@@ -0,0 +1,167 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizePlatforms(t *testing.T) {
+	art := &ArtifactOptions{Platforms: []string{"linux/x86_64", "linux/aarch64", "darwin/arm64"}}
+
+	if err := art.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Validate does not modify the options.
+	if expected := []string{"linux/x86_64", "linux/aarch64", "darwin/arm64"}; !reflect.DeepEqual(art.Platforms, expected) {
+		t.Errorf("expected platforms %v after Validate, got %v", expected, art.Platforms)
+	}
+
+	if err := art.NormalizePlatforms(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"linux/amd64", "linux/arm64", "darwin/arm64"}; !reflect.DeepEqual(art.Platforms, expected) {
+		t.Errorf("expected platforms %v, got %v", expected, art.Platforms)
+	}
+
+	for _, art := range []*ArtifactOptions{{Platforms: []string{"linux-amd64"}}, {Platforms: []string{"linux/"}}} {
+		if err := art.Validate(); err == nil {
+			t.Errorf("expected an error validating platforms %v", art.Platforms)
+		}
+		if err := art.NormalizePlatforms(); err == nil {
+			t.Errorf("expected an error normalizing platforms %v", art.Platforms)
+		}
+	}
+}
+
+func writeAnnotationFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return path
+}
+
+func TestLoadAnnotations(t *testing.T) {
+	t.Setenv("FALCOCTL_TEST_JOB", "42")
+	if err := os.Unsetenv("FALCOCTL_TEST_UNSET"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	yamlFile := writeAnnotationFile(t, "annotations.yaml", `
+org.opencontainers.image.title: from-file
+org.opencontainers.image.description: built by job ${FALCOCTL_TEST_JOB}
+com.example.unset: "${FALCOCTL_TEST_UNSET}"
+`)
+	jsonFile := writeAnnotationFile(t, "annotations.json", `{"com.example.team": "security"}`)
+	reservedFile := writeAnnotationFile(t, "reserved.yaml", "io.falcosecurity.artifact.type: rulesfile\n")
+	malformedFile := writeAnnotationFile(t, "malformed.yaml", "- not\n- a map\n")
+
+	testCases := []struct {
+		descr    string
+		art      ArtifactOptions
+		expected map[string]string
+		wantErr  bool
+	}{
+		{
+			descr:    "no annotations",
+			expected: map[string]string{},
+		},
+		{
+			descr: "file merged with flags, flags win",
+			art: ArtifactOptions{
+				AnnotationFile: yamlFile,
+				Annotations:    []string{"org.opencontainers.image.title=from-flag", "com.example.extra=a=b"},
+			},
+			expected: map[string]string{
+				"org.opencontainers.image.title":       "from-flag",
+				"org.opencontainers.image.description": "built by job 42",
+				"com.example.unset":                    "",
+				"com.example.extra":                    "a=b",
+			},
+		},
+		{
+			descr:    "json file",
+			art:      ArtifactOptions{AnnotationFile: jsonFile},
+			expected: map[string]string{"com.example.team": "security"},
+		},
+		{
+			descr:    "environment variables in flags",
+			art:      ArtifactOptions{Annotations: []string{"com.example.job=$FALCOCTL_TEST_JOB", "com.example.unset=x${FALCOCTL_TEST_UNSET}y"}},
+			expected: map[string]string{"com.example.job": "42", "com.example.unset": "xy"},
+		},
+		{
+			descr: "standard org.opencontainers keys are not reserved",
+			art:   ArtifactOptions{Annotations: []string{"org.opencontainers.image.source=https://github.com/falcosecurity/rules"}},
+			expected: map[string]string{
+				"org.opencontainers.image.source": "https://github.com/falcosecurity/rules",
+			},
+		},
+		{
+			descr:   "reserved key in flags",
+			art:     ArtifactOptions{Annotations: []string{"io.falcosecurity.artifact.type=rulesfile"}},
+			wantErr: true,
+		},
+		{
+			descr:   "reserved key in file",
+			art:     ArtifactOptions{AnnotationFile: reservedFile},
+			wantErr: true,
+		},
+		{
+			descr:    "reserved key forced",
+			art:      ArtifactOptions{AnnotationFile: reservedFile, ForceAnnotations: true},
+			expected: map[string]string{"io.falcosecurity.artifact.type": "rulesfile"},
+		},
+		{
+			descr:   "empty key",
+			art:     ArtifactOptions{Annotations: []string{"=value"}},
+			wantErr: true,
+		},
+		{
+			descr:   "malformed file",
+			art:     ArtifactOptions{AnnotationFile: malformedFile},
+			wantErr: true,
+		},
+		{
+			descr:   "missing file",
+			art:     ArtifactOptions{AnnotationFile: filepath.Join(t.TempDir(), "missing.yaml")},
+			wantErr: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := &testCases[i]
+		t.Run(tc.descr, func(t *testing.T) {
+			annotations, err := tc.art.LoadAnnotations()
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got annotations %v", annotations)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(annotations, tc.expected) {
+				t.Errorf("expected annotations %v, got %v", tc.expected, annotations)
+			}
+		})
+	}
+}
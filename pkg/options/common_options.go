@@ -16,9 +16,11 @@ package options
 
 import (
 	"io"
+	"os"
 
 	"github.com/spf13/pflag"
 
+	"github.com/falcosecurity/falcoctl/pkg/oci"
 	"github.com/falcosecurity/falcoctl/pkg/output"
 )
 
@@ -35,6 +37,17 @@ type CommonOptions struct {
 	writer io.Writer
 	// Used to store the verbose flag, and then passed to the printer.
 	verbose bool
+	// Output is the format used by commands to print their results.
+	Output output.Format
+	// Headers are the additional headers, in KEY=VALUE format, sent with the requests to the registry hosts.
+	Headers []string
+	// Strict is true if warnings are treated as errors.
+	Strict bool
+	// RegistryFlavor is the flavor of the contacted registries, detected for each of them if "auto".
+	RegistryFlavor oci.RegistryFlavor
+	// AllowedRegistries and DeniedRegistries override the registry policy of the config file, when set.
+	AllowedRegistries []string
+	DeniedRegistries  []string
 }
 
 // NewOptions returns a new CommonOptions struct.
@@ -68,9 +81,54 @@ func (o *CommonOptions) Initialize(cfgs ...Configs) {
 
 	// create the printer. The value of verbose is a flag value.
 	o.Printer = output.NewPrinter(o.printerScope, o.verbose, o.writer)
+	if o.Strict {
+		o.Printer.WarningsAsErrors()
+	}
+
+	// Keep stdout clean when the results are consumed by machines.
+	if o.Output.IsStructured() && o.writer == nil {
+		o.Printer.RedirectLogs(os.Stderr)
+	}
 }
 
 // AddFlags registers the common flags.
 func (o *CommonOptions) AddFlags(flags *pflag.FlagSet) {
 	flags.BoolVarP(&o.verbose, "verbose", "v", false, "Enable verbose logs (default false)")
 }
+
+// AddHeaderFlags registers the flags used to set additional headers of the registry requests.
+func (o *CommonOptions) AddHeaderFlags(flags *pflag.FlagSet) {
+	flags.StringArrayVar(&o.Headers, "header", nil,
+		"additional header, in KEY=VALUE format, sent with the requests to the registry hosts (advanced). Can be repeated multiple times")
+}
+
+// AddRegistryFlavorFlags registers the flags used to set the flavor of the contacted registries.
+func (o *CommonOptions) AddRegistryFlavorFlags(flags *pflag.FlagSet) {
+	o.RegistryFlavor = oci.FlavorAuto
+	flags.Var(&o.RegistryFlavor, "registry-flavor",
+		`flavor of the contacted registries, tuning the handling of their quirks. Allowed values: "auto", "generic", "harbor", "ghcr", "ecr", "zot"`)
+	flags.Var(&o.RegistryFlavor, "repository-type", "alias of --registry-flavor")
+	_ = flags.MarkHidden("repository-type")
+}
+
+// AddRegistryPolicyFlags registers the flags used to override the registry policy of the config file.
+func (o *CommonOptions) AddRegistryPolicyFlags(flags *pflag.FlagSet) {
+	flags.StringSliceVar(&o.AllowedRegistries, "allowed-registries", nil,
+		"patterns of the only registries that can be contacted, e.g. \"ghcr.io,*.corp\". Overrides the config file")
+	flags.StringSliceVar(&o.DeniedRegistries, "denied-registries", nil,
+		"patterns of the registries that cannot be contacted, even if allowed. Overrides the config file")
+}
+
+// AddStrictFlags registers the flags used to treat warnings as errors.
+func (o *CommonOptions) AddStrictFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.Strict, "strict", false,
+		"treat warnings as errors: the command exits with a non-zero exit code if any warning is printed")
+	flags.BoolVar(&o.Strict, "warnings-as-errors", false, "alias of --strict")
+	_ = flags.MarkHidden("warnings-as-errors")
+}
+
+// AddOutputFlags registers the flags used to select the output format.
+func (o *CommonOptions) AddOutputFlags(flags *pflag.FlagSet) {
+	o.Output = output.Text
+	flags.Var(&o.Output, "output", `output format. Allowed values: "text", "json", "yaml", "go-template=TEMPLATE", e.g. "go-template={{.Digest}}"`)
+}
@@ -0,0 +1,247 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+)
+
+// PushSpec is the declarative form of a push: the reference, the files and the options of the artifact.
+// Relative paths of files are relative to the directory of the spec file.
+type PushSpec struct {
+	Ref   string   `yaml:"ref"`
+	Type  string   `yaml:"type"`
+	Files []string `yaml:"files"`
+	// Platforms are the platforms of the files, in the same order, for plugins.
+	Platforms    []string `yaml:"platforms,omitempty"`
+	Tags         []string `yaml:"tags,omitempty"`
+	TagsFromGit  bool     `yaml:"tags_from_git,omitempty"`
+	Version      string   `yaml:"version,omitempty"`
+	VersionTags  []string `yaml:"version_tags,omitempty"`
+	Dependencies []string `yaml:"dependencies,omitempty"`
+	CheckDeps    bool     `yaml:"check_deps,omitempty"`
+	// Annotations are the manifest annotations, environment variables in the values are expanded when pushing.
+	Annotations      map[string]string `yaml:"annotations,omitempty"`
+	AnnotationSource string            `yaml:"annotation_source,omitempty"`
+	ForceAnnotations bool              `yaml:"force_annotations,omitempty"`
+	// Signature is the file holding the detached signature attached to the artifact.
+	Signature string        `yaml:"signature,omitempty"`
+	Build     PushSpecBuild `yaml:"build"`
+}
+
+// PushSpecBuild holds the options shaping the content of the artifact, hence its digest. Unset options
+// take the default values of the push command flags.
+type PushSpecBuild struct {
+	MediaTypeSet                 string `yaml:"media_type_set,omitempty"`
+	DigestAlgorithm              string `yaml:"digest_algorithm,omitempty"`
+	CompressionLevel             string `yaml:"compression_level,omitempty"`
+	Symlinks                     string `yaml:"symlinks,omitempty"`
+	IncludeHidden                *bool  `yaml:"include_hidden,omitempty"`
+	LayerAnnotationsFromFilename *bool  `yaml:"layer_annotations_from_filename,omitempty"`
+	AllowEmpty                   bool   `yaml:"allow_empty,omitempty"`
+	FallbackPerPlatform          bool   `yaml:"fallback_per_platform,omitempty"`
+}
+
+// NewPushSpec returns the spec of the push of the artifact with the given options, reference, files and detached
+// signature, to be written to specPath. The annotations are stored inline, before the expansion of the environment
+// variables, and the relative paths of the files are rebased on the directory of specPath.
+func NewPushSpec(art *ArtifactOptions, ref string, files []string, signature, specPath string) (*PushSpec, error) {
+	annotations, err := art.rawAnnotations()
+	if err != nil {
+		return nil, err
+	}
+
+	includeHidden, layerTitleFromFilename := art.IncludeHidden, art.LayerTitleFromFilename
+	spec := &PushSpec{
+		Ref:              ref,
+		Type:             art.ArtifactType.String(),
+		Platforms:        art.Platforms,
+		Tags:             art.Tags,
+		TagsFromGit:      art.TagsFromGit,
+		Version:          art.Version,
+		VersionTags:      art.VersionTags,
+		Dependencies:     art.Dependencies,
+		CheckDeps:        art.CheckDeps,
+		Annotations:      annotations,
+		AnnotationSource: art.AnnotationSource,
+		ForceAnnotations: art.ForceAnnotations,
+		Build: PushSpecBuild{
+			MediaTypeSet:                 art.MediaTypeSet.String(),
+			DigestAlgorithm:              art.DigestAlgorithm.String(),
+			CompressionLevel:             art.CompressionLevel.String(),
+			Symlinks:                     art.Symlinks.String(),
+			IncludeHidden:                &includeHidden,
+			LayerAnnotationsFromFilename: &layerTitleFromFilename,
+			AllowEmpty:                   art.AllowEmpty,
+			FallbackPerPlatform:          art.FallbackPerPlatform,
+		},
+	}
+
+	specDir, err := filepath.Abs(filepath.Dir(specPath))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		if isFileSource(art.ArtifactType, file) {
+			if file, err = rebase(file, "", specDir); err != nil {
+				return nil, err
+			}
+		}
+		spec.Files = append(spec.Files, file)
+	}
+
+	if signature != "" {
+		if spec.Signature, err = rebase(signature, "", specDir); err != nil {
+			return nil, err
+		}
+	}
+
+	return spec, nil
+}
+
+// LoadPushSpec reads the push spec at path.
+func LoadPushSpec(path string) (*PushSpec, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read push spec: %w", err)
+	}
+
+	var spec PushSpec
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err = decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("push spec %q is malformed: %w", path, err)
+	}
+
+	if spec.Ref == "" || spec.Type == "" || len(spec.Files) == 0 {
+		return nil, fmt.Errorf("push spec %q is malformed: ref, type and files must be set", path)
+	}
+
+	return &spec, nil
+}
+
+// Write writes the push spec to path.
+func (s *PushSpec) Write(path string) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("cannot marshal push spec: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Apply sets the artifact options to the ones of the spec, loaded from specPath, on top of their current values,
+// i.e. the defaults of the flags. It returns the reference, the files and the detached signature to be pushed,
+// with the relative paths resolved against the directory of specPath.
+func (s *PushSpec) Apply(art *ArtifactOptions, specPath string) (ref string, files []string, signature string, err error) {
+	if err = art.ArtifactType.Set(s.Type); err != nil {
+		return "", nil, "", fmt.Errorf("type %q: %w", s.Type, err)
+	}
+
+	art.Platforms = s.Platforms
+	art.Tags = s.Tags
+	art.TagsFromGit = s.TagsFromGit
+	art.Version = s.Version
+	if s.VersionTags != nil {
+		art.VersionTags = s.VersionTags
+	}
+	art.Dependencies = s.Dependencies
+	art.CheckDeps = s.CheckDeps
+	art.AnnotationSource = s.AnnotationSource
+	art.ForceAnnotations = s.ForceAnnotations
+	art.AnnotationFile = ""
+	art.Annotations = nil
+	for key, value := range s.Annotations {
+		art.Annotations = append(art.Annotations, key+"="+value)
+	}
+	sort.Strings(art.Annotations)
+
+	b := s.Build
+	for _, v := range []struct {
+		name  string
+		value string
+		set   func(string) error
+	}{
+		{"media_type_set", b.MediaTypeSet, art.MediaTypeSet.Set},
+		{"digest_algorithm", b.DigestAlgorithm, art.DigestAlgorithm.Set},
+		{"compression_level", b.CompressionLevel, art.CompressionLevel.Set},
+		{"symlinks", b.Symlinks, art.Symlinks.Set},
+	} {
+		if v.value == "" {
+			continue
+		}
+		if err = v.set(v.value); err != nil {
+			return "", nil, "", fmt.Errorf("%s %q: %w", v.name, v.value, err)
+		}
+	}
+	if b.IncludeHidden != nil {
+		art.IncludeHidden = *b.IncludeHidden
+	}
+	if b.LayerAnnotationsFromFilename != nil {
+		art.LayerTitleFromFilename = *b.LayerAnnotationsFromFilename
+	}
+	art.AllowEmpty = b.AllowEmpty
+	art.FallbackPerPlatform = b.FallbackPerPlatform
+
+	specDir := filepath.Dir(specPath)
+	for _, file := range s.Files {
+		if isFileSource(art.ArtifactType, file) && !filepath.IsAbs(file) {
+			file = filepath.Join(specDir, file)
+		}
+		files = append(files, file)
+	}
+
+	signature = s.Signature
+	if signature != "" && !filepath.IsAbs(signature) {
+		signature = filepath.Join(specDir, signature)
+	}
+
+	return s.Ref, files, signature, nil
+}
+
+// isFileSource returns true if source is a local file, directory or glob pattern, rather than a blob
+// referenced by digest or a member of a collection.
+func isFileSource(artifactType oci.ArtifactType, source string) bool {
+	return artifactType != oci.Collection && !strings.HasPrefix(source, "@")
+}
+
+// rebase returns path, relative to from or to the current directory if empty, relative to dir. Absolute paths
+// are returned as they are.
+func rebase(path, from, dir string) (string, error) {
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+
+	abs, err := filepath.Abs(filepath.Join(from, path))
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+)
+
+func TestPushSpecRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "specs", "push.yaml")
+	rules := filepath.Join(dir, "rules.yaml")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Relative paths are relative to the current directory when pushing, and to the spec file in the spec.
+	relRules, err := filepath.Rel(wd, rules)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	art := &ArtifactOptions{
+		ArtifactType: oci.Rulesfile,
+		Tags:         []string{"latest"},
+		Version:      "1.2.3",
+		Dependencies: []string{"myplugin:1.0.0"},
+		Annotations:  []string{"org.opencontainers.image.description=rules built by ${CI_JOB_ID}"},
+		MediaTypeSet: oci.DockerMediaTypes,
+	}
+
+	spec, err := NewPushSpec(art, "localhost:5000/rules:1.2.3", []string{relRules, "@sha256:123abc"}, "", specPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := []string{"../rules.yaml", "@sha256:123abc"}; !reflect.DeepEqual(spec.Files, expected) {
+		t.Errorf("expected files %v, got %v", expected, spec.Files)
+	}
+
+	if err = spec.Write(filepath.Join(dir, "push.yaml")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, err := LoadPushSpec(filepath.Join(dir, "push.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	applied := &ArtifactOptions{}
+	ref, files, _, err := loaded.Apply(applied, specPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if ref != "localhost:5000/rules:1.2.3" {
+		t.Errorf("unexpected reference %q", ref)
+	}
+	if expected := []string{rules, "@sha256:123abc"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected files %v, got %v", expected, files)
+	}
+	if !reflect.DeepEqual(applied.Annotations, art.Annotations) {
+		t.Errorf("expected annotations %v, got %v", art.Annotations, applied.Annotations)
+	}
+	if applied.ArtifactType != oci.Rulesfile || applied.Version != "1.2.3" || applied.MediaTypeSet != oci.DockerMediaTypes {
+		t.Errorf("unexpected options %+v", applied)
+	}
+}
+
+func TestLoadPushSpecMalformed(t *testing.T) {
+	dir := t.TempDir()
+	spec := &PushSpec{Ref: "localhost:5000/rules:latest", Type: "rulesfile"}
+	path := filepath.Join(dir, "push.yaml")
+	if err := spec.Write(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := LoadPushSpec(path); err == nil {
+		t.Errorf("expected error for a spec without files")
+	}
+}
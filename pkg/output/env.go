@@ -0,0 +1,111 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// envFileDelimiter is the delimiter of the multi-line values in env files, extended if found in the value.
+const envFileDelimiter = "FALCOCTL_EOF"
+
+var (
+	envUnsafeRgx = regexp.MustCompile(`[^A-Za-z0-9_./:@+,=-]`)
+	envNameRgx   = regexp.MustCompile(`[^A-Z0-9]+`)
+)
+
+// EnvName converts key into an environment variable name with the given prefix,
+// e.g. ("ARTIFACT", "config.mediaType") -> "ARTIFACT_CONFIG_MEDIA_TYPE".
+func EnvName(prefix, key string) string {
+	var b strings.Builder
+	var prev rune
+	for _, r := range key {
+		if unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+		prev = r
+	}
+
+	name := strings.Trim(envNameRgx.ReplaceAllString(b.String(), "_"), "_")
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "_" + name
+}
+
+// EnvLines returns vars as KEY=value lines, sorted by key, suitable for being sourced by a shell.
+// Values containing characters interpreted by the shell are single-quoted.
+func EnvLines(vars map[string]string) []string {
+	keys := sortedKeys(vars)
+	lines := make([]string, 0, len(keys))
+	for _, k := range keys {
+		lines = append(lines, k+"="+shellQuote(vars[k]))
+	}
+
+	return lines
+}
+
+// EnvFileLines returns vars as the lines of an env file, such as $GITHUB_ENV, sorted by key. Env files
+// are not interpreted by a shell, hence values are written as they are in KEY=value lines, except the
+// multi-line ones, written in the KEY<<DELIMITER heredoc form.
+func EnvFileLines(vars map[string]string) []string {
+	keys := sortedKeys(vars)
+	lines := make([]string, 0, len(keys))
+	for _, k := range keys {
+		v := vars[k]
+		if !strings.ContainsAny(v, "\r\n") {
+			lines = append(lines, k+"="+v)
+			continue
+		}
+
+		delimiter := envFileDelimiter
+		for strings.Contains(v, delimiter) {
+			delimiter += "_"
+		}
+		lines = append(lines, k+"<<"+delimiter, v, delimiter)
+	}
+
+	return lines
+}
+
+// PrintEnv prints vars as KEY=value lines, see EnvLines.
+func (p *Printer) PrintEnv(vars map[string]string) {
+	for _, l := range EnvLines(vars) {
+		p.DefaultText.Printfln("%s", l)
+	}
+}
+
+func shellQuote(s string) string {
+	if s != "" && !envUnsafeRgx.MatchString(s) {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func sortedKeys(vars map[string]string) []string {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Env", func() {
+	vars := map[string]string{
+		"ARTIFACT_TITLE":       "cloudtrail",
+		"ARTIFACT_URL":         "https://github.com/falcosecurity/plugins?tab=readme#cloudtrail",
+		"ARTIFACT_DESCRIPTION": "Read CloudTrail logs.\nEvents are read from S3.",
+	}
+
+	Context("printing lines for a shell", func() {
+		It("should quote the values interpreted by the shell", func() {
+			Expect(EnvLines(vars)).To(Equal([]string{
+				"ARTIFACT_DESCRIPTION='Read CloudTrail logs.\nEvents are read from S3.'",
+				"ARTIFACT_TITLE=cloudtrail",
+				"ARTIFACT_URL='https://github.com/falcosecurity/plugins?tab=readme#cloudtrail'",
+			}))
+		})
+	})
+
+	Context("writing lines for an env file", func() {
+		It("should not quote the values and write multi-line annotations as heredocs", func() {
+			Expect(EnvFileLines(vars)).To(Equal([]string{
+				"ARTIFACT_DESCRIPTION<<FALCOCTL_EOF",
+				"Read CloudTrail logs.\nEvents are read from S3.",
+				"FALCOCTL_EOF",
+				"ARTIFACT_TITLE=cloudtrail",
+				"ARTIFACT_URL=https://github.com/falcosecurity/plugins?tab=readme#cloudtrail",
+			}))
+		})
+
+		It("should use a delimiter not found in the value", func() {
+			Expect(EnvFileLines(map[string]string{"ARTIFACT_NOTES": "first\nFALCOCTL_EOF\nlast"})).To(Equal([]string{
+				"ARTIFACT_NOTES<<FALCOCTL_EOF_",
+				"first\nFALCOCTL_EOF\nlast",
+				"FALCOCTL_EOF_",
+			}))
+		})
+	})
+})
@@ -0,0 +1,139 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format represents the format used by commands to print their results.
+type Format string
+
+const (
+	// Text is the human readable format.
+	Text Format = "text"
+	// JSON prints the results as JSON documents.
+	JSON Format = "json"
+	// YAML prints the results as YAML documents.
+	YAML Format = "yaml"
+)
+
+// GoTemplatePrefix is the prefix of the formats rendering the results through a Go template,
+// e.g. "go-template={{.Digest}}". The template is executed on the result structs, using their field names.
+const GoTemplatePrefix = "go-template="
+
+// The following functions are necessary to use Format with Cobra.
+
+// String returns a string representation of Format.
+func (f *Format) String() string {
+	return string(*f)
+}
+
+// Set a Format.
+func (f *Format) Set(v string) error {
+	switch {
+	case v == "text", v == "json", v == "yaml":
+	case strings.HasPrefix(v, GoTemplatePrefix):
+		if _, err := Format(v).template(); err != nil {
+			return err
+		}
+	default:
+		return errors.New(`must be one of "text", "json", "yaml", "go-template=TEMPLATE"`)
+	}
+
+	*f = Format(v)
+	return nil
+}
+
+// Type returns a string representing this type.
+func (f *Format) Type() string {
+	return "Format"
+}
+
+// IsStructured returns true if the format is meant to be consumed by machines.
+func (f Format) IsStructured() bool {
+	return f == JSON || f == YAML || f.IsGoTemplate()
+}
+
+// IsGoTemplate returns true if the format renders the results through a Go template.
+func (f Format) IsGoTemplate() bool {
+	return strings.HasPrefix(string(f), GoTemplatePrefix)
+}
+
+// template parses the Go template of the format. Besides the builtin functions, "json" marshals its
+// argument to JSON, e.g. "{{json .Config}}".
+func (f Format) template() (*template.Template, error) {
+	tmpl, err := template.New("output").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			out, err := json.Marshal(v)
+			return string(out), err
+		},
+	}).Parse(strings.TrimPrefix(string(f), GoTemplatePrefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid go-template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// PrintData is a helper used to print data in a structured format.
+func (p *Printer) PrintData(format Format, data interface{}) error {
+	var out []byte
+	var err error
+
+	switch format {
+	case JSON:
+		out, err = json.MarshalIndent(data, "", "  ")
+	case YAML:
+		out, err = yaml.Marshal(data)
+	default:
+		if format.IsGoTemplate() {
+			return p.printTemplate(format, data)
+		}
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+
+	if err != nil {
+		return fmt.Errorf("cannot marshal data to %s: %w", format, err)
+	}
+
+	p.DefaultText.Println(strings.TrimRight(string(out), "\n"))
+
+	return nil
+}
+
+// printTemplate renders data through the Go template of format.
+func (p *Printer) printTemplate(format Format, data interface{}) error {
+	tmpl, err := format.template()
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if err = tmpl.Execute(&out, data); err != nil {
+		return fmt.Errorf("cannot execute go-template: %w", err)
+	}
+
+	p.DefaultText.Printfln("%s", strings.TrimRight(out.String(), "\n"))
+
+	return nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pterm/pterm"
+)
+
+var _ = Describe("Format", func() {
+	var (
+		printer      *Printer
+		format       Format
+		customWriter *bytes.Buffer
+		err          error
+		data         = struct {
+			Digest string `json:"digest" yaml:"digest"`
+		}{Digest: "sha256:123"}
+	)
+
+	JustBeforeEach(func() {
+		customWriter = &bytes.Buffer{}
+		printer = NewPrinter("", false, customWriter)
+		err = printer.PrintData(format, data)
+	})
+
+	Context("json format", func() {
+		BeforeEach(func() {
+			format = JSON
+		})
+
+		It("should print the data as json", func() {
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(customWriter.String()).Should(ContainSubstring(`"digest": "sha256:123"`))
+		})
+	})
+
+	Context("yaml format", func() {
+		BeforeEach(func() {
+			format = YAML
+		})
+
+		It("should print the data as yaml", func() {
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(customWriter.String()).Should(ContainSubstring("digest: sha256:123"))
+		})
+	})
+
+	Context("text format", func() {
+		BeforeEach(func() {
+			format = Text
+		})
+
+		It("should return an error", func() {
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("go-template format", func() {
+		BeforeEach(func() {
+			format = Format(GoTemplatePrefix + "digest={{.Digest}} {{json .}}")
+		})
+
+		It("should render the data through the template", func() {
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(customWriter.String()).Should(Equal("digest=sha256:123 {\"digest\":\"sha256:123\"}\n"))
+		})
+	})
+
+	Context("go-template format with a missing field", func() {
+		BeforeEach(func() {
+			format = Format(GoTemplatePrefix + "{{.Ref}}")
+		})
+
+		It("should return an execution error", func() {
+			Expect(err).Should(MatchError(ContainSubstring("cannot execute go-template")))
+		})
+	})
+
+	Context("setting the format from a flag", func() {
+		It("should reject unknown formats", func() {
+			var f Format
+			Expect(f.Set("yaml")).Should(Succeed())
+			Expect(f).Should(Equal(YAML))
+			Expect(f.Set("xml")).ShouldNot(Succeed())
+		})
+
+		It("should validate go-templates", func() {
+			var f Format
+			Expect(f.Set(GoTemplatePrefix + "{{.Digest}}")).Should(Succeed())
+			Expect(f.IsStructured()).Should(BeTrue())
+			Expect(f.Set(GoTemplatePrefix + "{{.Digest")).Should(MatchError(ContainSubstring("invalid go-template")))
+		})
+	})
+})
+
+var _ = Describe("Env", func() {
+	It("should derive environment variable names from keys", func() {
+		Expect(EnvName("ARTIFACT", "digest")).Should(Equal("ARTIFACT_DIGEST"))
+		Expect(EnvName("ARTIFACT", "config.mediaType")).Should(Equal("ARTIFACT_CONFIG_MEDIA_TYPE"))
+		Expect(EnvName("ARTIFACT", "io.falcosecurity.plugin.api-version")).Should(Equal("ARTIFACT_IO_FALCOSECURITY_PLUGIN_API_VERSION"))
+		Expect(EnvName("", "version")).Should(Equal("VERSION"))
+	})
+
+	It("should print sorted and quoted KEY=value lines", func() {
+		customWriter := &bytes.Buffer{}
+		printer := NewPrinter("", false, customWriter)
+		printer.PrintEnv(map[string]string{
+			"B_TITLE":  "it's a plugin",
+			"A_DIGEST": "sha256:123",
+			"C_EMPTY":  "",
+		})
+		Expect(customWriter.String()).Should(Equal("A_DIGEST=sha256:123\nB_TITLE='it'\\''s a plugin'\nC_EMPTY=''\n"))
+	})
+
+	It("should return the KEY=value lines", func() {
+		Expect(EnvLines(map[string]string{"B": "a b", "A": "1"})).Should(Equal([]string{"A=1", "B='a b'"}))
+	})
+})
+
+var _ = Describe("Markdown", func() {
+	It("should render Markdown for the terminal", func() {
+		md := "# Cloudtrail\n\nRead **AWS** events, see [the docs](https://falco.org/docs).\n\n" +
+			"- run `falco`\n```yaml\nplugins: []\n```\n"
+		Expect(pterm.RemoveColorFromString(RenderMarkdown(md))).Should(Equal(
+			"Cloudtrail\n\nRead AWS events, see the docs (https://falco.org/docs).\n\n• run falco\n    plugins: []\n"))
+	})
+})
@@ -0,0 +1,73 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pterm/pterm"
+)
+
+var (
+	mdLinkRgx = regexp.MustCompile(`!?\[([^\]]*)\]\(([^)\s]+)[^)]*\)`)
+	mdBoldRgx = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdCodeRgx = regexp.MustCompile("`([^`]+)`")
+	mdListRgx = regexp.MustCompile(`^(\s*)[-*+]\s+`)
+
+	mdHeadingStyle = pterm.NewStyle(pterm.FgLightCyan, pterm.Bold)
+)
+
+// RenderMarkdown renders md for the terminal: headings, lists, emphasis, inline code and code blocks
+// are styled, links are printed as "text (url)". Any other construct is kept as it is.
+func RenderMarkdown(md string) string {
+	var b strings.Builder
+	var fenced bool
+	for _, line := range strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"), strings.HasPrefix(trimmed, "~~~"):
+			fenced = !fenced
+			continue
+		case fenced:
+			line = "    " + pterm.FgGray.Sprint(line)
+		case strings.HasPrefix(trimmed, "#"):
+			line = mdHeadingStyle.Sprint(renderInline(strings.TrimSpace(strings.TrimLeft(trimmed, "#"))))
+		default:
+			line = renderInline(mdListRgx.ReplaceAllString(line, "$1• "))
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+func renderInline(s string) string {
+	s = mdLinkRgx.ReplaceAllStringFunc(s, func(m string) string {
+		groups := mdLinkRgx.FindStringSubmatch(m)
+		if groups[1] == "" || groups[1] == groups[2] {
+			return pterm.Underscore.Sprint(groups[2])
+		}
+		return groups[1] + " (" + pterm.Underscore.Sprint(groups[2]) + ")"
+	})
+	s = mdBoldRgx.ReplaceAllStringFunc(s, func(m string) string {
+		return pterm.Bold.Sprint(m[2 : len(m)-2])
+	})
+
+	return mdCodeRgx.ReplaceAllStringFunc(s, func(m string) string {
+		return pterm.FgCyan.Sprint(m[1 : len(m)-1])
+	})
+}
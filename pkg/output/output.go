@@ -15,13 +15,18 @@
 package output
 
 import (
+	"bytes"
+	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/pterm/pterm"
+	"golang.org/x/term"
 	"k8s.io/kubectl/pkg/cmd/util"
 )
 
@@ -35,8 +40,47 @@ const (
 	IndexList
 	// ArtifactInfo identifies the header for artifact info.
 	ArtifactInfo
+	// ArtifactUpdates identifies the header for artifact check-updates.
+	ArtifactUpdates
+	// RulesFindings identifies the header for artifact security-scan.
+	RulesFindings
+	// AnnotationCoverage identifies the header for artifact coverage.
+	AnnotationCoverage
+	// PlatformRegressions identifies the header for artifact cross-platform-check.
+	PlatformRegressions
+	// ArtifactReferrers identifies the header for artifact referrers.
+	ArtifactReferrers
+	// ArtifactCompatibility identifies the header for artifact list-compatible.
+	ArtifactCompatibility
+	// VulnerabilitySummary identifies the header for registry scan.
+	VulnerabilitySummary
+	// RepositoryUsage identifies the header for registry usage.
+	RepositoryUsage
+	// UnknownPackages identifies the header for artifact sbom-check.
+	UnknownPackages
+	// ProvenanceChecks identifies the header for artifact provenance-verify.
+	ProvenanceChecks
+	// InTotoSteps identifies the header for artifact in-toto-verify.
+	InTotoSteps
+	// TagSignatures identifies the header for artifact verify-all-tags.
+	TagSignatures
+	// ArtifactComparison identifies the header for artifact compare.
+	ArtifactComparison
+	// DependencyResolution identifies the header for registry resolve-deps.
+	DependencyResolution
+	// ComplianceControls identifies the header for artifact compliance-report.
+	ComplianceControls
+	// MissingDependencies identifies the header for artifact missing-deps.
+	MissingDependencies
 )
 
+// ErrSilentExit is returned by commands that need to exit with a non-zero exit code
+// without printing any error message, e.g. when the exit code itself is the result.
+var ErrSilentExit = errors.New("silent exit")
+
+// ErrWarnings is returned when warnings are treated as errors and at least one warning was printed.
+var ErrWarnings = errors.New("warnings treated as errors")
+
 var spinnerCharset = []string{"⠈⠁", "⠈⠑", "⠈⠱", "⠈⡱", "⢀⡱", "⢄⡱", "⢄⡱", "⢆⡱", "⢎⡱", "⢎⡰", "⢎⡠", "⢎⡀", "⢎⠁", "⠎⠁", "⠊⠁"}
 
 // Printer used by all commands to output messages.
@@ -55,6 +99,24 @@ type Printer struct {
 	Spinner *pterm.SpinnerPrinter
 
 	verbose bool
+	// strict is true if warnings are treated as errors.
+	strict bool
+	// warnings counts the warnings printed when strict is true.
+	warnings int32
+}
+
+// warningCounter counts the warnings written through it to the underlying writer.
+type warningCounter struct {
+	writer io.Writer
+	count  *int32
+}
+
+func (w *warningCounter) Write(p []byte) (int, error) {
+	atomic.AddInt32(w.count, 1)
+	if w.writer == nil {
+		return os.Stdout.Write(p)
+	}
+	return w.writer.Write(p)
 }
 
 // NewPrinter returns a printer ready to be used.
@@ -130,6 +192,39 @@ func NewPrinter(scope string, verbose bool, writer io.Writer) *Printer {
 	return printer
 }
 
+// RedirectLogs sets the writer of all the printers except DefaultText and TablePrinter,
+// which are used to print the results of the commands.
+func (p *Printer) RedirectLogs(writer io.Writer) {
+	p.Info = p.Info.WithWriter(writer)
+	p.Success = p.Success.WithWriter(writer)
+	p.Warning = p.Warning.WithWriter(writer)
+	if p.strict {
+		p.Warning = p.Warning.WithWriter(&warningCounter{writer: writer, count: &p.warnings})
+	}
+	p.Error = p.Error.WithWriter(writer)
+	p.ProgressBar = p.ProgressBar.WithWriter(writer)
+	p.Spinner = p.Spinner.WithWriter(writer)
+
+	p.Spinner.FailPrinter = p.Error
+	p.Spinner.WarningPrinter = p.Warning
+	p.Spinner.SuccessPrinter = p.Info
+}
+
+// WarningsAsErrors makes the printer count the warnings, so that CheckWarnings fails once any is printed.
+func (p *Printer) WarningsAsErrors() {
+	p.strict = true
+	p.Warning = p.Warning.WithWriter(&warningCounter{writer: p.Warning.Writer, count: &p.warnings})
+	p.Spinner.WarningPrinter = p.Warning
+}
+
+// CheckWarnings returns an error wrapping ErrWarnings if warnings are treated as errors and any was printed.
+func (p *Printer) CheckWarnings() error {
+	if n := atomic.LoadInt32(&p.warnings); p.strict && n > 0 {
+		return fmt.Errorf("%w: %d warning(s) printed", ErrWarnings, n)
+	}
+	return nil
+}
+
 // CheckErr prints a user-friendly error and exits with a non-zero exit code.
 // Based on the printer's configuration it will print through it or will use the
 // STDERR.
@@ -138,6 +233,10 @@ func (p *Printer) CheckErr(err error) {
 	case err == nil:
 		return
 
+	// The command already reported its result, just exit.
+	case errors.Is(err, ErrSilentExit):
+		os.Exit(util.DefaultErrorExitCode)
+
 	// Print the error through the spinner, if active.
 	case p != nil && p.Spinner.IsActive:
 		util.BehaviorOnFatal(func(msg string, code int) {
@@ -169,26 +268,102 @@ func (p *Printer) Verbosef(format string, args ...interface{}) {
 	}
 }
 
+// IsInteractive returns true if both the standard input and the standard output are terminals,
+// hence the user can be prompted.
+func (p *Printer) IsInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// Select prompts the user to select one of the given options.
+func (p *Printer) Select(text string, options []string, defaultOption string) (string, error) {
+	return pterm.DefaultInteractiveSelect.
+		WithOptions(options).
+		WithDefaultOption(defaultOption).
+		Show(text)
+}
+
 // PrintTable is a helper used to print data in table format.
 func (p *Printer) PrintTable(header TableHeader, data [][]string) error {
-	var table [][]string
+	columns, err := tableColumns(header)
+	if err != nil {
+		return err
+	}
+
+	table := [][]string{columns}
+	for i := range data {
+		table = append(table, data[i])
+	}
+
+	return p.TablePrinter.WithData(table).Render()
+}
+
+// PrintCSV prints the data as CSV, with the columns of the given table header as first record.
+func (p *Printer) PrintCSV(header TableHeader, data [][]string) error {
+	columns, err := tableColumns(header)
+	if err != nil {
+		return err
+	}
 
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err = w.WriteAll(append([][]string{columns}, data...)); err != nil {
+		return fmt.Errorf("cannot write CSV: %w", err)
+	}
+
+	p.DefaultText.Print(buf.String())
+
+	return nil
+}
+
+// tableColumns returns the names of the columns of a table.
+func tableColumns(header TableHeader) ([]string, error) {
 	switch header {
 	case ArtifactSearch:
-		table = [][]string{{"INDEX", "ARTIFACT", "TYPE", "REGISTRY", "REPOSITORY"}}
+		return []string{"INDEX", "ARTIFACT", "TYPE", "REGISTRY", "REPOSITORY"}, nil
 	case IndexList:
-		table = [][]string{{"NAME", "URL", "ADDED", "UPDATED"}}
+		return []string{"NAME", "URL", "ADDED", "UPDATED"}, nil
 	case ArtifactInfo:
-		table = [][]string{{"REF", "TAGS"}}
+		return []string{"REF", "TAGS"}, nil
+	case ArtifactUpdates:
+		return []string{"NAME", "TYPE", "INSTALLED", "AVAILABLE", "UPDATE"}, nil
+	case RulesFindings:
+		return []string{"RULE", "SEVERITY", "FINDING"}, nil
+	case AnnotationCoverage:
+		return []string{"ANNOTATION", "COVERAGE"}, nil
+	case PlatformRegressions:
+		return []string{"VERSION", "PLATFORMS", "MISSING"}, nil
+	case ArtifactReferrers:
+		return []string{"DIGEST", "MEDIA TYPE", "SIZE"}, nil
+	case ArtifactCompatibility:
+		return []string{"NAME", "TYPE", "VERSION", "REQUIRES", "STATUS"}, nil
+	case VulnerabilitySummary:
+		return []string{"SEVERITY", "COUNT"}, nil
+	case RepositoryUsage:
+		return []string{"TAG", "DIGEST", "BLOBS", "SIZE"}, nil
+	case UnknownPackages:
+		return []string{"NAME", "VERSION", "PURL"}, nil
+	case ProvenanceChecks:
+		return []string{"CHECK", "RESULT", "DETAILS"}, nil
+	case InTotoSteps:
+		return []string{"STEP", "RESULT", "LINKS", "ERROR"}, nil
+	case TagSignatures:
+		return []string{"TAG", "DIGEST", "SIGNED", "DETAILS"}, nil
+	case ArtifactComparison:
+		return []string{"FIELD", "FIRST", "SECOND"}, nil
+	case DependencyResolution:
+		return []string{"DEPENDENCY", "SELECTED", "VERSION", "STATUS"}, nil
+	case ComplianceControls:
+		return []string{"CONTROL", "TITLE", "STATUS", "EVIDENCE"}, nil
+	case MissingDependencies:
+		return []string{"DEPENDENCY", "REASON", "SUGGESTIONS"}, nil
 	default:
-		return fmt.Errorf("unsupported output table")
-	}
-
-	for i := range data {
-		table = append(table, data[i])
+		return nil, fmt.Errorf("unsupported output table")
 	}
+}
 
-	return p.TablePrinter.WithData(table).Render()
+// Highlight returns the text colored to stand out, e.g. in the cells of a table, unless colors are disabled.
+func Highlight(text string) string {
+	return pterm.FgYellow.Sprint(text)
 }
 
 // ExitOnErr aborts the execution in case of errors, without printing any error message.
@@ -110,4 +110,51 @@ var _ = Describe("Output", func() {
 			})
 		})
 	})
+
+	Context("testing CSV output", func() {
+		var customWriter *bytes.Buffer
+
+		BeforeEach(func() {
+			customWriter = &bytes.Buffer{}
+			writer = customWriter
+		})
+
+		It("should print the header and the quoted records", func() {
+			Expect(printer.PrintCSV(TagSignatures, [][]string{{"0.1.0", "sha256:abc", "false", "no signature, expected one"}})).Should(Succeed())
+			Expect(customWriter.String()).Should(Equal("TAG,DIGEST,SIGNED,DETAILS\n0.1.0,sha256:abc,false,\"no signature, expected one\"\n"))
+		})
+	})
+
+	Context("testing warnings treated as errors", func() {
+		var customWriter *bytes.Buffer
+
+		BeforeEach(func() {
+			customWriter = &bytes.Buffer{}
+			writer = customWriter
+		})
+
+		Context("warnings are not treated as errors", func() {
+			It("should not return an error after a warning", func() {
+				printer.Warning.Println("something odd")
+				Expect(printer.CheckWarnings()).Should(Succeed())
+			})
+		})
+
+		Context("warnings are treated as errors", func() {
+			JustBeforeEach(func() {
+				printer.WarningsAsErrors()
+			})
+
+			It("should not return an error without warnings", func() {
+				printer.Info.Println("all good")
+				Expect(printer.CheckWarnings()).Should(Succeed())
+			})
+
+			It("should print the warning and return an error", func() {
+				printer.Warning.Println("something odd")
+				Expect(customWriter.String()).Should(ContainSubstring("something odd"))
+				Expect(printer.CheckWarnings()).Should(MatchError(ErrWarnings))
+			})
+		})
+	})
 })
@@ -18,37 +18,68 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pterm/pterm"
+	"golang.org/x/term"
 	"oras.land/oras-go/v2"
 )
 
+const (
+	// rateSmoothingFactor is the weight given to the most recent sample of the transfer rate.
+	rateSmoothingFactor = 0.3
+	// rateUpdateInterval is the minimum interval between two samples of the transfer rate.
+	rateUpdateInterval = 500 * time.Millisecond
+)
+
 // ProgressTracker tracks the progress of pull and push operations.
 type ProgressTracker struct {
 	oras.Target
 	*Printer
 	msg string
+	// showRate is true when the transfer rate and the ETA are displayed.
+	showRate bool
+	// overall keeps track of the progress of all the layers handled by the tracker.
+	overall *rateEstimator
+	// shared, if set, renders the progress in a bar shared with concurrent transfers.
+	shared *SharedProgress
 }
 
 // NewProgressTracker returns a new ProgressTracker ready to be used.
 func NewProgressTracker(printer *Printer, target oras.Target, msg string) *ProgressTracker {
 	return &ProgressTracker{
-		Target:  target,
-		Printer: printer,
-		msg:     msg,
+		Target:   target,
+		Printer:  printer,
+		msg:      msg,
+		showRate: isTerminal(printer.ProgressBar.Writer),
+		overall:  &rateEstimator{},
 	}
 }
 
 // Push reimplements the Push function of the oras.Target interface adding the needed logic for the progress bar.
 func (t *ProgressTracker) Push(ctx context.Context, expected v1.Descriptor, content io.Reader) error { //nolint:gocritic,lll // needed to implement the oras.Target interface
+	if t.shared != nil {
+		return t.shared.push(ctx, t.Target, &expected, content)
+	}
+
 	d := expected.Digest.Encoded()[:12]
-	progressBar, _ := t.ProgressBar.WithTotal(int(expected.Size)).WithTitle(fmt.Sprintf(" INFO  %s %s:", t.msg, d)).WithShowCount(false).Start()
+	title := fmt.Sprintf(" INFO  %s %s:", t.msg, d)
+	progressBar, _ := t.ProgressBar.WithTotal(int(expected.Size)).WithTitle(title).WithShowCount(false).Start()
+
+	t.overall.addTotal(expected.Size)
 
 	reader := &trackedReader{
 		Reader:      content,
 		descriptor:  expected,
 		progressBar: progressBar,
+		title:       title,
+		showRate:    t.showRate,
+		layer:       &rateEstimator{total: expected.Size},
+		overall:     t.overall,
 	}
 	err := t.Target.Push(ctx, expected, reader)
 	_, _ = progressBar.Stop()
@@ -72,17 +103,243 @@ func (t *ProgressTracker) Exists(ctx context.Context, target v1.Descriptor) (boo
 	return ok, err
 }
 
+// SharedProgress renders the progress of concurrent transfers in a single progress bar, since only one
+// progress bar at a time can be displayed. The title reports the number of layers being transferred.
+type SharedProgress struct {
+	mu      sync.Mutex
+	printer *Printer
+	msg     string
+	bar     *pterm.ProgressbarPrinter
+	active  int
+	overall *rateEstimator
+}
+
+// NewSharedProgress returns a new SharedProgress ready to be used.
+func NewSharedProgress(printer *Printer, msg string) *SharedProgress {
+	return &SharedProgress{
+		printer: printer,
+		msg:     msg,
+		overall: &rateEstimator{},
+	}
+}
+
+// Tracker returns a ProgressTracker for target rendering its progress in the shared progress bar.
+func (s *SharedProgress) Tracker(target oras.Target) *ProgressTracker {
+	t := NewProgressTracker(s.printer, target, s.msg)
+	t.shared = s
+	return t
+}
+
+func (s *SharedProgress) push(ctx context.Context, target oras.Target, expected *v1.Descriptor, content io.Reader) error {
+	s.start(expected.Size)
+	err := target.Push(ctx, *expected, &sharedReader{Reader: content, shared: s})
+	s.stop()
+
+	if err != nil {
+		s.printer.Error.Printfln("unable to %s %s: %s", strings.ToLower(s.msg), expected.Digest.Encoded()[:12], err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *SharedProgress) start(size int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.overall.addTotal(size)
+	s.active++
+	if s.bar == nil {
+		s.bar, _ = s.printer.ProgressBar.WithTotal(int(size)).WithShowCount(false).Start()
+	} else {
+		s.bar.Total += int(size)
+	}
+	s.updateTitle()
+}
+
+func (s *SharedProgress) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.active--
+	if s.active == 0 {
+		_, _ = s.bar.Stop()
+		s.bar = nil
+		return
+	}
+	s.updateTitle()
+}
+
+func (s *SharedProgress) add(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.bar == nil || !s.bar.IsActive {
+		return
+	}
+
+	if s.overall.add(int64(n), time.Now()) {
+		s.updateTitle()
+	}
+	s.bar = s.bar.Add(n)
+}
+
+// updateTitle must be called holding the lock.
+func (s *SharedProgress) updateTitle() {
+	title := fmt.Sprintf(" INFO  %s %d layer(s):", s.msg, s.active)
+	if isTerminal(s.printer.ProgressBar.Writer) {
+		title += fmt.Sprintf(" %s/s ETA %s", formatBytes(s.overall.rate), formatETA(s.overall.eta()))
+	}
+	s.bar.UpdateTitle(title)
+}
+
+type sharedReader struct {
+	io.Reader
+	shared *SharedProgress
+}
+
+// Read adds the bytes read to the shared progress bar.
+func (sr *sharedReader) Read(p []byte) (n int, err error) {
+	n, err = sr.Reader.Read(p)
+	sr.shared.add(n)
+	return n, err
+}
+
 type trackedReader struct {
 	io.Reader
 	descriptor  v1.Descriptor
 	progressBar *pterm.ProgressbarPrinter
+	title       string
+	showRate    bool
+	layer       *rateEstimator
+	overall     *rateEstimator
 }
 
 // Read implements the logic of the progress bar.
 func (tr *trackedReader) Read(p []byte) (n int, err error) {
 	n, err = tr.Reader.Read(p)
-	if tr.progressBar.IsActive {
-		tr.progressBar = tr.progressBar.Add(n)
+	if !tr.progressBar.IsActive {
+		return n, err
+	}
+
+	now := time.Now()
+	layerUpdated := tr.layer.add(int64(n), now)
+	tr.overall.add(int64(n), now)
+
+	if tr.showRate && layerUpdated {
+		title := fmt.Sprintf("%s %s/s ETA %s", tr.title, formatBytes(tr.layer.rate), formatETA(tr.layer.eta()))
+		// Show the overall ETA only when the tracker is handling more than one layer.
+		if tr.overall.total > tr.layer.total {
+			title += fmt.Sprintf(" (total ETA %s)", formatETA(tr.overall.eta()))
+		}
+		tr.progressBar.UpdateTitle(title)
 	}
+
+	tr.progressBar = tr.progressBar.Add(n)
 	return n, err
 }
+
+// rateEstimator computes a smoothed transfer rate, using an exponential moving average,
+// and the estimated time needed to complete the transfer.
+type rateEstimator struct {
+	mu sync.Mutex
+	// total is the size of the transfer in bytes.
+	total int64
+	// done is the number of bytes transferred so far.
+	done int64
+	// rate is the smoothed transfer rate in bytes per second.
+	rate float64
+	// sampleStart and sampleBytes describe the sample currently being collected.
+	sampleStart time.Time
+	sampleBytes int64
+}
+
+func (r *rateEstimator) addTotal(size int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.total += size
+}
+
+// add records n transferred bytes at time now. It returns true if the rate has been updated.
+func (r *rateEstimator) add(n int64, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.done += n
+	if r.sampleStart.IsZero() {
+		r.sampleStart = now
+	}
+	r.sampleBytes += n
+
+	elapsed := now.Sub(r.sampleStart)
+	if elapsed < rateUpdateInterval {
+		return false
+	}
+
+	sample := float64(r.sampleBytes) / elapsed.Seconds()
+	if r.rate == 0 {
+		r.rate = sample
+	} else {
+		r.rate = rateSmoothingFactor*sample + (1-rateSmoothingFactor)*r.rate
+	}
+
+	r.sampleStart = now
+	r.sampleBytes = 0
+
+	return true
+}
+
+// eta returns the estimated time needed to complete the transfer, or a negative
+// duration if it cannot be estimated yet.
+func (r *rateEstimator) eta() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.rate <= 0 {
+		return -1
+	}
+
+	remaining := r.total - r.done
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return time.Duration(float64(remaining) / r.rate * float64(time.Second))
+}
+
+func formatETA(eta time.Duration) string {
+	if eta < 0 {
+		return "--"
+	}
+	return eta.Round(time.Second).String()
+}
+
+// FormatSize returns a human-readable representation of a size in bytes, e.g. "1.5 KiB".
+func FormatSize(size int64) string {
+	return formatBytes(float64(size))
+}
+
+func formatBytes(b float64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%.0f B", b)
+	}
+
+	div, exp := float64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", b/div, "KMGTPE"[exp])
+}
+
+// isTerminal returns true if the writer is a terminal. A nil writer means stdout.
+func isTerminal(writer io.Writer) bool {
+	if writer == nil {
+		writer = os.Stdout
+	}
+
+	f, ok := writer.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
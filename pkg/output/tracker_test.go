@@ -0,0 +1,70 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RateEstimator", func() {
+	var (
+		estimator *rateEstimator
+		start     time.Time
+	)
+
+	BeforeEach(func() {
+		estimator = &rateEstimator{total: 4000}
+		start = time.Now()
+	})
+
+	Context("no sample has been collected", func() {
+		It("should not estimate the remaining time", func() {
+			Expect(estimator.add(100, start)).Should(BeFalse())
+			Expect(estimator.eta()).Should(BeNumerically("<", 0))
+			Expect(formatETA(estimator.eta())).Should(Equal("--"))
+		})
+	})
+
+	Context("samples are collected at a constant rate", func() {
+		It("should estimate the remaining time", func() {
+			Expect(estimator.add(0, start)).Should(BeFalse())
+			Expect(estimator.add(1000, start.Add(time.Second))).Should(BeTrue())
+			Expect(estimator.add(1000, start.Add(2*time.Second))).Should(BeTrue())
+			Expect(estimator.rate).Should(BeNumerically("~", 1000, 1))
+			Expect(estimator.eta()).Should(BeNumerically("~", 2*time.Second, time.Millisecond))
+		})
+	})
+
+	Context("the rate changes", func() {
+		It("should smooth the rate", func() {
+			estimator.add(0, start)
+			estimator.add(1000, start.Add(time.Second))
+			estimator.add(2000, start.Add(2*time.Second))
+			Expect(estimator.rate).Should(BeNumerically(">", 1000))
+			Expect(estimator.rate).Should(BeNumerically("<", 2000))
+		})
+	})
+
+	Context("formatting the rate", func() {
+		It("should use binary units", func() {
+			Expect(formatBytes(512)).Should(Equal("512 B"))
+			Expect(formatBytes(1536)).Should(Equal("1.5 KiB"))
+			Expect(formatBytes(3 * 1024 * 1024)).Should(Equal("3.0 MiB"))
+		})
+	})
+})
@@ -0,0 +1,75 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+	"github.com/falcosecurity/falcoctl/pkg/signature"
+)
+
+// ErrNotFound error when no SLSA provenance attestation is attached to an artifact.
+var ErrNotFound = errors.New("no SLSA provenance attestation found")
+
+// Attestation is a SLSA provenance attestation attached to an artifact.
+type Attestation struct {
+	Envelope  *signature.Envelope
+	Statement *Statement
+}
+
+// Fetch returns the SLSA provenance attestations attached to the artifact pointed by ref. They are looked
+// for in the tag used by "cosign attest", i.e. "<alg>-<hex>.att", and among the referrers of the artifact.
+// Attestations with other predicate types are ignored.
+func Fetch(ctx context.Context, ref string, client *auth.Client) ([]Attestation, error) {
+	layers, incomplete := oci.AttachedLayers(ctx, ref, client, "att", signature.DSSEMediaType)
+	if incomplete != nil && !errors.Is(incomplete, oci.ErrIncompleteReferrers) {
+		return nil, incomplete
+	}
+
+	var attestations []Attestation
+	for i := range layers {
+		data, err := oci.FetchBlob(ctx, ref, client, &layers[i], oci.DefaultMaxMetadataSize)
+		if err != nil {
+			return nil, err
+		}
+
+		env, err := signature.ParseEnvelope(data)
+		if err != nil {
+			return nil, fmt.Errorf("layer %s: %w", layers[i].Digest, err)
+		}
+
+		st, err := ParseStatement(env)
+		if err != nil {
+			// Not a SLSA provenance, e.g. a vulnerability scan attestation.
+			continue
+		}
+
+		attestations = append(attestations, Attestation{Envelope: env, Statement: st})
+	}
+
+	if len(attestations) == 0 {
+		if incomplete != nil {
+			return nil, fmt.Errorf("%s: %w (%s)", ref, ErrNotFound, incomplete.Error())
+		}
+		return nil, fmt.Errorf("%s: %w", ref, ErrNotFound)
+	}
+
+	return attestations, nil
+}
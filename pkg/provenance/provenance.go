@@ -0,0 +1,236 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provenance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/falcosecurity/falcoctl/pkg/signature"
+)
+
+const (
+	// InTotoPayloadType is the payload type of the DSSE envelopes holding in-toto statements.
+	InTotoPayloadType = "application/vnd.in-toto+json"
+	// SLSAPredicateTypePrefix is the prefix of the predicate types of the SLSA provenance, e.g.
+	// "https://slsa.dev/provenance/v0.2" or "https://slsa.dev/provenance/v1".
+	SLSAPredicateTypePrefix = "https://slsa.dev/provenance/"
+)
+
+// Names of the checks performed by Verify.
+const (
+	CheckSignature  = "signature"
+	CheckBuilder    = "builder"
+	CheckSource     = "source"
+	CheckDigest     = "digest"
+	CheckInvocation = "invocation"
+)
+
+// Statement is an in-toto statement holding a SLSA provenance predicate.
+type Statement struct {
+	Type          string    `json:"_type"`
+	PredicateType string    `json:"predicateType"`
+	Subject       []Subject `json:"subject"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+// Subject is an artifact the statement refers to.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Predicate holds the fields of the SLSA provenance predicate used for the verification,
+// both in the v0.2 and in the v1 format.
+type Predicate struct {
+	// v0.2 fields.
+	Builder struct {
+		ID string `json:"id"`
+	} `json:"builder"`
+	Invocation struct {
+		ConfigSource struct {
+			URI        string `json:"uri"`
+			EntryPoint string `json:"entryPoint"`
+		} `json:"configSource"`
+		Parameters  json.RawMessage `json:"parameters"`
+		Environment json.RawMessage `json:"environment"`
+	} `json:"invocation"`
+	Materials []struct {
+		URI string `json:"uri"`
+	} `json:"materials"`
+
+	// v1 fields.
+	BuildDefinition struct {
+		ExternalParameters   json.RawMessage `json:"externalParameters"`
+		ResolvedDependencies []struct {
+			URI string `json:"uri"`
+		} `json:"resolvedDependencies"`
+	} `json:"buildDefinition"`
+	RunDetails struct {
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+	} `json:"runDetails"`
+}
+
+// ParseStatement parses the in-toto statement held by a DSSE envelope. An error is returned if
+// the statement does not hold a SLSA provenance predicate.
+func ParseStatement(env *signature.Envelope) (*Statement, error) {
+	if env.PayloadType != InTotoPayloadType {
+		return nil, fmt.Errorf("unexpected payload type %q", env.PayloadType)
+	}
+
+	payload, err := env.DecodedPayload()
+	if err != nil {
+		return nil, err
+	}
+
+	var st Statement
+	if err = json.Unmarshal(payload, &st); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal in-toto statement: %w", err)
+	}
+	if !strings.HasPrefix(st.PredicateType, SLSAPredicateTypePrefix) {
+		return nil, fmt.Errorf("predicate type %q is not a SLSA provenance", st.PredicateType)
+	}
+
+	return &st, nil
+}
+
+// BuilderID returns the ID of the builder which produced the artifact.
+func (st *Statement) BuilderID() string {
+	if st.Predicate.RunDetails.Builder.ID != "" {
+		return st.Predicate.RunDetails.Builder.ID
+	}
+	return st.Predicate.Builder.ID
+}
+
+// SourceURI returns the URI of the source repository the artifact was built from.
+func (st *Statement) SourceURI() string {
+	p := &st.Predicate
+	switch {
+	case p.Invocation.ConfigSource.URI != "":
+		return p.Invocation.ConfigSource.URI
+	case len(p.BuildDefinition.ResolvedDependencies) > 0:
+		return p.BuildDefinition.ResolvedDependencies[0].URI
+	case len(p.Materials) > 0:
+		return p.Materials[0].URI
+	default:
+		return ""
+	}
+}
+
+// InvocationParameters returns the parameters of the build invocation, or nil if there are none.
+func (st *Statement) InvocationParameters() json.RawMessage {
+	for _, params := range []json.RawMessage{
+		st.Predicate.BuildDefinition.ExternalParameters,
+		st.Predicate.Invocation.Parameters,
+		st.Predicate.Invocation.Environment,
+	} {
+		switch strings.TrimSpace(string(params)) {
+		case "", "null", "{}", "[]":
+		default:
+			return params
+		}
+	}
+	return nil
+}
+
+// HasSubjectDigest returns true if one of the subjects of the statement has the given digest, in <alg>:<hex> format.
+func (st *Statement) HasSubjectDigest(digest string) bool {
+	alg, hex, ok := strings.Cut(digest, ":")
+	if !ok {
+		return false
+	}
+	for _, s := range st.Subject {
+		if strings.EqualFold(s.Digest[alg], hex) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeSourceURI returns uri without the "git+" scheme prefix, the git reference after "@",
+// the ".git" suffix and the trailing slashes, to compare source URIs written in different forms.
+func NormalizeSourceURI(uri string) string {
+	uri = strings.TrimPrefix(uri, "git+")
+
+	scheme, rest := "", uri
+	if i := strings.Index(uri, "://"); i >= 0 {
+		scheme, rest = uri[:i+3], uri[i+3:]
+	}
+	// An "@" before the path separates the user info, after it the git reference.
+	if slash := strings.Index(rest, "/"); slash >= 0 {
+		if at := strings.Index(rest[slash:], "@"); at >= 0 {
+			rest = rest[:slash+at]
+		}
+	}
+
+	uri = strings.TrimRight(scheme+rest, "/")
+	return strings.TrimSuffix(uri, ".git")
+}
+
+// Check is the result of a single verification step.
+type Check struct {
+	Name    string `json:"name" yaml:"name"`
+	Passed  bool   `json:"passed" yaml:"passed"`
+	Details string `json:"details" yaml:"details"`
+}
+
+// Expectations are the values the provenance of an artifact is verified against.
+type Expectations struct {
+	BuilderID string
+	SourceURI string
+	// Digest is the digest of the manifest of the artifact, in <alg>:<hex> format.
+	Digest string
+}
+
+// Verify verifies the statement against the expectations, returning the result of each check
+// except the signature one, which is performed on the envelope.
+func (st *Statement) Verify(exp *Expectations) []Check {
+	checks := make([]Check, 0, 4)
+
+	builder := st.BuilderID()
+	checks = append(checks, Check{
+		Name:    CheckBuilder,
+		Passed:  builder != "" && builder == exp.BuilderID,
+		Details: fmt.Sprintf("builder %q, expected %q", builder, exp.BuilderID),
+	})
+
+	source := st.SourceURI()
+	checks = append(checks, Check{
+		Name:    CheckSource,
+		Passed:  source != "" && NormalizeSourceURI(source) == NormalizeSourceURI(exp.SourceURI),
+		Details: fmt.Sprintf("source %q, expected %q", source, exp.SourceURI),
+	})
+
+	digestCheck := Check{Name: CheckDigest, Passed: st.HasSubjectDigest(exp.Digest)}
+	if digestCheck.Passed {
+		digestCheck.Details = fmt.Sprintf("subject matches %s", exp.Digest)
+	} else {
+		digestCheck.Details = fmt.Sprintf("no subject matches %s", exp.Digest)
+	}
+	checks = append(checks, digestCheck)
+
+	invocationCheck := Check{Name: CheckInvocation, Passed: st.InvocationParameters() != nil}
+	if invocationCheck.Passed {
+		invocationCheck.Details = "build invocation parameters present"
+	} else {
+		invocationCheck.Details = "no build invocation parameters"
+	}
+	checks = append(checks, invocationCheck)
+
+	return checks
+}
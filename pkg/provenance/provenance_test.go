@@ -0,0 +1,82 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provenance
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/falcosecurity/falcoctl/pkg/signature"
+)
+
+const digest = "sha256:4d9f3c21d7f5e5d7b1c2a8e9f0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3"
+
+const statementV02 = `{
+  "_type": "https://in-toto.io/Statement/v0.1",
+  "predicateType": "https://slsa.dev/provenance/v0.2",
+  "subject": [{"name": "ghcr.io/falcosecurity/plugins/plugin/cloudtrail",
+    "digest": {"sha256": "4d9f3c21d7f5e5d7b1c2a8e9f0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3"}}],
+  "predicate": {
+    "builder": {"id": "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml@refs/tags/v1.9.0"},
+    "invocation": {
+      "configSource": {"uri": "git+https://github.com/falcosecurity/plugins@refs/heads/main", "entryPoint": ".github/workflows/release.yml"},
+      "parameters": {"event_name": "push"}
+    }
+  }
+}`
+
+func TestStatementVerify(t *testing.T) {
+	env := &signature.Envelope{PayloadType: InTotoPayloadType, Payload: base64.StdEncoding.EncodeToString([]byte(statementV02))}
+	st, err := ParseStatement(env)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exp := &Expectations{
+		BuilderID: "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml@refs/tags/v1.9.0",
+		SourceURI: "https://github.com/falcosecurity/plugins",
+		Digest:    digest,
+	}
+	for _, c := range st.Verify(exp) {
+		if !c.Passed {
+			t.Errorf("expected check %q to pass: %s", c.Name, c.Details)
+		}
+	}
+
+	exp.SourceURI = "https://github.com/falcosecurity/rules"
+	exp.Digest = "sha256:0000"
+	failed := map[string]bool{}
+	for _, c := range st.Verify(exp) {
+		failed[c.Name] = !c.Passed
+	}
+	if !failed[CheckSource] || !failed[CheckDigest] || failed[CheckBuilder] || failed[CheckInvocation] {
+		t.Errorf("unexpected check results %v", failed)
+	}
+}
+
+func TestNormalizeSourceURI(t *testing.T) {
+	tests := map[string]string{
+		"git+https://github.com/falcosecurity/plugins@refs/heads/main": "https://github.com/falcosecurity/plugins",
+		"https://github.com/falcosecurity/plugins.git":                 "https://github.com/falcosecurity/plugins",
+		"https://github.com/falcosecurity/plugins/":                    "https://github.com/falcosecurity/plugins",
+		"https://user@example.com/repo":                                "https://user@example.com/repo",
+	}
+
+	for uri, expected := range tests {
+		if got := NormalizeSourceURI(uri); got != expected {
+			t.Errorf("NormalizeSourceURI(%q) = %q, expected %q", uri, got, expected)
+		}
+	}
+}
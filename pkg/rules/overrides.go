@@ -0,0 +1,111 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OverridesFile returns the path of the file holding the local overrides of a rules file, following the
+// Falco convention, e.g. "/etc/falco/falco_rules.local.yaml" for "/etc/falco/falco_rules.yaml".
+func OverridesFile(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + ".local" + ext
+}
+
+// MergeOverrides appends the items of the overrides file src to the overrides file dst, creating it if needed.
+// The items already in dst are not appended again. It returns the number of appended items.
+func MergeOverrides(src, dst string) (int, error) {
+	srcItems, err := readItems(src)
+	if err != nil {
+		return 0, err
+	}
+
+	dstItems, err := readItems(dst)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+
+	existing := make(map[string]struct{}, len(dstItems))
+	for _, item := range dstItems {
+		data, err := yaml.Marshal(item)
+		if err != nil {
+			return 0, err
+		}
+		existing[string(data)] = struct{}{}
+	}
+
+	merged := dstItems
+	for _, item := range srcItems {
+		data, err := yaml.Marshal(item)
+		if err != nil {
+			return 0, err
+		}
+		if _, ok := existing[string(data)]; ok {
+			continue
+		}
+		existing[string(data)] = struct{}{}
+		merged = append(merged, item)
+	}
+
+	appended := len(merged) - len(dstItems)
+	if appended == 0 {
+		return 0, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(&yaml.Node{Kind: yaml.SequenceNode, Content: merged}); err != nil {
+		return 0, fmt.Errorf("cannot marshal overrides file %q: %w", dst, err)
+	}
+
+	if err = os.WriteFile(dst, buf.Bytes(), 0o644); err != nil { //nolint:gosec // rules files are world readable
+		return 0, err
+	}
+
+	return appended, nil
+}
+
+// readItems returns the nodes of the items of a rules file, keeping their comments.
+func readItems(path string) ([]*yaml.Node, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err = yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal rules file %q: %w", path, err)
+	}
+
+	// An empty file has no items.
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	if doc.Content[0].Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("rules file %q is not a list of items", path)
+	}
+
+	return doc.Content[0].Content, nil
+}
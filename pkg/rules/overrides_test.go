@@ -0,0 +1,73 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOverridesFile(t *testing.T) {
+	if got := OverridesFile("/etc/falco/falco_rules.yaml"); got != "/etc/falco/falco_rules.local.yaml" {
+		t.Errorf("unexpected overrides file %q", got)
+	}
+}
+
+func TestMergeOverrides(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "old.local.yaml")
+	dst := filepath.Join(dir, "new.local.yaml")
+
+	if err := os.WriteFile(src, []byte(`
+# exclude the CI runners
+- macro: user_known_shell
+  condition: container.image.repository = ci-runner
+
+- list: trusted_binaries
+  items: [sudo]
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := MergeOverrides(filepath.Join(dir, "missing.yaml"), dst); err == nil {
+		t.Fatal("expected an error for a missing source file")
+	}
+
+	merged, err := MergeOverrides(src, dst)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if merged != 2 {
+		t.Fatalf("expected 2 merged items, got %d", merged)
+	}
+
+	// Merging again does not duplicate the items.
+	if merged, err = MergeOverrides(src, dst); err != nil || merged != 0 {
+		t.Fatalf("expected no merged items, got %d, %v", merged, err)
+	}
+
+	data, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := Parse(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 2 || items[0].Macro != "user_known_shell" {
+		t.Errorf("unexpected items %+v", items)
+	}
+}
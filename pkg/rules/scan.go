@@ -0,0 +1,162 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity is the severity level of a finding.
+type Severity string
+
+const (
+	// SeverityError identifies findings that must be fixed.
+	SeverityError Severity = "error"
+	// SeverityWarning identifies findings that should be reviewed.
+	SeverityWarning Severity = "warning"
+	// SeverityInfo identifies suggestions.
+	SeverityInfo Severity = "info"
+)
+
+// adminTools are the common administrative tools that rules usually need to except.
+var adminTools = []string{"sudo", "ansible", "puppet", "chef-client", "salt-minion", "apt", "yum", "dnf"}
+
+var identifierRgx = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_.-]*`)
+
+// Item is an entry of a Falco rules file. Only the fields needed by the analysis are decoded.
+type Item struct {
+	Rule       string        `yaml:"rule"`
+	Macro      string        `yaml:"macro"`
+	Condition  string        `yaml:"condition"`
+	Output     string        `yaml:"output"`
+	Source     string        `yaml:"source"`
+	Append     bool          `yaml:"append"`
+	Exceptions []interface{} `yaml:"exceptions"`
+}
+
+// Finding is an issue found in a rule.
+type Finding struct {
+	Rule     string   `json:"rule" yaml:"rule"`
+	Severity Severity `json:"severity" yaml:"severity"`
+	Message  string   `json:"message" yaml:"message"`
+}
+
+// Parse decodes the content of a Falco rules file.
+func Parse(data []byte) ([]Item, error) {
+	var items []Item
+	if err := yaml.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal rules file: %w", err)
+	}
+
+	return items, nil
+}
+
+// Scan analyzes the rules in items and returns the findings, skipping the rules in ignore.
+func Scan(items []Item, ignore []string) []Finding {
+	ignored := make(map[string]struct{}, len(ignore))
+	for _, name := range ignore {
+		ignored[name] = struct{}{}
+	}
+
+	macros := make(map[string]string)
+	for i := range items {
+		if items[i].Macro != "" {
+			macros[items[i].Macro] += " " + items[i].Condition
+		}
+	}
+
+	var findings []Finding
+	for i := range items {
+		item := &items[i]
+		if item.Rule == "" || item.Append {
+			continue
+		}
+		if _, ok := ignored[item.Rule]; ok {
+			continue
+		}
+
+		if strings.TrimSpace(item.Output) == "" {
+			findings = append(findings, Finding{
+				Rule:     item.Rule,
+				Severity: SeverityError,
+				Message:  "rule has no output fields",
+			})
+		}
+
+		// Only syscall rules can filter on processes.
+		if item.Source != "" && item.Source != "syscall" {
+			continue
+		}
+
+		condition := expand(item.Condition, macros, make(map[string]bool))
+		if !strings.Contains(condition, "proc.") {
+			findings = append(findings, Finding{
+				Rule:     item.Rule,
+				Severity: SeverityWarning,
+				Message:  "condition has no process filter and may match too broadly",
+			})
+			continue
+		}
+
+		if len(item.Exceptions) == 0 && !mentionsAdminTools(condition) {
+			findings = append(findings, Finding{
+				Rule:     item.Rule,
+				Severity: SeverityInfo,
+				Message:  fmt.Sprintf("rule has no exceptions, consider excepting common administrative tools (%s)", strings.Join(adminTools, ", ")),
+			})
+		}
+	}
+
+	return findings
+}
+
+// HasErrors returns true if at least one of the findings has error severity.
+func HasErrors(findings []Finding) bool {
+	for i := range findings {
+		if findings[i].Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expand replaces the macros referenced by condition with their conditions, recursively.
+func expand(condition string, macros map[string]string, visited map[string]bool) string {
+	return identifierRgx.ReplaceAllStringFunc(condition, func(identifier string) string {
+		macro, ok := macros[identifier]
+		if !ok || visited[identifier] {
+			return identifier
+		}
+		visited[identifier] = true
+		return "(" + expand(macro, macros, visited) + ")"
+	})
+}
+
+func mentionsAdminTools(condition string) bool {
+	for _, identifier := range identifierRgx.FindAllString(condition, -1) {
+		for _, tool := range adminTools {
+			if identifier == tool {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import "testing"
+
+const rulesFile = `
+- required_engine_version: 10
+
+- macro: spawned_process
+  condition: evt.type = execve and proc.name != ""
+
+- rule: Broad open
+  desc: no process filter
+  condition: evt.type = open
+  output: "File opened (file=%fd.name)"
+  priority: WARNING
+
+- rule: No output
+  desc: missing output
+  condition: spawned_process and proc.name = nc
+  exceptions:
+    - name: proc_pname
+      fields: proc.pname
+
+- rule: Shell spawned
+  desc: no exceptions
+  condition: spawned_process and proc.name = bash
+  output: "Shell spawned (command=%proc.cmdline)"
+  priority: NOTICE
+
+- rule: Shell spawned excepted
+  desc: exceptions for admin tools
+  condition: spawned_process and proc.name = bash and not proc.pname in (sudo, ansible)
+  output: "Shell spawned (command=%proc.cmdline)"
+  priority: NOTICE
+
+- rule: Cloudtrail event
+  desc: plugin source
+  condition: ct.name = ConsoleLogin
+  output: "Console login (user=%ct.user)"
+  source: aws_cloudtrail
+`
+
+func TestScan(t *testing.T) {
+	items, err := Parse([]byte(rulesFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings := Scan(items, nil)
+
+	expected := map[string]Severity{
+		"Broad open":    SeverityWarning,
+		"No output":     SeverityError,
+		"Shell spawned": SeverityInfo,
+	}
+
+	if len(findings) != len(expected) {
+		t.Fatal("unexpected number of findings, got:", findings)
+	}
+
+	for _, f := range findings {
+		if expected[f.Rule] != f.Severity {
+			t.Fatalf("unexpected finding %+v", f)
+		}
+	}
+
+	if !HasErrors(findings) {
+		t.Fatal("expected error findings")
+	}
+
+	findings = Scan(items, []string{"No output"})
+	if HasErrors(findings) {
+		t.Fatal("expected ignored rule to not produce findings, got:", findings)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Allowlist is a list of approved packages. Each entry is either an exact match or a pattern,
+// where "*" matches any sequence of characters and "?" any single character. An entry is
+// matched against the name of a package, its name@version and its package URL.
+type Allowlist struct {
+	exact    map[string]struct{}
+	patterns []*regexp.Regexp
+}
+
+type allowlistFile struct {
+	Packages []string `json:"packages"`
+}
+
+// LoadAllowlist loads an allowlist from a json file, containing either an array of entries
+// or an object with the entries under the "packages" key.
+func LoadAllowlist(path string) (*Allowlist, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read allowlist: %w", err)
+	}
+
+	var entries []string
+	if err = json.Unmarshal(data, &entries); err != nil {
+		var file allowlistFile
+		if err = json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal allowlist %q: %w", path, err)
+		}
+		entries = file.Packages
+	}
+
+	return NewAllowlist(entries)
+}
+
+// NewAllowlist returns an allowlist with the given entries.
+func NewAllowlist(entries []string) (*Allowlist, error) {
+	a := &Allowlist{exact: make(map[string]struct{})}
+	for _, e := range entries {
+		if !strings.ContainsAny(e, "*?") {
+			a.exact[e] = struct{}{}
+			continue
+		}
+
+		var expr strings.Builder
+		expr.WriteString("^")
+		for _, r := range e {
+			switch r {
+			case '*':
+				expr.WriteString(".*")
+			case '?':
+				expr.WriteString(".")
+			default:
+				expr.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		expr.WriteString("$")
+
+		pattern, err := regexp.Compile(expr.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist entry %q: %w", e, err)
+		}
+		a.patterns = append(a.patterns, pattern)
+	}
+
+	return a, nil
+}
+
+// Allows returns true if the package matches an entry of the allowlist.
+func (a *Allowlist) Allows(p *Package) bool {
+	candidates := []string{p.Name, p.String()}
+	if p.PURL != "" {
+		candidates = append(candidates, p.PURL)
+	}
+
+	for _, c := range candidates {
+		if _, ok := a.exact[c]; ok {
+			return true
+		}
+		for _, pattern := range a.patterns {
+			if pattern.MatchString(c) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Unknown returns the packages not allowed by the allowlist.
+func (a *Allowlist) Unknown(packages []Package) []Package {
+	var unknown []Package
+	for i := range packages {
+		if !a.Allows(&packages[i]) {
+			unknown = append(unknown, packages[i])
+		}
+	}
+	return unknown
+}
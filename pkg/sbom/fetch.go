@@ -0,0 +1,54 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+)
+
+// DefaultMaxSize is the default maximum size in bytes of the SBOMs read from remote registries.
+const DefaultMaxSize int64 = 64 * 1024 * 1024
+
+// ErrNotFound error when no SBOM is attached to an artifact.
+var ErrNotFound = errors.New("no SBOM found")
+
+// Fetch returns the SBOM attached to the artifact pointed by ref and its media type. The SBOM is looked
+// for in the tag used by "cosign attach sbom", i.e. "<alg>-<hex>.sbom", and then among the referrers
+// of the artifact. The first layer with one of the supported media types is returned.
+func Fetch(ctx context.Context, ref string, client *auth.Client) (data []byte, mediaType string, err error) {
+	layers, err := oci.AttachedLayers(ctx, ref, client, "sbom", MediaTypes...)
+	if err != nil && !errors.Is(err, oci.ErrIncompleteReferrers) {
+		return nil, "", err
+	}
+
+	if len(layers) == 0 {
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w (%s)", ref, ErrNotFound, err.Error())
+		}
+		return nil, "", fmt.Errorf("%s: %w", ref, ErrNotFound)
+	}
+
+	if data, err = oci.FetchBlob(ctx, ref, client, &layers[0], DefaultMaxSize); err != nil {
+		return nil, "", err
+	}
+
+	return data, layers[0].MediaType, nil
+}
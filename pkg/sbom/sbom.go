@@ -0,0 +1,148 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// SPDXJSONMediaType is the media type of SPDX SBOMs in json format.
+	SPDXJSONMediaType = "text/spdx+json"
+	// SPDXMediaType is the media type used by cosign for SPDX SBOMs.
+	SPDXMediaType = "text/spdx"
+	// CycloneDXJSONMediaType is the media type of CycloneDX SBOMs in json format.
+	CycloneDXJSONMediaType = "application/vnd.cyclonedx+json"
+	// SyftJSONMediaType is the media type of Syft SBOMs in json format.
+	SyftJSONMediaType = "application/vnd.syft+json"
+)
+
+// MediaTypes are the media types of the supported SBOMs.
+var MediaTypes = []string{SPDXJSONMediaType, SPDXMediaType, CycloneDXJSONMediaType, SyftJSONMediaType}
+
+// ErrUnknownFormat error when the format of an SBOM is not recognized.
+var ErrUnknownFormat = errors.New("unknown SBOM format")
+
+// Package is a package listed in an SBOM.
+type Package struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// PURL is the package URL of the package, if any.
+	PURL string `json:"purl,omitempty" yaml:"purl,omitempty"`
+}
+
+// String returns a string representation of the package in name@version format.
+func (p *Package) String() string {
+	if p.Version == "" {
+		return p.Name
+	}
+	return p.Name + "@" + p.Version
+}
+
+type spdxDocument struct {
+	SPDXVersion string `json:"spdxVersion"`
+	Packages    []struct {
+		Name         string `json:"name"`
+		VersionInfo  string `json:"versionInfo"`
+		ExternalRefs []struct {
+			ReferenceType    string `json:"referenceType"`
+			ReferenceLocator string `json:"referenceLocator"`
+		} `json:"externalRefs"`
+	} `json:"packages"`
+}
+
+type cycloneDXComponent struct {
+	Name       string               `json:"name"`
+	Version    string               `json:"version"`
+	PURL       string               `json:"purl"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXDocument struct {
+	BOMFormat  string               `json:"bomFormat"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type syftDocument struct {
+	Artifacts []struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		PURL    string `json:"purl"`
+	} `json:"artifacts"`
+	Schema struct {
+		URL string `json:"url"`
+	} `json:"schema"`
+}
+
+// Parse returns the packages listed in an SBOM. SPDX, CycloneDX and Syft SBOMs in json format are supported:
+// the format is detected from the content.
+func Parse(data []byte) ([]Package, error) {
+	var spdx spdxDocument
+	if err := json.Unmarshal(data, &spdx); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal SBOM: %w", err)
+	}
+	if spdx.SPDXVersion != "" {
+		return spdxPackages(&spdx), nil
+	}
+
+	var cyclonedx cycloneDXDocument
+	if err := json.Unmarshal(data, &cyclonedx); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal SBOM: %w", err)
+	}
+	if cyclonedx.BOMFormat == "CycloneDX" {
+		var packages []Package
+		appendComponents(&packages, cyclonedx.Components)
+		return packages, nil
+	}
+
+	var syft syftDocument
+	if err := json.Unmarshal(data, &syft); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal SBOM: %w", err)
+	}
+	if syft.Schema.URL != "" {
+		packages := make([]Package, 0, len(syft.Artifacts))
+		for _, a := range syft.Artifacts {
+			packages = append(packages, Package{Name: a.Name, Version: a.Version, PURL: a.PURL})
+		}
+		return packages, nil
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+func spdxPackages(doc *spdxDocument) []Package {
+	packages := make([]Package, 0, len(doc.Packages))
+	for _, p := range doc.Packages {
+		pkg := Package{Name: p.Name, Version: p.VersionInfo}
+		for _, ref := range p.ExternalRefs {
+			if ref.ReferenceType == "purl" {
+				pkg.PURL = ref.ReferenceLocator
+				break
+			}
+		}
+		packages = append(packages, pkg)
+	}
+	return packages
+}
+
+// appendComponents appends the CycloneDX components, including the nested ones, to packages.
+func appendComponents(packages *[]Package, components []cycloneDXComponent) {
+	for i := range components {
+		*packages = append(*packages, Package{Name: components[i].Name, Version: components[i].Version, PURL: components[i].PURL})
+		appendComponents(packages, components[i].Components)
+	}
+}
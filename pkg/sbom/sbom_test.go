@@ -0,0 +1,108 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const spdxSBOM = `{
+  "spdxVersion": "SPDX-2.3",
+  "packages": [
+    {"name": "github.com/spf13/cobra", "versionInfo": "v1.5.0",
+     "externalRefs": [{"referenceType": "purl", "referenceLocator": "pkg:golang/github.com/spf13/cobra@v1.5.0"}]},
+    {"name": "golang.org/x/sys", "versionInfo": "v0.1.0"},
+    {"name": "github.com/evil/backdoor", "versionInfo": "v6.6.6"}
+  ]
+}`
+
+const cycloneDXSBOM = `{
+  "bomFormat": "CycloneDX",
+  "components": [
+    {"name": "github.com/spf13/cobra", "version": "v1.5.0",
+     "components": [{"name": "github.com/spf13/pflag", "version": "v1.0.5"}]}
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	packages, err := Parse([]byte(spdxSBOM))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(packages) != 3 {
+		t.Fatalf("expected 3 packages, got %d", len(packages))
+	}
+	if packages[0].PURL != "pkg:golang/github.com/spf13/cobra@v1.5.0" {
+		t.Errorf("unexpected purl %q", packages[0].PURL)
+	}
+
+	packages, err = Parse([]byte(cycloneDXSBOM))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(packages) != 2 || packages[1].String() != "github.com/spf13/pflag@v1.0.5" {
+		t.Errorf("unexpected packages %v", packages)
+	}
+
+	if _, err = Parse([]byte(`{"foo": "bar"}`)); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestAllowlist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "allowlist.json")
+	if err := os.WriteFile(path, []byte(`{"packages": ["github.com/spf13/cobra@v1.5.0", "golang.org/x/*"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	allowlist, err := LoadAllowlist(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	packages, err := Parse([]byte(spdxSBOM))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	unknown := allowlist.Unknown(packages)
+	if len(unknown) != 1 || unknown[0].Name != "github.com/evil/backdoor" {
+		t.Errorf("unexpected unknown packages %v", unknown)
+	}
+
+	// A different version of an exact entry is not allowed.
+	if allowlist.Allows(&Package{Name: "github.com/spf13/cobra", Version: "v1.6.0"}) {
+		t.Error("expected github.com/spf13/cobra@v1.6.0 not to be allowed")
+	}
+}
+
+func TestLoadAllowlistArray(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "allowlist.json")
+	if err := os.WriteFile(path, []byte(`["pkg:golang/github.com/spf13/*"]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	allowlist, err := LoadAllowlist(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !allowlist.Allows(&Package{Name: "cobra", PURL: "pkg:golang/github.com/spf13/cobra@v1.5.0"}) {
+		t.Error("expected the package to be allowed by its purl")
+	}
+}
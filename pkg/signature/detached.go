@@ -0,0 +1,135 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+)
+
+// Detached is a signature computed out of falcoctl, e.g. by an HSM-backed tooling, to be attached to an artifact.
+type Detached struct {
+	// Layer is the descriptor of the layer holding the signature.
+	Layer v1.Descriptor
+	// Content is the content of the layer.
+	Content []byte
+	// signedDigest is the digest of the manifest signed by a cosign signature, empty for DSSE envelopes.
+	signedDigest string
+}
+
+// signedPayload is a cosign signature along with the signed payload, as printed by "cosign download signature".
+type signedPayload struct {
+	Base64Signature string
+	Payload         string
+}
+
+// ParseDetached parses a detached signature, either a DSSE envelope or a cosign signature along with its simple
+// signing payload, in the format printed by "cosign download signature". Only the format is validated, the
+// signatures are not verified.
+func ParseDetached(data []byte) (*Detached, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal signature: %w", err)
+	}
+
+	if _, ok := fields["payloadType"]; ok {
+		return parseDSSE(data)
+	}
+
+	return parseCosign(data)
+}
+
+func parseDSSE(data []byte) (*Detached, error) {
+	env, err := ParseEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = env.DecodedPayload(); err != nil {
+		return nil, err
+	}
+	if len(env.Signatures) == 0 {
+		return nil, fmt.Errorf("malformed DSSE envelope: no signature")
+	}
+	for i := range env.Signatures {
+		if _, err := base64.StdEncoding.DecodeString(env.Signatures[i].Sig); err != nil || env.Signatures[i].Sig == "" {
+			return nil, fmt.Errorf("malformed DSSE envelope: signature %d is not base64 encoded", i)
+		}
+	}
+
+	return &Detached{
+		Layer: v1.Descriptor{
+			MediaType: DSSEMediaType,
+			Digest:    digest.FromBytes(data),
+			Size:      int64(len(data)),
+		},
+		Content: data,
+	}, nil
+}
+
+func parseCosign(data []byte) (*Detached, error) {
+	var signed signedPayload
+	if err := json.Unmarshal(data, &signed); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal cosign signature: %w", err)
+	}
+	if signed.Base64Signature == "" || signed.Payload == "" {
+		return nil, fmt.Errorf("unsupported signature format: expected a DSSE envelope or a cosign signature with its payload")
+	}
+	if _, err := base64.StdEncoding.DecodeString(signed.Base64Signature); err != nil {
+		return nil, fmt.Errorf("malformed cosign signature: %w", err)
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(signed.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cosign payload: %w", err)
+	}
+
+	var p Payload
+	if err = json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal cosign payload: %w", err)
+	}
+	if p.Critical.Type != payloadType || p.Critical.Image.DockerManifestDigest == "" {
+		return nil, fmt.Errorf("malformed cosign payload: not a %q signing a manifest digest", payloadType)
+	}
+
+	return &Detached{
+		Layer: v1.Descriptor{
+			MediaType:   SimpleSigningMediaType,
+			Digest:      digest.FromBytes(payload),
+			Size:        int64(len(payload)),
+			Annotations: map[string]string{SignatureAnnotation: signed.Base64Signature},
+		},
+		Content:      payload,
+		signedDigest: p.Critical.Image.DockerManifestDigest,
+	}, nil
+}
+
+// AttachDetached attaches the detached signature as a referrer of the manifest described by subject, in the
+// repository pointed by ref. A cosign signature must sign the digest of subject. It returns the descriptor of
+// the manifest of the referrer.
+func AttachDetached(ctx context.Context, ref string, client *auth.Client, subject v1.Descriptor, sig *Detached) (*v1.Descriptor, error) {
+	if sig.signedDigest != "" && sig.signedDigest != subject.Digest.String() {
+		return nil, fmt.Errorf("the signature signs %s, not %s: %w", sig.signedDigest, subject.Digest, ErrInvalidSignature)
+	}
+
+	return oci.PushReferrer(ctx, ref, client, subject, sig.Layer, sig.Content)
+}
@@ -0,0 +1,118 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestParseDetached(t *testing.T) {
+	payload, err := NewPayload("ghcr.io/falcosecurity/plugins/plugin/cloudtrail", testDigest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64 := base64.StdEncoding.EncodeToString
+	envelope := func(signatures string) string {
+		return fmt.Sprintf(`{"payloadType":"application/vnd.in-toto+json","payload":%q,"signatures":%s}`, b64([]byte("{}")), signatures)
+	}
+
+	tests := []struct {
+		name      string
+		data      string
+		mediaType string
+		wantErr   bool
+	}{
+		{
+			name:      "dsse envelope",
+			data:      envelope(fmt.Sprintf(`[{"sig":%q}]`, b64([]byte("sig")))),
+			mediaType: DSSEMediaType,
+		},
+		{
+			name:    "dsse envelope without signatures",
+			data:    envelope(`[]`),
+			wantErr: true,
+		},
+		{
+			name:    "dsse envelope with malformed signature",
+			data:    envelope(`[{"sig":"%%"}]`),
+			wantErr: true,
+		},
+		{
+			name:      "cosign signature",
+			data:      fmt.Sprintf(`{"Base64Signature":%q,"Payload":%q}`, b64([]byte("sig")), b64(payload)),
+			mediaType: SimpleSigningMediaType,
+		},
+		{
+			name:    "cosign signature of another payload type",
+			data:    fmt.Sprintf(`{"Base64Signature":%q,"Payload":%q}`, b64([]byte("sig")), b64([]byte(`{"critical":{"type":"other"}}`))),
+			wantErr: true,
+		},
+		{
+			name:    "raw signature",
+			data:    b64([]byte("sig")),
+			wantErr: true,
+		},
+		{
+			name:    "unknown json",
+			data:    `{"signature":"abc"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := ParseDetached([]byte(tt.data))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sig.Layer.MediaType != tt.mediaType {
+				t.Errorf("expected media type %q, got %q", tt.mediaType, sig.Layer.MediaType)
+			}
+			if sig.Layer.Digest != digest.FromBytes(sig.Content) {
+				t.Errorf("layer digest %s does not match its content", sig.Layer.Digest)
+			}
+		})
+	}
+}
+
+func TestAttachDetachedOtherDigest(t *testing.T) {
+	payload, err := NewPayload("ghcr.io/falcosecurity/plugins/plugin/cloudtrail", testDigest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ParseDetached([]byte(fmt.Sprintf(`{"Base64Signature":"c2ln","Payload":%q}`, base64.StdEncoding.EncodeToString(payload))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject := v1.Descriptor{MediaType: v1.MediaTypeImageManifest, Digest: digest.FromString("other")}
+	_, err = AttachDetached(context.Background(), "ghcr.io/falcosecurity/plugins/plugin/cloudtrail", nil, subject, sig)
+	if !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+}
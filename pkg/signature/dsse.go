@@ -0,0 +1,92 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// DSSEMediaType is the media type of the layers holding DSSE envelopes.
+const DSSEMediaType = "application/vnd.dsse.envelope.v1+json"
+
+// Envelope is a DSSE envelope, as stored by cosign in the layers of the attestations.
+type Envelope struct {
+	PayloadType string              `json:"payloadType"`
+	Payload     string              `json:"payload"`
+	Signatures  []EnvelopeSignature `json:"signatures"`
+}
+
+// EnvelopeSignature is a signature of a DSSE envelope.
+type EnvelopeSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// ParseEnvelope parses a DSSE envelope in json format.
+func ParseEnvelope(data []byte) (*Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal DSSE envelope: %w", err)
+	}
+	if env.PayloadType == "" || env.Payload == "" {
+		return nil, fmt.Errorf("malformed DSSE envelope: missing payload")
+	}
+	return &env, nil
+}
+
+// DecodedPayload returns the payload of the envelope, decoded from base64.
+func (e *Envelope) DecodedPayload() ([]byte, error) {
+	payload, err := base64.StdEncoding.DecodeString(e.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode DSSE payload: %w", err)
+	}
+	return payload, nil
+}
+
+// pae returns the pre-authentication encoding of the envelope, i.e. the signed message.
+func (e *Envelope) pae(payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(e.PayloadType), e.PayloadType, len(payload), payload))
+}
+
+// Verify verifies that at least one signature of the envelope is valid for the public key.
+// ECDSA, RSA (PKCS #1 v1.5) and Ed25519 keys are supported.
+func (e *Envelope) Verify(publicKey crypto.PublicKey) error {
+	payload, err := e.DecodedPayload()
+	if err != nil {
+		return err
+	}
+	message := e.pae(payload)
+
+	for _, s := range e.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+
+		err = VerifyMessage(publicKey, message, sig, RSAPaddingPKCS1v15)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, ErrInvalidSignature) {
+			return err
+		}
+	}
+
+	return ErrInvalidSignature
+}
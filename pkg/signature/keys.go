@@ -0,0 +1,96 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// RSAPadding is the padding scheme of the RSA signatures.
+type RSAPadding int
+
+const (
+	// RSAPaddingPKCS1v15 is the PKCS #1 v1.5 padding, used by cosign.
+	RSAPaddingPKCS1v15 RSAPadding = iota
+	// RSAPaddingPSS is the PSS padding, used by the "rsassa-pss-sha256" in-toto scheme.
+	RSAPaddingPSS
+)
+
+// LoadPublicKey loads a PEM encoded public key, such as the one generated by "cosign generate-key-pair".
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read public key: %w", err)
+	}
+
+	key, err := ParsePublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", path, err)
+	}
+
+	return key, nil
+}
+
+// ParsePublicKey parses a PEM encoded public key in PKIX format.
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse public key: %w", err)
+	}
+
+	return key, nil
+}
+
+// VerifyMessage verifies that sig is a signature of message by publicKey. ECDSA and RSA signatures are
+// computed on the SHA-256 digest of the message, the latter with the given padding, while Ed25519 ones
+// are computed on the message itself. It returns ErrInvalidSignature if the signature is not valid.
+func VerifyMessage(publicKey crypto.PublicKey, message, sig []byte, padding RSAPadding) error {
+	hash := sha256.Sum256(message)
+
+	var valid bool
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(key, hash[:], sig)
+	case *rsa.PublicKey:
+		if padding == RSAPaddingPSS {
+			valid = rsa.VerifyPSS(key, crypto.SHA256, hash[:], sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto}) == nil
+		} else {
+			valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], sig) == nil
+		}
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, message, sig)
+	default:
+		return fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+
+	if !valid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
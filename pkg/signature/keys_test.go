@@ -0,0 +1,85 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"testing"
+)
+
+func TestVerifyMessage(t *testing.T) {
+	message := []byte("DSSEv1 0  2 {}")
+	hash := sha256.Sum256(message)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaSig, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs1Sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pssSig, err := rsa.SignPSS(rand.Reader, rsaKey, crypto.SHA256, hash[:], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edSig := ed25519.Sign(edPrivate, message)
+
+	tests := []struct {
+		name      string
+		publicKey crypto.PublicKey
+		sig       []byte
+		padding   RSAPadding
+		valid     bool
+	}{
+		{"ecdsa", &ecdsaKey.PublicKey, ecdsaSig, RSAPaddingPKCS1v15, true},
+		{"rsa pkcs1v15", &rsaKey.PublicKey, pkcs1Sig, RSAPaddingPKCS1v15, true},
+		{"rsa pss", &rsaKey.PublicKey, pssSig, RSAPaddingPSS, true},
+		{"rsa wrong padding", &rsaKey.PublicKey, pssSig, RSAPaddingPKCS1v15, false},
+		{"ed25519", edPublic, edSig, RSAPaddingPKCS1v15, true},
+		{"wrong key", edPublic, ecdsaSig, RSAPaddingPKCS1v15, false},
+	}
+
+	for _, tt := range tests {
+		err := VerifyMessage(tt.publicKey, message, tt.sig, tt.padding)
+		if tt.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: expected ErrInvalidSignature, got %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/falcosecurity/falcoctl/pkg/oci"
+)
+
+// emptyConfig is the config of the signature manifests.
+var emptyConfig = []byte("{}")
+
+// SignatureTag returns the tag holding the signatures of the manifest with the given digest, e.g. "sha256-123abc.sig".
+func SignatureTag(d string) string {
+	return strings.Replace(d, ":", "-", 1) + ".sig"
+}
+
+// HasValidSignature returns true if the artifact pointed by ref, which must be pinned by digest, has a signature
+// valid for publicKey, either in the signature tag or among its referrers. If the referrers could be retrieved
+// only partially and no valid signature was found, an error wrapping oci.ErrIncompleteReferrers is returned.
+func HasValidSignature(ctx context.Context, ref string, client *auth.Client, d string, publicKey crypto.PublicKey) (bool, error) {
+	layers, incomplete := oci.AttachedLayers(ctx, ref, client, "sig", SimpleSigningMediaType)
+	if incomplete != nil && !errors.Is(incomplete, oci.ErrIncompleteReferrers) {
+		return false, incomplete
+	}
+
+	for i := range layers {
+		signature, ok := layers[i].Annotations[SignatureAnnotation]
+		if !ok {
+			continue
+		}
+
+		payload, err := oci.FetchBlob(ctx, ref, client, &layers[i], oci.DefaultMaxMetadataSize)
+		if err != nil {
+			return false, err
+		}
+
+		if Verify(publicKey, payload, signature, d) == nil {
+			return true, nil
+		}
+	}
+
+	return false, incomplete
+}
+
+// Attach signs the manifest with the given digest in the repository pointed by ref and stores the signature
+// in the signature tag, along with the signatures already stored there. It returns the descriptor of the
+// manifest of the signature tag.
+func Attach(ctx context.Context, ref string, client *auth.Client, d string, signer crypto.Signer) (*v1.Descriptor, error) {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new repository with ref %s: %w", ref, err)
+	}
+	repo.Client = client
+
+	payload, err := NewPayload(repo.Reference.Registry+"/"+repo.Reference.Repository, d)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := Sign(signer, payload)
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign %s: %w", d, err)
+	}
+
+	layerDesc := v1.Descriptor{
+		MediaType:   SimpleSigningMediaType,
+		Digest:      digest.FromBytes(payload),
+		Size:        int64(len(payload)),
+		Annotations: map[string]string{SignatureAnnotation: signature},
+	}
+	configDesc := v1.Descriptor{
+		MediaType: v1.MediaTypeImageConfig,
+		Digest:    digest.FromBytes(emptyConfig),
+		Size:      int64(len(emptyConfig)),
+	}
+
+	for _, blob := range []struct {
+		desc    v1.Descriptor
+		content []byte
+	}{{layerDesc, payload}, {configDesc, emptyConfig}} {
+		exists, err := repo.Exists(ctx, blob.desc)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			if err = repo.Push(ctx, blob.desc, bytes.NewReader(blob.content)); err != nil {
+				return nil, fmt.Errorf("unable to push %s: %w", blob.desc.Digest, err)
+			}
+		}
+	}
+
+	manifest := v1.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: v1.MediaTypeImageManifest,
+		Config:    configDesc,
+	}
+
+	tag := SignatureTag(d)
+	existing, err := repo.Resolve(ctx, tag)
+	switch {
+	case err == nil:
+		data, err := oci.FetchBlob(ctx, ref, client, &existing, oci.DefaultMaxMetadataSize)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal manifest of tag %s: %w", tag, err)
+		}
+	case !errors.Is(err, errdef.ErrNotFound):
+		return nil, fmt.Errorf("unable to resolve tag %s: %w", tag, err)
+	}
+	manifest.Layers = append(manifest.Layers, layerDesc)
+
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	manifestDesc := v1.Descriptor{
+		MediaType: v1.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifestBytes),
+		Size:      int64(len(manifestBytes)),
+	}
+
+	if err = repo.PushReference(ctx, manifestDesc, bytes.NewReader(manifestBytes), tag); err != nil {
+		return nil, fmt.Errorf("unable to push signature tag %s: %w", tag, err)
+	}
+
+	return &manifestDesc, nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// SimpleSigningMediaType is the media type of the layers holding the signed payloads.
+	SimpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// SignatureAnnotation is the layer annotation holding the base64 encoded signature of the payload.
+	SignatureAnnotation = "dev.cosignproject.cosign/signature"
+	// payloadType is the type of the signed payloads.
+	payloadType = "cosign container image signature"
+)
+
+// ErrInvalidSignature error when a signature cannot be verified.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Payload is the signed payload, in the simple signing format, binding a repository to a manifest digest.
+type Payload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]interface{} `json:"optional"`
+}
+
+// NewPayload returns the payload signing the manifest with the given digest in the repository.
+func NewPayload(repository, digest string) ([]byte, error) {
+	var p Payload
+	p.Critical.Identity.DockerReference = repository
+	p.Critical.Image.DockerManifestDigest = digest
+	p.Critical.Type = payloadType
+	return json.Marshal(p)
+}
+
+// Sign returns the base64 encoded signature of the payload.
+func Sign(signer crypto.Signer, payload []byte) (string, error) {
+	var sig []byte
+	var err error
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		sig, err = signer.Sign(rand.Reader, payload, crypto.Hash(0))
+	} else {
+		hash := sha256.Sum256(payload)
+		sig, err = signer.Sign(rand.Reader, hash[:], crypto.SHA256)
+	}
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// Verify verifies that signature is a valid signature of payload by publicKey, and that
+// the payload signs the manifest with the given digest.
+func Verify(publicKey crypto.PublicKey, payload []byte, signature, digest string) error {
+	var p Payload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("unable to unmarshal signed payload: %w", err)
+	}
+	if p.Critical.Image.DockerManifestDigest != digest {
+		return fmt.Errorf("payload signs %s, expected %s: %w", p.Critical.Image.DockerManifestDigest, digest, ErrInvalidSignature)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("malformed signature: %w", err)
+	}
+
+	return VerifyMessage(publicKey, payload, sig, RSAPaddingPKCS1v15)
+}
+
+// LoadPrivateKey loads an unencrypted PEM encoded private key, in PKCS #8, SEC 1 (EC) or PKCS #1 (RSA) format.
+// The encrypted keys generated by "cosign generate-key-pair" are not supported.
+func LoadPrivateKey(path string) (crypto.Signer, error) {
+	return LoadPrivateKeyWithPassphrase(path, nil)
+}
+
+// LoadPrivateKeyWithPassphrase loads a PEM encoded private key like LoadPrivateKey, decrypting it with
+// the passphrase if it is encrypted in the legacy PEM format ("Proc-Type: 4,ENCRYPTED"), as generated
+// e.g. by "openssl ec -aes256". The encrypted keys generated by "cosign generate-key-pair" are not supported.
+func LoadPrivateKeyWithPassphrase(path string, passphrase []byte) (crypto.Signer, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read private key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %q", path)
+	}
+
+	der := block.Bytes
+	//nolint:staticcheck // legacy PEM encryption is the only one supported by the standard library
+	if x509.IsEncryptedPEMBlock(block) {
+		if len(passphrase) == 0 {
+			return nil, fmt.Errorf("private key %q is encrypted, a passphrase is required", path)
+		}
+		//nolint:staticcheck // see above
+		if der, err = x509.DecryptPEMBlock(block, passphrase); err != nil {
+			return nil, fmt.Errorf("unable to decrypt private key %q: %w", path, err)
+		}
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(der)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(der)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(der)
+	case "ENCRYPTED COSIGN PRIVATE KEY", "ENCRYPTED SIGSTORE PRIVATE KEY":
+		return nil, fmt.Errorf("encrypted cosign private keys are not supported, use an unencrypted PEM private key")
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q in %q", block.Type, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse private key %q: %w", path, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	return signer, nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testDigest = "sha256:4d9f3c21d7f5e5d7b1c2a8e9f0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3"
+
+func TestSignVerify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := LoadPrivateKey(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	payload, err := NewPayload("ghcr.io/falcosecurity/plugins/plugin/cloudtrail", testDigest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := Sign(signer, payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err = Verify(signer.Public(), payload, sig, testDigest); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err = Verify(signer.Public(), payload, sig, "sha256:0000"); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for a different digest, got %v", err)
+	}
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = Verify(&otherKey.PublicKey, payload, sig, testDigest); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for a different key, got %v", err)
+	}
+}
+
+func TestLoadPrivateKeyEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cosign.key")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED COSIGN PRIVATE KEY", Bytes: []byte("x")}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadPrivateKey(path); err == nil {
+		t.Error("expected error loading an encrypted cosign private key")
+	}
+}
+
+func TestLoadPrivateKeyWithPassphrase(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//nolint:staticcheck // legacy PEM encryption
+	block, err := x509.EncryptPEMBlock(rand.Reader, "EC PRIVATE KEY", der, []byte("secret"), x509.PEMCipherAES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err = os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = LoadPrivateKey(path); err == nil {
+		t.Error("expected error loading an encrypted private key without passphrase")
+	}
+	if _, err = LoadPrivateKeyWithPassphrase(path, []byte("wrong")); err == nil {
+		t.Error("expected error loading an encrypted private key with a wrong passphrase")
+	}
+
+	signer, err := LoadPrivateKeyWithPassphrase(path, []byte("secret"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !key.PublicKey.Equal(signer.Public()) {
+		t.Error("loaded key does not match the original one")
+	}
+}
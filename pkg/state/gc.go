@@ -0,0 +1,56 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+// CheckFiles compares the files of the entry against the disk. It returns the files missing or not accessible,
+// and the files whose digest differs from the recorded one.
+func (e *Entry) CheckFiles() (missing, modified []string) {
+	for k := range e.Files {
+		file := &e.Files[k]
+		current, err := NewFile(file.Path)
+		switch {
+		case err != nil:
+			missing = append(missing, file.Path)
+		case current.Digest != file.Digest:
+			modified = append(modified, file.Path)
+		}
+	}
+
+	return missing, modified
+}
+
+// RemoveDuplicates removes the entries having the same name of another entry, keeping the most recently
+// updated one or, if they were updated at the same time, the last one. It returns the removed entries.
+func (s *State) RemoveDuplicates() []Entry {
+	kept := make(map[string]int, len(s.Entries))
+	for k := range s.Entries {
+		name := s.Entries[k].Name
+		if i, ok := kept[name]; !ok || s.Entries[k].UpdatedTimestamp >= s.Entries[i].UpdatedTimestamp {
+			kept[name] = k
+		}
+	}
+
+	var entries, removed []Entry
+	for k := range s.Entries {
+		if kept[s.Entries[k].Name] == k {
+			entries = append(entries, s.Entries[k])
+		} else {
+			removed = append(removed, s.Entries[k])
+		}
+	}
+	s.Entries = entries
+
+	return removed
+}
@@ -0,0 +1,83 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"fmt"
+)
+
+// HookEvent is a point of the lifecycle of an artifact where hooks are executed.
+type HookEvent string
+
+const (
+	// PreInstall hooks run before an artifact is installed or updated. A failure aborts the operation.
+	PreInstall HookEvent = "pre-install"
+	// PostInstall hooks run after an artifact is installed for the first time.
+	PostInstall HookEvent = "post-install"
+	// PostUpdate hooks run after an already installed artifact is installed again.
+	PostUpdate HookEvent = "post-update"
+)
+
+// String returns a string representation of HookEvent.
+func (e *HookEvent) String() string {
+	return string(*e)
+}
+
+// Set a HookEvent.
+func (e *HookEvent) Set(v string) error {
+	switch v {
+	case string(PreInstall), string(PostInstall), string(PostUpdate):
+		*e = HookEvent(v)
+		return nil
+	default:
+		return fmt.Errorf(`must be one of %q, %q, %q`, PreInstall, PostInstall, PostUpdate)
+	}
+}
+
+// Type returns a string representing this type.
+func (e *HookEvent) Type() string {
+	return "HookEvent"
+}
+
+// Hook is a script executed at a point of the lifecycle of an artifact.
+type Hook struct {
+	Name   string    `json:"name" yaml:"name"`
+	Event  HookEvent `json:"event" yaml:"event"`
+	Script string    `json:"script" yaml:"script"`
+}
+
+// AddHook registers a hook. It returns false if the same hook is already registered.
+func (s *State) AddHook(hook *Hook) bool {
+	for k := range s.Hooks {
+		if s.Hooks[k] == *hook {
+			return false
+		}
+	}
+
+	s.Hooks = append(s.Hooks, *hook)
+	return true
+}
+
+// HooksFor returns the hooks registered for an artifact and an event, in registration order.
+func (s *State) HooksFor(name string, event HookEvent) []Hook {
+	var hooks []Hook
+	for k := range s.Hooks {
+		if s.Hooks[k].Name == name && s.Hooks[k].Event == event {
+			hooks = append(hooks, s.Hooks[k])
+		}
+	}
+
+	return hooks
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AnnotationLock records an artifact manifest whose annotations have been locked.
+type AnnotationLock struct {
+	Ref             string `yaml:"ref"`
+	Digest          string `yaml:"digest"`
+	LockedTimestamp string `yaml:"locked_timestamp"`
+}
+
+// AnnotationLocks is the local registry of the manifests whose annotations must not be modified.
+type AnnotationLocks struct {
+	Locks []AnnotationLock `yaml:"locks"`
+}
+
+// LoadAnnotationLocks loads the annotation locks from a file. No lock is returned if the file does not exist.
+func LoadAnnotationLocks(path string) (*AnnotationLocks, error) {
+	file, err := os.ReadFile(filepath.Clean(path))
+	if os.IsNotExist(err) {
+		return &AnnotationLocks{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var locks AnnotationLocks
+	if err = yaml.Unmarshal(file, &locks); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal annotation locks file %q: %w", path, err)
+	}
+
+	return &locks, nil
+}
+
+// Get returns the lock of the manifest with the given digest, if it is locked.
+func (l *AnnotationLocks) Get(digest string) (*AnnotationLock, bool) {
+	for k := range l.Locks {
+		if l.Locks[k].Digest == digest {
+			return &l.Locks[k], true
+		}
+	}
+
+	return nil, false
+}
+
+// Add locks the manifest with the given digest, if not already locked.
+func (l *AnnotationLocks) Add(ref, digest, timestamp string) {
+	if _, ok := l.Get(digest); ok {
+		return
+	}
+
+	l.Locks = append(l.Locks, AnnotationLock{Ref: ref, Digest: digest, LockedTimestamp: timestamp})
+}
+
+// Remove unlocks the manifest with the given digest.
+func (l *AnnotationLocks) Remove(digest string) {
+	for k := range l.Locks {
+		if l.Locks[k].Digest == digest {
+			l.Locks = append(l.Locks[:k], l.Locks[k+1:]...)
+			return
+		}
+	}
+}
+
+// Write writes the annotation locks to disk, creating the parent directory if needed.
+func (l *AnnotationLocks) Write(path string) error {
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, writePermissions)
+}
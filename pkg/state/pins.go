@@ -0,0 +1,109 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Pin locks an installed artifact to the digest it had when pinned.
+type Pin struct {
+	Name   string `yaml:"name"`
+	Ref    string `yaml:"ref,omitempty"`
+	URL    string `yaml:"url,omitempty"`
+	Digest string `yaml:"digest"`
+}
+
+// Pins is the lock file snapshotting the installed artifacts. While it exists, the pinned
+// artifacts must not be installed with a different digest.
+type Pins struct {
+	PinnedTimestamp string `yaml:"pinned_timestamp"`
+	Artifacts       []Pin  `yaml:"artifacts"`
+}
+
+// NewPins pins all the entries of the State to their current digests.
+func NewPins(s *State, timestamp string) *Pins {
+	pins := &Pins{
+		PinnedTimestamp: timestamp,
+		Artifacts:       make([]Pin, 0, len(s.Entries)),
+	}
+
+	for k := range s.Entries {
+		entry := &s.Entries[k]
+		pins.Artifacts = append(pins.Artifacts, Pin{Name: entry.Name, Ref: entry.Ref, URL: entry.URL, Digest: entry.Digest})
+	}
+
+	return pins
+}
+
+// LoadPins loads the pins from a lock file. Nil is returned if the file does not exist, i.e. nothing is pinned.
+func LoadPins(path string) (*Pins, error) {
+	file, err := os.ReadFile(filepath.Clean(path))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var pins Pins
+	if err = yaml.Unmarshal(file, &pins); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal pins file %q: %w", path, err)
+	}
+
+	return &pins, nil
+}
+
+// Get returns the pin of an artifact, if it is pinned. It is safe to call it on nil Pins.
+func (p *Pins) Get(name string) (*Pin, bool) {
+	if p == nil {
+		return nil, false
+	}
+
+	for k := range p.Artifacts {
+		if p.Artifacts[k].Name == name {
+			return &p.Artifacts[k], true
+		}
+	}
+
+	return nil, false
+}
+
+// Check returns an error if the artifact is pinned to a digest other than the given one.
+func (p *Pins) Check(name, digest string) error {
+	pin, ok := p.Get(name)
+	if !ok || pin.Digest == digest {
+		return nil
+	}
+
+	return fmt.Errorf("%q is pinned to %s since %s, refusing to install %s", name, pin.Digest, p.PinnedTimestamp, digest)
+}
+
+// Write writes the pins to disk, creating the parent directory if needed.
+func (p *Pins) Write(path string) error {
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, writePermissions)
+}
@@ -0,0 +1,169 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File describes a single file installed as part of an artifact.
+type File struct {
+	Path   string      `json:"path" yaml:"path"`
+	Digest string      `json:"digest" yaml:"digest"`
+	Mode   os.FileMode `json:"mode" yaml:"mode"`
+}
+
+// Entry describes an artifact installed by falcoctl.
+type Entry struct {
+	Name               string `json:"name" yaml:"name"`
+	Type               string `json:"type" yaml:"type"`
+	Ref                string `json:"ref,omitempty" yaml:"ref,omitempty"`
+	URL                string `json:"url,omitempty" yaml:"url,omitempty"`
+	Checksum           string `json:"checksum,omitempty" yaml:"checksum,omitempty"`
+	Digest             string `json:"digest" yaml:"digest"`
+	Dir                string `json:"dir" yaml:"dir"`
+	Files              []File `json:"files" yaml:"files"`
+	InstalledTimestamp string `json:"installed_timestamp" yaml:"installed_timestamp"`
+	UpdatedTimestamp   string `json:"updated_timestamp" yaml:"updated_timestamp"`
+}
+
+// State aggregates the entries of all the installed artifacts.
+type State struct {
+	Entries []Entry `json:"entries" yaml:"entries"`
+	Hooks   []Hook  `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+}
+
+// New loads the state from a file. An empty state is returned if the file does not exist.
+func New(path string) (*State, error) {
+	var state State
+	file, err := os.ReadFile(filepath.Clean(path))
+	if os.IsNotExist(err) {
+		return &state, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err = yaml.Unmarshal(file, &state); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal state file %q: %w", path, err)
+	}
+
+	return &state, nil
+}
+
+// Upsert adds a new entry to the State or updates the one having the same name.
+// When updating, the original installation timestamp is preserved.
+func (s *State) Upsert(entry *Entry) {
+	for k := range s.Entries {
+		if s.Entries[k].Name == entry.Name {
+			if entry.InstalledTimestamp == "" {
+				entry.InstalledTimestamp = s.Entries[k].InstalledTimestamp
+			}
+			s.Entries[k] = *entry
+			return
+		}
+	}
+
+	s.Entries = append(s.Entries, *entry)
+}
+
+// Remove removes an entry by name from the State.
+func (s *State) Remove(name string) error {
+	for k := range s.Entries {
+		if s.Entries[k].Name == name {
+			s.Entries = append(s.Entries[:k], s.Entries[k+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("cannot remove %s: not installed", name)
+}
+
+// Get returns a pointer to an entry in the State.
+func (s *State) Get(name string) (*Entry, error) {
+	for k := range s.Entries {
+		if s.Entries[k].Name == name {
+			return &s.Entries[k], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s: not installed", name)
+}
+
+// RenameRepository points the entries installed from the oldRepo repository to the newRepo one, keeping their
+// tags or digests. It returns the names of the updated entries.
+func (s *State) RenameRepository(oldRepo, newRepo string) []string {
+	var renamed []string
+	for k := range s.Entries {
+		ref := s.Entries[k].Ref
+		if ref != oldRepo && !strings.HasPrefix(ref, oldRepo+":") && !strings.HasPrefix(ref, oldRepo+"@") {
+			continue
+		}
+		s.Entries[k].Ref = newRepo + strings.TrimPrefix(ref, oldRepo)
+		renamed = append(renamed, s.Entries[k].Name)
+	}
+
+	return renamed
+}
+
+// Write writes the State to disk, creating the parent directory if needed.
+func (s *State) Write(path string) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, writePermissions)
+}
+
+// NewFile computes the digest and collects the permissions of an installed file.
+func NewFile(path string) (*File, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, f); err != nil {
+		return nil, fmt.Errorf("cannot compute digest of %q: %w", path, err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{
+		Path:   absPath,
+		Digest: fmt.Sprintf("sha256:%x", hash.Sum(nil)),
+		Mode:   info.Mode().Perm(),
+	}, nil
+}
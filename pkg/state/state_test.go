@@ -0,0 +1,294 @@
+// Copyright 2022 The Falco Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpsert(t *testing.T) {
+	s := &State{}
+
+	s.Upsert(&Entry{
+		Name:               "cloudtrail",
+		Ref:                "ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.5.0",
+		InstalledTimestamp: "2022-10-25 15:01:25",
+	})
+
+	if len(s.Entries) != 1 {
+		t.Fatalf("error upsert in state")
+	}
+
+	s.Upsert(&Entry{
+		Name: "cloudtrail",
+		Ref:  "ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.6.0",
+	})
+
+	entry, err := s.Get("cloudtrail")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Entries) != 1 {
+		t.Errorf("upsert added a duplicate entry")
+	}
+
+	if entry.Ref != "ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.6.0" {
+		t.Errorf("upsert didn't modify the entry")
+	}
+
+	if entry.InstalledTimestamp != "2022-10-25 15:01:25" {
+		t.Errorf("upsert didn't preserve the installation timestamp")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	s := &State{}
+
+	s.Upsert(&Entry{Name: "cloudtrail"})
+
+	if err := s.Remove("cloudtrail"); err != nil {
+		t.Error(err)
+	}
+
+	if len(s.Entries) != 0 {
+		t.Errorf("error remove from state")
+	}
+
+	if err := s.Remove("cloudtrail"); err == nil {
+		t.Errorf("expected error removing a not installed artifact")
+	}
+}
+
+func TestWriteAndRead(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "falcoctl", "state.yaml")
+
+	s, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Entries) != 0 {
+		t.Fatalf("expected empty state when the file does not exist")
+	}
+
+	filePath := filepath.Join(dir, "rules.yaml")
+	if err = os.WriteFile(filePath, []byte("- rule: test\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := NewFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(file.Digest, "sha256:") || len(file.Digest) != len("sha256:")+64 {
+		t.Errorf("unexpected digest format %q", file.Digest)
+	}
+
+	s.Upsert(&Entry{
+		Name:  "rules",
+		Type:  "rulesfile",
+		Dir:   dir,
+		Files: []File{*file},
+	})
+
+	if err = s.Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := s.Get("rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entry.Files) != 1 || entry.Files[0].Digest != file.Digest || entry.Files[0].Mode != 0o600 {
+		t.Errorf("state not correctly read back from disk: %+v", entry)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	s := &State{}
+
+	if !s.AddHook(&Hook{Name: "cloudtrail", Event: PreInstall, Script: "/opt/hooks/stop-falco.sh"}) {
+		t.Fatalf("expected hook to be added")
+	}
+	if s.AddHook(&Hook{Name: "cloudtrail", Event: PreInstall, Script: "/opt/hooks/stop-falco.sh"}) {
+		t.Errorf("expected duplicate hook not to be added")
+	}
+	s.AddHook(&Hook{Name: "cloudtrail", Event: PostUpdate, Script: "/opt/hooks/start-falco.sh"})
+	s.AddHook(&Hook{Name: "k8saudit", Event: PreInstall, Script: "/opt/hooks/stop-falco.sh"})
+
+	hooks := s.HooksFor("cloudtrail", PreInstall)
+	if len(hooks) != 1 || hooks[0].Script != "/opt/hooks/stop-falco.sh" {
+		t.Errorf("unexpected pre-install hooks: %+v", hooks)
+	}
+
+	if hooks = s.HooksFor("cloudtrail", PostInstall); len(hooks) != 0 {
+		t.Errorf("unexpected post-install hooks: %+v", hooks)
+	}
+
+	var event HookEvent
+	if err := event.Set("post-update"); err != nil || event != PostUpdate {
+		t.Errorf("unexpected result setting event: %q, %v", event, err)
+	}
+	if err := event.Set("pre-remove"); err == nil {
+		t.Errorf("expected error setting an unknown event")
+	}
+}
+
+func TestPins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "falcoctl-pins.yaml")
+
+	pins, err := LoadPins(path)
+	if err != nil || pins != nil {
+		t.Fatalf("expected no pins without lock file, got %v (%v)", pins, err)
+	}
+	if err = pins.Check("cloudtrail", "sha256:b"); err != nil {
+		t.Errorf("unexpected error without pins: %v", err)
+	}
+
+	s := &State{Entries: []Entry{
+		{Name: "cloudtrail", Ref: "ghcr.io/falcosecurity/plugins/plugin/cloudtrail:0.6.0", Digest: "sha256:a"},
+		{Name: "k8saudit", URL: "https://example.com/k8saudit.tar.gz", Digest: "sha256:c"},
+	}}
+	if err = NewPins(s, "2022-10-25 15:01:25").Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if pins, err = LoadPins(path); err != nil {
+		t.Fatal(err)
+	}
+	if len(pins.Artifacts) != 2 || pins.PinnedTimestamp != "2022-10-25 15:01:25" {
+		t.Fatalf("unexpected pins %+v", pins)
+	}
+
+	if err = pins.Check("cloudtrail", "sha256:a"); err != nil {
+		t.Errorf("unexpected error for the pinned digest: %v", err)
+	}
+	if err = pins.Check("cloudtrail", "sha256:b"); err == nil || !strings.Contains(err.Error(), "pinned") {
+		t.Errorf("expected error for a different digest, got %v", err)
+	}
+	if err = pins.Check("json", "sha256:d"); err != nil {
+		t.Errorf("unexpected error for an artifact not pinned: %v", err)
+	}
+}
+
+func TestGarbageCollect(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rules.yaml")
+	if err := os.WriteFile(path, []byte("- rule: test\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	file, err := NewFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	present := Entry{Name: "rules", Files: []File{*file}}
+	if missing, modified := present.CheckFiles(); len(missing) != 0 || len(modified) != 0 {
+		t.Errorf("unexpected check result: missing %v, modified %v", missing, modified)
+	}
+
+	orphaned := Entry{Name: "cloudtrail", Files: []File{{Path: filepath.Join(dir, "libcloudtrail.so"), Digest: "sha256:a"}}}
+	if missing, _ := orphaned.CheckFiles(); len(missing) != 1 {
+		t.Errorf("expected the file of the entry to be missing, got %v", missing)
+	}
+
+	if err = os.WriteFile(path, []byte("- rule: changed\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	missing, modified := present.CheckFiles()
+	if len(missing) != 0 || len(modified) != 1 || modified[0] != file.Path {
+		t.Errorf("unexpected check result: missing %v, modified %v", missing, modified)
+	}
+
+	s := &State{Entries: []Entry{
+		{Name: "rules", Ref: "old", UpdatedTimestamp: "2022-10-25 15:01:25"},
+		{Name: "cloudtrail"},
+		{Name: "rules", Ref: "new", UpdatedTimestamp: "2022-11-02 10:00:00"},
+		{Name: "rules", Ref: "older", UpdatedTimestamp: "2022-10-01 10:00:00"},
+	}}
+	removed := s.RemoveDuplicates()
+	if len(removed) != 2 || len(s.Entries) != 2 {
+		t.Fatalf("unexpected result removing duplicates: removed %v, kept %v", removed, s.Entries)
+	}
+	if entry, err := s.Get("rules"); err != nil || entry.Ref != "new" {
+		t.Errorf("expected the most recently updated entry to be kept, got %v", entry)
+	}
+}
+
+func TestAnnotationLocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "annotation-locks.yaml")
+
+	locks, err := LoadAnnotationLocks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locks.Locks) != 0 {
+		t.Fatalf("expected no lock without file, got %v", locks.Locks)
+	}
+
+	locks.Add("ghcr.io/myorg/rules/myrules:1.0.0", "sha256:a", "2023-03-01 10:00:00")
+	locks.Add("ghcr.io/myorg/rules/myrules:1.0.0", "sha256:a", "2023-03-02 10:00:00")
+	locks.Add("ghcr.io/myorg/rules/other:1.0.0", "sha256:b", "2023-03-02 10:00:00")
+	if err = locks.Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if locks, err = LoadAnnotationLocks(path); err != nil {
+		t.Fatal(err)
+	}
+	if len(locks.Locks) != 2 {
+		t.Fatalf("expected 2 locks, got %v", locks.Locks)
+	}
+	if lock, ok := locks.Get("sha256:a"); !ok || lock.LockedTimestamp != "2023-03-01 10:00:00" {
+		t.Errorf("expected the first lock to be kept, got %v", lock)
+	}
+
+	locks.Remove("sha256:a")
+	if _, ok := locks.Get("sha256:a"); ok || len(locks.Locks) != 1 {
+		t.Errorf("lock not removed, got %v", locks.Locks)
+	}
+}
+
+func TestRenameRepository(t *testing.T) {
+	s := &State{Entries: []Entry{
+		{Name: "cloudtrail", Ref: "ghcr.io/myorg/plugins/cloudtrail:0.6.0"},
+		{Name: "cloudtrail-rules", Ref: "ghcr.io/myorg/plugins/cloudtrail-rules:0.6.0"},
+		{Name: "pinned", Ref: "ghcr.io/myorg/plugins/cloudtrail@sha256:a"},
+		{Name: "from-url", URL: "https://example.com/rules.tar.gz"},
+	}}
+
+	renamed := s.RenameRepository("ghcr.io/myorg/plugins/cloudtrail", "ghcr.io/myorg/falco/cloudtrail")
+	if len(renamed) != 2 || renamed[0] != "cloudtrail" || renamed[1] != "pinned" {
+		t.Fatalf("unexpected renamed entries %v", renamed)
+	}
+	if s.Entries[0].Ref != "ghcr.io/myorg/falco/cloudtrail:0.6.0" || s.Entries[2].Ref != "ghcr.io/myorg/falco/cloudtrail@sha256:a" {
+		t.Errorf("unexpected references %q, %q", s.Entries[0].Ref, s.Entries[2].Ref)
+	}
+	if s.Entries[1].Ref != "ghcr.io/myorg/plugins/cloudtrail-rules:0.6.0" {
+		t.Errorf("entry of another repository renamed: %q", s.Entries[1].Ref)
+	}
+}